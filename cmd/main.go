@@ -1,92 +1,443 @@
 package main
 
 import (
+	"context"
+	"database/sql"
 	"flag"
 	"fmt"
+	"io"
 	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"syscall"
 	"time"
 
 	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/go-kit/kit/metrics/prometheus"
+	"github.com/go-redis/redis/v8"
+	kitgrpc "github.com/go-kit/kit/transport/grpc"
+	stdprometheus "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 
 	"github.com/oklog/oklog/pkg/group"
 
+	"github.com/hecomp/session-management/internal/config"
 	"github.com/hecomp/session-management/internal/util"
+	"github.com/hecomp/session-management/pkg/auth"
+	"github.com/hecomp/session-management/pkg/file_store"
+	"github.com/hecomp/session-management/pkg/pb"
+	"github.com/hecomp/session-management/pkg/redis_store"
+	"github.com/hecomp/session-management/pkg/sql_store"
+	"github.com/hecomp/session-management/pkg/tlsconfig"
+	"github.com/hecomp/session-management/pkg/token"
 	. "github.com/hecomp/session-management/pkg/in_memory"
 	. "github.com/hecomp/session-management/pkg/repository"
 	"github.com/hecomp/session-management/pkg/session_management"
 )
 
-// SessionInterval parameter controls how frequently expired session data is removed by the
-// background cleanup goroutine
-const SessionInterval = 2 * time.Minute
+// HealthTimeout parameter controls how long a component has to answer the health probes
+const HealthTimeout = 2 * time.Second
 
 func main() {
 
-	// Define our flags. Your service probably won't need to bind listeners for
-	// *all* supported transports, or support both Zipkin and LightStep, and so
-	// on, but we do it here for demonstration purposes.
+	// Define our flags. Every flag can also be set by its SESSION_MGMT_ environment variable
+	// or in the configuration file named by -config, the flags taking precedence.
 	fs := flag.NewFlagSet("sessionManagementSvc", flag.ExitOnError)
-
-	var (
-		httpAddr = fs.String("http_response-addr", ":8081", "HTTP listen address")
-	)
-
 	fs.Usage = util.UsageFor(fs, os.Args[0]+" [flags]")
-	fs.Parse(os.Args[1:])
+	cfg, err := config.Load(fs, os.Args[1:], os.LookupEnv)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
 
 	// Create a single logger, which we'll use and give to other components.
 	var logger log.Logger
 	{
 		logger = log.NewLogfmtLogger(os.Stderr)
+		logger = level.NewFilter(logger, cfg.Log.Filter())
 		logger = log.With(logger, "ts", log.DefaultTimestampUTC)
 		logger = log.With(logger, "caller", log.DefaultCaller)
 	}
 
-	var (
-		inMemStore = NewInMemStore(SessionInterval, logger)
-	)
+	// The stores log every call, which is only useful when debugging.
+	storeLogger := level.Debug(logger)
 
-	var (
-		sessionMgmntRepo = NewSessionMgmntRepository(inMemStore, logger)
-	)
+	// Create the store-level metrics, which are updated by the in-memory store.
+	var storeMetrics Metrics
+	{
+		storeMetrics.ActiveSessions = prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+			Namespace: "session_management",
+			Subsystem: "store",
+			Name:      "active_sessions",
+			Help:      "Number of sessions currently held by the store.",
+		}, []string{})
+		storeMetrics.ExpiredSessions = prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: "session_management",
+			Subsystem: "store",
+			Name:      "expired_sessions_total",
+			Help:      "Total number of sessions removed by the cleanup sweeps.",
+		}, []string{})
+		storeMetrics.CleanupSweeps = prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: "session_management",
+			Subsystem: "store",
+			Name:      "cleanup_sweeps_total",
+			Help:      "Total number of expired-session cleanup sweeps.",
+		}, []string{})
+	}
+
+	// Create the tracer. Spans are only recorded when an exporter is configured, otherwise
+	// the tracer is a no-op and the store and service are not wrapped.
+	var tracer trace.Tracer
+	var tracing bool
+	{
+		var exporter sdktrace.SpanExporter
+		var err error
+		switch cfg.Tracing.Exporter {
+		case "none":
+		case "stdout":
+			exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+		case "otlp":
+			exporter, err = otlptracegrpc.New(context.Background(), otlptracegrpc.WithEndpoint(cfg.Tracing.OTLPEndpoint), otlptracegrpc.WithInsecure())
+		default:
+			logger.Log("trace-exporter", cfg.Tracing.Exporter, "err", "unknown trace exporter")
+			os.Exit(1)
+		}
+		if err != nil {
+			logger.Log("trace-exporter", cfg.Tracing.Exporter, "during", "New", "err", err)
+			os.Exit(1)
+		}
+
+		var tracerProvider trace.TracerProvider = trace.NewNoopTracerProvider()
+		if exporter != nil {
+			sdkProvider := sdktrace.NewTracerProvider(
+				sdktrace.WithBatcher(exporter),
+				sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceNameKey.String("session-management"))),
+			)
+			defer sdkProvider.Shutdown(context.Background())
+			tracerProvider = sdkProvider
+			tracing = true
+		}
+		otel.SetTracerProvider(tracerProvider)
+		otel.SetTextMapPropagator(propagation.TraceContext{})
+		tracer = tracerProvider.Tracer(session_management.TracerName)
+	}
+
+	var jwtIssuerKeys *token.JWTIssuer
+	{
+		switch cfg.Token.Mode {
+		case "opaque":
+		case "jwt":
+			var err error
+			jwtIssuerKeys, err = token.LoadJWTIssuer(cfg.Token.JWTIssuer, cfg.Token.JWTKeys)
+			if err != nil {
+				logger.Log("jwt-keys", cfg.Token.JWTKeys, "err", err)
+				os.Exit(1)
+			}
+		default:
+			logger.Log("token-mode", cfg.Token.Mode, "err", "unknown session token mode")
+			os.Exit(1)
+		}
+	}
+
+	// The revocation list of the jwt token mode is kept by the same backend as the sessions,
+	// so it is shared by the replicas and survives restarts like them. The connection to the
+	// backend, if any, is closed on shutdown once both stores are.
+	var memStore, revocationStore MemStore
+	var backend io.Closer
+	{
+		revoking := jwtIssuerKeys != nil
+		switch cfg.Store.Backend {
+		case "memory":
+			memStore = NewInstrumentedInMemStore(cfg.Session.CleanupInterval, storeMetrics, storeLogger)
+			if revoking {
+				revocationStore = NewInMemStore(cfg.Session.CleanupInterval, storeLogger)
+			}
+		case "sharded":
+			memStore = NewInstrumentedShardedInMemStore(cfg.Store.Shards, cfg.Session.CleanupInterval, storeMetrics, storeLogger)
+			if revoking {
+				revocationStore = NewInMemStore(cfg.Session.CleanupInterval, storeLogger)
+			}
+		case "file":
+			var err error
+			memStore, err = file_store.NewInstrumentedFileStore(cfg.Store.Dir, cfg.Session.CleanupInterval, cfg.Store.SnapshotInterval, storeMetrics, storeLogger)
+			if err != nil {
+				logger.Log("store", cfg.Store.Backend, "during", "Open", "err", err)
+				os.Exit(1)
+			}
+			if revoking {
+				revocationStore, err = file_store.NewFileStore(filepath.Join(cfg.Store.Dir, "revocations"), cfg.Session.CleanupInterval, cfg.Store.SnapshotInterval, storeLogger)
+				if err != nil {
+					logger.Log("store", cfg.Store.Backend, "during", "Open", "err", err)
+					os.Exit(1)
+				}
+			}
+		case "redis":
+			client := redis.NewClient(&redis.Options{Addr: cfg.Store.RedisAddr})
+			if err := client.Ping(context.Background()).Err(); err != nil {
+				logger.Log("store", cfg.Store.Backend, "during", "Ping", "err", err)
+				os.Exit(1)
+			}
+			backend = client
+			memStore = redis_store.NewRedisStore(client, cfg.Store.RedisPrefix, storeLogger)
+			if revoking {
+				revocationStore = redis_store.NewRedisStore(client, cfg.Store.RedisPrefix+"revoked:", storeLogger)
+			}
+		case "sql":
+			db, err := sql.Open(cfg.Store.SQLDriver, cfg.Store.SQLDSN)
+			if err != nil {
+				logger.Log("store", cfg.Store.Backend, "during", "Open", "err", err)
+				os.Exit(1)
+			}
+			backend = db
+			memStore, err = sql_store.NewInstrumentedSQLStore(db, cfg.Store.SQLDriver, cfg.Session.CleanupInterval, storeMetrics, storeLogger)
+			if err != nil {
+				logger.Log("store", cfg.Store.Backend, "during", "Migrate", "err", err)
+				os.Exit(1)
+			}
+			if revoking {
+				revocationStore, err = sql_store.NewRevocationSQLStore(db, cfg.Store.SQLDriver, cfg.Session.CleanupInterval, storeLogger)
+				if err != nil {
+					logger.Log("store", cfg.Store.Backend, "during", "Migrate", "err", err)
+					os.Exit(1)
+				}
+			}
+		default:
+			logger.Log("store", cfg.Store.Backend, "err", "unknown session store backend")
+			os.Exit(1)
+		}
+	}
+
+	// The store has to be reachable for the service to be ready, a stalled cleanup goroutine
+	// is only fixed by a restart.
+	var health *session_management.Health
+	{
+		health = session_management.NewHealth(HealthTimeout)
+		if checker, ok := memStore.(HealthChecker); ok {
+			health.AddReadinessCheck("store", checker.Ping)
+			health.AddLivenessCheck("store_cleanup", checker.CheckCleanup)
+		}
+		if checker, ok := revocationStore.(HealthChecker); ok {
+			health.AddReadinessCheck("revocation_store", checker.Ping)
+			health.AddLivenessCheck("revocation_store_cleanup", checker.CheckCleanup)
+		}
+	}
+
+	if tracing {
+		memStore = NewTracingMemStore(tracer, memStore)
+		if revocationStore != nil {
+			revocationStore = NewTracingMemStore(tracer, revocationStore)
+		}
+	}
+
+	var sessionMgmntRepo SessionMgmntRepository
+	{
+		sessionMgmntRepo = NewSessionMgmntRepository(memStore, logger)
+		if revocationStore != nil {
+			sessionMgmntRepo = NewRevokingSessionMgmntRepository(memStore, revocationStore, logger)
+		}
+	}
+
+	var sessionLimit session_management.SessionLimit
+	{
+		policy, err := session_management.ParseLimitPolicy(cfg.Session.LimitPolicy)
+		if err != nil {
+			logger.Log("session-limit-policy", cfg.Session.LimitPolicy, "err", err)
+			os.Exit(1)
+		}
+		sessionLimit = session_management.SessionLimit{Max: cfg.Session.Limit, Policy: policy}
+	}
+
+	var keyRing *token.KeyRing
+	if cfg.Token.Keys != "" {
+		var err error
+		keyRing, err = token.LoadKeyRing(cfg.Token.Keys)
+		if err != nil {
+			logger.Log("token-keys", cfg.Token.Keys, "err", err)
+			os.Exit(1)
+		}
+	}
+
+	// Without API keys the HTTP API is open to anyone who can reach the listener.
+	var apiKeys *auth.KeyStore
+	if cfg.Auth.APIKeys != "" {
+		var err error
+		apiKeys, err = auth.LoadKeyStore(cfg.Auth.APIKeys)
+		if err != nil {
+			logger.Log("api-keys", cfg.Auth.APIKeys, "err", err)
+			os.Exit(1)
+		}
+	} else {
+		level.Warn(logger).Log("msg", "no API keys configured, the HTTP API is not authenticated")
+	}
+
+	// With a certificate the listeners serve TLS, and with a client CA bundle they only
+	// accept the clients presenting a certificate it verifies. The files are reloaded when
+	// they change on disk.
+	var tlsReloader *tlsconfig.Reloader
+	if cfg.TLS.Enabled() {
+		var err error
+		tlsReloader, err = tlsconfig.NewReloader(cfg.TLS.CertFile, cfg.TLS.KeyFile, cfg.TLS.ClientCAFile, cfg.TLS.ReloadInterval, log.With(logger, "component", "tls"))
+		if err != nil {
+			logger.Log("tls-cert", cfg.TLS.CertFile, "err", err)
+			os.Exit(1)
+		}
+		defer tlsReloader.StopReload()
+	}
 
 	var sessionMgmnt session_management.SessionMgmntService
 	{
-		sessionMgmnt = session_management.NewService(sessionMgmntRepo, logger)
+		sessionMgmnt = session_management.NewServiceWithTTL(sessionMgmntRepo, sessionLimit, session_management.SessionTTL{
+			Default: cfg.Session.DefaultTTL,
+			Max:     cfg.Session.MaxTTL,
+		}, logger)
+		if keyRing != nil {
+			sessionMgmnt = session_management.NewSigningService(keyRing, sessionMgmnt)
+		}
+		if jwtIssuerKeys != nil {
+			sessionMgmnt = session_management.NewJWTService(jwtIssuerKeys, sessionMgmntRepo, sessionMgmnt)
+		}
 		sessionMgmnt = session_management.NewLoggingService(log.With(logger, "component", "sessionMgmnt"), sessionMgmnt)
+		sessionMgmnt = session_management.NewInstrumentingService(
+			prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+				Namespace: "session_management",
+				Subsystem: "service",
+				Name:      "request_count",
+				Help:      "Number of requests received.",
+			}, []string{"method", "error"}),
+			prometheus.NewHistogramFrom(stdprometheus.HistogramOpts{
+				Namespace: "session_management",
+				Subsystem: "service",
+				Name:      "request_latency_seconds",
+				Help:      "Total duration of requests in seconds.",
+				Buckets:   stdprometheus.DefBuckets,
+			}, []string{"method", "error"}),
+			sessionMgmnt,
+		)
+		if tracing {
+			sessionMgmnt = session_management.NewTracingService(tracer, sessionMgmnt)
+		}
 	}
 
 	var (
-		httpHandler = session_management.MakeHandler(sessionMgmnt)
+		httpHandler = http.NewServeMux()
+		grpcServer  = session_management.NewGRPCServer(sessionMgmnt)
 	)
+	{
+		httpHandler.Handle("/", session_management.MakeHandler(sessionMgmnt, tracer, apiKeys))
+		httpHandler.Handle("/v2/", session_management.MakeV2Handler(sessionMgmnt, tracer, apiKeys))
+		httpHandler.Handle("/metrics", promhttp.Handler())
+		healthHandler := session_management.MakeHealthHandler(health)
+		httpHandler.Handle(session_management.LivenessPath, healthHandler)
+		httpHandler.Handle(session_management.ReadinessPath, healthHandler)
+		if jwtIssuerKeys != nil {
+			jwtHandler := session_management.MakeJWTHandler(jwtIssuerKeys, sessionMgmntRepo)
+			httpHandler.Handle(session_management.JWKSPath, jwtHandler)
+			httpHandler.Handle(session_management.RevocationsPath, jwtHandler)
+		}
+	}
 
 	var g group.Group
 	{
 		// The HTTP listener mounts the Go kit HTTP handler we created.
-		httpListener, err := net.Listen("tcp", *httpAddr)
+		// On shutdown it stops accepting connections and waits for the in-flight requests
+		// until the shutdown timeout.
+		httpListener, err := net.Listen("tcp", cfg.Listen.HTTP)
 		if err != nil {
 			logger.Log("transport", "HTTP", "during", "Listen", "err", err)
 			os.Exit(1)
 		}
+		httpServer := &http.Server{Handler: httpHandler}
+		if tlsReloader != nil {
+			httpServer.TLSConfig = tlsReloader.ServerConfig()
+		}
 		g.Add(func() error {
-			logger.Log("transport", "HTTP", "addr", *httpAddr)
-			return http.Serve(httpListener, httpHandler)
+			logger.Log("transport", "HTTP", "addr", cfg.Listen.HTTP, "tls", tlsReloader != nil)
+			var err error
+			if tlsReloader != nil {
+				err = httpServer.ServeTLS(httpListener, "", "")
+			} else {
+				err = httpServer.Serve(httpListener)
+			}
+			if err != http.ErrServerClosed {
+				return err
+			}
+			return nil
 		}, func(error) {
-			httpListener.Close()
+			ctx, cancel := context.WithTimeout(context.Background(), cfg.Shutdown.Timeout)
+			defer cancel()
+			if err := httpServer.Shutdown(ctx); err != nil {
+				logger.Log("transport", "HTTP", "during", "Shutdown", "err", err)
+				httpServer.Close()
+			}
 		})
 	}
 	{
-		// This function just sits and waits for ctrl-C.
+		// The gRPC listener mounts the Go kit gRPC server we created.
+		// On shutdown it waits for the in-flight calls until the shutdown timeout.
+		grpcListener, err := net.Listen("tcp", cfg.Listen.GRPC)
+		if err != nil {
+			logger.Log("transport", "gRPC", "during", "Listen", "err", err)
+			os.Exit(1)
+		}
+		options := []grpc.ServerOption{
+			grpc.ChainUnaryInterceptor(
+				kitgrpc.Interceptor,
+				session_management.ClientCertificateInterceptor,
+				session_management.AuthenticateInterceptor(apiKeys),
+			),
+		}
+		if tlsReloader != nil {
+			options = append(options, grpc.Creds(credentials.NewTLS(tlsReloader.ServerConfig())))
+		}
+		baseServer := grpc.NewServer(options...)
+		pb.RegisterSessionManagementServer(baseServer, grpcServer)
+		g.Add(func() error {
+			logger.Log("transport", "gRPC", "addr", cfg.Listen.GRPC, "tls", tlsReloader != nil)
+			return baseServer.Serve(grpcListener)
+		}, func(error) {
+			stopped := make(chan struct{})
+			go func() {
+				baseServer.GracefulStop()
+				close(stopped)
+			}()
+			select {
+			case <-stopped:
+			case <-time.After(cfg.Shutdown.Timeout):
+				logger.Log("transport", "gRPC", "during", "GracefulStop", "err", "shutdown timeout exceeded")
+				baseServer.Stop()
+			}
+		})
+	}
+	{
+		// This function just sits and waits for ctrl-C. On a signal the service reports
+		// not ready for the drain delay, so the load balancers stop sending requests
+		// before the listeners close.
 		cancelInterrupt := make(chan struct{})
 		g.Add(func() error {
 			c := make(chan os.Signal, 1)
 			signal.Notify(c, syscall.SIGINT, syscall.SIGTERM)
 			select {
 			case sig := <-c:
+				health.Drain()
+				logger.Log("signal", sig, "msg", "draining", "delay", cfg.Shutdown.Drain)
+				select {
+				case <-time.After(cfg.Shutdown.Drain):
+				case <-c:
+				case <-cancelInterrupt:
+				}
 				return fmt.Errorf("received signal %s", sig)
 			case <-cancelInterrupt:
 				return nil
@@ -96,6 +447,24 @@ func main() {
 		})
 	}
 	logger.Log("exit", g.Run())
+
+	// The listeners are closed, the stores stop their background goroutines and flush
+	// their final state.
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Shutdown.Timeout)
+	defer cancel()
+	if err := memStore.Close(ctx); err != nil {
+		logger.Log("store", cfg.Store.Backend, "during", "Close", "err", err)
+	}
+	if revocationStore != nil {
+		if err := revocationStore.Close(ctx); err != nil {
+			logger.Log("store", cfg.Store.Backend, "during", "Close", "err", err)
+		}
+	}
+	if backend != nil {
+		if err := backend.Close(); err != nil {
+			logger.Log("store", cfg.Store.Backend, "during", "Close", "err", err)
+		}
+	}
 }
 
 
@@ -0,0 +1,7 @@
+//go:build sqlite
+// +build sqlite
+
+package main
+
+// The sqlite3 driver of the sql store needs cgo, so it is only built in with the sqlite tag.
+import _ "github.com/mattn/go-sqlite3"
@@ -3,12 +3,24 @@ module github.com/hecomp/session-management
 go 1.14
 
 require (
+	github.com/alicebob/miniredis/v2 v2.17.0
 	github.com/go-kit/kit v0.11.0
+	github.com/go-redis/redis/v8 v8.11.0
 	github.com/google/uuid v1.1.2
+	github.com/mattn/go-sqlite3 v1.14.8
 	github.com/maxbrunsfeld/counterfeiter/v6 v6.4.1 // indirect
 	github.com/oklog/oklog v0.3.2
 	github.com/oklog/run v1.1.0 // indirect
 	github.com/onsi/ginkgo v1.16.4
 	github.com/onsi/gomega v1.11.0
+	github.com/prometheus/client_golang v1.11.0
+	go.opentelemetry.io/otel v1.0.0
+	go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc v1.0.0
+	go.opentelemetry.io/otel/exporters/stdout/stdouttrace v1.0.0
+	go.opentelemetry.io/otel/sdk v1.0.0
+	go.opentelemetry.io/otel/trace v1.0.0
 	golang.org/x/sys v0.0.0-20210630005230-0f9fa26af87c // indirect
+	google.golang.org/grpc v1.40.0
+	google.golang.org/protobuf v1.27.1
+	gopkg.in/yaml.v2 v2.4.0
 )
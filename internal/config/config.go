@@ -0,0 +1,374 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/go-kit/kit/log/level"
+	"gopkg.in/yaml.v2"
+
+	"github.com/hecomp/session-management/pkg/in_memory"
+	"github.com/hecomp/session-management/pkg/redis_store"
+	"github.com/hecomp/session-management/pkg/session_management"
+)
+
+const (
+	// EnvPrefix prefixes the environment variable of every flag: -store-dir is read from
+	// SESSION_MGMT_STORE_DIR
+	EnvPrefix = "SESSION_MGMT_"
+	// deprecatedHTTPAddr is the name the -http-addr flag had before the configuration
+	// file, it is kept so that existing deployments keep working
+	deprecatedHTTPAddr = "http_response-addr"
+)
+
+// Config holds every setting of the service. The settings are read, by increasing
+// precedence, from their defaults, the configuration file, the environment and the flags.
+type Config struct {
+	Listen   Listen   `yaml:"listen"`
+	Session  Session  `yaml:"session"`
+	Store    Store    `yaml:"store"`
+	Token    Token    `yaml:"token"`
+	Auth     Auth     `yaml:"auth"`
+	TLS      TLS      `yaml:"tls"`
+	Tracing  Tracing  `yaml:"tracing"`
+	Log      Log      `yaml:"log"`
+	Shutdown Shutdown `yaml:"shutdown"`
+}
+
+// Listen holds the listen addresses of the transports
+type Listen struct {
+	HTTP string `yaml:"http"`
+	GRPC string `yaml:"grpc"`
+}
+
+// Session holds the TTL defaults and caps, in seconds, and the per-subject session limit
+type Session struct {
+	DefaultTTL      int64         `yaml:"default_ttl"`
+	MaxTTL          int64         `yaml:"max_ttl"`
+	CleanupInterval time.Duration `yaml:"cleanup_interval"`
+	Limit           int           `yaml:"limit"`
+	LimitPolicy     string        `yaml:"limit_policy"`
+}
+
+// Store holds the backend selection and the settings of each backend
+type Store struct {
+	Backend          string        `yaml:"backend"`
+	Dir              string        `yaml:"dir"`
+	SnapshotInterval time.Duration `yaml:"snapshot_interval"`
+	Shards           int           `yaml:"shards"`
+	RedisAddr        string        `yaml:"redis_addr"`
+	RedisPrefix      string        `yaml:"redis_prefix"`
+	SQLDriver        string        `yaml:"sql_driver"`
+	SQLDSN           string        `yaml:"sql_dsn"`
+}
+
+// Token holds the kind of session tokens handed out and their keys
+type Token struct {
+	Mode      string `yaml:"mode"`
+	Keys      string `yaml:"keys"`
+	JWTKeys   string `yaml:"jwt_keys"`
+	JWTIssuer string `yaml:"jwt_issuer"`
+}
+
+// Auth holds the API keys of the HTTP API
+type Auth struct {
+	APIKeys string `yaml:"api_keys"`
+}
+
+// TLS holds the certificate of the listeners and the CA bundle verifying the client
+// certificates of mutual TLS
+type TLS struct {
+	CertFile       string        `yaml:"cert_file"`
+	KeyFile        string        `yaml:"key_file"`
+	ClientCAFile   string        `yaml:"client_ca_file"`
+	ReloadInterval time.Duration `yaml:"reload_interval"`
+}
+
+// Enabled reports whether the listeners serve TLS
+func (t TLS) Enabled() bool {
+	return t.CertFile != ""
+}
+
+// Tracing holds the exporter of the request spans
+type Tracing struct {
+	Exporter     string `yaml:"exporter"`
+	OTLPEndpoint string `yaml:"otlp_endpoint"`
+}
+
+// Log holds the lowest level of the records written to the log
+type Log struct {
+	Level string `yaml:"level"`
+}
+
+// Filter returns the option of a level.NewFilter logger letting the records of the level
+// and above through, the records without a level are always let through.
+func (l Log) Filter() level.Option {
+	switch l.Level {
+	case "debug":
+		return level.AllowDebug()
+	case "warn":
+		return level.AllowWarn()
+	case "error":
+		return level.AllowError()
+	}
+	return level.AllowInfo()
+}
+
+// Shutdown holds the delays of a graceful shutdown
+type Shutdown struct {
+	Drain   time.Duration `yaml:"drain"`
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// Default returns the configuration used when nothing is set
+func Default() Config {
+	return Config{
+		Listen: Listen{HTTP: ":8081", GRPC: ":8082"},
+		Session: Session{
+			DefaultTTL:      session_management.DefaultTime,
+			MaxTTL:          session_management.MaxTTL,
+			CleanupInterval: time.Second,
+			LimitPolicy:     string(session_management.PolicyReject),
+		},
+		Store: Store{
+			Backend:          "memory",
+			Dir:              "data",
+			SnapshotInterval: 5 * time.Minute,
+			Shards:           in_memory.DefaultShards,
+			RedisAddr:        "localhost:6379",
+			RedisPrefix:      redis_store.DefaultPrefix,
+			SQLDriver:        "sqlite3",
+			SQLDSN:           "sessions.db",
+		},
+		Token:    Token{Mode: "opaque", JWTIssuer: "session-management"},
+		TLS:      TLS{ReloadInterval: 30 * time.Second},
+		Tracing:  Tracing{Exporter: "none", OTLPEndpoint: "localhost:4317"},
+		Log:      Log{Level: "info"},
+		Shutdown: Shutdown{Drain: 5 * time.Second, Timeout: 15 * time.Second},
+	}
+}
+
+// RegisterFlags registers a flag for every setting of the configuration on the flag set,
+// along with the -config flag naming the configuration file.
+func (c *Config) RegisterFlags(fs *flag.FlagSet) *string {
+	configFile := fs.String("config", "", "YAML or JSON configuration file, the flags and the environment override its settings")
+
+	fs.StringVar(&c.Listen.HTTP, "http-addr", c.Listen.HTTP, "HTTP listen address")
+	fs.StringVar(&c.Listen.GRPC, "grpc-addr", c.Listen.GRPC, "gRPC listen address")
+
+	fs.Int64Var(&c.Session.DefaultTTL, "session-default-ttl", c.Session.DefaultTTL, "TTL in seconds of the sessions created or extended without one")
+	fs.Int64Var(&c.Session.MaxTTL, "session-max-ttl", c.Session.MaxTTL, "maximum TTL in seconds of a session extension")
+	fs.DurationVar(&c.Session.CleanupInterval, "session-cleanup-interval", c.Session.CleanupInterval, "minimum delay between two runs of the expired-session cleanup")
+	fs.IntVar(&c.Session.Limit, "session-limit", c.Session.Limit, "maximum number of concurrent sessions per subject, 0 means unlimited")
+	fs.StringVar(&c.Session.LimitPolicy, "session-limit-policy", c.Session.LimitPolicy, "what to do when a subject reaches the session limit: reject, evict-oldest or evict-lru")
+
+	fs.StringVar(&c.Store.Backend, "store", c.Store.Backend, "session store backend: memory, sharded, file, redis or sql")
+	fs.StringVar(&c.Store.Dir, "store-dir", c.Store.Dir, "directory used by the file store for its write-ahead log and snapshots")
+	fs.DurationVar(&c.Store.SnapshotInterval, "store-snapshot-interval", c.Store.SnapshotInterval, "how frequently the file store compacts its write-ahead log into a snapshot")
+	fs.IntVar(&c.Store.Shards, "store-shards", c.Store.Shards, "number of independently locked shards of the sharded store")
+	fs.StringVar(&c.Store.RedisAddr, "redis-addr", c.Store.RedisAddr, "address of the Redis server used by the redis store")
+	fs.StringVar(&c.Store.RedisPrefix, "redis-prefix", c.Store.RedisPrefix, "prefix of the keys written by the redis store")
+	fs.StringVar(&c.Store.SQLDriver, "sql-driver", c.Store.SQLDriver, "database/sql driver used by the sql store, it must be built in")
+	fs.StringVar(&c.Store.SQLDSN, "sql-dsn", c.Store.SQLDSN, "data source name of the database used by the sql store")
+
+	fs.StringVar(&c.Token.Mode, "token-mode", c.Token.Mode, "session tokens handed out: opaque session ids, signed with -token-keys when set, or jwt")
+	fs.StringVar(&c.Token.Keys, "token-keys", c.Token.Keys, "JSON key ring file used to sign session tokens, empty hands out unsigned session ids")
+	fs.StringVar(&c.Token.JWTKeys, "jwt-keys", c.Token.JWTKeys, "JSON file naming the ECDSA P-256 PEM keys that sign the JWTs of the jwt token mode")
+	fs.StringVar(&c.Token.JWTIssuer, "jwt-issuer", c.Token.JWTIssuer, "iss claim of the JWTs of the jwt token mode")
+
+	fs.StringVar(&c.Auth.APIKeys, "api-keys", c.Auth.APIKeys, "JSON file of the hashed API keys and scopes required by the HTTP API, empty leaves it open")
+
+	fs.StringVar(&c.TLS.CertFile, "tls-cert", c.TLS.CertFile, "PEM certificate served by the HTTP and gRPC listeners, empty serves plain text")
+	fs.StringVar(&c.TLS.KeyFile, "tls-key", c.TLS.KeyFile, "PEM private key of the -tls-cert certificate")
+	fs.StringVar(&c.TLS.ClientCAFile, "tls-client-ca", c.TLS.ClientCAFile, "PEM CA bundle the client certificates must be signed by, empty does not request client certificates")
+	fs.DurationVar(&c.TLS.ReloadInterval, "tls-reload-interval", c.TLS.ReloadInterval, "how often the TLS files are checked for changes and reloaded, 0 disables the reload")
+
+	fs.StringVar(&c.Tracing.Exporter, "trace-exporter", c.Tracing.Exporter, "exporter of the request spans: none, stdout or otlp")
+	fs.StringVar(&c.Tracing.OTLPEndpoint, "otlp-endpoint", c.Tracing.OTLPEndpoint, "address of the OTLP gRPC collector used by the otlp trace exporter")
+
+	fs.StringVar(&c.Log.Level, "log-level", c.Log.Level, "lowest level of the records written to the log: debug, info, warn or error")
+
+	fs.DurationVar(&c.Shutdown.Drain, "shutdown-drain", c.Shutdown.Drain, "how long the service reports not ready on shutdown before closing its listeners")
+	fs.DurationVar(&c.Shutdown.Timeout, "shutdown-timeout", c.Shutdown.Timeout, "how long the in-flight requests and the store flush have to complete on shutdown")
+
+	fs.StringVar(&c.Listen.HTTP, deprecatedHTTPAddr, c.Listen.HTTP, "deprecated, use -http-addr")
+
+	return configFile
+}
+
+// Load returns the configuration of the command line args: the defaults are overridden
+// by the configuration file named by -config or SESSION_MGMT_CONFIG, then by the
+// environment variables of the flags and finally by the flags set in args. The
+// configuration is validated before it is returned.
+func Load(fs *flag.FlagSet, args []string, lookupEnv func(string) (string, bool)) (Config, error) {
+	c := Default()
+	configFile := c.RegisterFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return c, err
+	}
+
+	// The flags set in args are written down before the file and the environment
+	// overwrite their values, to be applied again last.
+	set := make(map[string]string)
+	fs.Visit(func(f *flag.Flag) {
+		set[f.Name] = f.Value.String()
+	})
+
+	path := *configFile
+	if path == "" {
+		path, _ = lookupEnv(EnvName("config"))
+	}
+	if path != "" {
+		if err := c.loadFile(path); err != nil {
+			return c, err
+		}
+	}
+
+	var err error
+	fs.VisitAll(func(f *flag.Flag) {
+		if f.Name == "config" || f.Name == deprecatedHTTPAddr || err != nil {
+			return
+		}
+		if value, found := lookupEnv(EnvName(f.Name)); found {
+			if setErr := fs.Set(f.Name, value); setErr != nil {
+				err = fmt.Errorf("invalid value %q for %s: %v", value, EnvName(f.Name), setErr)
+			}
+		}
+	})
+	if err != nil {
+		return c, err
+	}
+
+	for name, value := range set {
+		if err := fs.Set(name, value); err != nil {
+			return c, err
+		}
+	}
+
+	return c, c.Validate()
+}
+
+// EnvName returns the environment variable of the flag
+func EnvName(flagName string) string {
+	return EnvPrefix + strings.ToUpper(strings.NewReplacer("-", "_").Replace(flagName))
+}
+
+// loadFile overrides the configuration with the settings of the YAML or JSON file, a
+// JSON document being valid YAML. Unknown settings are reported.
+func (c *Config) loadFile(path string) error {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := yaml.UnmarshalStrict(b, c); err != nil {
+		return fmt.Errorf("configuration file %s: %v", filepath.Base(path), err)
+	}
+	return nil
+}
+
+// ValidationError lists every invalid setting of a configuration
+type ValidationError []string
+
+func (e ValidationError) Error() string {
+	return "invalid configuration: " + strings.Join(e, "; ")
+}
+
+// Validate reports every invalid setting of the configuration as a ValidationError
+func (c Config) Validate() error {
+	var errs ValidationError
+	invalid := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Sprintf(format, args...))
+	}
+	oneOf := func(name, value string, allowed ...string) {
+		for _, a := range allowed {
+			if value == a {
+				return
+			}
+		}
+		invalid("%s must be one of %s, got %q", name, strings.Join(allowed, ", "), value)
+	}
+
+	if c.Listen.HTTP == "" {
+		invalid("listen.http must be set")
+	}
+	if c.Listen.GRPC == "" {
+		invalid("listen.grpc must be set")
+	}
+
+	if c.Session.DefaultTTL <= 0 {
+		invalid("session.default_ttl must be positive, got %d", c.Session.DefaultTTL)
+	}
+	if c.Session.MaxTTL < c.Session.DefaultTTL {
+		invalid("session.max_ttl must be at least session.default_ttl, got %d", c.Session.MaxTTL)
+	}
+	if c.Session.CleanupInterval <= 0 {
+		invalid("session.cleanup_interval must be positive, got %s", c.Session.CleanupInterval)
+	}
+	if c.Session.Limit < 0 {
+		invalid("session.limit must not be negative, got %d", c.Session.Limit)
+	}
+	if _, err := session_management.ParseLimitPolicy(c.Session.LimitPolicy); err != nil {
+		invalid("session.limit_policy: %v", err)
+	}
+
+	oneOf("store.backend", c.Store.Backend, "memory", "sharded", "file", "redis", "sql")
+	if c.Store.SnapshotInterval < 0 {
+		invalid("store.snapshot_interval must not be negative, got %s", c.Store.SnapshotInterval)
+	}
+	if c.Store.Shards <= 0 {
+		invalid("store.shards must be positive, got %d", c.Store.Shards)
+	}
+	switch c.Store.Backend {
+	case "file":
+		if c.Store.Dir == "" {
+			invalid("store.dir must be set for the file store")
+		}
+	case "redis":
+		if c.Store.RedisAddr == "" {
+			invalid("store.redis_addr must be set for the redis store")
+		}
+	case "sql":
+		if c.Store.SQLDriver == "" || c.Store.SQLDSN == "" {
+			invalid("store.sql_driver and store.sql_dsn must be set for the sql store")
+		}
+	}
+
+	oneOf("token.mode", c.Token.Mode, "opaque", "jwt")
+	if c.Token.Mode == "jwt" {
+		if c.Token.JWTKeys == "" {
+			invalid("token.jwt_keys must be set for the jwt token mode")
+		}
+		if c.Token.Keys != "" {
+			invalid("token.keys only applies to opaque session tokens")
+		}
+	}
+
+	if (c.TLS.CertFile == "") != (c.TLS.KeyFile == "") {
+		invalid("tls.cert_file and tls.key_file must be set together")
+	}
+	if c.TLS.ClientCAFile != "" && c.TLS.CertFile == "" {
+		invalid("tls.client_ca_file requires tls.cert_file")
+	}
+	if c.TLS.ReloadInterval < 0 {
+		invalid("tls.reload_interval must not be negative, got %s", c.TLS.ReloadInterval)
+	}
+
+	oneOf("tracing.exporter", c.Tracing.Exporter, "none", "stdout", "otlp")
+	oneOf("log.level", c.Log.Level, "debug", "info", "warn", "error")
+
+	if c.Shutdown.Drain < 0 {
+		invalid("shutdown.drain must not be negative, got %s", c.Shutdown.Drain)
+	}
+	if c.Shutdown.Timeout <= 0 {
+		invalid("shutdown.timeout must be positive, got %s", c.Shutdown.Timeout)
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package config_test
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/hecomp/session-management/internal/config"
+)
+
+var _ = Describe("Config", func() {
+
+	var dir string
+	var env map[string]string
+
+	lookupEnv := func(name string) (string, bool) {
+		value, found := env[name]
+		return value, found
+	}
+
+	load := func(args ...string) (config.Config, error) {
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		fs.SetOutput(ioutil.Discard)
+		return config.Load(fs, args, lookupEnv)
+	}
+
+	writeFile := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		Expect(ioutil.WriteFile(path, []byte(content), 0600)).To(Succeed())
+		return path
+	}
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "config")
+		Expect(err).To(BeNil())
+		env = make(map[string]string)
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	Context("Load()", func() {
+		It("returns the defaults when nothing is set", func() {
+			cfg, err := load()
+			Expect(err).To(BeNil())
+			Expect(cfg).To(Equal(config.Default()))
+		})
+		It("reads a YAML configuration file", func() {
+			path := writeFile("config.yaml", `
+listen:
+  http: ":9081"
+session:
+  default_ttl: 60
+  max_ttl: 600
+  cleanup_interval: 5s
+store:
+  backend: file
+  dir: /var/lib/sessions
+log:
+  level: debug
+`)
+			cfg, err := load("-config", path)
+			Expect(err).To(BeNil())
+			Expect(cfg.Listen.HTTP).To(Equal(":9081"))
+			Expect(cfg.Listen.GRPC).To(Equal(config.Default().Listen.GRPC))
+			Expect(cfg.Session.DefaultTTL).To(Equal(int64(60)))
+			Expect(cfg.Session.MaxTTL).To(Equal(int64(600)))
+			Expect(cfg.Session.CleanupInterval).To(Equal(5 * time.Second))
+			Expect(cfg.Store.Backend).To(Equal("file"))
+			Expect(cfg.Store.Dir).To(Equal("/var/lib/sessions"))
+			Expect(cfg.Log.Level).To(Equal("debug"))
+		})
+		It("reads a JSON configuration file named by the environment", func() {
+			env["SESSION_MGMT_CONFIG"] = writeFile("config.json", `{"store": {"backend": "sharded", "shards": 8}}`)
+			cfg, err := load()
+			Expect(err).To(BeNil())
+			Expect(cfg.Store.Backend).To(Equal("sharded"))
+			Expect(cfg.Store.Shards).To(Equal(8))
+		})
+		It("reports the unknown settings of the file", func() {
+			path := writeFile("config.yaml", "store:\n  backend: memory\n  bakend: redis\n")
+			_, err := load("-config", path)
+			Expect(err).ToNot(BeNil())
+			Expect(err.Error()).To(ContainSubstring("bakend"))
+		})
+		It("overrides the file with the environment and the environment with the flags", func() {
+			path := writeFile("config.yaml", "listen:\n  http: \":9081\"\n  grpc: \":9082\"\nstore:\n  backend: redis\n")
+			env["SESSION_MGMT_HTTP_ADDR"] = ":10081"
+			env["SESSION_MGMT_GRPC_ADDR"] = ":10082"
+			env["SESSION_MGMT_SHUTDOWN_DRAIN"] = "1s"
+			cfg, err := load("-config", path, "-grpc-addr", ":11082")
+			Expect(err).To(BeNil())
+			Expect(cfg.Store.Backend).To(Equal("redis"))
+			Expect(cfg.Listen.HTTP).To(Equal(":10081"))
+			Expect(cfg.Listen.GRPC).To(Equal(":11082"))
+			Expect(cfg.Shutdown.Drain).To(Equal(time.Second))
+		})
+		It("keeps the deprecated HTTP listen address flag", func() {
+			cfg, err := load("-http_response-addr", ":9081")
+			Expect(err).To(BeNil())
+			Expect(cfg.Listen.HTTP).To(Equal(":9081"))
+		})
+		It("reports an invalid environment variable", func() {
+			env["SESSION_MGMT_STORE_SHARDS"] = "many"
+			_, err := load()
+			Expect(err).ToNot(BeNil())
+			Expect(err.Error()).To(ContainSubstring("SESSION_MGMT_STORE_SHARDS"))
+		})
+		It("reports every invalid setting", func() {
+			_, err := load("-session-default-ttl", "0", "-store", "etcd", "-token-mode", "jwt", "-log-level", "trace")
+			Expect(err).To(BeAssignableToTypeOf(config.ValidationError{}))
+			Expect(err.(config.ValidationError)).To(ConsistOf(
+				"session.default_ttl must be positive, got 0",
+				"store.backend must be one of memory, sharded, file, redis, sql, got \"etcd\"",
+				"token.jwt_keys must be set for the jwt token mode",
+				"log.level must be one of debug, info, warn, error, got \"trace\"",
+			))
+		})
+		It("reports an incomplete TLS configuration", func() {
+			_, err := load("-tls-key", "server.key", "-tls-client-ca", "clients.pem")
+			Expect(err).To(BeAssignableToTypeOf(config.ValidationError{}))
+			Expect(err.(config.ValidationError)).To(ConsistOf(
+				"tls.cert_file and tls.key_file must be set together",
+				"tls.client_ca_file requires tls.cert_file",
+			))
+		})
+	})
+
+	Context("EnvName()", func() {
+		It("returns the environment variable of a flag", func() {
+			Expect(config.EnvName("store-dir")).To(Equal("SESSION_MGMT_STORE_DIR"))
+		})
+	})
+})
@@ -1,20 +1,66 @@
 package models
 
+import "time"
+
 // Item
 type Item struct {
 	Oject      []byte
 	Expiration int64
+	Subject    string
+	LastAccess int64
+	// IdleTimeout in nanoseconds expires the session that long after its LastAccess and
+	// slides the Expiration on every lookup, zero disables it
+	IdleTimeout int64
+	// MaxExpiration is the absolute UnixNano the Expiration can never pass, zero disables it
+	MaxExpiration int64
+	// CreatedAt is the UnixNano the session was created at. The stores set it to the commit
+	// time when it is zero, it stays zero for the sessions stored before it was recorded.
+	CreatedAt int64
+}
+
+// Deadline returns the UnixNano the item expires at: its Expiration, its MaxExpiration or
+// the end of its IdleTimeout after the LastAccess, whichever comes first
+func (item Item) Deadline() int64 {
+	deadline := item.Expiration
+	if item.MaxExpiration > 0 && item.MaxExpiration < deadline {
+		deadline = item.MaxExpiration
+	}
+	if item.IdleTimeout > 0 && item.LastAccess+item.IdleTimeout < deadline {
+		deadline = item.LastAccess + item.IdleTimeout
+	}
+	return deadline
+}
+
+// Record represents the session metadata and payload kept in Item.Oject. ExpiresAt is
+// the expiration the session was created with, which is the exp of its JWT. The record of
+// a rotated session id is a tombstone whose RotatedTo names the session id replacing it.
+type Record struct {
+	SessionId string                 `json:"session_id"`
+	Subject   string                 `json:"subject,omitempty"`
+	CreatedAt int64                  `json:"created_at"`
+	ExpiresAt int64                  `json:"expires_at,omitempty"`
+	RotatedTo string                 `json:"rotated_to,omitempty"`
+	Data      map[string]interface{} `json:"data,omitempty"`
 }
 
 // SessionRequest  represents th etype for the TTL as an optional param to create
 type SessionRequest struct {
-	TTL int64 `json:"ttl"`
+	TTL         int64                  `json:"ttl"`
+	IdleTimeout int64                  `json:"idle_timeout,omitempty"`
+	MaxLifetime int64                  `json:"max_lifetime,omitempty"`
+	Subject     string                 `json:"subject,omitempty"`
+	Data        map[string]interface{} `json:"data,omitempty"`
 }
 
 type DestroyRequest struct {
 	SessionId string `json:"session_id" validate:"required"`
 }
 
+// RotateRequest represents the type to replace a session id by a fresh one
+type RotateRequest struct {
+	SessionId string `json:"session_id" validate:"required"`
+}
+
 type ExtendRequest struct {
 	TTL int64 `json:"ttl"`
 	SessionId string `json:"session_id" validate:"required"`
@@ -27,5 +73,107 @@ type Session struct {
 
 type Sessions struct {
 	List []string `json:"list"`
+	// NextCursor requests the next page of a paginated list, it is empty on the last page
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// ListRequest represents the type to page, filter and sort the listed sessions. The time
+// windows include their start and exclude their end, a zero time leaves a side open.
+type ListRequest struct {
+	Cursor        string    `json:"cursor,omitempty"`
+	Limit         int       `json:"limit,omitempty"`
+	Subject       string    `json:"subject,omitempty"`
+	CreatedAfter  time.Time `json:"created_after,omitempty"`
+	CreatedBefore time.Time `json:"created_before,omitempty"`
+	// MinTTL and MaxTTL bound the remaining TTL of the sessions in seconds, zero disables them
+	MinTTL int64 `json:"min_ttl,omitempty"`
+	MaxTTL int64 `json:"max_ttl,omitempty"`
+	// Sort orders the sessions by created_at, the default, or expires_at
+	Sort string `json:"sort,omitempty"`
+	// Order is asc, the default, or desc
+	Order string `json:"order,omitempty"`
+}
+
+// BatchCreateRequest represents the type to create several sessions at once
+type BatchCreateRequest struct {
+	Sessions []SessionRequest `json:"sessions"`
+}
+
+// BatchExtendRequest represents the type to extend several sessions at once
+type BatchExtendRequest struct {
+	Sessions []ExtendRequest `json:"sessions"`
+}
+
+// BatchDestroyRequest represents the type to destroy several sessions at once
+type BatchDestroyRequest struct {
+	Sessions []DestroyRequest `json:"sessions"`
+}
+
+// BatchResult represents the outcome of a session of a batch request. Err is the error of
+// the session, which the transports encode in Error and StatusCode.
+type BatchResult struct {
+	SessionId  string `json:"session_id,omitempty"`
+	Err        error  `json:"-"`
+	Error      string `json:"error,omitempty"`
+	StatusCode int    `json:"status_code"`
+}
+
+// BatchResults represents the outcomes of a batch request, in the order of its sessions
+type BatchResults struct {
+	Results []BatchResult `json:"results"`
+}
+
+// SubjectRequest represents the type to act on every session of a subject
+type SubjectRequest struct {
+	Subject string `json:"subject" validate:"required"`
+}
+
+// SessionDataRequest represents the type to set or patch the payload of a session
+type SessionDataRequest struct {
+	SessionId string                 `json:"session_id" validate:"required"`
+	Data      map[string]interface{} `json:"data"`
+}
+
+// SessionData represents the payload attached to a session
+type SessionData struct {
+	SessionId string                 `json:"session_id"`
+	Data      map[string]interface{} `json:"data"`
 }
 
+// SessionDetails represents the type returned by a session lookup
+type SessionDetails struct {
+	SessionId  string    `json:"session_id"`
+	Subject    string    `json:"subject,omitempty"`
+	Exists     bool      `json:"exists"`
+	CreatedAt  time.Time `json:"created_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	LastAccess time.Time `json:"last_access"`
+	TTL        int64     `json:"ttl"`
+	// IdleTimeout and MaxExpiresAt are only set for sessions created with them
+	IdleTimeout  int64      `json:"idle_timeout,omitempty"`
+	MaxExpiresAt *time.Time `json:"max_expires_at,omitempty"`
+}
+
+// Revocation represents a destroyed session whose tokens are revoked until they expire
+type Revocation struct {
+	SessionId string `json:"jti"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// Revocations represents the revocation list served to the verifiers of session JWTs
+type Revocations struct {
+	List []Revocation `json:"list"`
+}
+
+// HealthStatus represents the state of the service and of its components served to the
+// liveness and readiness probes
+type HealthStatus struct {
+	Status     string                     `json:"status"`
+	Components map[string]ComponentStatus `json:"components"`
+}
+
+// ComponentStatus represents the state of a component of the service
+type ComponentStatus struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
@@ -0,0 +1,186 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+)
+
+// Scope is a permission granted to an API key
+type Scope string
+
+const (
+	// ScopeCreate allows creating sessions
+	ScopeCreate Scope = "sessions:create"
+	// ScopeRead allows reading a session and its data
+	ScopeRead Scope = "sessions:read"
+	// ScopeWrite allows extending, rotating and destroying a session and writing its data
+	ScopeWrite Scope = "sessions:write"
+	// ScopeAdmin allows everything, including listing every session, the operations on
+	// all the sessions of a subject and the batch operations
+	ScopeAdmin Scope = "sessions:admin"
+)
+
+// hashPrefix prefixes the hex-encoded SHA-256 hash of an API key in the key files
+const hashPrefix = "sha256:"
+
+// Principal is the owner of an API key along with the scopes granted to it
+type Principal struct {
+	Name   string
+	Scopes []Scope
+}
+
+// HasScope reports whether the principal was granted the scope, the admin scope grants
+// every scope
+func (p Principal) HasScope(scope Scope) bool {
+	for _, granted := range p.Scopes {
+		if granted == scope || granted == ScopeAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+// APIKey is an API key of a key file, only the hash of the key is stored. An entry with a
+// subject instead of a hash grants its scopes to the clients presenting a verified
+// certificate with that distinguished name.
+type APIKey struct {
+	Name    string  `json:"name"`
+	Hash    string  `json:"hash,omitempty"`
+	Subject string  `json:"subject,omitempty"`
+	Scopes  []Scope `json:"scopes"`
+}
+
+// KeyStore authenticates the API keys whose hashes it holds. The keys are expected to be
+// long random strings, so an unsalted SHA-256 hash is enough to keep them from leaking
+// with the key file and lets a key be found by its hash.
+type KeyStore struct {
+	principals map[string]Principal
+	subjects   map[string]Principal
+}
+
+// keyStoreFile is the JSON layout of the key files
+type keyStoreFile struct {
+	Keys []APIKey `json:"keys"`
+}
+
+// HashKey returns the hash of the API key as written in the key files
+func HashKey(apiKey string) string {
+	sum := sha256.Sum256([]byte(apiKey))
+	return hashPrefix + hex.EncodeToString(sum[:])
+}
+
+// NewKeyStore returns a key store authenticating the given keys
+func NewKeyStore(keys ...APIKey) (*KeyStore, error) {
+	principals := make(map[string]Principal, len(keys))
+	subjects := make(map[string]Principal)
+	for _, key := range keys {
+		if err := validateScopes(key); err != nil {
+			return nil, err
+		}
+		if key.Subject != "" {
+			if key.Hash != "" {
+				return nil, fmt.Errorf("key %q: hash and subject are mutually exclusive", key.Name)
+			}
+			if _, found := subjects[key.Subject]; found {
+				return nil, fmt.Errorf("key %q: subject used by another key", key.Name)
+			}
+			subjects[key.Subject] = Principal{Name: key.Name, Scopes: key.Scopes}
+			continue
+		}
+
+		hash := strings.ToLower(key.Hash)
+		if !strings.HasPrefix(hash, hashPrefix) || len(hash) != len(hashPrefix)+2*sha256.Size {
+			return nil, fmt.Errorf("key %q: hash must be %s followed by 64 hex digits", key.Name, hashPrefix)
+		}
+		if _, err := hex.DecodeString(strings.TrimPrefix(hash, hashPrefix)); err != nil {
+			return nil, fmt.Errorf("key %q: %v", key.Name, err)
+		}
+		if _, found := principals[hash]; found {
+			return nil, fmt.Errorf("key %q: hash used by another key", key.Name)
+		}
+		principals[hash] = Principal{Name: key.Name, Scopes: key.Scopes}
+	}
+	return &KeyStore{principals: principals, subjects: subjects}, nil
+}
+
+// validateScopes returns an error when the key was granted an unknown scope
+func validateScopes(key APIKey) error {
+	for _, scope := range key.Scopes {
+		switch scope {
+		case ScopeCreate, ScopeRead, ScopeWrite, ScopeAdmin:
+		default:
+			return fmt.Errorf("key %q: unknown scope %q", key.Name, scope)
+		}
+	}
+	return nil
+}
+
+// LoadKeyStore reads a key store from a JSON file of the form
+//
+//	{"keys": [{"name": "frontend", "hash": "sha256:<hex>", "scopes": ["sessions:create"]},
+//	          {"name": "backoffice", "subject": "CN=backoffice,O=Example", "scopes": ["sessions:admin"]}]}
+func LoadKeyStore(path string) (*KeyStore, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file keyStoreFile
+	if err := json.Unmarshal(b, &file); err != nil {
+		return nil, err
+	}
+	return NewKeyStore(file.Keys...)
+}
+
+// Authenticate returns the principal of the API key
+func (k *KeyStore) Authenticate(apiKey string) (Principal, bool) {
+	if apiKey == "" {
+		return Principal{}, false
+	}
+	principal, found := k.principals[HashKey(apiKey)]
+	return principal, found
+}
+
+// AuthenticateSubject returns the principal of the client certificate subject
+func (k *KeyStore) AuthenticateSubject(subject string) (Principal, bool) {
+	if subject == "" {
+		return Principal{}, false
+	}
+	principal, found := k.subjects[subject]
+	return principal, found
+}
+
+// principalKey is the context key of the authenticated principal
+type principalKey struct{}
+
+// NewContext returns a copy of ctx carrying the authenticated principal
+func NewContext(ctx context.Context, principal Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// FromContext returns the authenticated principal carried by ctx, if any
+func FromContext(ctx context.Context) (Principal, bool) {
+	principal, found := ctx.Value(principalKey{}).(Principal)
+	return principal, found
+}
+
+// clientKey is the context key of the subject of the verified client certificate
+type clientKey struct{}
+
+// NewClientContext returns a copy of ctx carrying the distinguished name of the verified
+// client certificate of the request
+func NewClientContext(ctx context.Context, subject string) context.Context {
+	return context.WithValue(ctx, clientKey{}, subject)
+}
+
+// ClientFromContext returns the distinguished name of the verified client certificate
+// carried by ctx, if any
+func ClientFromContext(ctx context.Context) (string, bool) {
+	subject, found := ctx.Value(clientKey{}).(string)
+	return subject, found
+}
@@ -0,0 +1,133 @@
+package auth_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/hecomp/session-management/pkg/auth"
+)
+
+var _ = Describe("KeyStore", func() {
+
+	const frontendKey = "frontend-0123456789abcdef0123456789"
+
+	var keys *KeyStore
+
+	BeforeEach(func() {
+		var err error
+		keys, err = NewKeyStore(
+			APIKey{Name: "frontend", Hash: HashKey(frontendKey), Scopes: []Scope{ScopeCreate, ScopeRead}},
+			APIKey{Name: "ops", Hash: HashKey("ops-0123456789abcdef0123456789"), Scopes: []Scope{ScopeAdmin}},
+			APIKey{Name: "backoffice", Subject: "CN=backoffice,O=Example", Scopes: []Scope{ScopeRead}},
+		)
+		Expect(err).To(BeNil())
+	})
+
+	Context("Authenticate()", func() {
+		It("returns the principal of a known key", func() {
+			principal, found := keys.Authenticate(frontendKey)
+			Expect(found).To(BeTrue())
+			Expect(principal.Name).To(Equal("frontend"))
+			Expect(principal.HasScope(ScopeCreate)).To(BeTrue())
+			Expect(principal.HasScope(ScopeRead)).To(BeTrue())
+			Expect(principal.HasScope(ScopeWrite)).To(BeFalse())
+			Expect(principal.HasScope(ScopeAdmin)).To(BeFalse())
+		})
+		It("grants every scope to the admin scope", func() {
+			principal, found := keys.Authenticate("ops-0123456789abcdef0123456789")
+			Expect(found).To(BeTrue())
+			Expect(principal.HasScope(ScopeWrite)).To(BeTrue())
+		})
+		It("refuses an unknown or empty key", func() {
+			_, found := keys.Authenticate("unknown")
+			Expect(found).To(BeFalse())
+			_, found = keys.Authenticate("")
+			Expect(found).To(BeFalse())
+		})
+	})
+
+	Context("AuthenticateSubject()", func() {
+		It("returns the principal of a known client certificate subject", func() {
+			principal, found := keys.AuthenticateSubject("CN=backoffice,O=Example")
+			Expect(found).To(BeTrue())
+			Expect(principal.Name).To(Equal("backoffice"))
+			Expect(principal.HasScope(ScopeRead)).To(BeTrue())
+			Expect(principal.HasScope(ScopeWrite)).To(BeFalse())
+		})
+		It("refuses an unknown or empty subject", func() {
+			_, found := keys.AuthenticateSubject("CN=unknown")
+			Expect(found).To(BeFalse())
+			_, found = keys.AuthenticateSubject("")
+			Expect(found).To(BeFalse())
+		})
+		It("does not authenticate a subject as an API key", func() {
+			_, found := keys.Authenticate("CN=backoffice,O=Example")
+			Expect(found).To(BeFalse())
+		})
+	})
+
+	Context("NewKeyStore()", func() {
+		It("refuses a malformed hash", func() {
+			_, err := NewKeyStore(APIKey{Name: "frontend", Hash: frontendKey})
+			Expect(err).ToNot(BeNil())
+		})
+		It("refuses an unknown scope", func() {
+			_, err := NewKeyStore(APIKey{Name: "frontend", Hash: HashKey(frontendKey), Scopes: []Scope{"sessions:everything"}})
+			Expect(err).ToNot(BeNil())
+		})
+		It("refuses two keys with the same hash", func() {
+			_, err := NewKeyStore(APIKey{Name: "a", Hash: HashKey(frontendKey)}, APIKey{Name: "b", Hash: HashKey(frontendKey)})
+			Expect(err).ToNot(BeNil())
+		})
+		It("refuses a key with both a hash and a subject", func() {
+			_, err := NewKeyStore(APIKey{Name: "a", Hash: HashKey(frontendKey), Subject: "CN=a"})
+			Expect(err).ToNot(BeNil())
+		})
+		It("refuses two keys with the same subject", func() {
+			_, err := NewKeyStore(APIKey{Name: "a", Subject: "CN=a"}, APIKey{Name: "b", Subject: "CN=a"})
+			Expect(err).ToNot(BeNil())
+		})
+	})
+
+	Context("LoadKeyStore()", func() {
+		It("reads the keys of a JSON file", func() {
+			dir, err := ioutil.TempDir("", "auth")
+			Expect(err).To(BeNil())
+			defer os.RemoveAll(dir)
+			path := filepath.Join(dir, "keys.json")
+			content := `{"keys": [{"name": "frontend", "hash": "` + HashKey(frontendKey) + `", "scopes": ["sessions:create"]}]}`
+			Expect(ioutil.WriteFile(path, []byte(content), 0600)).To(Succeed())
+
+			keys, err := LoadKeyStore(path)
+			Expect(err).To(BeNil())
+			principal, found := keys.Authenticate(frontendKey)
+			Expect(found).To(BeTrue())
+			Expect(principal.Scopes).To(Equal([]Scope{ScopeCreate}))
+		})
+	})
+
+	Context("NewContext()", func() {
+		It("carries the principal", func() {
+			_, found := FromContext(context.Background())
+			Expect(found).To(BeFalse())
+			principal, found := FromContext(NewContext(context.Background(), Principal{Name: "frontend"}))
+			Expect(found).To(BeTrue())
+			Expect(principal.Name).To(Equal("frontend"))
+		})
+	})
+
+	Context("NewClientContext()", func() {
+		It("carries the client certificate subject", func() {
+			_, found := ClientFromContext(context.Background())
+			Expect(found).To(BeFalse())
+			subject, found := ClientFromContext(NewClientContext(context.Background(), "CN=backoffice"))
+			Expect(found).To(BeTrue())
+			Expect(subject).To(Equal("CN=backoffice"))
+		})
+	})
+})
@@ -0,0 +1,589 @@
+package file_store
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/log"
+
+	. "github.com/hecomp/session-management/internal/models"
+	"github.com/hecomp/session-management/pkg/in_memory"
+)
+
+const (
+	// WalFile is the name of the write-ahead log inside the store directory
+	WalFile = "sessions.wal"
+	// SnapshotFile is the name of the compacted snapshot inside the store directory
+	SnapshotFile = "sessions.snapshot"
+)
+
+const (
+	opCommit = "commit"
+	opDelete = "delete"
+	opReset  = "reset"
+	opUpdate = "update"
+
+	opDeleteSubject = "delete_subject"
+)
+
+// walRecord represents a single operation appended to the write-ahead log.
+type walRecord struct {
+	Op         string `json:"op"`
+	SessionId  string `json:"session_id,omitempty"`
+	Subject    string `json:"subject,omitempty"`
+	Object     []byte `json:"object,omitempty"`
+	Expiration int64  `json:"expiration,omitempty"`
+
+	IdleTimeout   int64 `json:"idle_timeout,omitempty"`
+	MaxExpiration int64 `json:"max_expiration,omitempty"`
+	CreatedAt     int64 `json:"created_at,omitempty"`
+	// LastAccess restores the idle deadline of the session on replay
+	LastAccess int64 `json:"last_access,omitempty"`
+}
+
+// FileStore represents a durable session store. Every Commit, Delete, Reset and Update is
+// appended to a write-ahead log before being applied to an in-memory store, and the
+// log is periodically compacted into a snapshot. Both are replayed on startup.
+type FileStore struct {
+	logger        log.Logger
+	dir           string
+	mem           *in_memory.InMemStore
+	wal           *os.File
+	mu            sync.Mutex
+	stopSnapshot  chan bool
+	stopOnce      sync.Once
+	probeSnapshot chan struct{}
+}
+
+// NewFileStore returns a new FileStore instance rooted at dir. The snapshot and
+// write-ahead log found in dir are replayed before the store is returned. Expired
+// sessions are removed every sessionInterval and the log is compacted every
+// snapshotInterval; a zero interval disables the corresponding goroutine.
+func NewFileStore(dir string, sessionInterval, snapshotInterval time.Duration, logger log.Logger) (in_memory.MemStore, error) {
+	return NewInstrumentedFileStore(dir, sessionInterval, snapshotInterval, in_memory.Metrics{}, logger)
+}
+
+// NewInstrumentedFileStore returns a new FileStore instance like NewFileStore, reporting
+// the store-level metrics of its in-memory store.
+func NewInstrumentedFileStore(dir string, sessionInterval, snapshotInterval time.Duration, metrics in_memory.Metrics, logger log.Logger) (in_memory.MemStore, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+
+	f := &FileStore{
+		logger: logger,
+		dir:    dir,
+		mem:    in_memory.NewInstrumentedInMemStore(sessionInterval, metrics, logger).(*in_memory.InMemStore),
+	}
+
+	items, err := f.loadSnapshot()
+	if err != nil {
+		return nil, err
+	}
+	if err := f.replayWal(items); err != nil {
+		return nil, err
+	}
+	if err := f.commitLive(items); err != nil {
+		return nil, err
+	}
+
+	wal, err := os.OpenFile(f.walPath(), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+	f.wal = wal
+
+	if snapshotInterval > 0 {
+		f.stopSnapshot = make(chan bool)
+		f.probeSnapshot = make(chan struct{})
+		go f.startSnapshot(snapshotInterval)
+	}
+
+	return f, nil
+}
+
+// Find returns the data for a given session from the FileStore instance.
+func (f *FileStore) Find(ctx context.Context, sessionId string) ([]byte, bool, error) {
+	item, found, err := f.Lookup(ctx, sessionId)
+	if err != nil || !found {
+		return nil, false, err
+	}
+	return item.Oject, true, nil
+}
+
+// Lookup returns the item for a given session from the FileStore instance. The last
+// access and expiration of a session with an idle timeout move on every lookup, so they
+// are written to the log to survive a restart.
+func (f *FileStore) Lookup(ctx context.Context, sessionId string) (Item, bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	item, found, err := f.mem.Lookup(ctx, sessionId)
+	if err != nil || !found || item.IdleTimeout == 0 {
+		return item, found, err
+	}
+
+	record := walRecord{Op: opReset, SessionId: sessionId, Expiration: item.Expiration, LastAccess: item.LastAccess}
+	if err := f.append(record); err != nil {
+		return Item{}, false, err
+	}
+	return item, true, nil
+}
+
+// Commit appends the session to the write-ahead log and then adds it to the store.
+func (f *FileStore) Commit(ctx context.Context, sessionId string, b []byte, expiration time.Time) error {
+	return f.CommitWithSubject(ctx, sessionId, "", b, expiration)
+}
+
+// CommitWithSubject appends the session and its subject to the write-ahead log and
+// then adds it to the store.
+func (f *FileStore) CommitWithSubject(ctx context.Context, sessionId string, subject string, b []byte, expiration time.Time) error {
+	return f.CommitItem(ctx, sessionId, Item{Oject: b, Expiration: expiration.UnixNano(), Subject: subject})
+}
+
+// CommitItem appends the session, its subject, idle timeout, maximum expiration and
+// creation time to the write-ahead log and then adds it to the store.
+func (f *FileStore) CommitItem(ctx context.Context, sessionId string, item Item) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	item.LastAccess = time.Now().UnixNano()
+	if item.CreatedAt == 0 {
+		item.CreatedAt = item.LastAccess
+	}
+	if err := f.append(commitRecord(sessionId, item)); err != nil {
+		return err
+	}
+	return f.mem.CommitItem(ctx, sessionId, item)
+}
+
+// CommitBatch appends every session to the write-ahead log with a single write and sync,
+// and then adds them to the store.
+func (f *FileStore) CommitBatch(ctx context.Context, items map[string]Item) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	now := time.Now().UnixNano()
+	batch := make(map[string]Item, len(items))
+	records := make([]walRecord, 0, len(items))
+	for sessionId, item := range items {
+		item.LastAccess = now
+		if item.CreatedAt == 0 {
+			item.CreatedAt = now
+		}
+		batch[sessionId] = item
+		records = append(records, commitRecord(sessionId, item))
+	}
+	if err := f.append(records...); err != nil {
+		return err
+	}
+	return f.mem.CommitBatch(ctx, batch)
+}
+
+// Delete appends the removal to the write-ahead log and then removes the session
+// from the store.
+func (f *FileStore) Delete(ctx context.Context, sessionId string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.append(walRecord{Op: opDelete, SessionId: sessionId}); err != nil {
+		return err
+	}
+	return f.mem.Delete(ctx, sessionId)
+}
+
+// DeleteBatch appends the removal of the sessions to the write-ahead log with a single
+// write and sync, and then removes them from the store.
+func (f *FileStore) DeleteBatch(ctx context.Context, sessionIds []string) (map[string]Item, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	records := make([]walRecord, len(sessionIds))
+	for i, sessionId := range sessionIds {
+		records[i] = walRecord{Op: opDelete, SessionId: sessionId}
+	}
+	if err := f.append(records...); err != nil {
+		return nil, err
+	}
+	return f.mem.DeleteBatch(ctx, sessionIds)
+}
+
+// DeleteBySubject appends the removal of every session of the subject to the
+// write-ahead log and then removes them from the store.
+func (f *FileStore) DeleteBySubject(ctx context.Context, subject string) ([]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.append(walRecord{Op: opDeleteSubject, Subject: subject}); err != nil {
+		return nil, err
+	}
+	return f.mem.DeleteBySubject(ctx, subject)
+}
+
+// ListBySubject returns the live sessions of the subject from the FileStore instance.
+func (f *FileStore) ListBySubject(ctx context.Context, subject string) (map[string]Item, error) {
+	return f.mem.ListBySubject(ctx, subject)
+}
+
+// Reset extend a session ttl from the FileStore instance. Only resets of live
+// sessions are written to the log, with the expiration capped at the maximum
+// expiration of the session.
+func (f *FileStore) Reset(ctx context.Context, sessionId string, expiration time.Time) ([]byte, bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	item, found, err := f.mem.Peek(ctx, sessionId)
+	if err != nil || !found {
+		return nil, false, err
+	}
+
+	record := walRecord{Op: opReset, SessionId: sessionId, Expiration: capExpiration(item, expiration.UnixNano()), LastAccess: time.Now().UnixNano()}
+	if err := f.append(record); err != nil {
+		return nil, false, err
+	}
+	return f.mem.Reset(ctx, sessionId, expiration)
+}
+
+// ResetBatch appends the capped expiration of the live sessions to the write-ahead log
+// with a single write and sync, and then extends them in the store.
+func (f *FileStore) ResetBatch(ctx context.Context, expirations map[string]time.Time) (map[string]Item, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	now := time.Now().UnixNano()
+	live := make(map[string]time.Time, len(expirations))
+	records := make([]walRecord, 0, len(expirations))
+	for sessionId, expiration := range expirations {
+		item, found, err := f.mem.Peek(ctx, sessionId)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			continue
+		}
+		live[sessionId] = expiration
+		records = append(records, walRecord{Op: opReset, SessionId: sessionId, Expiration: capExpiration(item, expiration.UnixNano()), LastAccess: now})
+	}
+	if len(records) == 0 {
+		return map[string]Item{}, nil
+	}
+	if err := f.append(records...); err != nil {
+		return nil, err
+	}
+	return f.mem.ResetBatch(ctx, live)
+}
+
+// Update appends the new data of a live session to the write-ahead log and then
+// replaces it in the store. The session is looked up without sliding its expiration,
+// which would not be written to the log.
+func (f *FileStore) Update(ctx context.Context, sessionId string, b []byte) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, found, err := f.mem.Peek(ctx, sessionId); err != nil || !found {
+		return false, err
+	}
+
+	if err := f.append(walRecord{Op: opUpdate, SessionId: sessionId, Object: b, LastAccess: time.Now().UnixNano()}); err != nil {
+		return false, err
+	}
+	return f.mem.Update(ctx, sessionId, b)
+}
+
+// List return a list of all the sessions from the store
+func (f *FileStore) List(ctx context.Context) (map[string]Item, error) {
+	return f.mem.List(ctx)
+}
+
+// Query returns the page of the live sessions selected by the query from the in-memory store
+func (f *FileStore) Query(ctx context.Context, query in_memory.Query) (in_memory.Page, error) {
+	return f.mem.Query(ctx, query)
+}
+
+// Get returns the underlying session map
+func (f *FileStore) Get(ctx context.Context) map[string]Item {
+	return f.mem.Get(ctx)
+}
+
+// Compact writes every live session to a new snapshot and truncates the write-ahead log.
+// The sessions past their expiration, maximum expiration or idle timeout are left out.
+func (f *FileStore) Compact() error {
+	f.logger.Log("method", "compact")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	items, err := f.mem.List(context.Background())
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UnixNano()
+	live := make(map[string]Item, len(items))
+	for sessionId, item := range items {
+		if now <= item.Deadline() {
+			live[sessionId] = item
+		}
+	}
+
+	b, err := json.Marshal(live)
+	if err != nil {
+		return err
+	}
+	if err := writeFileSync(f.snapshotPath(), b); err != nil {
+		return err
+	}
+
+	if err := f.wal.Truncate(0); err != nil {
+		return err
+	}
+	return f.wal.Sync()
+}
+
+// StopSnapshot terminates the background compaction goroutine for the FileStore instance.
+func (f *FileStore) StopSnapshot() {
+	f.logger.Log("stopSnapshot")
+	if f.stopSnapshot != nil {
+		f.stopOnce.Do(func() { f.stopSnapshot <- true })
+	}
+}
+
+// Close stops the background goroutines of the FileStore instance and flushes its state:
+// the write-ahead log is compacted into a final snapshot, so the next start does not have
+// to replay it, and closed. The store can not be written to afterwards.
+func (f *FileStore) Close(ctx context.Context) error {
+	f.logger.Log("method", "close")
+	f.StopSnapshot()
+	if err := f.mem.Close(ctx); err != nil {
+		return err
+	}
+	if err := f.Compact(); err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.wal.Close()
+}
+
+// Ping reports whether the write-ahead log of the FileStore instance is still open
+func (f *FileStore) Ping(ctx context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_, err := f.wal.Stat()
+	return err
+}
+
+// CheckCleanup reports whether the session cleanup and the compaction goroutines of the
+// FileStore instance answer before the context is done.
+func (f *FileStore) CheckCleanup(ctx context.Context) error {
+	if err := f.mem.CheckCleanup(ctx); err != nil {
+		return err
+	}
+	return in_memory.ProbeCleanup(ctx, f.probeSnapshot)
+}
+
+// startSnapshot compacts the write-ahead log into a snapshot on every tick
+func (f *FileStore) startSnapshot(interval time.Duration) {
+	f.logger.Log("method", "startSnapshot")
+	ticker := time.NewTicker(interval)
+	for {
+		select {
+		case <-ticker.C:
+			if err := f.Compact(); err != nil {
+				f.logger.Log("method", "compact", "err", err)
+			}
+		case <-f.probeSnapshot:
+		case <-f.stopSnapshot:
+			ticker.Stop()
+			return
+		}
+	}
+}
+
+// commitRecord returns the write-ahead log record of the commit of the session
+func commitRecord(sessionId string, item Item) walRecord {
+	return walRecord{
+		Op:            opCommit,
+		SessionId:     sessionId,
+		Subject:       item.Subject,
+		Object:        item.Oject,
+		Expiration:    item.Expiration,
+		IdleTimeout:   item.IdleTimeout,
+		MaxExpiration: item.MaxExpiration,
+		CreatedAt:     item.CreatedAt,
+		LastAccess:    item.LastAccess,
+	}
+}
+
+// append encodes every record as a JSON line and syncs them to disk with a single write
+func (f *FileStore) append(records ...walRecord) error {
+	var buf []byte
+	for _, record := range records {
+		b, err := json.Marshal(record)
+		if err != nil {
+			return err
+		}
+		buf = append(append(buf, b...), '\n')
+	}
+	if _, err := f.wal.Write(buf); err != nil {
+		return err
+	}
+	return f.wal.Sync()
+}
+
+// loadSnapshot returns every session from the snapshot file, if any
+func (f *FileStore) loadSnapshot() (map[string]Item, error) {
+	items := make(map[string]Item)
+	b, err := ioutil.ReadFile(f.snapshotPath())
+	if os.IsNotExist(err) {
+		return items, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(b, &items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// replayWal applies every record of the write-ahead log on top of the snapshot items. A
+// partially written trailing record, left behind by a crash, is cut from the log.
+func (f *FileStore) replayWal(items map[string]Item) error {
+	file, err := os.Open(f.walPath())
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var offset int64
+	reader := bufio.NewReader(file)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			if len(line) > 0 {
+				f.logger.Log("method", "replayWal", "action", "truncated-record-removed")
+				return os.Truncate(f.walPath(), offset)
+			}
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		offset += int64(len(line))
+
+		var record walRecord
+		if err := json.Unmarshal(line, &record); err != nil {
+			return err
+		}
+		apply(items, record)
+	}
+}
+
+// commitLive adds the replayed sessions that did not expire while the store was
+// closed to the in-memory store. Expiration is only checked once the whole log is
+// replayed, as a later reset may extend an expired commit. The records written before
+// the last access was logged restart the idle timeout of their session.
+func (f *FileStore) commitLive(items map[string]Item) error {
+	now := time.Now().UnixNano()
+	for sessionId, item := range items {
+		if item.LastAccess == 0 {
+			item.LastAccess = now
+		}
+		if now > item.Deadline() {
+			continue
+		}
+		if err := f.mem.CommitItem(context.Background(), sessionId, item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// apply replays a single write-ahead log record against the replayed items
+func apply(items map[string]Item, record walRecord) {
+	switch record.Op {
+	case opCommit:
+		items[record.SessionId] = Item{
+			Oject:         record.Object,
+			Expiration:    record.Expiration,
+			Subject:       record.Subject,
+			IdleTimeout:   record.IdleTimeout,
+			MaxExpiration: record.MaxExpiration,
+			CreatedAt:     record.CreatedAt,
+			LastAccess:    record.LastAccess,
+		}
+	case opDelete:
+		delete(items, record.SessionId)
+	case opDeleteSubject:
+		for sessionId, item := range items {
+			if item.Subject == record.Subject {
+				delete(items, sessionId)
+			}
+		}
+	case opReset:
+		if item, found := items[record.SessionId]; found {
+			item.Expiration = record.Expiration
+			if record.LastAccess != 0 {
+				item.LastAccess = record.LastAccess
+			}
+			items[record.SessionId] = item
+		}
+	case opUpdate:
+		if item, found := items[record.SessionId]; found {
+			item.Oject = record.Object
+			if record.LastAccess != 0 {
+				item.LastAccess = record.LastAccess
+			}
+			items[record.SessionId] = item
+		}
+	}
+}
+
+// capExpiration returns the expiration limited to the maximum expiration of the item
+func capExpiration(item Item, expiration int64) int64 {
+	if item.MaxExpiration > 0 && expiration > item.MaxExpiration {
+		return item.MaxExpiration
+	}
+	return expiration
+}
+
+func (f *FileStore) walPath() string {
+	return filepath.Join(f.dir, WalFile)
+}
+
+func (f *FileStore) snapshotPath() string {
+	return filepath.Join(f.dir, SnapshotFile)
+}
+
+// writeFileSync atomically replaces the named file by writing to a temporary file,
+// syncing it and renaming it into place.
+func writeFileSync(name string, b []byte) error {
+	tmp := name + ".tmp"
+	file, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(b); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, name)
+}
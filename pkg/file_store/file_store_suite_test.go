@@ -0,0 +1,17 @@
+package file_store_test
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// ctx is the context of the calls made by the specs
+var ctx = context.Background()
+
+func TestFileStore(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "FileStore Suite")
+}
@@ -0,0 +1,479 @@
+package file_store_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/hecomp/session-management/internal/models"
+	. "github.com/hecomp/session-management/pkg/file_store"
+	. "github.com/hecomp/session-management/pkg/in_memory"
+	"github.com/hecomp/session-management/pkg/test"
+)
+
+type FileStoreSuite struct {
+	mem    MemStore
+	dir    string
+	logger log.Logger
+}
+
+var _ = Describe("FileStore", func() {
+
+	s := &FileStoreSuite{}
+
+	BeforeEach(func() {
+		var err error
+		s.logger = test.GetLogger()
+		s.dir, err = ioutil.TempDir("", "file_store")
+		Expect(err).To(BeNil())
+		s.mem, err = NewFileStore(s.dir, 0, 0, s.logger)
+		Expect(err).To(BeNil())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(s.dir)
+	})
+
+	Describe("Create session", func() {
+
+		inMemResponse := "90660b89-100e-4f8f-9801-2524df6fbe34"
+
+		Context("Commit()", func() {
+			When("the API os called with TTL as param", func() {
+				It("stores an unique sessionId in the file store", func() {
+					uniqueUUID := "90660b89-100e-4f8f-9801-2524df6fbe34"
+					err := s.mem.Commit(ctx, uniqueUUID, []byte(uniqueUUID), time.Now().Add(time.Minute))
+					sessionMap, _ := s.mem.List(ctx)
+					Expect(err).To(BeNil())
+					Expect(string(sessionMap[uniqueUUID].Oject)).To(Equal(inMemResponse))
+				})
+			})
+		})
+	})
+
+	Describe("Find session", func() {
+		inMemResponse := "90660b89-100e-4f8f-9801-2524df6fbe34"
+		uniqueUUID := "90660b89-100e-4f8f-9801-2524df6fbe34"
+		BeforeEach(func() {
+			err := s.mem.Commit(ctx, uniqueUUID, []byte(uniqueUUID), time.Now().Add(time.Minute))
+			Expect(err).To(BeNil())
+		})
+
+		Context("Find()", func() {
+			When("the API os called with TTL as param", func() {
+				It("finds sessionId in the file store", func() {
+					obj, found, err := s.mem.Find(ctx, uniqueUUID)
+					Expect(err).To(BeNil())
+					Expect(found).To(BeTrue())
+					Expect(string(obj)).To(Equal(inMemResponse))
+				})
+				It("does not matches sessionId in the file store", func() {
+					uniqueUUID2 := "90660b89-100e-4f8f-9801-2524df6fbe99"
+					_, found, err := s.mem.Find(ctx, uniqueUUID2)
+					Expect(err).To(BeNil())
+					Expect(found).ToNot(BeTrue())
+				})
+				It("expired sessionId in the file store", func() {
+					er := s.mem.Commit(ctx, uniqueUUID, []byte(uniqueUUID), time.Now().Add(100*time.Millisecond))
+					time.Sleep(101 * time.Millisecond)
+
+					obj, found, err := s.mem.Find(ctx, uniqueUUID)
+					Expect(err).To(BeNil())
+					Expect(er).To(BeNil())
+					Expect(obj).To(BeNil())
+					Expect(found).ToNot(BeTrue())
+				})
+			})
+		})
+	})
+
+	Describe("Destroy session", func() {
+		uniqueUUID := "90660b89-100e-4f8f-9801-2524df6fbe34"
+		BeforeEach(func() {
+			err := s.mem.Commit(ctx, uniqueUUID, []byte(uniqueUUID), time.Now().Add(time.Minute))
+			Expect(err).To(BeNil())
+		})
+
+		Context("Delete()", func() {
+			When("the API os called", func() {
+				It("deletes sessionId in the file store", func() {
+					err := s.mem.Delete(ctx, uniqueUUID)
+					sessionMap := s.mem.Get(ctx)
+					Expect(err).To(BeNil())
+					Expect(string(sessionMap[uniqueUUID].Oject)).To(BeEmpty())
+				})
+			})
+		})
+	})
+
+	Describe("Extend session", func() {
+		inMemResponse := "90660b89-100e-4f8f-9801-2524df6fbe34"
+		uniqueUUID := "90660b89-100e-4f8f-9801-2524df6fbe34"
+		BeforeEach(func() {
+			err := s.mem.Commit(ctx, uniqueUUID, []byte(uniqueUUID), time.Now().Add(time.Minute))
+			Expect(err).To(BeNil())
+		})
+
+		Context("Reset()", func() {
+			When("the API os called with TTL as param", func() {
+				It("extend sessionId in the file store", func() {
+					expiration := time.Now().Add(time.Minute * time.Duration(5))
+
+					session, _, err := s.mem.Reset(ctx, uniqueUUID, expiration)
+					sessionMap := s.mem.Get(ctx)
+					Expect(err).To(BeNil())
+					Expect(string(session)).To(Equal(inMemResponse))
+					Expect(sessionMap[uniqueUUID].Expiration).To(Equal(expiration.UnixNano()))
+				})
+				It("extend sessionId in the file store not found", func() {
+					uniqueUUID2 := "90660b89-100e-4f8f-9801-2524df6fbe99"
+
+					expiration := time.Now().Add(time.Minute * time.Duration(5))
+					_, found, err := s.mem.Reset(ctx, uniqueUUID2, expiration)
+					Expect(err).To(BeNil())
+					Expect(found).ToNot(BeTrue())
+				})
+			})
+		})
+	})
+
+	Describe("List session", func() {
+		uniqueUUID1 := "90660b89-100e-4f8f-9801-2524df6fbe34"
+		uniqueUUID2 := "90660b89-100e-4f8f-9801-2524df6fbe99"
+		BeforeEach(func() {
+			err := s.mem.Commit(ctx, uniqueUUID1, []byte(uniqueUUID1), time.Now().Add(time.Minute))
+			Expect(err).To(BeNil())
+			err = s.mem.Commit(ctx, uniqueUUID2, []byte(uniqueUUID2), time.Now().Add(time.Minute))
+			Expect(err).To(BeNil())
+		})
+
+		Context("List()", func() {
+			When("the API os called", func() {
+				It("lists sessionIds in the file store", func() {
+					sessions, err := s.mem.List(ctx)
+					sessionMap := s.mem.Get(ctx)
+					Expect(err).To(BeNil())
+					Expect(sessions).To(Equal(sessionMap))
+				})
+			})
+		})
+	})
+
+	Describe("Replay sessions", func() {
+		uniqueUUID1 := "90660b89-100e-4f8f-9801-2524df6fbe34"
+		uniqueUUID2 := "90660b89-100e-4f8f-9801-2524df6fbe99"
+		uniqueUUID3 := "90660b89-100e-4f8f-9801-2524df6fbe88"
+		expiration := time.Now().Add(time.Hour)
+		BeforeEach(func() {
+			err := s.mem.Commit(ctx, uniqueUUID1, []byte(uniqueUUID1), time.Now().Add(time.Minute))
+			Expect(err).To(BeNil())
+			err = s.mem.Commit(ctx, uniqueUUID2, []byte(uniqueUUID2), time.Now().Add(time.Minute))
+			Expect(err).To(BeNil())
+			err = s.mem.Commit(ctx, uniqueUUID3, []byte(uniqueUUID3), time.Now().Add(100*time.Millisecond))
+			Expect(err).To(BeNil())
+			err = s.mem.Delete(ctx, uniqueUUID2)
+			Expect(err).To(BeNil())
+			_, _, err = s.mem.Reset(ctx, uniqueUUID1, expiration)
+			Expect(err).To(BeNil())
+		})
+
+		Context("NewFileStore()", func() {
+			When("the store is reopened from the write-ahead log", func() {
+				It("restores live sessions", func() {
+					time.Sleep(101 * time.Millisecond)
+					reopened, err := NewFileStore(s.dir, 0, 0, s.logger)
+					Expect(err).To(BeNil())
+
+					sessionMap, err := reopened.List(ctx)
+					Expect(err).To(BeNil())
+					Expect(sessionMap).To(HaveLen(1))
+					Expect(string(sessionMap[uniqueUUID1].Oject)).To(Equal(uniqueUUID1))
+					Expect(sessionMap[uniqueUUID1].Expiration).To(Equal(expiration.UnixNano()))
+				})
+			})
+			When("the sessions of a subject were deleted", func() {
+				It("restores the subject index", func() {
+					err := s.mem.CommitWithSubject(ctx, uniqueUUID2, "user-42", []byte(uniqueUUID2), time.Now().Add(time.Minute))
+					Expect(err).To(BeNil())
+					err = s.mem.CommitWithSubject(ctx, uniqueUUID3, "user-42", []byte(uniqueUUID3), time.Now().Add(time.Minute))
+					Expect(err).To(BeNil())
+					Expect(s.mem.(*FileStore).Compact()).To(Succeed())
+					_, err = s.mem.DeleteBySubject(ctx, "user-42")
+					Expect(err).To(BeNil())
+					err = s.mem.CommitWithSubject(ctx, uniqueUUID3, "user-42", []byte(uniqueUUID3), time.Now().Add(time.Minute))
+					Expect(err).To(BeNil())
+
+					reopened, err := NewFileStore(s.dir, 0, 0, s.logger)
+					Expect(err).To(BeNil())
+					sessionMap, err := reopened.ListBySubject(ctx, "user-42")
+					Expect(err).To(BeNil())
+					Expect(sessionMap).To(HaveLen(1))
+					Expect(sessionMap).To(HaveKey(uniqueUUID3))
+				})
+			})
+			When("sessions have an idle timeout and a maximum expiration", func() {
+				It("restores them with the slid expiration", func() {
+					maxExpiration := time.Now().Add(time.Hour).UnixNano()
+					err := s.mem.CommitItem(ctx, uniqueUUID2, models.Item{
+						Oject:         []byte(uniqueUUID2),
+						Expiration:    time.Now().Add(100 * time.Millisecond).UnixNano(),
+						IdleTimeout:   int64(time.Minute),
+						MaxExpiration: maxExpiration,
+					})
+					Expect(err).To(BeNil())
+					item, found, err := s.mem.Lookup(ctx, uniqueUUID2)
+					Expect(err).To(BeNil())
+					Expect(found).To(BeTrue())
+
+					time.Sleep(101 * time.Millisecond)
+					reopened, err := NewFileStore(s.dir, 0, 0, s.logger)
+					Expect(err).To(BeNil())
+					sessionMap, err := reopened.List(ctx)
+					Expect(err).To(BeNil())
+					Expect(sessionMap[uniqueUUID2].Expiration).To(Equal(item.Expiration))
+					Expect(sessionMap[uniqueUUID2].IdleTimeout).To(Equal(int64(time.Minute)))
+					Expect(sessionMap[uniqueUUID2].MaxExpiration).To(Equal(maxExpiration))
+				})
+				It("restores the expiration enforced after an update", func() {
+					err := s.mem.CommitItem(ctx, uniqueUUID2, models.Item{
+						Oject:       []byte(uniqueUUID2),
+						Expiration:  time.Now().Add(100 * time.Millisecond).UnixNano(),
+						IdleTimeout: int64(time.Minute),
+					})
+					Expect(err).To(BeNil())
+					updated, err := s.mem.Update(ctx, uniqueUUID2, []byte("data"))
+					Expect(err).To(BeNil())
+					Expect(updated).To(BeTrue())
+					live, err := s.mem.List(ctx)
+					Expect(err).To(BeNil())
+
+					reopened, err := NewFileStore(s.dir, 0, 0, s.logger)
+					Expect(err).To(BeNil())
+					sessionMap, err := reopened.List(ctx)
+					Expect(err).To(BeNil())
+					Expect(sessionMap[uniqueUUID2].Oject).To(Equal([]byte("data")))
+					Expect(sessionMap[uniqueUUID2].Expiration).To(Equal(live[uniqueUUID2].Expiration))
+				})
+				It("drops the sessions that stayed idle for their idle timeout", func() {
+					err := s.mem.CommitItem(ctx, uniqueUUID2, models.Item{
+						Oject:       []byte(uniqueUUID2),
+						Expiration:  time.Now().Add(time.Hour).UnixNano(),
+						IdleTimeout: int64(100 * time.Millisecond),
+					})
+					Expect(err).To(BeNil())
+					_, found, err := s.mem.Lookup(ctx, uniqueUUID2)
+					Expect(err).To(BeNil())
+					Expect(found).To(BeTrue())
+
+					time.Sleep(150 * time.Millisecond)
+					_, found, err = s.mem.Lookup(ctx, uniqueUUID2)
+					Expect(err).To(BeNil())
+					Expect(found).ToNot(BeTrue())
+					reopened, err := NewFileStore(s.dir, 0, 0, s.logger)
+					Expect(err).To(BeNil())
+					sessionMap, err := reopened.List(ctx)
+					Expect(err).To(BeNil())
+					Expect(sessionMap).ToNot(HaveKey(uniqueUUID2))
+				})
+			})
+			When("sessions have a creation time", func() {
+				It("restores it and queries the sessions by it", func() {
+					createdAt := time.Now().Add(-time.Hour).UnixNano()
+					err := s.mem.CommitItem(ctx, uniqueUUID2, models.Item{
+						Oject:      []byte(uniqueUUID2),
+						Expiration: time.Now().Add(time.Minute).UnixNano(),
+						CreatedAt:  createdAt,
+					})
+					Expect(err).To(BeNil())
+
+					reopened, err := NewFileStore(s.dir, 0, 0, s.logger)
+					Expect(err).To(BeNil())
+					page, err := reopened.Query(ctx, Query{Limit: 1})
+					Expect(err).To(BeNil())
+					Expect(page.Items).To(HaveLen(1))
+					Expect(page.Items[0].SessionId).To(Equal(uniqueUUID2))
+					Expect(page.Items[0].Item.CreatedAt).To(Equal(createdAt))
+					Expect(page.NextCursor).ToNot(BeEmpty())
+				})
+			})
+			When("sessions were written in batches", func() {
+				It("restores the batches", func() {
+					err := s.mem.CommitBatch(ctx, map[string]models.Item{
+						uniqueUUID2: {Oject: []byte(uniqueUUID2), Subject: "user-42", Expiration: time.Now().Add(time.Minute).UnixNano()},
+						uniqueUUID3: {Oject: []byte(uniqueUUID3), Subject: "user-42", Expiration: time.Now().Add(time.Minute).UnixNano()},
+					})
+					Expect(err).To(BeNil())
+					extended, err := s.mem.ResetBatch(ctx, map[string]time.Time{uniqueUUID2: expiration})
+					Expect(err).To(BeNil())
+					Expect(extended).To(HaveKey(uniqueUUID2))
+					deleted, err := s.mem.DeleteBatch(ctx, []string{uniqueUUID1, uniqueUUID3})
+					Expect(err).To(BeNil())
+					Expect(deleted).To(HaveLen(2))
+
+					reopened, err := NewFileStore(s.dir, 0, 0, s.logger)
+					Expect(err).To(BeNil())
+					sessionMap, err := reopened.List(ctx)
+					Expect(err).To(BeNil())
+					Expect(sessionMap).To(HaveLen(1))
+					Expect(sessionMap[uniqueUUID2].Subject).To(Equal("user-42"))
+					Expect(sessionMap[uniqueUUID2].Expiration).To(Equal(expiration.UnixNano()))
+				})
+				It("leaves the sessions unchanged when the log can not be written", func() {
+					before := s.mem.Get(ctx)[uniqueUUID1].Expiration
+					Expect(s.mem.Close(ctx)).To(Succeed())
+
+					_, err := s.mem.ResetBatch(ctx, map[string]time.Time{uniqueUUID1: expiration.Add(time.Hour)})
+					Expect(err).ToNot(BeNil())
+					Expect(s.mem.Get(ctx)[uniqueUUID1].Expiration).To(Equal(before))
+				})
+			})
+			When("a session is extended past its maximum expiration", func() {
+				It("logs the capped expiration", func() {
+					maxExpiration := time.Now().Add(2 * time.Hour).UnixNano()
+					err := s.mem.CommitItem(ctx, uniqueUUID2, models.Item{
+						Oject:         []byte(uniqueUUID2),
+						Expiration:    time.Now().Add(time.Minute).UnixNano(),
+						MaxExpiration: maxExpiration,
+					})
+					Expect(err).To(BeNil())
+					_, found, err := s.mem.Reset(ctx, uniqueUUID2, time.Now().Add(3*time.Hour))
+					Expect(err).To(BeNil())
+					Expect(found).To(BeTrue())
+					_, err = s.mem.ResetBatch(ctx, map[string]time.Time{uniqueUUID2: time.Now().Add(3 * time.Hour)})
+					Expect(err).To(BeNil())
+
+					b, err := ioutil.ReadFile(filepath.Join(s.dir, WalFile))
+					Expect(err).To(BeNil())
+					lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+					for _, line := range lines[len(lines)-2:] {
+						var record map[string]interface{}
+						Expect(json.Unmarshal([]byte(line), &record)).To(Succeed())
+						Expect(record["op"]).To(Equal("reset"))
+						Expect(int64(record["expiration"].(float64))).To(BeNumerically("~", maxExpiration, int64(time.Millisecond)))
+					}
+				})
+			})
+			When("the store is reopened from a snapshot", func() {
+				It("restores live sessions and truncates the log", func() {
+					err := s.mem.(*FileStore).Compact()
+					Expect(err).To(BeNil())
+					info, err := os.Stat(filepath.Join(s.dir, WalFile))
+					Expect(err).To(BeNil())
+					Expect(info.Size()).To(BeZero())
+
+					reopened, err := NewFileStore(s.dir, 0, 0, s.logger)
+					Expect(err).To(BeNil())
+					_, found, err := reopened.Find(ctx, uniqueUUID1)
+					Expect(err).To(BeNil())
+					Expect(found).To(BeTrue())
+					_, found, err = reopened.Find(ctx, uniqueUUID2)
+					Expect(err).To(BeNil())
+					Expect(found).ToNot(BeTrue())
+				})
+				It("leaves out the sessions past their maximum expiration", func() {
+					err := s.mem.CommitItem(ctx, uniqueUUID2, models.Item{
+						Oject:         []byte(uniqueUUID2),
+						Expiration:    time.Now().Add(time.Hour).UnixNano(),
+						MaxExpiration: time.Now().Add(100 * time.Millisecond).UnixNano(),
+					})
+					Expect(err).To(BeNil())
+					time.Sleep(101 * time.Millisecond)
+					Expect(s.mem.(*FileStore).Compact()).To(Succeed())
+
+					b, err := ioutil.ReadFile(filepath.Join(s.dir, SnapshotFile))
+					Expect(err).To(BeNil())
+					Expect(string(b)).To(ContainSubstring(uniqueUUID1))
+					Expect(string(b)).ToNot(ContainSubstring(uniqueUUID2))
+				})
+			})
+			When("the last write-ahead log record is truncated", func() {
+				It("ignores the partial record", func() {
+					wal, err := os.OpenFile(filepath.Join(s.dir, WalFile), os.O_WRONLY|os.O_APPEND, 0600)
+					Expect(err).To(BeNil())
+					_, err = wal.WriteString(`{"op":"commit","session_id":"90660b89`)
+					Expect(err).To(BeNil())
+					Expect(wal.Close()).To(BeNil())
+
+					reopened, err := NewFileStore(s.dir, 0, 0, s.logger)
+					Expect(err).To(BeNil())
+					_, found, err := reopened.Find(ctx, uniqueUUID1)
+					Expect(err).To(BeNil())
+					Expect(found).To(BeTrue())
+				})
+			})
+		})
+	})
+	Describe("Health checks", func() {
+		var checkCtx context.Context
+		var cancel context.CancelFunc
+		BeforeEach(func() {
+			var err error
+			s.mem, err = NewFileStore(s.dir, 10*time.Millisecond, 10*time.Millisecond, s.logger)
+			Expect(err).To(BeNil())
+			checkCtx, cancel = context.WithTimeout(ctx, 100*time.Millisecond)
+		})
+
+		AfterEach(func() {
+			cancel()
+		})
+
+		Context("Ping()", func() {
+			It("succeeds while the write-ahead log is open", func() {
+				Expect(s.mem.(HealthChecker).Ping(checkCtx)).To(Succeed())
+				s.mem.(*FileStore).StopSnapshot()
+			})
+		})
+		Context("CheckCleanup()", func() {
+			It("succeeds while the compaction goroutine runs", func() {
+				Expect(s.mem.(HealthChecker).CheckCleanup(checkCtx)).To(Succeed())
+				s.mem.(*FileStore).StopSnapshot()
+			})
+			It("fails once the compaction goroutine stopped", func() {
+				s.mem.(*FileStore).StopSnapshot()
+				Expect(s.mem.(HealthChecker).CheckCleanup(checkCtx)).To(Equal(ErrCleanupStalled))
+			})
+		})
+	})
+	Describe("Close store", func() {
+		uniqueUUID := "90660b89-100e-4f8f-9801-2524df6fbe34"
+		BeforeEach(func() {
+			var err error
+			s.mem, err = NewFileStore(s.dir, 10*time.Millisecond, 10*time.Millisecond, s.logger)
+			Expect(err).To(BeNil())
+			err = s.mem.Commit(ctx, uniqueUUID, []byte(uniqueUUID), time.Now().Add(time.Minute))
+			Expect(err).To(BeNil())
+		})
+
+		Context("Close()", func() {
+			It("flushes the sessions into a final snapshot", func() {
+				Expect(s.mem.Close(ctx)).To(Succeed())
+
+				info, err := os.Stat(filepath.Join(s.dir, WalFile))
+				Expect(err).To(BeNil())
+				Expect(info.Size()).To(BeZero())
+				_, err = os.Stat(filepath.Join(s.dir, SnapshotFile))
+				Expect(err).To(BeNil())
+
+				reopened, err := NewFileStore(s.dir, 0, 0, s.logger)
+				Expect(err).To(BeNil())
+				b, found, err := reopened.Find(ctx, uniqueUUID)
+				Expect(err).To(BeNil())
+				Expect(found).To(BeTrue())
+				Expect(b).To(Equal([]byte(uniqueUUID)))
+			})
+			It("refuses the writes made afterwards", func() {
+				Expect(s.mem.Close(ctx)).To(Succeed())
+				err := s.mem.Commit(ctx, uniqueUUID, []byte(uniqueUUID), time.Now().Add(time.Minute))
+				Expect(err).ToNot(BeNil())
+			})
+		})
+	})
+})
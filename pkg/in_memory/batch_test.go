@@ -0,0 +1,134 @@
+package in_memory_test
+
+import (
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/hecomp/session-management/internal/models"
+	. "github.com/hecomp/session-management/pkg/in_memory"
+	"github.com/hecomp/session-management/pkg/test"
+)
+
+var _ = Describe("Batch", func() {
+
+	sessionIds := make([]string, 8)
+	for i := range sessionIds {
+		sessionIds[i] = fmt.Sprintf("90660b89-100e-4f8f-9801-2524df6fbe%02d", i)
+	}
+
+	batch := func(sessionIds []string, expiration time.Time) map[string]models.Item {
+		items := make(map[string]models.Item, len(sessionIds))
+		for _, sessionId := range sessionIds {
+			items[sessionId] = models.Item{Oject: []byte(sessionId), Subject: "user-42", Expiration: expiration.UnixNano()}
+		}
+		return items
+	}
+
+	keys := func(items map[string]models.Item) []string {
+		var list []string
+		for sessionId := range items {
+			list = append(list, sessionId)
+		}
+		return list
+	}
+
+	for name, newStore := range map[string]func() MemStore{
+		"InMemStore":        func() MemStore { return NewInMemStore(0, test.GetLogger()) },
+		"ShardedInMemStore": func() MemStore { return NewShardedInMemStore(4, 0, test.GetLogger()) },
+	} {
+		newStore := newStore
+
+		Describe(name, func() {
+			var mem MemStore
+
+			BeforeEach(func() {
+				mem = newStore()
+				Expect(mem.CommitBatch(ctx, batch(sessionIds, time.Now().Add(time.Minute)))).To(Succeed())
+			})
+
+			It("commits every session of the batch", func() {
+				items, err := mem.ListBySubject(ctx, "user-42")
+				Expect(err).To(BeNil())
+				Expect(keys(items)).To(ConsistOf(sessionIds))
+				for _, item := range items {
+					Expect(item.CreatedAt).ToNot(BeZero())
+				}
+			})
+			It("deletes the sessions of the batch and returns the live ones", func() {
+				Expect(mem.Commit(ctx, "expired", []byte("expired"), time.Now().Add(-time.Minute))).To(Succeed())
+				deleted, err := mem.DeleteBatch(ctx, append([]string{"expired", "unknown"}, sessionIds[:3]...))
+				Expect(err).To(BeNil())
+				Expect(keys(deleted)).To(ConsistOf(sessionIds[:3]))
+				Expect(deleted[sessionIds[0]].Oject).To(Equal([]byte(sessionIds[0])))
+
+				items, err := mem.List(ctx)
+				Expect(err).To(BeNil())
+				Expect(keys(items)).To(ConsistOf(sessionIds[3:]))
+			})
+			It("extends the live sessions of the batch", func() {
+				expiration := time.Now().Add(time.Hour)
+				extended, err := mem.ResetBatch(ctx, map[string]time.Time{
+					sessionIds[0]: expiration,
+					sessionIds[1]: expiration,
+					"unknown":     expiration,
+				})
+				Expect(err).To(BeNil())
+				Expect(keys(extended)).To(ConsistOf(sessionIds[:2]))
+
+				item, found, err := mem.Lookup(ctx, sessionIds[1])
+				Expect(err).To(BeNil())
+				Expect(found).To(BeTrue())
+				Expect(item.Expiration).To(Equal(expiration.UnixNano()))
+				item, _, err = mem.Lookup(ctx, sessionIds[2])
+				Expect(err).To(BeNil())
+				Expect(item.Expiration).To(BeNumerically("<", expiration.UnixNano()))
+			})
+			It("caps the extension at the maximum lifetime", func() {
+				maxExpiration := time.Now().Add(2 * time.Minute).UnixNano()
+				err := mem.CommitBatch(ctx, map[string]models.Item{
+					"bounded": {Oject: []byte("bounded"), Expiration: time.Now().Add(time.Minute).UnixNano(), MaxExpiration: maxExpiration},
+				})
+				Expect(err).To(BeNil())
+				extended, err := mem.ResetBatch(ctx, map[string]time.Time{"bounded": time.Now().Add(time.Hour)})
+				Expect(err).To(BeNil())
+				Expect(extended["bounded"].Expiration).To(Equal(maxExpiration))
+			})
+		})
+	}
+
+	Context("InMemStore", func() {
+		It("applies the batch at once", func() {
+			mem := NewInMemStore(0, test.GetLogger())
+			done := make(chan struct{})
+			sizes := make(chan int, 1)
+			go func() {
+				defer GinkgoRecover()
+				defer close(sizes)
+				for {
+					select {
+					case <-done:
+						return
+					default:
+					}
+					items, err := mem.List(ctx)
+					Expect(err).To(BeNil())
+					if len(items) != 0 && len(items) != len(sessionIds) {
+						sizes <- len(items)
+						return
+					}
+				}
+			}()
+
+			for i := 0; i < 50; i++ {
+				Expect(mem.CommitBatch(ctx, batch(sessionIds, time.Now().Add(time.Minute)))).To(Succeed())
+				_, err := mem.DeleteBatch(ctx, sessionIds)
+				Expect(err).To(BeNil())
+			}
+			close(done)
+			Expect(<-sizes).To(BeZero(), "a reader saw a partial batch")
+		})
+	})
+})
@@ -0,0 +1,86 @@
+package in_memory
+
+import "container/heap"
+
+// expiryEntry is the position of a session in the expiry index
+type expiryEntry struct {
+	sessionId  string
+	expiration int64
+	index      int
+}
+
+// expiryIndex is a min-heap of the sessions ordered by expiration, so the sessions
+// that expire first are found in O(1) and added, moved or removed in O(log n). It is
+// not safe for concurrent use, the InMemStore guards it with its lock.
+type expiryIndex struct {
+	entries   []*expiryEntry
+	bySession map[string]*expiryEntry
+}
+
+func newExpiryIndex() *expiryIndex {
+	return &expiryIndex{bySession: make(map[string]*expiryEntry)}
+}
+
+// set adds the session to the index or moves it to its new expiration, and reports
+// whether it is now the first session to expire
+func (x *expiryIndex) set(sessionId string, expiration int64) bool {
+	if entry, found := x.bySession[sessionId]; found {
+		entry.expiration = expiration
+		heap.Fix(x, entry.index)
+		return entry.index == 0
+	}
+
+	entry := &expiryEntry{sessionId: sessionId, expiration: expiration}
+	x.bySession[sessionId] = entry
+	heap.Push(x, entry)
+	return entry.index == 0
+}
+
+// remove drops the session from the index
+func (x *expiryIndex) remove(sessionId string) {
+	entry, found := x.bySession[sessionId]
+	if !found {
+		return
+	}
+	heap.Remove(x, entry.index)
+	delete(x.bySession, sessionId)
+}
+
+// first returns the session that expires first and its expiration
+func (x *expiryIndex) first() (string, int64, bool) {
+	if len(x.entries) == 0 {
+		return "", 0, false
+	}
+	return x.entries[0].sessionId, x.entries[0].expiration, true
+}
+
+// Len, Less, Swap, Push and Pop implement heap.Interface, they are only meant to be
+// called by the heap package.
+
+func (x *expiryIndex) Len() int {
+	return len(x.entries)
+}
+
+func (x *expiryIndex) Less(i, j int) bool {
+	return x.entries[i].expiration < x.entries[j].expiration
+}
+
+func (x *expiryIndex) Swap(i, j int) {
+	x.entries[i], x.entries[j] = x.entries[j], x.entries[i]
+	x.entries[i].index = i
+	x.entries[j].index = j
+}
+
+func (x *expiryIndex) Push(e interface{}) {
+	entry := e.(*expiryEntry)
+	entry.index = len(x.entries)
+	x.entries = append(x.entries, entry)
+}
+
+func (x *expiryIndex) Pop() interface{} {
+	last := len(x.entries) - 1
+	entry := x.entries[last]
+	x.entries[last] = nil
+	x.entries = x.entries[:last]
+	return entry
+}
@@ -0,0 +1,6 @@
+package in_memory
+
+// DeleteSessionExpired exposes the expired-session cleanup to the benchmarks
+func (m *InMemStore) DeleteSessionExpired() {
+	m.deleteSessionExpired()
+}
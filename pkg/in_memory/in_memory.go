@@ -1,42 +1,115 @@
 package in_memory
 
 import (
+	"context"
+	"errors"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/metrics"
+	"github.com/go-kit/kit/metrics/discard"
 
 	. "github.com/hecomp/session-management/internal/models"
 )
 
 // MemStore
+//
 //go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 . MemStore
 type MemStore interface {
-	Commit(sessionId string, b []byte, expiration time.Time) error
-	Delete(sessionId string) error
-	Reset(sessionId string, expiration time.Time) ([]byte, bool, error)
-	Find(sessionId string) ([]byte, bool, error)
-	List() (map[string]Item, error)
-	Get() map[string]Item
+	Commit(ctx context.Context, sessionId string, b []byte, expiration time.Time) error
+	CommitWithSubject(ctx context.Context, sessionId string, subject string, b []byte, expiration time.Time) error
+	CommitItem(ctx context.Context, sessionId string, item Item) error
+	CommitBatch(ctx context.Context, items map[string]Item) error
+	Delete(ctx context.Context, sessionId string) error
+	DeleteBatch(ctx context.Context, sessionIds []string) (map[string]Item, error)
+	DeleteBySubject(ctx context.Context, subject string) ([]string, error)
+	Reset(ctx context.Context, sessionId string, expiration time.Time) ([]byte, bool, error)
+	ResetBatch(ctx context.Context, expirations map[string]time.Time) (map[string]Item, error)
+	Update(ctx context.Context, sessionId string, b []byte) (bool, error)
+	Find(ctx context.Context, sessionId string) ([]byte, bool, error)
+	Lookup(ctx context.Context, sessionId string) (Item, bool, error)
+	ListBySubject(ctx context.Context, subject string) (map[string]Item, error)
+	List(ctx context.Context) (map[string]Item, error)
+	Query(ctx context.Context, query Query) (Page, error)
+	Get(ctx context.Context) map[string]Item
+	Close(ctx context.Context) error
+}
+
+// HealthChecker is implemented by the stores that can report whether they are able to
+// serve requests, it backs the health and readiness probes of the service.
+type HealthChecker interface {
+	// Ping reports whether the backend of the store is reachable
+	Ping(ctx context.Context) error
+	// CheckCleanup reports whether the background cleanup goroutine of the store, if it
+	// has one, still answers before the context is done
+	CheckCleanup(ctx context.Context) error
+}
+
+// ErrCleanupStalled is returned by CheckCleanup when the cleanup goroutine exited or is stuck
+var ErrCleanupStalled = errors.New("session cleanup goroutine is not answering")
+
+// Metrics are the store-level instruments updated by the InMemStore. A nil instrument
+// is replaced by a discarding one.
+type Metrics struct {
+	// ActiveSessions tracks the number of sessions held by the store, it is only ever
+	// added to so that several stores can share it
+	ActiveSessions metrics.Gauge
+	// ExpiredSessions counts the sessions removed by the background cleanup
+	ExpiredSessions metrics.Counter
+	// CleanupSweeps counts the runs of the background cleanup
+	CleanupSweeps metrics.Counter
 }
 
 // InMemStore represents the session in-memory store.
 type InMemStore struct {
-	logger      log.Logger
-	items       map[string]Item
-	mu          sync.RWMutex
-	stopCleanup chan bool
+	logger       log.Logger
+	metrics      Metrics
+	items        map[string]Item
+	lastAccess   map[string]*int64
+	subjects     map[string]map[string]struct{}
+	expiry       *expiryIndex
+	mu           sync.RWMutex
+	wakeCleanup  chan struct{}
+	stopCleanup  chan bool
+	stopOnce     sync.Once
+	probeCleanup chan struct{}
 }
 
 // NewInMemStore returns a new InMemStore instance, with a background session cleanup goroutine that
-// runs every minute to remove expired session data.
+// removes expired session data as their expiration is reached, waiting at least sessionInterval
+// between two runs.
 func NewInMemStore(sessionInterval time.Duration, logger log.Logger) MemStore {
+	return NewInstrumentedInMemStore(sessionInterval, Metrics{}, logger)
+}
+
+// NewInstrumentedInMemStore returns a new InMemStore instance like NewInMemStore, reporting
+// the number of active sessions and the expired-session sweeps to the given metrics.
+func NewInstrumentedInMemStore(sessionInterval time.Duration, metrics Metrics, logger log.Logger) MemStore {
+	if metrics.ActiveSessions == nil {
+		metrics.ActiveSessions = discard.NewGauge()
+	}
+	if metrics.ExpiredSessions == nil {
+		metrics.ExpiredSessions = discard.NewCounter()
+	}
+	if metrics.CleanupSweeps == nil {
+		metrics.CleanupSweeps = discard.NewCounter()
+	}
+
 	m := &InMemStore{
-		items: make(map[string]Item),
-		logger: logger,
+		items:       make(map[string]Item),
+		lastAccess:  make(map[string]*int64),
+		subjects:    make(map[string]map[string]struct{}),
+		expiry:      newExpiryIndex(),
+		wakeCleanup: make(chan struct{}, 1),
+		logger:      logger,
+		metrics:     metrics,
 	}
 
 	if sessionInterval > 0 {
+		m.stopCleanup = make(chan bool)
+		m.probeCleanup = make(chan struct{})
 		go m.startSessionCleanup(sessionInterval)
 	}
 
@@ -46,95 +119,290 @@ func NewInMemStore(sessionInterval time.Duration, logger log.Logger) MemStore {
 // Find returns the sessionId for a given session from the InMemStore instance.
 // If the session id is not found or is expired, the returned exists flag will
 // be set to false.
-func (m *InMemStore) Find(sessionId string) ([]byte, bool, error) {
+func (m *InMemStore) Find(ctx context.Context, sessionId string) ([]byte, bool, error) {
 	m.logger.Log("method", "find", "sessionId", sessionId)
+	item, found := m.touch(sessionId)
+	if !found {
+		return nil, false, nil
+	}
+
+	return item.Oject, true, nil
+}
+
+// Lookup returns the item, data and expiration, for a given session from the InMemStore
+// instance. If the session id is not found or is expired, the returned exists flag will
+// be set to false.
+func (m *InMemStore) Lookup(ctx context.Context, sessionId string) (Item, bool, error) {
+	m.logger.Log("method", "lookup", "sessionId", sessionId)
+	item, found := m.touch(sessionId)
+	if !found {
+		return Item{}, false, nil
+	}
+
+	return item, true, nil
+}
+
+// Peek returns the item of a live session like Lookup, without refreshing its last access
+// time or sliding its expiration.
+func (m *InMemStore) Peek(ctx context.Context, sessionId string) (Item, bool, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
 	item, found := m.items[sessionId]
 	if !found {
-		return nil, false, nil
+		return Item{}, false, nil
 	}
-
-	if time.Now().UnixNano() > item.Expiration {
-		m.logger.Log("action", "expired", "sessionId", sessionId)
-		return nil, false, nil
+	item, live := m.live(sessionId, item, time.Now().UnixNano())
+	if !live {
+		return Item{}, false, nil
 	}
-
-	return item.Oject, true, nil
+	return item, true, nil
 }
 
 // Commit adds a session sessionId and data to the InMemStore instance with the given
 // expiration time. If the session sessionId already exists, then the data and expiration
 // time are updated.
-func (m *InMemStore) Commit(sessionId string, b []byte, expiration time.Time) error {
+func (m *InMemStore) Commit(ctx context.Context, sessionId string, b []byte, expiration time.Time) error {
+	return m.CommitWithSubject(ctx, sessionId, "", b, expiration)
+}
+
+// CommitWithSubject adds a session like Commit and indexes it under the given subject,
+// so that every session of the subject can be found or deleted at once.
+func (m *InMemStore) CommitWithSubject(ctx context.Context, sessionId string, subject string, b []byte, expiration time.Time) error {
+	return m.CommitItem(ctx, sessionId, Item{Oject: b, Expiration: expiration.UnixNano(), Subject: subject})
+}
+
+// CommitItem adds a session like CommitWithSubject, keeping the idle timeout, maximum
+// expiration and creation time of the item. The expiration is capped at the maximum
+// expiration.
+func (m *InMemStore) CommitItem(ctx context.Context, sessionId string, item Item) error {
 	m.logger.Log("method", "commit", "sessionId", sessionId)
-	m.mu.Lock()
-	m.items[sessionId] = Item{
-		Oject:     b,
-		Expiration: expiration.UnixNano(),
+	item.Expiration = capExpiration(item, item.Expiration)
+	item.LastAccess = time.Now().UnixNano()
+	if item.CreatedAt == 0 {
+		item.CreatedAt = item.LastAccess
 	}
+
+	m.mu.Lock()
+	m.put(sessionId, item)
 	m.mu.Unlock()
 
 	return nil
 }
 
+// CommitBatch adds every session like CommitItem under a single acquisition of the lock
+func (m *InMemStore) CommitBatch(ctx context.Context, items map[string]Item) error {
+	m.logger.Log("method", "commitBatch", "sessions", len(items))
+	now := time.Now().UnixNano()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for sessionId, item := range items {
+		item.Expiration = capExpiration(item, item.Expiration)
+		item.LastAccess = now
+		if item.CreatedAt == 0 {
+			item.CreatedAt = now
+		}
+		m.put(sessionId, item)
+	}
+	return nil
+}
+
 // Delete removes a session sessionId and corresponding data from the InMemStore
 // instance.
-func (m *InMemStore) Delete(sessionId string) error {
+func (m *InMemStore) Delete(ctx context.Context, sessionId string) error {
 	m.logger.Log("method", "delete", "sessionId", sessionId)
 	m.mu.Lock()
-	delete(m.items, sessionId)
+	m.remove(sessionId)
 	m.mu.Unlock()
 
 	return nil
 }
 
-// Reset extend a session ttl from the InMemStore instance.
-func (m *InMemStore) Reset(sessionId string, expiration time.Time) ([]byte, bool, error) {
-	m.logger.Log("method", "reset")
+// DeleteBatch removes the sessions under a single acquisition of the lock and returns the
+// live sessions that were removed
+func (m *InMemStore) DeleteBatch(ctx context.Context, sessionIds []string) (map[string]Item, error) {
+	m.logger.Log("method", "deleteBatch", "sessions", len(sessionIds))
+	now := time.Now().UnixNano()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	deleted := make(map[string]Item)
+	for _, sessionId := range sessionIds {
+		if item, found := m.items[sessionId]; found {
+			if item, live := m.live(sessionId, item, now); live {
+				deleted[sessionId] = item
+			}
+		}
+		m.remove(sessionId)
+	}
+	return deleted, nil
+}
+
+// DeleteBySubject removes every session of the subject from the InMemStore instance
+// and returns the ids of the live sessions that were removed.
+func (m *InMemStore) DeleteBySubject(ctx context.Context, subject string) ([]string, error) {
+	m.logger.Log("method", "deleteBySubject", "subject", subject)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var sessionIds []string
+	for sessionId := range m.listBySubject(subject) {
+		sessionIds = append(sessionIds, sessionId)
+	}
+	for sessionId := range m.subjects[subject] {
+		m.remove(sessionId)
+	}
+
+	return sessionIds, nil
+}
+
+// ListBySubject returns the live sessions of the subject from the InMemStore
+// instance, without refreshing their last access time.
+func (m *InMemStore) ListBySubject(ctx context.Context, subject string) (map[string]Item, error) {
+	m.logger.Log("method", "listBySubject", "subject", subject)
 	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.listBySubject(subject), nil
+}
+
+// Reset extend a session ttl from the InMemStore instance. The new expiration is capped
+// at the maximum expiration of the session.
+func (m *InMemStore) Reset(ctx context.Context, sessionId string, expiration time.Time) ([]byte, bool, error) {
+	m.logger.Log("method", "reset")
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	item, found := m.items[sessionId]
 	if !found {
 		return nil, false, nil
 	}
 
-	if time.Now().UnixNano() > item.Expiration {
+	now := time.Now().UnixNano()
+	if _, live := m.live(sessionId, item, now); !live {
 		m.logger.Log("action", "expired", "sessionId", sessionId)
 		return nil, false, nil
 	}
-	m.mu.RUnlock()
 
-	m.mu.Lock()
-	item.Expiration = expiration.UnixNano()
-	m.items[sessionId] = item
-	m.mu.Unlock()
-		
+	item.Expiration = capExpiration(item, expiration.UnixNano())
+	item.LastAccess = now
+	m.store(sessionId, item)
+	m.index(sessionId, item.Deadline())
+
 	return item.Oject, true, nil
-	
+}
+
+// ResetBatch extends the live sessions to their expiration like Reset under a single
+// acquisition of the lock and returns the sessions that were extended
+func (m *InMemStore) ResetBatch(ctx context.Context, expirations map[string]time.Time) (map[string]Item, error) {
+	m.logger.Log("method", "resetBatch", "sessions", len(expirations))
+	now := time.Now().UnixNano()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	extended := make(map[string]Item)
+	for sessionId, expiration := range expirations {
+		item, found := m.items[sessionId]
+		if !found {
+			continue
+		}
+		if _, live := m.live(sessionId, item, now); !live {
+			continue
+		}
+		item.Expiration = capExpiration(item, expiration.UnixNano())
+		item.LastAccess = now
+		m.store(sessionId, item)
+		m.index(sessionId, item.Deadline())
+		extended[sessionId] = item
+	}
+	return extended, nil
+}
+
+// Update replaces the data of a live session in the InMemStore instance, keeping
+// its expiration time.
+func (m *InMemStore) Update(ctx context.Context, sessionId string, b []byte) (bool, error) {
+	m.logger.Log("method", "update", "sessionId", sessionId)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	item, found := m.items[sessionId]
+	if !found {
+		return false, nil
+	}
+
+	now := time.Now().UnixNano()
+	if _, live := m.live(sessionId, item, now); !live {
+		m.logger.Log("action", "expired", "sessionId", sessionId)
+		return false, nil
+	}
+
+	item.Oject = b
+	item.LastAccess = now
+	m.store(sessionId, item)
+
+	return true, nil
 }
 
 // List return a list of all the sessions from in-mem store
-func (m *InMemStore) List() (map[string]Item, error) {
+func (m *InMemStore) List(ctx context.Context) (map[string]Item, error) {
 	m.logger.Log("method", "list")
 	m.mu.RLock()
 	defer m.mu.RUnlock()
-	return m.items, nil
+
+	items := make(map[string]Item, len(m.items))
+	for sessionId, item := range m.items {
+		items[sessionId] = m.load(sessionId, item)
+	}
+	return items, nil
+}
+
+// Query returns the page of the live sessions selected by the query, filtered in place
+// without copying the other sessions. The sessions of a subject are found by its index.
+func (m *InMemStore) Query(ctx context.Context, query Query) (Page, error) {
+	m.logger.Log("method", "query", "subject", query.Subject, "limit", query.Limit)
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if query.Subject != "" {
+		return query.Filter(m.listBySubject(query.Subject), time.Now().UnixNano())
+	}
+	return query.filter(m.items, time.Now().UnixNano(), m.load)
 }
 
-// startSessionCleanup only the sessions that have not expired are expected to be kept in memory
+// startSessionCleanup only the sessions that have not expired are expected to be kept in memory.
+// It sleeps until the first expiration of the expiry index, or until a session that expires
+// sooner is indexed, keeping at least interval between two runs to batch close expirations.
 func (m *InMemStore) startSessionCleanup(interval time.Duration) {
 	m.logger.Log("method", "startSessionCleanup")
-	m.stopCleanup = make(chan bool)
-	ticker := time.NewTicker(interval)
+	lastRun := time.Now()
 	for {
+		var timer *time.Timer
+		var expire <-chan time.Time
+		if next, found := m.nextExpiration(); found {
+			wait := time.Until(next)
+			if minWait := time.Until(lastRun.Add(interval)); minWait > wait {
+				wait = minWait
+			}
+			timer = time.NewTimer(wait)
+			expire = timer.C
+		}
+
 		select {
-		case <-ticker.C:
+		case <-expire:
 			m.deleteSessionExpired()
+			lastRun = time.Now()
+		case <-m.wakeCleanup:
+		case <-m.probeCleanup:
 		case <-m.stopCleanup:
-			ticker.Stop()
+			if timer != nil {
+				timer.Stop()
+			}
 			return
 		}
+		if timer != nil {
+			timer.Stop()
+		}
 	}
 }
 
@@ -142,24 +410,237 @@ func (m *InMemStore) startSessionCleanup(interval time.Duration) {
 func (m *InMemStore) StopSessionCleanup() {
 	m.logger.Log("stopCleanup")
 	if m.stopCleanup != nil {
-		m.stopCleanup <- true
+		m.stopOnce.Do(func() { m.stopCleanup <- true })
+	}
+}
+
+// Close stops the background cleanup goroutine of the InMemStore instance, its sessions
+// are only held in memory so there is nothing to flush.
+func (m *InMemStore) Close(ctx context.Context) error {
+	m.StopSessionCleanup()
+	return nil
+}
+
+// Ping reports whether the InMemStore instance is reachable, which it always is
+func (m *InMemStore) Ping(ctx context.Context) error {
+	return nil
+}
+
+// CheckCleanup reports whether the background cleanup goroutine of the InMemStore instance
+// answers before the context is done.
+func (m *InMemStore) CheckCleanup(ctx context.Context) error {
+	return ProbeCleanup(ctx, m.probeCleanup)
+}
+
+// ProbeCleanup sends on the probe channel of a cleanup goroutine, which receives from it
+// in its select loop, and fails with ErrCleanupStalled once the context is done. A nil
+// channel means the store has no cleanup goroutine.
+func ProbeCleanup(ctx context.Context, probe chan struct{}) error {
+	if probe == nil {
+		return nil
+	}
+	select {
+	case probe <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ErrCleanupStalled
 	}
 }
 
-// deleteSessionExpired any expired sessions should be removed automatically
+// deleteSessionExpired any expired sessions should be removed automatically, they are taken
+// from the front of the expiry index so only the expired sessions are visited
 func (m *InMemStore) deleteSessionExpired() {
 	m.logger.Log("deleteSessionExpired")
 	now := time.Now().UnixNano()
 	m.mu.Lock()
-	for sessionId, item := range m.items {
-		if now > item.Expiration {
-			m.logger.Log("action", "session-expired", "sessionId", sessionId)
-			delete(m.items, sessionId)
+	for {
+		sessionId, expiration, found := m.expiry.first()
+		if !found || now <= expiration {
+			break
+		}
+		// the lookups under the read lock move the idle deadline without reindexing
+		if item, live := m.live(sessionId, m.items[sessionId], now); live {
+			m.expiry.set(sessionId, item.Deadline())
+			continue
 		}
+		m.logger.Log("action", "session-expired", "sessionId", sessionId)
+		m.remove(sessionId)
+		m.metrics.ExpiredSessions.Add(1)
 	}
+	m.metrics.CleanupSweeps.Add(1)
 	m.mu.Unlock()
 }
 
-func (m *InMemStore) Get() map[string]Item {
+// nextExpiration returns the time the first session of the expiry index expires
+func (m *InMemStore) nextExpiration() (time.Time, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	_, expiration, found := m.expiry.first()
+	if !found {
+		return time.Time{}, false
+	}
+	// sessions expire strictly after their expiration
+	return time.Unix(0, expiration+1), true
+}
+
+func (m *InMemStore) Get(ctx context.Context) map[string]Item {
 	return m.items
-}
\ No newline at end of file
+}
+
+// touch returns the live item of the session and refreshes its last access time. The
+// lookup runs under the read lock, the write lock is only taken to slide the expiration
+// of a session with an idle timeout.
+func (m *InMemStore) touch(sessionId string) (Item, bool) {
+	now := time.Now().UnixNano()
+	m.mu.RLock()
+	item, found := m.items[sessionId]
+	if found {
+		item, found = m.live(sessionId, item, now)
+		if !found {
+			m.logger.Log("action", "expired", "sessionId", sessionId)
+		}
+	}
+	if !found {
+		m.mu.RUnlock()
+		return Item{}, false
+	}
+	if item.IdleTimeout == 0 || now+item.IdleTimeout <= item.Expiration {
+		m.accessed(sessionId, now)
+		if item.LastAccess < now {
+			item.LastAccess = now
+		}
+		m.mu.RUnlock()
+		return item, true
+	}
+	m.mu.RUnlock()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// the session may have changed while the lock was released
+	item, found = m.items[sessionId]
+	if !found {
+		return Item{}, false
+	}
+	if item, found = m.live(sessionId, item, now); !found {
+		return Item{}, false
+	}
+	if item.LastAccess < now {
+		item.LastAccess = now
+	}
+	if now+item.IdleTimeout > item.Expiration {
+		item.Expiration = capExpiration(item, now+item.IdleTimeout)
+	}
+	m.store(sessionId, item)
+	return item, true
+}
+
+// listBySubject returns the live sessions of the subject, the caller must hold
+// the lock
+func (m *InMemStore) listBySubject(subject string) map[string]Item {
+	now := time.Now().UnixNano()
+	items := make(map[string]Item, len(m.subjects[subject]))
+	for sessionId := range m.subjects[subject] {
+		if item, live := m.live(sessionId, m.items[sessionId], now); live {
+			items[sessionId] = item
+		}
+	}
+	return items
+}
+
+// index moves the session to its expiration in the expiry index and wakes the cleanup
+// when it is now the first session to expire, the caller must hold the write lock
+func (m *InMemStore) index(sessionId string, expiration int64) {
+	if m.expiry.set(sessionId, expiration) {
+		select {
+		case m.wakeCleanup <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// store replaces the item of a stored session in place, the caller must hold the write
+// lock
+func (m *InMemStore) store(sessionId string, item Item) {
+	m.items[sessionId] = item
+	atomic.StoreInt64(m.lastAccess[sessionId], item.LastAccess)
+}
+
+// load returns the item of a stored session with its last access time, the caller must
+// hold the lock
+func (m *InMemStore) load(sessionId string, item Item) Item {
+	item.LastAccess = atomic.LoadInt64(m.lastAccess[sessionId])
+	return item
+}
+
+// live returns the stored item with its last access time and whether it has not expired
+// at now, the caller must hold the lock
+func (m *InMemStore) live(sessionId string, item Item, now int64) (Item, bool) {
+	item = m.load(sessionId, item)
+	return item, !expired(item, now)
+}
+
+// accessed moves the last access time of a stored session forward to now, the caller
+// must hold the lock. Lookups holding the read lock race on it, so it is only updated
+// atomically.
+func (m *InMemStore) accessed(sessionId string, now int64) {
+	last := m.lastAccess[sessionId]
+	for {
+		previous := atomic.LoadInt64(last)
+		if previous >= now || atomic.CompareAndSwapInt64(last, previous, now) {
+			return
+		}
+	}
+}
+
+// put stores the item and keeps the subject and expiry indexes in sync, the caller
+// must hold the write lock
+func (m *InMemStore) put(sessionId string, item Item) {
+	m.remove(sessionId)
+	lastAccess := item.LastAccess
+	m.items[sessionId] = item
+	m.lastAccess[sessionId] = &lastAccess
+	m.index(sessionId, item.Deadline())
+	m.metrics.ActiveSessions.Add(1)
+	if item.Subject == "" {
+		return
+	}
+	if m.subjects[item.Subject] == nil {
+		m.subjects[item.Subject] = make(map[string]struct{})
+	}
+	m.subjects[item.Subject][sessionId] = struct{}{}
+}
+
+// remove deletes the item and its subject and expiry index entries, the caller must
+// hold the write lock
+func (m *InMemStore) remove(sessionId string) {
+	item, found := m.items[sessionId]
+	if !found {
+		return
+	}
+	delete(m.items, sessionId)
+	delete(m.lastAccess, sessionId)
+	m.expiry.remove(sessionId)
+	m.metrics.ActiveSessions.Add(-1)
+	if item.Subject == "" {
+		return
+	}
+	delete(m.subjects[item.Subject], sessionId)
+	if len(m.subjects[item.Subject]) == 0 {
+		delete(m.subjects, item.Subject)
+	}
+}
+
+// expired reports whether the item is past its deadline
+func expired(item Item, now int64) bool {
+	return now > item.Deadline()
+}
+
+// capExpiration returns the expiration limited to the maximum expiration of the item
+func capExpiration(item Item, expiration int64) int64 {
+	if item.MaxExpiration > 0 && expiration > item.MaxExpiration {
+		return item.MaxExpiration
+	}
+	return expiration
+}
@@ -0,0 +1,167 @@
+package in_memory_test
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+
+	"github.com/hecomp/session-management/internal/models"
+	. "github.com/hecomp/session-management/pkg/in_memory"
+)
+
+const benchSessions = 10000
+
+// benchSessionIds returns the session ids shared by the benchmarks
+func benchSessionIds() []string {
+	sessionIds := make([]string, benchSessions)
+	for i := range sessionIds {
+		sessionIds[i] = "90660b89-100e-4f8f-9801-" + strconv.Itoa(100000000000+i)
+	}
+	return sessionIds
+}
+
+// benchmarkCommit commits sessions from parallel goroutines
+func benchmarkCommit(b *testing.B, mem MemStore) {
+	sessionIds := benchSessionIds()
+	expiration := time.Now().Add(time.Hour)
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			sessionId := sessionIds[i%benchSessions]
+			mem.Commit(ctx, sessionId, []byte(sessionId), expiration)
+			i++
+		}
+	})
+}
+
+// benchmarkFind looks up committed sessions from parallel goroutines
+func benchmarkFind(b *testing.B, mem MemStore) {
+	sessionIds := benchSessionIds()
+	expiration := time.Now().Add(time.Hour)
+	for _, sessionId := range sessionIds {
+		mem.Commit(ctx, sessionId, []byte(sessionId), expiration)
+	}
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			mem.Find(ctx, sessionIds[i%benchSessions])
+			i++
+		}
+	})
+}
+
+// benchmarkMixed runs one Commit for every nine Find from parallel goroutines
+func benchmarkMixed(b *testing.B, mem MemStore) {
+	sessionIds := benchSessionIds()
+	expiration := time.Now().Add(time.Hour)
+	for _, sessionId := range sessionIds {
+		mem.Commit(ctx, sessionId, []byte(sessionId), expiration)
+	}
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			sessionId := sessionIds[i%benchSessions]
+			if i%10 == 0 {
+				mem.Commit(ctx, sessionId, []byte(sessionId), expiration)
+			} else {
+				mem.Find(ctx, sessionId)
+			}
+			i++
+		}
+	})
+}
+
+func BenchmarkInMemStoreCommit(b *testing.B) {
+	benchmarkCommit(b, NewInMemStore(0, log.NewNopLogger()))
+}
+
+func BenchmarkShardedInMemStoreCommit(b *testing.B) {
+	benchmarkCommit(b, NewShardedInMemStore(DefaultShards, 0, log.NewNopLogger()))
+}
+
+func BenchmarkInMemStoreFind(b *testing.B) {
+	benchmarkFind(b, NewInMemStore(0, log.NewNopLogger()))
+}
+
+func BenchmarkShardedInMemStoreFind(b *testing.B) {
+	benchmarkFind(b, NewShardedInMemStore(DefaultShards, 0, log.NewNopLogger()))
+}
+
+func BenchmarkInMemStoreMixed(b *testing.B) {
+	benchmarkMixed(b, NewInMemStore(0, log.NewNopLogger()))
+}
+
+func BenchmarkShardedInMemStoreMixed(b *testing.B) {
+	benchmarkMixed(b, NewShardedInMemStore(DefaultShards, 0, log.NewNopLogger()))
+}
+
+// The FindDuringCleanup benchmarks sweep the store every millisecond while looking up sessions
+func BenchmarkInMemStoreFindDuringCleanup(b *testing.B) {
+	mem := NewInMemStore(time.Millisecond, log.NewNopLogger())
+	defer mem.(*InMemStore).StopSessionCleanup()
+	benchmarkFind(b, mem)
+}
+
+func BenchmarkShardedInMemStoreFindDuringCleanup(b *testing.B) {
+	mem := NewShardedInMemStore(DefaultShards, time.Millisecond, log.NewNopLogger())
+	defer mem.(*ShardedInMemStore).StopSessionCleanup()
+	benchmarkFind(b, mem)
+}
+
+const (
+	sweepSessions = 1000000
+	sweepExpired  = 1000
+)
+
+// BenchmarkInMemStoreSweep measures a cleanup run removing sweepExpired sessions from a
+// store holding a million sessions
+func BenchmarkInMemStoreSweep(b *testing.B) {
+	mem := NewInMemStore(0, log.NewNopLogger()).(*InMemStore)
+	live := time.Now().Add(time.Hour)
+	for i := 0; i < sweepSessions; i++ {
+		sessionId := strconv.Itoa(i)
+		mem.Commit(ctx, sessionId, nil, live)
+	}
+
+	expiredAt := time.Now().Add(-time.Second)
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		b.StopTimer()
+		for i := 0; i < sweepExpired; i++ {
+			mem.Commit(ctx, "expired-"+strconv.Itoa(i), nil, expiredAt)
+		}
+		b.StartTimer()
+		mem.DeleteSessionExpired()
+	}
+}
+
+// BenchmarkMapScanSweep measures the same cleanup run done by walking every session, as
+// the store did before it kept an expiry index
+func BenchmarkMapScanSweep(b *testing.B) {
+	items := make(map[string]models.Item, sweepSessions+sweepExpired)
+	live := time.Now().Add(time.Hour).UnixNano()
+	for i := 0; i < sweepSessions; i++ {
+		items[strconv.Itoa(i)] = models.Item{Expiration: live}
+	}
+
+	expiredAt := time.Now().Add(-time.Second).UnixNano()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		b.StopTimer()
+		for i := 0; i < sweepExpired; i++ {
+			items["expired-"+strconv.Itoa(i)] = models.Item{Expiration: expiredAt}
+		}
+		b.StartTimer()
+		now := time.Now().UnixNano()
+		for sessionId, item := range items {
+			if now > item.Expiration {
+				delete(items, sessionId)
+			}
+		}
+	}
+}
@@ -1,12 +1,15 @@
 package in_memory_test
 
 import (
+	"context"
 	"time"
 
 	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/metrics/generic"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
+	"github.com/hecomp/session-management/internal/models"
 	. "github.com/hecomp/session-management/pkg/in_memory"
 	"github.com/hecomp/session-management/pkg/test"
 )
@@ -33,8 +36,8 @@ var _ = Describe("InMemory", func() {
 			When("the API os called with TTL as param", func() {
 				It("stores an unique sessionId in-memory store", func() {
 					uniqueUUID := "90660b89-100e-4f8f-9801-2524df6fbe34"
-					err := s.mem.Commit(uniqueUUID, []byte(uniqueUUID), time.Now().Add(time.Minute))
-					sessionMap, _ := s.mem.List()
+					err := s.mem.Commit(ctx, uniqueUUID, []byte(uniqueUUID), time.Now().Add(time.Minute))
+					sessionMap, _ := s.mem.List(ctx)
 					Expect(err).To(BeNil())
 					Expect(string(sessionMap[uniqueUUID].Oject)).To(Equal(inMemResponse))
 				})
@@ -46,34 +49,34 @@ var _ = Describe("InMemory", func() {
 		inMemResponse := "90660b89-100e-4f8f-9801-2524df6fbe34"
 		uniqueUUID := "90660b89-100e-4f8f-9801-2524df6fbe34"
 		BeforeEach(func() {
-			err := s.mem.Commit(uniqueUUID, []byte(uniqueUUID), time.Now().Add(time.Minute))
+			err := s.mem.Commit(ctx, uniqueUUID, []byte(uniqueUUID), time.Now().Add(time.Minute))
 			Expect(err).To(BeNil())
 		})
 
 		Context("Find()", func() {
 			When("the API os called with TTL as param", func() {
 				It("finds sessionId in-memory store", func() {
-					obj, _, err := s.mem.Find(uniqueUUID)
+					obj, _, err := s.mem.Find(ctx, uniqueUUID)
 					Expect(err).To(BeNil())
 					Expect(string(obj)).To(Equal(inMemResponse))
 				})
 				It("matches sessionId in the in-memory store", func() {
-					_, found, err := s.mem.Find(uniqueUUID)
+					_, found, err := s.mem.Find(ctx, uniqueUUID)
 					Expect(err).To(BeNil())
 					Expect(found).To(BeTrue())
 				})
 				It("does not matches sessionId in the in-memory store", func() {
 					uniqueUUID2 := "90660b89-100e-4f8f-9801-2524df6fbe99"
-					_, found, err := s.mem.Find(uniqueUUID2)
+					_, found, err := s.mem.Find(ctx, uniqueUUID2)
 					Expect(err).To(BeNil())
 					Expect(found).ToNot(BeTrue())
 				})
 
 				It("expired sessionId in the in-memory store", func() {
-					er := s.mem.Commit(uniqueUUID, []byte(uniqueUUID), time.Now().Add(100*time.Millisecond))
+					er := s.mem.Commit(ctx, uniqueUUID, []byte(uniqueUUID), time.Now().Add(100*time.Millisecond))
 					time.Sleep(101 * time.Millisecond)
 
-					obj, found, err := s.mem.Find(uniqueUUID)
+					obj, found, err := s.mem.Find(ctx, uniqueUUID)
 					Expect(err).To(BeNil())
 					Expect(er).To(BeNil())
 					Expect(obj).To(BeNil())
@@ -83,18 +86,48 @@ var _ = Describe("InMemory", func() {
 		})
 	})
 
+	Describe("Lookup session", func() {
+		uniqueUUID := "90660b89-100e-4f8f-9801-2524df6fbe34"
+		expiration := time.Now().Add(time.Minute)
+		BeforeEach(func() {
+			err := s.mem.Commit(ctx, uniqueUUID, []byte(uniqueUUID), expiration)
+			Expect(err).To(BeNil())
+		})
+
+		Context("Lookup()", func() {
+			When("the API os called with a sessionId", func() {
+				It("returns the item in-memory store", func() {
+					item, found, err := s.mem.Lookup(ctx, uniqueUUID)
+					Expect(err).To(BeNil())
+					Expect(found).To(BeTrue())
+					Expect(string(item.Oject)).To(Equal(uniqueUUID))
+					Expect(item.Expiration).To(Equal(expiration.UnixNano()))
+				})
+				It("expired sessionId in the in-memory store", func() {
+					er := s.mem.Commit(ctx, uniqueUUID, []byte(uniqueUUID), time.Now().Add(100*time.Millisecond))
+					time.Sleep(101 * time.Millisecond)
+
+					_, found, err := s.mem.Lookup(ctx, uniqueUUID)
+					Expect(er).To(BeNil())
+					Expect(err).To(BeNil())
+					Expect(found).ToNot(BeTrue())
+				})
+			})
+		})
+	})
+
 	Describe("Destroy session", func() {
 		uniqueUUID := "90660b89-100e-4f8f-9801-2524df6fbe34"
 		BeforeEach(func() {
-			err := s.mem.Commit(uniqueUUID, []byte(uniqueUUID), time.Now().Add(time.Minute))
+			err := s.mem.Commit(ctx, uniqueUUID, []byte(uniqueUUID), time.Now().Add(time.Minute))
 			Expect(err).To(BeNil())
 		})
 
 		Context("Delete()", func() {
 			When("the API os called", func() {
 				It("deletes sessionId in-memory store", func() {
-					err := s.mem.Delete(uniqueUUID)
-					sessionMap := s.mem.Get()
+					err := s.mem.Delete(ctx, uniqueUUID)
+					sessionMap := s.mem.Get(ctx)
 					Expect(err).To(BeNil())
 					Expect(string(sessionMap[uniqueUUID].Oject)).To(BeEmpty())
 				})
@@ -106,7 +139,7 @@ var _ = Describe("InMemory", func() {
 		inMemResponse := "90660b89-100e-4f8f-9801-2524df6fbe34"
 		uniqueUUID := "90660b89-100e-4f8f-9801-2524df6fbe34"
 		BeforeEach(func() {
-			err := s.mem.Commit(uniqueUUID, []byte(uniqueUUID), time.Now().Add(time.Minute))
+			err := s.mem.Commit(ctx, uniqueUUID, []byte(uniqueUUID), time.Now().Add(time.Minute))
 			Expect(err).To(BeNil())
 		})
 
@@ -115,8 +148,8 @@ var _ = Describe("InMemory", func() {
 				It("extend sessionId in-memory store", func() {
 					expiration := time.Now().Add(time.Minute * time.Duration(5))
 
-					session, _, err := s.mem.Reset(uniqueUUID, expiration)
-					sessionMap := s.mem.Get()
+					session, _, err := s.mem.Reset(ctx, uniqueUUID, expiration)
+					sessionMap := s.mem.Get(ctx)
 					Expect(err).To(BeNil())
 					Expect(string(session)).To(Equal(inMemResponse))
 					Expect(sessionMap[uniqueUUID].Expiration).To(Equal(expiration.UnixNano()))
@@ -125,17 +158,17 @@ var _ = Describe("InMemory", func() {
 					uniqueUUID2 := "90660b89-100e-4f8f-9801-2524df6fbe99"
 
 					expiration := time.Now().Add(time.Minute * time.Duration(5))
-					_, found, err := s.mem.Reset(uniqueUUID2, expiration)
+					_, found, err := s.mem.Reset(ctx, uniqueUUID2, expiration)
 					Expect(err).To(BeNil())
 					Expect(found).ToNot(BeTrue())
 				})
 				It("extend sessionId in-memory store expired", func() {
 					uniqueUUID2 := "90660b89-100e-4f8f-9801-2524df6fbe88"
-					er := s.mem.Commit(uniqueUUID, []byte(uniqueUUID), time.Now().Add(100*time.Millisecond))
+					er := s.mem.Commit(ctx, uniqueUUID, []byte(uniqueUUID), time.Now().Add(100*time.Millisecond))
 					time.Sleep(101 * time.Millisecond)
 
 					expiration := time.Now().Add(time.Minute * time.Duration(5))
-					_, found, err := s.mem.Reset(uniqueUUID2, expiration)
+					_, found, err := s.mem.Reset(ctx, uniqueUUID2, expiration)
 					Expect(err).To(BeNil())
 					Expect(er).To(BeNil())
 					Expect(found).ToNot(BeTrue())
@@ -144,24 +177,51 @@ var _ = Describe("InMemory", func() {
 		})
 	})
 
+	Describe("Update session", func() {
+		uniqueUUID := "90660b89-100e-4f8f-9801-2524df6fbe34"
+		expiration := time.Now().Add(time.Minute)
+		BeforeEach(func() {
+			err := s.mem.Commit(ctx, uniqueUUID, []byte(uniqueUUID), expiration)
+			Expect(err).To(BeNil())
+		})
+
+		Context("Update()", func() {
+			When("the API os called with data", func() {
+				It("replaces the data and keeps the expiration", func() {
+					found, err := s.mem.Update(ctx, uniqueUUID, []byte("payload"))
+					sessionMap := s.mem.Get(ctx)
+					Expect(err).To(BeNil())
+					Expect(found).To(BeTrue())
+					Expect(string(sessionMap[uniqueUUID].Oject)).To(Equal("payload"))
+					Expect(sessionMap[uniqueUUID].Expiration).To(Equal(expiration.UnixNano()))
+				})
+				It("update sessionId in-memory store not found", func() {
+					found, err := s.mem.Update(ctx, "90660b89-100e-4f8f-9801-2524df6fbe99", []byte("payload"))
+					Expect(err).To(BeNil())
+					Expect(found).ToNot(BeTrue())
+				})
+			})
+		})
+	})
+
 	Describe("List session", func() {
 		uniqueUUID1 := "90660b89-100e-4f8f-9801-2524df6fbe34"
 		uniqueUUID2 := "90660b89-100e-4f8f-9801-2524df6fbe99"
 		uniqueUUID3 := "90660b89-100e-4f8f-9801-2524df6fbe88"
 		BeforeEach(func() {
-			err := s.mem.Commit(uniqueUUID1, []byte(uniqueUUID1), time.Now().Add(time.Minute))
+			err := s.mem.Commit(ctx, uniqueUUID1, []byte(uniqueUUID1), time.Now().Add(time.Minute))
 			Expect(err).To(BeNil())
-			err = s.mem.Commit(uniqueUUID2, []byte(uniqueUUID2), time.Now().Add(time.Minute))
+			err = s.mem.Commit(ctx, uniqueUUID2, []byte(uniqueUUID2), time.Now().Add(time.Minute))
 			Expect(err).To(BeNil())
-			err = s.mem.Commit(uniqueUUID3, []byte(uniqueUUID3), time.Now().Add(time.Minute))
+			err = s.mem.Commit(ctx, uniqueUUID3, []byte(uniqueUUID3), time.Now().Add(time.Minute))
 			Expect(err).To(BeNil())
 		})
 
 		Context("Lit()", func() {
 			When("the API os called", func() {
 				It("deletes sessionId in-memory store", func() {
-					sessions, err := s.mem.List()
-					sessionMap := s.mem.Get()
+					sessions, err := s.mem.List(ctx)
+					sessionMap := s.mem.Get(ctx)
 					Expect(err).To(BeNil())
 					Expect(sessions).To(Equal(sessionMap))
 				})
@@ -175,24 +235,411 @@ var _ = Describe("InMemory", func() {
 		uniqueUUID3 := "90660b89-100e-4f8f-9801-2524df6fbe88"
 		BeforeEach(func() {
 			s.mem = NewInMemStore(101 * time.Millisecond, s.logger)
-			err := s.mem.Commit(uniqueUUID1, []byte(uniqueUUID1), time.Now().Add(time.Minute))
+			err := s.mem.Commit(ctx, uniqueUUID1, []byte(uniqueUUID1), time.Now().Add(time.Minute))
 			Expect(err).To(BeNil())
-			err = s.mem.Commit(uniqueUUID2, []byte(uniqueUUID2), time.Now().Add(100*time.Millisecond))
+			err = s.mem.Commit(ctx, uniqueUUID2, []byte(uniqueUUID2), time.Now().Add(100*time.Millisecond))
 			Expect(err).To(BeNil())
-			err = s.mem.Commit(uniqueUUID3, []byte(uniqueUUID3), time.Now().Add(time.Minute))
+			err = s.mem.Commit(ctx, uniqueUUID3, []byte(uniqueUUID3), time.Now().Add(time.Minute))
 			Expect(err).To(BeNil())
 		})
 
+		AfterEach(func() {
+			s.mem.(*InMemStore).StopSessionCleanup()
+		})
+
 		Context("DeleteSessionExpired()", func() {
 			When("the API os called", func() {
 				It("deletes sessionId in-memory store", func() {
-					sessionMap := s.mem.Get()
 					time.Sleep(200 * time.Millisecond)
+					sessionMap, err := s.mem.List(ctx)
 
+					Expect(err).To(BeNil())
 					Expect(string(sessionMap[uniqueUUID2].Oject)).To(BeEmpty())
 				})
 			})
 		})
 	})
 
-})
\ No newline at end of file
+	Describe("Expiry index", func() {
+		uniqueUUID1 := "90660b89-100e-4f8f-9801-2524df6fbe34"
+		uniqueUUID2 := "90660b89-100e-4f8f-9801-2524df6fbe99"
+		BeforeEach(func() {
+			s.mem = NewInMemStore(10*time.Millisecond, s.logger)
+			err := s.mem.Commit(ctx, uniqueUUID1, []byte(uniqueUUID1), time.Now().Add(time.Minute))
+			Expect(err).To(BeNil())
+		})
+
+		AfterEach(func() {
+			s.mem.(*InMemStore).StopSessionCleanup()
+		})
+
+		sessionCount := func() int {
+			sessionMap, _ := s.mem.List(ctx)
+			return len(sessionMap)
+		}
+
+		Context("DeleteSessionExpired()", func() {
+			When("a session expiring sooner is committed", func() {
+				It("removes it close to its expiration", func() {
+					err := s.mem.Commit(ctx, uniqueUUID2, []byte(uniqueUUID2), time.Now().Add(50*time.Millisecond))
+					Expect(err).To(BeNil())
+					Eventually(sessionCount, 150*time.Millisecond, 5*time.Millisecond).Should(Equal(1))
+					_, found, err := s.mem.Find(ctx, uniqueUUID1)
+					Expect(err).To(BeNil())
+					Expect(found).To(BeTrue())
+				})
+			})
+			When("the session is reset", func() {
+				It("moves it in the expiry index", func() {
+					err := s.mem.Commit(ctx, uniqueUUID2, []byte(uniqueUUID2), time.Now().Add(50*time.Millisecond))
+					Expect(err).To(BeNil())
+					_, found, err := s.mem.Reset(ctx, uniqueUUID2, time.Now().Add(time.Minute))
+					Expect(err).To(BeNil())
+					Expect(found).To(BeTrue())
+					Consistently(sessionCount, 150*time.Millisecond, 10*time.Millisecond).Should(Equal(2))
+
+					_, found, err = s.mem.Reset(ctx, uniqueUUID2, time.Now().Add(20*time.Millisecond))
+					Expect(err).To(BeNil())
+					Expect(found).To(BeTrue())
+					Eventually(sessionCount, 150*time.Millisecond, 5*time.Millisecond).Should(Equal(1))
+				})
+			})
+		})
+	})
+
+	Describe("Health checks", func() {
+		var checkCtx context.Context
+		var cancel context.CancelFunc
+		BeforeEach(func() {
+			s.mem = NewInMemStore(10*time.Millisecond, s.logger)
+			checkCtx, cancel = context.WithTimeout(ctx, 100*time.Millisecond)
+		})
+
+		AfterEach(func() {
+			cancel()
+		})
+
+		Context("Ping()", func() {
+			It("succeeds", func() {
+				Expect(s.mem.(HealthChecker).Ping(checkCtx)).To(Succeed())
+			})
+		})
+		Context("CheckCleanup()", func() {
+			It("succeeds while the cleanup goroutine runs", func() {
+				Expect(s.mem.(HealthChecker).CheckCleanup(checkCtx)).To(Succeed())
+				s.mem.(*InMemStore).StopSessionCleanup()
+			})
+			It("fails once the cleanup goroutine stopped", func() {
+				s.mem.(*InMemStore).StopSessionCleanup()
+				Expect(s.mem.(HealthChecker).CheckCleanup(checkCtx)).To(Equal(ErrCleanupStalled))
+			})
+			It("succeeds without a cleanup goroutine", func() {
+				Expect(NewInMemStore(0, s.logger).(HealthChecker).CheckCleanup(checkCtx)).To(Succeed())
+			})
+		})
+	})
+
+	Describe("Close store", func() {
+		Context("Close()", func() {
+			It("stops the cleanup goroutine and can be called again", func() {
+				s.mem = NewInMemStore(10*time.Millisecond, s.logger)
+				Expect(s.mem.Close(ctx)).To(Succeed())
+				Expect(s.mem.Close(ctx)).To(Succeed())
+
+				checkCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+				defer cancel()
+				Expect(s.mem.(HealthChecker).CheckCleanup(checkCtx)).To(Equal(ErrCleanupStalled))
+			})
+		})
+	})
+
+	Describe("Subject sessions", func() {
+		subject := "user-42"
+		uniqueUUID1 := "90660b89-100e-4f8f-9801-2524df6fbe34"
+		uniqueUUID2 := "90660b89-100e-4f8f-9801-2524df6fbe99"
+		uniqueUUID3 := "90660b89-100e-4f8f-9801-2524df6fbe88"
+		BeforeEach(func() {
+			err := s.mem.CommitWithSubject(ctx, uniqueUUID1, subject, []byte(uniqueUUID1), time.Now().Add(time.Minute))
+			Expect(err).To(BeNil())
+			err = s.mem.CommitWithSubject(ctx, uniqueUUID2, subject, []byte(uniqueUUID2), time.Now().Add(time.Minute))
+			Expect(err).To(BeNil())
+			err = s.mem.CommitWithSubject(ctx, uniqueUUID3, "user-7", []byte(uniqueUUID3), time.Now().Add(time.Minute))
+			Expect(err).To(BeNil())
+		})
+
+		Context("ListBySubject()", func() {
+			When("the API os called with a subject", func() {
+				It("lists the sessions of the subject", func() {
+					sessionMap, err := s.mem.ListBySubject(ctx, subject)
+					Expect(err).To(BeNil())
+					Expect(sessionMap).To(HaveLen(2))
+					Expect(sessionMap).To(HaveKey(uniqueUUID1))
+					Expect(sessionMap).To(HaveKey(uniqueUUID2))
+				})
+				It("drops deleted sessions from the index", func() {
+					Expect(s.mem.Delete(ctx, uniqueUUID1)).To(Succeed())
+					sessionMap, err := s.mem.ListBySubject(ctx, subject)
+					Expect(err).To(BeNil())
+					Expect(sessionMap).To(HaveLen(1))
+					Expect(sessionMap).To(HaveKey(uniqueUUID2))
+				})
+				It("moves a re-committed session to its new subject", func() {
+					Expect(s.mem.Commit(ctx, uniqueUUID1, []byte(uniqueUUID1), time.Now().Add(time.Minute))).To(Succeed())
+					sessionMap, err := s.mem.ListBySubject(ctx, subject)
+					Expect(err).To(BeNil())
+					Expect(sessionMap).To(HaveLen(1))
+					Expect(sessionMap).To(HaveKey(uniqueUUID2))
+				})
+				It("refreshes the last access time only on lookups", func() {
+					before, _ := s.mem.ListBySubject(ctx, subject)
+					_, found, err := s.mem.Find(ctx, uniqueUUID1)
+					Expect(err).To(BeNil())
+					Expect(found).To(BeTrue())
+					after, _ := s.mem.ListBySubject(ctx, subject)
+					Expect(after[uniqueUUID1].LastAccess).To(BeNumerically(">", before[uniqueUUID1].LastAccess))
+					Expect(after[uniqueUUID2].LastAccess).To(Equal(before[uniqueUUID2].LastAccess))
+				})
+				It("refreshes the last access time of concurrent lookups", func() {
+					before, _ := s.mem.ListBySubject(ctx, subject)
+					done := make(chan struct{})
+					for i := 0; i < 8; i++ {
+						go func() {
+							defer GinkgoRecover()
+							defer func() { done <- struct{}{} }()
+							for j := 0; j < 100; j++ {
+								_, found, err := s.mem.Lookup(ctx, uniqueUUID1)
+								Expect(err).To(BeNil())
+								Expect(found).To(BeTrue())
+								_, err = s.mem.List(ctx)
+								Expect(err).To(BeNil())
+							}
+						}()
+					}
+					for i := 0; i < 8; i++ {
+						<-done
+					}
+					after, _ := s.mem.ListBySubject(ctx, subject)
+					Expect(after[uniqueUUID1].LastAccess).To(BeNumerically(">", before[uniqueUUID1].LastAccess))
+					Expect(after[uniqueUUID1].Expiration).To(Equal(before[uniqueUUID1].Expiration))
+				})
+			})
+		})
+
+		Context("DeleteBySubject()", func() {
+			When("the API os called with a subject", func() {
+				It("deletes every session of the subject", func() {
+					sessionIds, err := s.mem.DeleteBySubject(ctx, subject)
+					sessionMap := s.mem.Get(ctx)
+					Expect(err).To(BeNil())
+					Expect(sessionIds).To(ConsistOf(uniqueUUID1, uniqueUUID2))
+					Expect(sessionMap).To(HaveLen(1))
+					Expect(sessionMap).To(HaveKey(uniqueUUID3))
+				})
+			})
+		})
+
+		Context("DeleteSessionExpired()", func() {
+			When("the cleanup expires a session", func() {
+				It("removes it from the subject index", func() {
+					s.mem = NewInMemStore(101*time.Millisecond, s.logger)
+					defer s.mem.(*InMemStore).StopSessionCleanup()
+					err := s.mem.CommitWithSubject(ctx, uniqueUUID1, subject, []byte(uniqueUUID1), time.Now().Add(time.Minute))
+					Expect(err).To(BeNil())
+					err = s.mem.CommitWithSubject(ctx, uniqueUUID2, subject, []byte(uniqueUUID2), time.Now().Add(100*time.Millisecond))
+					Expect(err).To(BeNil())
+					time.Sleep(250 * time.Millisecond)
+					Expect(s.mem.List(ctx)).To(HaveLen(1))
+
+					sessionIds, err := s.mem.DeleteBySubject(ctx, subject)
+					Expect(err).To(BeNil())
+					Expect(sessionIds).To(ConsistOf(uniqueUUID1))
+					Expect(s.mem.List(ctx)).To(BeEmpty())
+				})
+			})
+		})
+	})
+
+	Describe("Session lifetime", func() {
+		uniqueUUID := "90660b89-100e-4f8f-9801-2524df6fbe34"
+
+		Context("Find()", func() {
+			When("the session has an idle timeout", func() {
+				It("slides the expiration on every lookup", func() {
+					err := s.mem.CommitItem(ctx, uniqueUUID, models.Item{
+						Oject:       []byte(uniqueUUID),
+						Expiration:  time.Now().Add(100 * time.Millisecond).UnixNano(),
+						IdleTimeout: int64(100 * time.Millisecond),
+					})
+					Expect(err).To(BeNil())
+
+					for i := 0; i < 3; i++ {
+						time.Sleep(60 * time.Millisecond)
+						_, found, err := s.mem.Find(ctx, uniqueUUID)
+						Expect(err).To(BeNil())
+						Expect(found).To(BeTrue())
+					}
+
+					time.Sleep(101 * time.Millisecond)
+					_, found, err := s.mem.Find(ctx, uniqueUUID)
+					Expect(err).To(BeNil())
+					Expect(found).ToNot(BeTrue())
+				})
+				It("never slides past the maximum expiration", func() {
+					maxExpiration := time.Now().Add(150 * time.Millisecond).UnixNano()
+					err := s.mem.CommitItem(ctx, uniqueUUID, models.Item{
+						Oject:         []byte(uniqueUUID),
+						Expiration:    time.Now().Add(100 * time.Millisecond).UnixNano(),
+						IdleTimeout:   int64(100 * time.Millisecond),
+						MaxExpiration: maxExpiration,
+					})
+					Expect(err).To(BeNil())
+
+					time.Sleep(80 * time.Millisecond)
+					item, found, err := s.mem.Lookup(ctx, uniqueUUID)
+					Expect(err).To(BeNil())
+					Expect(found).To(BeTrue())
+					Expect(item.Expiration).To(Equal(maxExpiration))
+
+					time.Sleep(80 * time.Millisecond)
+					_, found, err = s.mem.Find(ctx, uniqueUUID)
+					Expect(err).To(BeNil())
+					Expect(found).ToNot(BeTrue())
+				})
+				It("expires the session after the idle timeout before its expiration", func() {
+					err := s.mem.CommitItem(ctx, uniqueUUID, models.Item{
+						Oject:       []byte(uniqueUUID),
+						Expiration:  time.Now().Add(5 * time.Second).UnixNano(),
+						IdleTimeout: int64(100 * time.Millisecond),
+					})
+					Expect(err).To(BeNil())
+
+					time.Sleep(60 * time.Millisecond)
+					_, found, err := s.mem.Find(ctx, uniqueUUID)
+					Expect(err).To(BeNil())
+					Expect(found).To(BeTrue())
+
+					time.Sleep(150 * time.Millisecond)
+					_, found, err = s.mem.Find(ctx, uniqueUUID)
+					Expect(err).To(BeNil())
+					Expect(found).ToNot(BeTrue())
+				})
+			})
+		})
+
+		Context("Reset()", func() {
+			When("the session has a maximum expiration", func() {
+				It("caps the new expiration", func() {
+					maxExpiration := time.Now().Add(time.Minute).UnixNano()
+					err := s.mem.CommitItem(ctx, uniqueUUID, models.Item{
+						Oject:         []byte(uniqueUUID),
+						Expiration:    time.Now().Add(time.Second).UnixNano(),
+						MaxExpiration: maxExpiration,
+					})
+					Expect(err).To(BeNil())
+
+					_, found, err := s.mem.Reset(ctx, uniqueUUID, time.Now().Add(time.Hour))
+					Expect(err).To(BeNil())
+					Expect(found).To(BeTrue())
+					Expect(s.mem.Get(ctx)[uniqueUUID].Expiration).To(Equal(maxExpiration))
+				})
+			})
+		})
+
+		Context("CommitItem()", func() {
+			When("the expiration is past the maximum expiration", func() {
+				It("caps the expiration", func() {
+					maxExpiration := time.Now().Add(time.Minute).UnixNano()
+					err := s.mem.CommitItem(ctx, uniqueUUID, models.Item{
+						Oject:         []byte(uniqueUUID),
+						Expiration:    time.Now().Add(time.Hour).UnixNano(),
+						MaxExpiration: maxExpiration,
+					})
+					Expect(err).To(BeNil())
+					Expect(s.mem.Get(ctx)[uniqueUUID].Expiration).To(Equal(maxExpiration))
+				})
+			})
+		})
+
+		Context("DeleteSessionExpired()", func() {
+			When("the session reached its maximum expiration", func() {
+				It("deletes the session", func() {
+					s.mem = NewInMemStore(101*time.Millisecond, s.logger)
+					err := s.mem.CommitItem(ctx, uniqueUUID, models.Item{
+						Oject:         []byte(uniqueUUID),
+						Expiration:    time.Now().Add(time.Hour).UnixNano(),
+						MaxExpiration: time.Now().Add(100 * time.Millisecond).UnixNano(),
+					})
+					Expect(err).To(BeNil())
+
+					Eventually(func() int {
+						sessionMap, _ := s.mem.List(ctx)
+						return len(sessionMap)
+					}).Should(BeZero())
+				})
+			})
+			When("the session stayed idle for its idle timeout", func() {
+				It("deletes the session before its expiration", func() {
+					s.mem = NewInMemStore(10*time.Millisecond, s.logger)
+					err := s.mem.CommitItem(ctx, uniqueUUID, models.Item{
+						Oject:       []byte(uniqueUUID),
+						Expiration:  time.Now().Add(5 * time.Second).UnixNano(),
+						IdleTimeout: int64(100 * time.Millisecond),
+					})
+					Expect(err).To(BeNil())
+
+					Eventually(func() int {
+						sessionMap, _ := s.mem.List(ctx)
+						return len(sessionMap)
+					}, time.Second).Should(BeZero())
+				})
+				It("keeps the session looked up since it was indexed", func() {
+					s.mem = NewInMemStore(10*time.Millisecond, s.logger)
+					err := s.mem.CommitItem(ctx, uniqueUUID, models.Item{
+						Oject:       []byte(uniqueUUID),
+						Expiration:  time.Now().Add(5 * time.Second).UnixNano(),
+						IdleTimeout: int64(100 * time.Millisecond),
+					})
+					Expect(err).To(BeNil())
+
+					for i := 0; i < 4; i++ {
+						time.Sleep(60 * time.Millisecond)
+						_, found, err := s.mem.Find(ctx, uniqueUUID)
+						Expect(err).To(BeNil())
+						Expect(found).To(BeTrue())
+					}
+					Expect(s.mem.Get(ctx)).To(HaveKey(uniqueUUID))
+				})
+			})
+		})
+	})
+
+	Describe("Store metrics", func() {
+		uniqueUUID1 := "90660b89-100e-4f8f-9801-2524df6fbe34"
+		uniqueUUID2 := "90660b89-100e-4f8f-9801-2524df6fbe99"
+		var metrics Metrics
+		BeforeEach(func() {
+			metrics = Metrics{
+				ActiveSessions:  generic.NewGauge("active_sessions"),
+				ExpiredSessions: generic.NewCounter("expired_sessions_total"),
+				CleanupSweeps:   generic.NewCounter("cleanup_sweeps_total"),
+			}
+			s.mem = NewInstrumentedInMemStore(101*time.Millisecond, metrics, s.logger)
+			err := s.mem.Commit(ctx, uniqueUUID1, []byte(uniqueUUID1), time.Now().Add(time.Minute))
+			Expect(err).To(BeNil())
+			err = s.mem.Commit(ctx, uniqueUUID2, []byte(uniqueUUID2), time.Now().Add(100*time.Millisecond))
+			Expect(err).To(BeNil())
+		})
+
+		Context("DeleteSessionExpired()", func() {
+			When("the cleanup runs", func() {
+				It("reports active and expired sessions", func() {
+					Expect(metrics.ActiveSessions.(*generic.Gauge).Value()).To(Equal(float64(2)))
+					Eventually(metrics.CleanupSweeps.(*generic.Counter).Value).Should(BeNumerically(">=", 1))
+					Eventually(metrics.ExpiredSessions.(*generic.Counter).Value).Should(Equal(float64(1)))
+					Eventually(metrics.ActiveSessions.(*generic.Gauge).Value).Should(Equal(float64(1)))
+				})
+			})
+		})
+	})
+
+})
@@ -2,6 +2,7 @@
 package in_memoryfakes
 
 import (
+	"context"
 	"sync"
 	"time"
 
@@ -10,12 +11,24 @@ import (
 )
 
 type FakeMemStore struct {
-	CommitStub        func(string, []byte, time.Time) error
+	CloseStub        func(context.Context) error
+	closeMutex       sync.RWMutex
+	closeArgsForCall []struct {
+		arg1 context.Context
+	}
+	closeReturns struct {
+		result1 error
+	}
+	closeReturnsOnCall map[int]struct {
+		result1 error
+	}
+	CommitStub        func(context.Context, string, []byte, time.Time) error
 	commitMutex       sync.RWMutex
 	commitArgsForCall []struct {
-		arg1 string
-		arg2 []byte
-		arg3 time.Time
+		arg1 context.Context
+		arg2 string
+		arg3 []byte
+		arg4 time.Time
 	}
 	commitReturns struct {
 		result1 error
@@ -23,10 +36,51 @@ type FakeMemStore struct {
 	commitReturnsOnCall map[int]struct {
 		result1 error
 	}
-	DeleteStub        func(string) error
+	CommitBatchStub        func(context.Context, map[string]models.Item) error
+	commitBatchMutex       sync.RWMutex
+	commitBatchArgsForCall []struct {
+		arg1 context.Context
+		arg2 map[string]models.Item
+	}
+	commitBatchReturns struct {
+		result1 error
+	}
+	commitBatchReturnsOnCall map[int]struct {
+		result1 error
+	}
+	CommitItemStub        func(context.Context, string, models.Item) error
+	commitItemMutex       sync.RWMutex
+	commitItemArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 models.Item
+	}
+	commitItemReturns struct {
+		result1 error
+	}
+	commitItemReturnsOnCall map[int]struct {
+		result1 error
+	}
+	CommitWithSubjectStub        func(context.Context, string, string, []byte, time.Time) error
+	commitWithSubjectMutex       sync.RWMutex
+	commitWithSubjectArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+		arg4 []byte
+		arg5 time.Time
+	}
+	commitWithSubjectReturns struct {
+		result1 error
+	}
+	commitWithSubjectReturnsOnCall map[int]struct {
+		result1 error
+	}
+	DeleteStub        func(context.Context, string) error
 	deleteMutex       sync.RWMutex
 	deleteArgsForCall []struct {
-		arg1 string
+		arg1 context.Context
+		arg2 string
 	}
 	deleteReturns struct {
 		result1 error
@@ -34,10 +88,39 @@ type FakeMemStore struct {
 	deleteReturnsOnCall map[int]struct {
 		result1 error
 	}
-	FindStub        func(string) ([]byte, bool, error)
+	DeleteBatchStub        func(context.Context, []string) (map[string]models.Item, error)
+	deleteBatchMutex       sync.RWMutex
+	deleteBatchArgsForCall []struct {
+		arg1 context.Context
+		arg2 []string
+	}
+	deleteBatchReturns struct {
+		result1 map[string]models.Item
+		result2 error
+	}
+	deleteBatchReturnsOnCall map[int]struct {
+		result1 map[string]models.Item
+		result2 error
+	}
+	DeleteBySubjectStub        func(context.Context, string) ([]string, error)
+	deleteBySubjectMutex       sync.RWMutex
+	deleteBySubjectArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+	}
+	deleteBySubjectReturns struct {
+		result1 []string
+		result2 error
+	}
+	deleteBySubjectReturnsOnCall map[int]struct {
+		result1 []string
+		result2 error
+	}
+	FindStub        func(context.Context, string) ([]byte, bool, error)
 	findMutex       sync.RWMutex
 	findArgsForCall []struct {
-		arg1 string
+		arg1 context.Context
+		arg2 string
 	}
 	findReturns struct {
 		result1 []byte
@@ -49,9 +132,10 @@ type FakeMemStore struct {
 		result2 bool
 		result3 error
 	}
-	GetStub        func() map[string]models.Item
+	GetStub        func(context.Context) map[string]models.Item
 	getMutex       sync.RWMutex
 	getArgsForCall []struct {
+		arg1 context.Context
 	}
 	getReturns struct {
 		result1 map[string]models.Item
@@ -59,9 +143,10 @@ type FakeMemStore struct {
 	getReturnsOnCall map[int]struct {
 		result1 map[string]models.Item
 	}
-	ListStub        func() (map[string]models.Item, error)
+	ListStub        func(context.Context) (map[string]models.Item, error)
 	listMutex       sync.RWMutex
 	listArgsForCall []struct {
+		arg1 context.Context
 	}
 	listReturns struct {
 		result1 map[string]models.Item
@@ -71,11 +156,56 @@ type FakeMemStore struct {
 		result1 map[string]models.Item
 		result2 error
 	}
-	ResetStub        func(string, time.Time) ([]byte, bool, error)
+	ListBySubjectStub        func(context.Context, string) (map[string]models.Item, error)
+	listBySubjectMutex       sync.RWMutex
+	listBySubjectArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+	}
+	listBySubjectReturns struct {
+		result1 map[string]models.Item
+		result2 error
+	}
+	listBySubjectReturnsOnCall map[int]struct {
+		result1 map[string]models.Item
+		result2 error
+	}
+	LookupStub        func(context.Context, string) (models.Item, bool, error)
+	lookupMutex       sync.RWMutex
+	lookupArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+	}
+	lookupReturns struct {
+		result1 models.Item
+		result2 bool
+		result3 error
+	}
+	lookupReturnsOnCall map[int]struct {
+		result1 models.Item
+		result2 bool
+		result3 error
+	}
+	QueryStub        func(context.Context, in_memory.Query) (in_memory.Page, error)
+	queryMutex       sync.RWMutex
+	queryArgsForCall []struct {
+		arg1 context.Context
+		arg2 in_memory.Query
+	}
+	queryReturns struct {
+		result1 in_memory.Page
+		result2 error
+	}
+	queryReturnsOnCall map[int]struct {
+		result1 in_memory.Page
+		result2 error
+	}
+	ResetStub        func(context.Context, string, time.Time) ([]byte, bool, error)
 	resetMutex       sync.RWMutex
 	resetArgsForCall []struct {
-		arg1 string
-		arg2 time.Time
+		arg1 context.Context
+		arg2 string
+		arg3 time.Time
 	}
 	resetReturns struct {
 		result1 []byte
@@ -87,29 +217,120 @@ type FakeMemStore struct {
 		result2 bool
 		result3 error
 	}
+	ResetBatchStub        func(context.Context, map[string]time.Time) (map[string]models.Item, error)
+	resetBatchMutex       sync.RWMutex
+	resetBatchArgsForCall []struct {
+		arg1 context.Context
+		arg2 map[string]time.Time
+	}
+	resetBatchReturns struct {
+		result1 map[string]models.Item
+		result2 error
+	}
+	resetBatchReturnsOnCall map[int]struct {
+		result1 map[string]models.Item
+		result2 error
+	}
+	UpdateStub        func(context.Context, string, []byte) (bool, error)
+	updateMutex       sync.RWMutex
+	updateArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 []byte
+	}
+	updateReturns struct {
+		result1 bool
+		result2 error
+	}
+	updateReturnsOnCall map[int]struct {
+		result1 bool
+		result2 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
 
-func (fake *FakeMemStore) Commit(arg1 string, arg2 []byte, arg3 time.Time) error {
-	var arg2Copy []byte
-	if arg2 != nil {
-		arg2Copy = make([]byte, len(arg2))
-		copy(arg2Copy, arg2)
+func (fake *FakeMemStore) Close(arg1 context.Context) error {
+	fake.closeMutex.Lock()
+	ret, specificReturn := fake.closeReturnsOnCall[len(fake.closeArgsForCall)]
+	fake.closeArgsForCall = append(fake.closeArgsForCall, struct {
+		arg1 context.Context
+	}{arg1})
+	stub := fake.CloseStub
+	fakeReturns := fake.closeReturns
+	fake.recordInvocation("Close", []interface{}{arg1})
+	fake.closeMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeMemStore) CloseCallCount() int {
+	fake.closeMutex.RLock()
+	defer fake.closeMutex.RUnlock()
+	return len(fake.closeArgsForCall)
+}
+
+func (fake *FakeMemStore) CloseCalls(stub func(context.Context) error) {
+	fake.closeMutex.Lock()
+	defer fake.closeMutex.Unlock()
+	fake.CloseStub = stub
+}
+
+func (fake *FakeMemStore) CloseArgsForCall(i int) context.Context {
+	fake.closeMutex.RLock()
+	defer fake.closeMutex.RUnlock()
+	argsForCall := fake.closeArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeMemStore) CloseReturns(result1 error) {
+	fake.closeMutex.Lock()
+	defer fake.closeMutex.Unlock()
+	fake.CloseStub = nil
+	fake.closeReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeMemStore) CloseReturnsOnCall(i int, result1 error) {
+	fake.closeMutex.Lock()
+	defer fake.closeMutex.Unlock()
+	fake.CloseStub = nil
+	if fake.closeReturnsOnCall == nil {
+		fake.closeReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.closeReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeMemStore) Commit(arg1 context.Context, arg2 string, arg3 []byte, arg4 time.Time) error {
+	var arg3Copy []byte
+	if arg3 != nil {
+		arg3Copy = make([]byte, len(arg3))
+		copy(arg3Copy, arg3)
 	}
 	fake.commitMutex.Lock()
 	ret, specificReturn := fake.commitReturnsOnCall[len(fake.commitArgsForCall)]
 	fake.commitArgsForCall = append(fake.commitArgsForCall, struct {
-		arg1 string
-		arg2 []byte
-		arg3 time.Time
-	}{arg1, arg2Copy, arg3})
+		arg1 context.Context
+		arg2 string
+		arg3 []byte
+		arg4 time.Time
+	}{arg1, arg2, arg3Copy, arg4})
 	stub := fake.CommitStub
 	fakeReturns := fake.commitReturns
-	fake.recordInvocation("Commit", []interface{}{arg1, arg2Copy, arg3})
+	fake.recordInvocation("Commit", []interface{}{arg1, arg2, arg3Copy, arg4})
 	fake.commitMutex.Unlock()
 	if stub != nil {
-		return stub(arg1, arg2, arg3)
+		return stub(arg1, arg2, arg3, arg4)
 	}
 	if specificReturn {
 		return ret.result1
@@ -123,17 +344,17 @@ func (fake *FakeMemStore) CommitCallCount() int {
 	return len(fake.commitArgsForCall)
 }
 
-func (fake *FakeMemStore) CommitCalls(stub func(string, []byte, time.Time) error) {
+func (fake *FakeMemStore) CommitCalls(stub func(context.Context, string, []byte, time.Time) error) {
 	fake.commitMutex.Lock()
 	defer fake.commitMutex.Unlock()
 	fake.CommitStub = stub
 }
 
-func (fake *FakeMemStore) CommitArgsForCall(i int) (string, []byte, time.Time) {
+func (fake *FakeMemStore) CommitArgsForCall(i int) (context.Context, string, []byte, time.Time) {
 	fake.commitMutex.RLock()
 	defer fake.commitMutex.RUnlock()
 	argsForCall := fake.commitArgsForCall[i]
-	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
 }
 
 func (fake *FakeMemStore) CommitReturns(result1 error) {
@@ -159,18 +380,214 @@ func (fake *FakeMemStore) CommitReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
-func (fake *FakeMemStore) Delete(arg1 string) error {
+func (fake *FakeMemStore) CommitBatch(arg1 context.Context, arg2 map[string]models.Item) error {
+	fake.commitBatchMutex.Lock()
+	ret, specificReturn := fake.commitBatchReturnsOnCall[len(fake.commitBatchArgsForCall)]
+	fake.commitBatchArgsForCall = append(fake.commitBatchArgsForCall, struct {
+		arg1 context.Context
+		arg2 map[string]models.Item
+	}{arg1, arg2})
+	stub := fake.CommitBatchStub
+	fakeReturns := fake.commitBatchReturns
+	fake.recordInvocation("CommitBatch", []interface{}{arg1, arg2})
+	fake.commitBatchMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeMemStore) CommitBatchCallCount() int {
+	fake.commitBatchMutex.RLock()
+	defer fake.commitBatchMutex.RUnlock()
+	return len(fake.commitBatchArgsForCall)
+}
+
+func (fake *FakeMemStore) CommitBatchCalls(stub func(context.Context, map[string]models.Item) error) {
+	fake.commitBatchMutex.Lock()
+	defer fake.commitBatchMutex.Unlock()
+	fake.CommitBatchStub = stub
+}
+
+func (fake *FakeMemStore) CommitBatchArgsForCall(i int) (context.Context, map[string]models.Item) {
+	fake.commitBatchMutex.RLock()
+	defer fake.commitBatchMutex.RUnlock()
+	argsForCall := fake.commitBatchArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeMemStore) CommitBatchReturns(result1 error) {
+	fake.commitBatchMutex.Lock()
+	defer fake.commitBatchMutex.Unlock()
+	fake.CommitBatchStub = nil
+	fake.commitBatchReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeMemStore) CommitBatchReturnsOnCall(i int, result1 error) {
+	fake.commitBatchMutex.Lock()
+	defer fake.commitBatchMutex.Unlock()
+	fake.CommitBatchStub = nil
+	if fake.commitBatchReturnsOnCall == nil {
+		fake.commitBatchReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.commitBatchReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeMemStore) CommitItem(arg1 context.Context, arg2 string, arg3 models.Item) error {
+	fake.commitItemMutex.Lock()
+	ret, specificReturn := fake.commitItemReturnsOnCall[len(fake.commitItemArgsForCall)]
+	fake.commitItemArgsForCall = append(fake.commitItemArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 models.Item
+	}{arg1, arg2, arg3})
+	stub := fake.CommitItemStub
+	fakeReturns := fake.commitItemReturns
+	fake.recordInvocation("CommitItem", []interface{}{arg1, arg2, arg3})
+	fake.commitItemMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeMemStore) CommitItemCallCount() int {
+	fake.commitItemMutex.RLock()
+	defer fake.commitItemMutex.RUnlock()
+	return len(fake.commitItemArgsForCall)
+}
+
+func (fake *FakeMemStore) CommitItemCalls(stub func(context.Context, string, models.Item) error) {
+	fake.commitItemMutex.Lock()
+	defer fake.commitItemMutex.Unlock()
+	fake.CommitItemStub = stub
+}
+
+func (fake *FakeMemStore) CommitItemArgsForCall(i int) (context.Context, string, models.Item) {
+	fake.commitItemMutex.RLock()
+	defer fake.commitItemMutex.RUnlock()
+	argsForCall := fake.commitItemArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeMemStore) CommitItemReturns(result1 error) {
+	fake.commitItemMutex.Lock()
+	defer fake.commitItemMutex.Unlock()
+	fake.CommitItemStub = nil
+	fake.commitItemReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeMemStore) CommitItemReturnsOnCall(i int, result1 error) {
+	fake.commitItemMutex.Lock()
+	defer fake.commitItemMutex.Unlock()
+	fake.CommitItemStub = nil
+	if fake.commitItemReturnsOnCall == nil {
+		fake.commitItemReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.commitItemReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeMemStore) CommitWithSubject(arg1 context.Context, arg2 string, arg3 string, arg4 []byte, arg5 time.Time) error {
+	var arg4Copy []byte
+	if arg4 != nil {
+		arg4Copy = make([]byte, len(arg4))
+		copy(arg4Copy, arg4)
+	}
+	fake.commitWithSubjectMutex.Lock()
+	ret, specificReturn := fake.commitWithSubjectReturnsOnCall[len(fake.commitWithSubjectArgsForCall)]
+	fake.commitWithSubjectArgsForCall = append(fake.commitWithSubjectArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+		arg4 []byte
+		arg5 time.Time
+	}{arg1, arg2, arg3, arg4Copy, arg5})
+	stub := fake.CommitWithSubjectStub
+	fakeReturns := fake.commitWithSubjectReturns
+	fake.recordInvocation("CommitWithSubject", []interface{}{arg1, arg2, arg3, arg4Copy, arg5})
+	fake.commitWithSubjectMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4, arg5)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeMemStore) CommitWithSubjectCallCount() int {
+	fake.commitWithSubjectMutex.RLock()
+	defer fake.commitWithSubjectMutex.RUnlock()
+	return len(fake.commitWithSubjectArgsForCall)
+}
+
+func (fake *FakeMemStore) CommitWithSubjectCalls(stub func(context.Context, string, string, []byte, time.Time) error) {
+	fake.commitWithSubjectMutex.Lock()
+	defer fake.commitWithSubjectMutex.Unlock()
+	fake.CommitWithSubjectStub = stub
+}
+
+func (fake *FakeMemStore) CommitWithSubjectArgsForCall(i int) (context.Context, string, string, []byte, time.Time) {
+	fake.commitWithSubjectMutex.RLock()
+	defer fake.commitWithSubjectMutex.RUnlock()
+	argsForCall := fake.commitWithSubjectArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5
+}
+
+func (fake *FakeMemStore) CommitWithSubjectReturns(result1 error) {
+	fake.commitWithSubjectMutex.Lock()
+	defer fake.commitWithSubjectMutex.Unlock()
+	fake.CommitWithSubjectStub = nil
+	fake.commitWithSubjectReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeMemStore) CommitWithSubjectReturnsOnCall(i int, result1 error) {
+	fake.commitWithSubjectMutex.Lock()
+	defer fake.commitWithSubjectMutex.Unlock()
+	fake.CommitWithSubjectStub = nil
+	if fake.commitWithSubjectReturnsOnCall == nil {
+		fake.commitWithSubjectReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.commitWithSubjectReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeMemStore) Delete(arg1 context.Context, arg2 string) error {
 	fake.deleteMutex.Lock()
 	ret, specificReturn := fake.deleteReturnsOnCall[len(fake.deleteArgsForCall)]
 	fake.deleteArgsForCall = append(fake.deleteArgsForCall, struct {
-		arg1 string
-	}{arg1})
+		arg1 context.Context
+		arg2 string
+	}{arg1, arg2})
 	stub := fake.DeleteStub
 	fakeReturns := fake.deleteReturns
-	fake.recordInvocation("Delete", []interface{}{arg1})
+	fake.recordInvocation("Delete", []interface{}{arg1, arg2})
 	fake.deleteMutex.Unlock()
 	if stub != nil {
-		return stub(arg1)
+		return stub(arg1, arg2)
 	}
 	if specificReturn {
 		return ret.result1
@@ -184,17 +601,17 @@ func (fake *FakeMemStore) DeleteCallCount() int {
 	return len(fake.deleteArgsForCall)
 }
 
-func (fake *FakeMemStore) DeleteCalls(stub func(string) error) {
+func (fake *FakeMemStore) DeleteCalls(stub func(context.Context, string) error) {
 	fake.deleteMutex.Lock()
 	defer fake.deleteMutex.Unlock()
 	fake.DeleteStub = stub
 }
 
-func (fake *FakeMemStore) DeleteArgsForCall(i int) string {
+func (fake *FakeMemStore) DeleteArgsForCall(i int) (context.Context, string) {
 	fake.deleteMutex.RLock()
 	defer fake.deleteMutex.RUnlock()
 	argsForCall := fake.deleteArgsForCall[i]
-	return argsForCall.arg1
+	return argsForCall.arg1, argsForCall.arg2
 }
 
 func (fake *FakeMemStore) DeleteReturns(result1 error) {
@@ -220,18 +637,154 @@ func (fake *FakeMemStore) DeleteReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
-func (fake *FakeMemStore) Find(arg1 string) ([]byte, bool, error) {
+func (fake *FakeMemStore) DeleteBatch(arg1 context.Context, arg2 []string) (map[string]models.Item, error) {
+	var arg2Copy []string
+	if arg2 != nil {
+		arg2Copy = make([]string, len(arg2))
+		copy(arg2Copy, arg2)
+	}
+	fake.deleteBatchMutex.Lock()
+	ret, specificReturn := fake.deleteBatchReturnsOnCall[len(fake.deleteBatchArgsForCall)]
+	fake.deleteBatchArgsForCall = append(fake.deleteBatchArgsForCall, struct {
+		arg1 context.Context
+		arg2 []string
+	}{arg1, arg2Copy})
+	stub := fake.DeleteBatchStub
+	fakeReturns := fake.deleteBatchReturns
+	fake.recordInvocation("DeleteBatch", []interface{}{arg1, arg2Copy})
+	fake.deleteBatchMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeMemStore) DeleteBatchCallCount() int {
+	fake.deleteBatchMutex.RLock()
+	defer fake.deleteBatchMutex.RUnlock()
+	return len(fake.deleteBatchArgsForCall)
+}
+
+func (fake *FakeMemStore) DeleteBatchCalls(stub func(context.Context, []string) (map[string]models.Item, error)) {
+	fake.deleteBatchMutex.Lock()
+	defer fake.deleteBatchMutex.Unlock()
+	fake.DeleteBatchStub = stub
+}
+
+func (fake *FakeMemStore) DeleteBatchArgsForCall(i int) (context.Context, []string) {
+	fake.deleteBatchMutex.RLock()
+	defer fake.deleteBatchMutex.RUnlock()
+	argsForCall := fake.deleteBatchArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeMemStore) DeleteBatchReturns(result1 map[string]models.Item, result2 error) {
+	fake.deleteBatchMutex.Lock()
+	defer fake.deleteBatchMutex.Unlock()
+	fake.DeleteBatchStub = nil
+	fake.deleteBatchReturns = struct {
+		result1 map[string]models.Item
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeMemStore) DeleteBatchReturnsOnCall(i int, result1 map[string]models.Item, result2 error) {
+	fake.deleteBatchMutex.Lock()
+	defer fake.deleteBatchMutex.Unlock()
+	fake.DeleteBatchStub = nil
+	if fake.deleteBatchReturnsOnCall == nil {
+		fake.deleteBatchReturnsOnCall = make(map[int]struct {
+			result1 map[string]models.Item
+			result2 error
+		})
+	}
+	fake.deleteBatchReturnsOnCall[i] = struct {
+		result1 map[string]models.Item
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeMemStore) DeleteBySubject(arg1 context.Context, arg2 string) ([]string, error) {
+	fake.deleteBySubjectMutex.Lock()
+	ret, specificReturn := fake.deleteBySubjectReturnsOnCall[len(fake.deleteBySubjectArgsForCall)]
+	fake.deleteBySubjectArgsForCall = append(fake.deleteBySubjectArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.DeleteBySubjectStub
+	fakeReturns := fake.deleteBySubjectReturns
+	fake.recordInvocation("DeleteBySubject", []interface{}{arg1, arg2})
+	fake.deleteBySubjectMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeMemStore) DeleteBySubjectCallCount() int {
+	fake.deleteBySubjectMutex.RLock()
+	defer fake.deleteBySubjectMutex.RUnlock()
+	return len(fake.deleteBySubjectArgsForCall)
+}
+
+func (fake *FakeMemStore) DeleteBySubjectCalls(stub func(context.Context, string) ([]string, error)) {
+	fake.deleteBySubjectMutex.Lock()
+	defer fake.deleteBySubjectMutex.Unlock()
+	fake.DeleteBySubjectStub = stub
+}
+
+func (fake *FakeMemStore) DeleteBySubjectArgsForCall(i int) (context.Context, string) {
+	fake.deleteBySubjectMutex.RLock()
+	defer fake.deleteBySubjectMutex.RUnlock()
+	argsForCall := fake.deleteBySubjectArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeMemStore) DeleteBySubjectReturns(result1 []string, result2 error) {
+	fake.deleteBySubjectMutex.Lock()
+	defer fake.deleteBySubjectMutex.Unlock()
+	fake.DeleteBySubjectStub = nil
+	fake.deleteBySubjectReturns = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeMemStore) DeleteBySubjectReturnsOnCall(i int, result1 []string, result2 error) {
+	fake.deleteBySubjectMutex.Lock()
+	defer fake.deleteBySubjectMutex.Unlock()
+	fake.DeleteBySubjectStub = nil
+	if fake.deleteBySubjectReturnsOnCall == nil {
+		fake.deleteBySubjectReturnsOnCall = make(map[int]struct {
+			result1 []string
+			result2 error
+		})
+	}
+	fake.deleteBySubjectReturnsOnCall[i] = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeMemStore) Find(arg1 context.Context, arg2 string) ([]byte, bool, error) {
 	fake.findMutex.Lock()
 	ret, specificReturn := fake.findReturnsOnCall[len(fake.findArgsForCall)]
 	fake.findArgsForCall = append(fake.findArgsForCall, struct {
-		arg1 string
-	}{arg1})
+		arg1 context.Context
+		arg2 string
+	}{arg1, arg2})
 	stub := fake.FindStub
 	fakeReturns := fake.findReturns
-	fake.recordInvocation("Find", []interface{}{arg1})
+	fake.recordInvocation("Find", []interface{}{arg1, arg2})
 	fake.findMutex.Unlock()
 	if stub != nil {
-		return stub(arg1)
+		return stub(arg1, arg2)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2, ret.result3
@@ -245,17 +798,17 @@ func (fake *FakeMemStore) FindCallCount() int {
 	return len(fake.findArgsForCall)
 }
 
-func (fake *FakeMemStore) FindCalls(stub func(string) ([]byte, bool, error)) {
+func (fake *FakeMemStore) FindCalls(stub func(context.Context, string) ([]byte, bool, error)) {
 	fake.findMutex.Lock()
 	defer fake.findMutex.Unlock()
 	fake.FindStub = stub
 }
 
-func (fake *FakeMemStore) FindArgsForCall(i int) string {
+func (fake *FakeMemStore) FindArgsForCall(i int) (context.Context, string) {
 	fake.findMutex.RLock()
 	defer fake.findMutex.RUnlock()
 	argsForCall := fake.findArgsForCall[i]
-	return argsForCall.arg1
+	return argsForCall.arg1, argsForCall.arg2
 }
 
 func (fake *FakeMemStore) FindReturns(result1 []byte, result2 bool, result3 error) {
@@ -287,17 +840,18 @@ func (fake *FakeMemStore) FindReturnsOnCall(i int, result1 []byte, result2 bool,
 	}{result1, result2, result3}
 }
 
-func (fake *FakeMemStore) Get() map[string]models.Item {
+func (fake *FakeMemStore) Get(arg1 context.Context) map[string]models.Item {
 	fake.getMutex.Lock()
 	ret, specificReturn := fake.getReturnsOnCall[len(fake.getArgsForCall)]
 	fake.getArgsForCall = append(fake.getArgsForCall, struct {
-	}{})
+		arg1 context.Context
+	}{arg1})
 	stub := fake.GetStub
 	fakeReturns := fake.getReturns
-	fake.recordInvocation("Get", []interface{}{})
+	fake.recordInvocation("Get", []interface{}{arg1})
 	fake.getMutex.Unlock()
 	if stub != nil {
-		return stub()
+		return stub(arg1)
 	}
 	if specificReturn {
 		return ret.result1
@@ -311,12 +865,19 @@ func (fake *FakeMemStore) GetCallCount() int {
 	return len(fake.getArgsForCall)
 }
 
-func (fake *FakeMemStore) GetCalls(stub func() map[string]models.Item) {
+func (fake *FakeMemStore) GetCalls(stub func(context.Context) map[string]models.Item) {
 	fake.getMutex.Lock()
 	defer fake.getMutex.Unlock()
 	fake.GetStub = stub
 }
 
+func (fake *FakeMemStore) GetArgsForCall(i int) context.Context {
+	fake.getMutex.RLock()
+	defer fake.getMutex.RUnlock()
+	argsForCall := fake.getArgsForCall[i]
+	return argsForCall.arg1
+}
+
 func (fake *FakeMemStore) GetReturns(result1 map[string]models.Item) {
 	fake.getMutex.Lock()
 	defer fake.getMutex.Unlock()
@@ -340,17 +901,18 @@ func (fake *FakeMemStore) GetReturnsOnCall(i int, result1 map[string]models.Item
 	}{result1}
 }
 
-func (fake *FakeMemStore) List() (map[string]models.Item, error) {
+func (fake *FakeMemStore) List(arg1 context.Context) (map[string]models.Item, error) {
 	fake.listMutex.Lock()
 	ret, specificReturn := fake.listReturnsOnCall[len(fake.listArgsForCall)]
 	fake.listArgsForCall = append(fake.listArgsForCall, struct {
-	}{})
+		arg1 context.Context
+	}{arg1})
 	stub := fake.ListStub
 	fakeReturns := fake.listReturns
-	fake.recordInvocation("List", []interface{}{})
+	fake.recordInvocation("List", []interface{}{arg1})
 	fake.listMutex.Unlock()
 	if stub != nil {
-		return stub()
+		return stub(arg1)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
@@ -364,12 +926,19 @@ func (fake *FakeMemStore) ListCallCount() int {
 	return len(fake.listArgsForCall)
 }
 
-func (fake *FakeMemStore) ListCalls(stub func() (map[string]models.Item, error)) {
+func (fake *FakeMemStore) ListCalls(stub func(context.Context) (map[string]models.Item, error)) {
 	fake.listMutex.Lock()
 	defer fake.listMutex.Unlock()
 	fake.ListStub = stub
 }
 
+func (fake *FakeMemStore) ListArgsForCall(i int) context.Context {
+	fake.listMutex.RLock()
+	defer fake.listMutex.RUnlock()
+	argsForCall := fake.listArgsForCall[i]
+	return argsForCall.arg1
+}
+
 func (fake *FakeMemStore) ListReturns(result1 map[string]models.Item, result2 error) {
 	fake.listMutex.Lock()
 	defer fake.listMutex.Unlock()
@@ -396,19 +965,218 @@ func (fake *FakeMemStore) ListReturnsOnCall(i int, result1 map[string]models.Ite
 	}{result1, result2}
 }
 
-func (fake *FakeMemStore) Reset(arg1 string, arg2 time.Time) ([]byte, bool, error) {
+func (fake *FakeMemStore) ListBySubject(arg1 context.Context, arg2 string) (map[string]models.Item, error) {
+	fake.listBySubjectMutex.Lock()
+	ret, specificReturn := fake.listBySubjectReturnsOnCall[len(fake.listBySubjectArgsForCall)]
+	fake.listBySubjectArgsForCall = append(fake.listBySubjectArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.ListBySubjectStub
+	fakeReturns := fake.listBySubjectReturns
+	fake.recordInvocation("ListBySubject", []interface{}{arg1, arg2})
+	fake.listBySubjectMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeMemStore) ListBySubjectCallCount() int {
+	fake.listBySubjectMutex.RLock()
+	defer fake.listBySubjectMutex.RUnlock()
+	return len(fake.listBySubjectArgsForCall)
+}
+
+func (fake *FakeMemStore) ListBySubjectCalls(stub func(context.Context, string) (map[string]models.Item, error)) {
+	fake.listBySubjectMutex.Lock()
+	defer fake.listBySubjectMutex.Unlock()
+	fake.ListBySubjectStub = stub
+}
+
+func (fake *FakeMemStore) ListBySubjectArgsForCall(i int) (context.Context, string) {
+	fake.listBySubjectMutex.RLock()
+	defer fake.listBySubjectMutex.RUnlock()
+	argsForCall := fake.listBySubjectArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeMemStore) ListBySubjectReturns(result1 map[string]models.Item, result2 error) {
+	fake.listBySubjectMutex.Lock()
+	defer fake.listBySubjectMutex.Unlock()
+	fake.ListBySubjectStub = nil
+	fake.listBySubjectReturns = struct {
+		result1 map[string]models.Item
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeMemStore) ListBySubjectReturnsOnCall(i int, result1 map[string]models.Item, result2 error) {
+	fake.listBySubjectMutex.Lock()
+	defer fake.listBySubjectMutex.Unlock()
+	fake.ListBySubjectStub = nil
+	if fake.listBySubjectReturnsOnCall == nil {
+		fake.listBySubjectReturnsOnCall = make(map[int]struct {
+			result1 map[string]models.Item
+			result2 error
+		})
+	}
+	fake.listBySubjectReturnsOnCall[i] = struct {
+		result1 map[string]models.Item
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeMemStore) Lookup(arg1 context.Context, arg2 string) (models.Item, bool, error) {
+	fake.lookupMutex.Lock()
+	ret, specificReturn := fake.lookupReturnsOnCall[len(fake.lookupArgsForCall)]
+	fake.lookupArgsForCall = append(fake.lookupArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.LookupStub
+	fakeReturns := fake.lookupReturns
+	fake.recordInvocation("Lookup", []interface{}{arg1, arg2})
+	fake.lookupMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2, ret.result3
+	}
+	return fakeReturns.result1, fakeReturns.result2, fakeReturns.result3
+}
+
+func (fake *FakeMemStore) LookupCallCount() int {
+	fake.lookupMutex.RLock()
+	defer fake.lookupMutex.RUnlock()
+	return len(fake.lookupArgsForCall)
+}
+
+func (fake *FakeMemStore) LookupCalls(stub func(context.Context, string) (models.Item, bool, error)) {
+	fake.lookupMutex.Lock()
+	defer fake.lookupMutex.Unlock()
+	fake.LookupStub = stub
+}
+
+func (fake *FakeMemStore) LookupArgsForCall(i int) (context.Context, string) {
+	fake.lookupMutex.RLock()
+	defer fake.lookupMutex.RUnlock()
+	argsForCall := fake.lookupArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeMemStore) LookupReturns(result1 models.Item, result2 bool, result3 error) {
+	fake.lookupMutex.Lock()
+	defer fake.lookupMutex.Unlock()
+	fake.LookupStub = nil
+	fake.lookupReturns = struct {
+		result1 models.Item
+		result2 bool
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeMemStore) LookupReturnsOnCall(i int, result1 models.Item, result2 bool, result3 error) {
+	fake.lookupMutex.Lock()
+	defer fake.lookupMutex.Unlock()
+	fake.LookupStub = nil
+	if fake.lookupReturnsOnCall == nil {
+		fake.lookupReturnsOnCall = make(map[int]struct {
+			result1 models.Item
+			result2 bool
+			result3 error
+		})
+	}
+	fake.lookupReturnsOnCall[i] = struct {
+		result1 models.Item
+		result2 bool
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeMemStore) Query(arg1 context.Context, arg2 in_memory.Query) (in_memory.Page, error) {
+	fake.queryMutex.Lock()
+	ret, specificReturn := fake.queryReturnsOnCall[len(fake.queryArgsForCall)]
+	fake.queryArgsForCall = append(fake.queryArgsForCall, struct {
+		arg1 context.Context
+		arg2 in_memory.Query
+	}{arg1, arg2})
+	stub := fake.QueryStub
+	fakeReturns := fake.queryReturns
+	fake.recordInvocation("Query", []interface{}{arg1, arg2})
+	fake.queryMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeMemStore) QueryCallCount() int {
+	fake.queryMutex.RLock()
+	defer fake.queryMutex.RUnlock()
+	return len(fake.queryArgsForCall)
+}
+
+func (fake *FakeMemStore) QueryCalls(stub func(context.Context, in_memory.Query) (in_memory.Page, error)) {
+	fake.queryMutex.Lock()
+	defer fake.queryMutex.Unlock()
+	fake.QueryStub = stub
+}
+
+func (fake *FakeMemStore) QueryArgsForCall(i int) (context.Context, in_memory.Query) {
+	fake.queryMutex.RLock()
+	defer fake.queryMutex.RUnlock()
+	argsForCall := fake.queryArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeMemStore) QueryReturns(result1 in_memory.Page, result2 error) {
+	fake.queryMutex.Lock()
+	defer fake.queryMutex.Unlock()
+	fake.QueryStub = nil
+	fake.queryReturns = struct {
+		result1 in_memory.Page
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeMemStore) QueryReturnsOnCall(i int, result1 in_memory.Page, result2 error) {
+	fake.queryMutex.Lock()
+	defer fake.queryMutex.Unlock()
+	fake.QueryStub = nil
+	if fake.queryReturnsOnCall == nil {
+		fake.queryReturnsOnCall = make(map[int]struct {
+			result1 in_memory.Page
+			result2 error
+		})
+	}
+	fake.queryReturnsOnCall[i] = struct {
+		result1 in_memory.Page
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeMemStore) Reset(arg1 context.Context, arg2 string, arg3 time.Time) ([]byte, bool, error) {
 	fake.resetMutex.Lock()
 	ret, specificReturn := fake.resetReturnsOnCall[len(fake.resetArgsForCall)]
 	fake.resetArgsForCall = append(fake.resetArgsForCall, struct {
-		arg1 string
-		arg2 time.Time
-	}{arg1, arg2})
+		arg1 context.Context
+		arg2 string
+		arg3 time.Time
+	}{arg1, arg2, arg3})
 	stub := fake.ResetStub
 	fakeReturns := fake.resetReturns
-	fake.recordInvocation("Reset", []interface{}{arg1, arg2})
+	fake.recordInvocation("Reset", []interface{}{arg1, arg2, arg3})
 	fake.resetMutex.Unlock()
 	if stub != nil {
-		return stub(arg1, arg2)
+		return stub(arg1, arg2, arg3)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2, ret.result3
@@ -422,17 +1190,17 @@ func (fake *FakeMemStore) ResetCallCount() int {
 	return len(fake.resetArgsForCall)
 }
 
-func (fake *FakeMemStore) ResetCalls(stub func(string, time.Time) ([]byte, bool, error)) {
+func (fake *FakeMemStore) ResetCalls(stub func(context.Context, string, time.Time) ([]byte, bool, error)) {
 	fake.resetMutex.Lock()
 	defer fake.resetMutex.Unlock()
 	fake.ResetStub = stub
 }
 
-func (fake *FakeMemStore) ResetArgsForCall(i int) (string, time.Time) {
+func (fake *FakeMemStore) ResetArgsForCall(i int) (context.Context, string, time.Time) {
 	fake.resetMutex.RLock()
 	defer fake.resetMutex.RUnlock()
 	argsForCall := fake.resetArgsForCall[i]
-	return argsForCall.arg1, argsForCall.arg2
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
 }
 
 func (fake *FakeMemStore) ResetReturns(result1 []byte, result2 bool, result3 error) {
@@ -464,21 +1232,179 @@ func (fake *FakeMemStore) ResetReturnsOnCall(i int, result1 []byte, result2 bool
 	}{result1, result2, result3}
 }
 
+func (fake *FakeMemStore) ResetBatch(arg1 context.Context, arg2 map[string]time.Time) (map[string]models.Item, error) {
+	fake.resetBatchMutex.Lock()
+	ret, specificReturn := fake.resetBatchReturnsOnCall[len(fake.resetBatchArgsForCall)]
+	fake.resetBatchArgsForCall = append(fake.resetBatchArgsForCall, struct {
+		arg1 context.Context
+		arg2 map[string]time.Time
+	}{arg1, arg2})
+	stub := fake.ResetBatchStub
+	fakeReturns := fake.resetBatchReturns
+	fake.recordInvocation("ResetBatch", []interface{}{arg1, arg2})
+	fake.resetBatchMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeMemStore) ResetBatchCallCount() int {
+	fake.resetBatchMutex.RLock()
+	defer fake.resetBatchMutex.RUnlock()
+	return len(fake.resetBatchArgsForCall)
+}
+
+func (fake *FakeMemStore) ResetBatchCalls(stub func(context.Context, map[string]time.Time) (map[string]models.Item, error)) {
+	fake.resetBatchMutex.Lock()
+	defer fake.resetBatchMutex.Unlock()
+	fake.ResetBatchStub = stub
+}
+
+func (fake *FakeMemStore) ResetBatchArgsForCall(i int) (context.Context, map[string]time.Time) {
+	fake.resetBatchMutex.RLock()
+	defer fake.resetBatchMutex.RUnlock()
+	argsForCall := fake.resetBatchArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeMemStore) ResetBatchReturns(result1 map[string]models.Item, result2 error) {
+	fake.resetBatchMutex.Lock()
+	defer fake.resetBatchMutex.Unlock()
+	fake.ResetBatchStub = nil
+	fake.resetBatchReturns = struct {
+		result1 map[string]models.Item
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeMemStore) ResetBatchReturnsOnCall(i int, result1 map[string]models.Item, result2 error) {
+	fake.resetBatchMutex.Lock()
+	defer fake.resetBatchMutex.Unlock()
+	fake.ResetBatchStub = nil
+	if fake.resetBatchReturnsOnCall == nil {
+		fake.resetBatchReturnsOnCall = make(map[int]struct {
+			result1 map[string]models.Item
+			result2 error
+		})
+	}
+	fake.resetBatchReturnsOnCall[i] = struct {
+		result1 map[string]models.Item
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeMemStore) Update(arg1 context.Context, arg2 string, arg3 []byte) (bool, error) {
+	var arg3Copy []byte
+	if arg3 != nil {
+		arg3Copy = make([]byte, len(arg3))
+		copy(arg3Copy, arg3)
+	}
+	fake.updateMutex.Lock()
+	ret, specificReturn := fake.updateReturnsOnCall[len(fake.updateArgsForCall)]
+	fake.updateArgsForCall = append(fake.updateArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 []byte
+	}{arg1, arg2, arg3Copy})
+	stub := fake.UpdateStub
+	fakeReturns := fake.updateReturns
+	fake.recordInvocation("Update", []interface{}{arg1, arg2, arg3Copy})
+	fake.updateMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeMemStore) UpdateCallCount() int {
+	fake.updateMutex.RLock()
+	defer fake.updateMutex.RUnlock()
+	return len(fake.updateArgsForCall)
+}
+
+func (fake *FakeMemStore) UpdateCalls(stub func(context.Context, string, []byte) (bool, error)) {
+	fake.updateMutex.Lock()
+	defer fake.updateMutex.Unlock()
+	fake.UpdateStub = stub
+}
+
+func (fake *FakeMemStore) UpdateArgsForCall(i int) (context.Context, string, []byte) {
+	fake.updateMutex.RLock()
+	defer fake.updateMutex.RUnlock()
+	argsForCall := fake.updateArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeMemStore) UpdateReturns(result1 bool, result2 error) {
+	fake.updateMutex.Lock()
+	defer fake.updateMutex.Unlock()
+	fake.UpdateStub = nil
+	fake.updateReturns = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeMemStore) UpdateReturnsOnCall(i int, result1 bool, result2 error) {
+	fake.updateMutex.Lock()
+	defer fake.updateMutex.Unlock()
+	fake.UpdateStub = nil
+	if fake.updateReturnsOnCall == nil {
+		fake.updateReturnsOnCall = make(map[int]struct {
+			result1 bool
+			result2 error
+		})
+	}
+	fake.updateReturnsOnCall[i] = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeMemStore) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
+	fake.closeMutex.RLock()
+	defer fake.closeMutex.RUnlock()
 	fake.commitMutex.RLock()
 	defer fake.commitMutex.RUnlock()
+	fake.commitBatchMutex.RLock()
+	defer fake.commitBatchMutex.RUnlock()
+	fake.commitItemMutex.RLock()
+	defer fake.commitItemMutex.RUnlock()
+	fake.commitWithSubjectMutex.RLock()
+	defer fake.commitWithSubjectMutex.RUnlock()
 	fake.deleteMutex.RLock()
 	defer fake.deleteMutex.RUnlock()
+	fake.deleteBatchMutex.RLock()
+	defer fake.deleteBatchMutex.RUnlock()
+	fake.deleteBySubjectMutex.RLock()
+	defer fake.deleteBySubjectMutex.RUnlock()
 	fake.findMutex.RLock()
 	defer fake.findMutex.RUnlock()
 	fake.getMutex.RLock()
 	defer fake.getMutex.RUnlock()
 	fake.listMutex.RLock()
 	defer fake.listMutex.RUnlock()
+	fake.listBySubjectMutex.RLock()
+	defer fake.listBySubjectMutex.RUnlock()
+	fake.lookupMutex.RLock()
+	defer fake.lookupMutex.RUnlock()
+	fake.queryMutex.RLock()
+	defer fake.queryMutex.RUnlock()
 	fake.resetMutex.RLock()
 	defer fake.resetMutex.RUnlock()
+	fake.resetBatchMutex.RLock()
+	defer fake.resetBatchMutex.RUnlock()
+	fake.updateMutex.RLock()
+	defer fake.updateMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
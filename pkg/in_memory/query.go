@@ -0,0 +1,199 @@
+package in_memory
+
+import (
+	"encoding/base64"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+
+	. "github.com/hecomp/session-management/internal/models"
+)
+
+// SortField is the item field the sessions listed by MemStore.Query are ordered by
+type SortField string
+
+const (
+	// SortByCreation orders the sessions by their creation time
+	SortByCreation SortField = "created_at"
+	// SortByExpiration orders the sessions by their expiration time
+	SortByExpiration SortField = "expires_at"
+)
+
+// ErrInvalidCursor is returned when the cursor of a query was not returned by a query
+// with the same ordering
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Query selects, orders and pages the live sessions returned by MemStore.Query. The
+// sessions are ordered by the sort field and then by session id, so the order is stable
+// across pages. The time windows are in UnixNano, they include their start and exclude
+// their end, and a zero bound leaves a side open.
+type Query struct {
+	// Subject keeps the sessions of the subject, empty keeps every session
+	Subject       string
+	CreatedAfter  int64
+	CreatedBefore int64
+	ExpiresAfter  int64
+	ExpiresBefore int64
+	// SortBy defaults to SortByCreation
+	SortBy     SortField
+	Descending bool
+	// Cursor is the NextCursor of the previous page, empty starts from the first session
+	Cursor string
+	// Limit caps the number of sessions of the page, zero or less returns every session
+	Limit int
+}
+
+// QueryItem is a session of a Page
+type QueryItem struct {
+	SessionId string
+	Item      Item
+}
+
+// Page is the result of a Query
+type Page struct {
+	Items []QueryItem
+	// NextCursor is the Cursor of the query returning the next page, it is empty on the
+	// last page
+	NextCursor string
+}
+
+// Position is the place of a session in the order of a query, which a cursor encodes
+type Position struct {
+	Key       int64
+	SessionId string
+}
+
+// Key returns the value of the sort field of the item
+func (q Query) Key(item Item) int64 {
+	if q.sortBy() == SortByExpiration {
+		return item.Expiration
+	}
+	return item.CreatedAt
+}
+
+// After decodes the cursor of the query, the zero Position and false are returned when
+// the query starts from the first session
+func (q Query) After() (Position, bool, error) {
+	if q.Cursor == "" {
+		return Position{}, false, nil
+	}
+	b, err := base64.RawURLEncoding.DecodeString(q.Cursor)
+	if err != nil {
+		return Position{}, false, ErrInvalidCursor
+	}
+	parts := strings.SplitN(string(b), ":", 3)
+	if len(parts) != 3 || parts[0] != q.cursorPrefix() || parts[2] == "" {
+		return Position{}, false, ErrInvalidCursor
+	}
+	key, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return Position{}, false, ErrInvalidCursor
+	}
+	return Position{Key: key, SessionId: parts[2]}, true, nil
+}
+
+// CursorAt returns the cursor of the page following the position
+func (q Query) CursorAt(position Position) string {
+	cursor := q.cursorPrefix() + ":" + strconv.FormatInt(position.Key, 10) + ":" + position.SessionId
+	return base64.RawURLEncoding.EncodeToString([]byte(cursor))
+}
+
+// Match reports whether the live item is selected by the filters of the query, the
+// cursor aside
+func (q Query) Match(item Item) bool {
+	if q.Subject != "" && item.Subject != q.Subject {
+		return false
+	}
+	if q.CreatedAfter != 0 && item.CreatedAt < q.CreatedAfter {
+		return false
+	}
+	if q.CreatedBefore != 0 && item.CreatedAt >= q.CreatedBefore {
+		return false
+	}
+	if q.ExpiresAfter != 0 && item.Expiration < q.ExpiresAfter {
+		return false
+	}
+	if q.ExpiresBefore != 0 && item.Expiration >= q.ExpiresBefore {
+		return false
+	}
+	return true
+}
+
+// Filter returns the page of the query over the items, for the stores that can not push
+// the query down to their backend. The expired items are skipped.
+func (q Query) Filter(items map[string]Item, now int64) (Page, error) {
+	return q.filter(items, now, nil)
+}
+
+// filter selects the page like Filter, load completes the items before they are checked
+// when it is not nil
+func (q Query) filter(items map[string]Item, now int64, load func(string, Item) Item) (Page, error) {
+	after, hasCursor, err := q.After()
+	if err != nil {
+		return Page{}, err
+	}
+
+	var selected []QueryItem
+	for sessionId, item := range items {
+		if load != nil {
+			item = load(sessionId, item)
+		}
+		if expired(item, now) || !q.Match(item) {
+			continue
+		}
+		if hasCursor && !q.follows(Position{Key: q.Key(item), SessionId: sessionId}, after) {
+			continue
+		}
+		selected = append(selected, QueryItem{SessionId: sessionId, Item: item})
+	}
+	return q.Paginate(selected, false), nil
+}
+
+// Paginate orders the selected items and keeps the first Limit of them. more reports
+// whether items following the selected ones were left out, as a page of a shard does.
+func (q Query) Paginate(selected []QueryItem, more bool) Page {
+	sort.Slice(selected, func(i, j int) bool {
+		return q.follows(
+			Position{Key: q.Key(selected[j].Item), SessionId: selected[j].SessionId},
+			Position{Key: q.Key(selected[i].Item), SessionId: selected[i].SessionId})
+	})
+
+	page := Page{Items: selected}
+	if q.Limit > 0 && len(selected) > q.Limit {
+		page.Items = selected[:q.Limit]
+		more = true
+	}
+	if more && len(page.Items) > 0 {
+		last := page.Items[len(page.Items)-1]
+		page.NextCursor = q.CursorAt(Position{Key: q.Key(last.Item), SessionId: last.SessionId})
+	}
+	return page
+}
+
+// follows reports whether the position comes after the other one in the order of the query
+func (q Query) follows(position, other Position) bool {
+	if position.Key != other.Key {
+		return (position.Key > other.Key) != q.Descending
+	}
+	if position.SessionId != other.SessionId {
+		return (position.SessionId > other.SessionId) != q.Descending
+	}
+	return false
+}
+
+// cursorPrefix ties the cursors to the ordering of the query they were returned by
+func (q Query) cursorPrefix() string {
+	prefix := string(q.sortBy())
+	if q.Descending {
+		prefix = "-" + prefix
+	}
+	return prefix
+}
+
+func (q Query) sortBy() SortField {
+	if q.SortBy == "" {
+		return SortByCreation
+	}
+	return q.SortBy
+}
@@ -0,0 +1,138 @@
+package in_memory_test
+
+import (
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/hecomp/session-management/internal/models"
+	. "github.com/hecomp/session-management/pkg/in_memory"
+	"github.com/hecomp/session-management/pkg/test"
+)
+
+var _ = Describe("Query", func() {
+
+	// base is the creation time of the first session, the sessions are created a second
+	// apart and expire in the reverse order of their creation
+	base := time.Now().Add(-time.Hour).UnixNano()
+	sessionIds := make([]string, 10)
+	for i := range sessionIds {
+		sessionIds[i] = fmt.Sprintf("90660b89-100e-4f8f-9801-2524df6fbe%02d", i)
+	}
+
+	commit := func(mem MemStore) {
+		for i, sessionId := range sessionIds {
+			subject := "user-42"
+			if i%2 == 1 {
+				subject = "user-43"
+			}
+			err := mem.CommitItem(ctx, sessionId, models.Item{
+				Oject:      []byte(sessionId),
+				Subject:    subject,
+				CreatedAt:  base + int64(i)*int64(time.Second),
+				Expiration: time.Now().Add(time.Duration(20-i) * time.Minute).UnixNano(),
+			})
+			Expect(err).To(BeNil())
+		}
+	}
+
+	ids := func(page Page) []string {
+		var list []string
+		for _, item := range page.Items {
+			list = append(list, item.SessionId)
+		}
+		return list
+	}
+
+	// walk returns the session ids of every page of the query
+	walk := func(mem MemStore, query Query) []string {
+		var list []string
+		for pages := 0; pages < len(sessionIds)+1; pages++ {
+			page, err := mem.Query(ctx, query)
+			Expect(err).To(BeNil())
+			list = append(list, ids(page)...)
+			if page.NextCursor == "" {
+				return list
+			}
+			query.Cursor = page.NextCursor
+		}
+		Fail("the pages do not end")
+		return nil
+	}
+
+	for name, newStore := range map[string]func() MemStore{
+		"InMemStore":        func() MemStore { return NewInMemStore(0, test.GetLogger()) },
+		"ShardedInMemStore": func() MemStore { return NewShardedInMemStore(4, 0, test.GetLogger()) },
+	} {
+		newStore := newStore
+
+		Describe(name, func() {
+			var mem MemStore
+
+			BeforeEach(func() {
+				mem = newStore()
+				commit(mem)
+			})
+
+			It("orders the sessions by creation", func() {
+				page, err := mem.Query(ctx, Query{})
+				Expect(err).To(BeNil())
+				Expect(ids(page)).To(Equal(sessionIds))
+				Expect(page.NextCursor).To(BeEmpty())
+			})
+			It("walks the pages in a stable order", func() {
+				Expect(walk(mem, Query{Limit: 3})).To(Equal(sessionIds))
+			})
+			It("walks the pages in the descending order of expiration", func() {
+				Expect(walk(mem, Query{Limit: 4, SortBy: SortByExpiration, Descending: true})).To(Equal(sessionIds))
+			})
+			It("keeps the sessions of the subject", func() {
+				page, err := mem.Query(ctx, Query{Subject: "user-43"})
+				Expect(err).To(BeNil())
+				Expect(ids(page)).To(Equal([]string{sessionIds[1], sessionIds[3], sessionIds[5], sessionIds[7], sessionIds[9]}))
+			})
+			It("keeps the sessions of the creation window", func() {
+				page, err := mem.Query(ctx, Query{
+					CreatedAfter:  base + 2*int64(time.Second),
+					CreatedBefore: base + 5*int64(time.Second),
+				})
+				Expect(err).To(BeNil())
+				Expect(ids(page)).To(Equal(sessionIds[2:5]))
+			})
+			It("keeps the sessions of the expiration window", func() {
+				page, err := mem.Query(ctx, Query{ExpiresAfter: time.Now().Add(15 * time.Minute).UnixNano()})
+				Expect(err).To(BeNil())
+				Expect(ids(page)).To(Equal(sessionIds[:5]))
+			})
+			It("skips the expired sessions", func() {
+				err := mem.CommitItem(ctx, "expired", models.Item{Oject: []byte("expired"), Expiration: time.Now().Add(-time.Minute).UnixNano()})
+				Expect(err).To(BeNil())
+				page, err := mem.Query(ctx, Query{})
+				Expect(err).To(BeNil())
+				Expect(ids(page)).To(Equal(sessionIds))
+			})
+			It("refuses a cursor of another ordering", func() {
+				page, err := mem.Query(ctx, Query{Limit: 1})
+				Expect(err).To(BeNil())
+				_, err = mem.Query(ctx, Query{Limit: 1, Cursor: page.NextCursor, Descending: true})
+				Expect(err).To(Equal(ErrInvalidCursor))
+				_, err = mem.Query(ctx, Query{Cursor: "not a cursor"})
+				Expect(err).To(Equal(ErrInvalidCursor))
+			})
+		})
+	}
+
+	Context("CommitItem()", func() {
+		It("sets the creation time of a new session", func() {
+			mem := NewInMemStore(0, test.GetLogger())
+			before := time.Now().UnixNano()
+			Expect(mem.CommitItem(ctx, sessionIds[0], models.Item{Oject: []byte(sessionIds[0]), Expiration: time.Now().Add(time.Minute).UnixNano()})).To(Succeed())
+			item, found, err := mem.Lookup(ctx, sessionIds[0])
+			Expect(err).To(BeNil())
+			Expect(found).To(BeTrue())
+			Expect(item.CreatedAt).To(BeNumerically(">=", before))
+		})
+	})
+})
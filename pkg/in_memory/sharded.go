@@ -0,0 +1,297 @@
+package in_memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/metrics/discard"
+
+	. "github.com/hecomp/session-management/internal/models"
+)
+
+// DefaultShards is the number of shards used when a non-positive count is given
+const DefaultShards = 32
+
+// ShardedInMemStore represents a session in-memory store split across independently
+// locked shards. Session ids are hashed to a shard, so requests for different sessions
+// rarely contend on the same lock and the expiry sweep only locks one shard at a time.
+type ShardedInMemStore struct {
+	logger       log.Logger
+	metrics      Metrics
+	shards       []*InMemStore
+	stopCleanup  chan bool
+	stopOnce     sync.Once
+	probeCleanup chan struct{}
+}
+
+// NewShardedInMemStore returns a new ShardedInMemStore instance with the given number of
+// shards, with a background session cleanup goroutine that sweeps the shards one by one
+// every sessionInterval to remove expired session data.
+func NewShardedInMemStore(shards int, sessionInterval time.Duration, logger log.Logger) MemStore {
+	return NewInstrumentedShardedInMemStore(shards, sessionInterval, Metrics{}, logger)
+}
+
+// NewInstrumentedShardedInMemStore returns a new ShardedInMemStore instance like
+// NewShardedInMemStore, reporting the store-level metrics of every shard.
+func NewInstrumentedShardedInMemStore(shards int, sessionInterval time.Duration, metrics Metrics, logger log.Logger) MemStore {
+	if shards <= 0 {
+		shards = DefaultShards
+	}
+	if metrics.CleanupSweeps == nil {
+		metrics.CleanupSweeps = discard.NewCounter()
+	}
+
+	m := &ShardedInMemStore{
+		logger:  logger,
+		metrics: metrics,
+		shards:  make([]*InMemStore, shards),
+	}
+
+	// The shards share the session gauge and counter, a sweep is only counted once
+	// every shard was swept.
+	shardMetrics := Metrics{ActiveSessions: metrics.ActiveSessions, ExpiredSessions: metrics.ExpiredSessions}
+	for i := range m.shards {
+		m.shards[i] = NewInstrumentedInMemStore(0, shardMetrics, logger).(*InMemStore)
+	}
+
+	if sessionInterval > 0 {
+		m.stopCleanup = make(chan bool)
+		m.probeCleanup = make(chan struct{})
+		go m.startSessionCleanup(sessionInterval)
+	}
+
+	return m
+}
+
+// Find returns the data for a given session from its shard.
+func (m *ShardedInMemStore) Find(ctx context.Context, sessionId string) ([]byte, bool, error) {
+	return m.shard(sessionId).Find(ctx, sessionId)
+}
+
+// Lookup returns the item for a given session from its shard.
+func (m *ShardedInMemStore) Lookup(ctx context.Context, sessionId string) (Item, bool, error) {
+	return m.shard(sessionId).Lookup(ctx, sessionId)
+}
+
+// Commit adds a session to its shard.
+func (m *ShardedInMemStore) Commit(ctx context.Context, sessionId string, b []byte, expiration time.Time) error {
+	return m.shard(sessionId).Commit(ctx, sessionId, b, expiration)
+}
+
+// CommitWithSubject adds a session to its shard and indexes it under the given subject.
+func (m *ShardedInMemStore) CommitWithSubject(ctx context.Context, sessionId string, subject string, b []byte, expiration time.Time) error {
+	return m.shard(sessionId).CommitWithSubject(ctx, sessionId, subject, b, expiration)
+}
+
+// CommitItem adds a session to its shard, keeping its idle timeout and maximum expiration.
+func (m *ShardedInMemStore) CommitItem(ctx context.Context, sessionId string, item Item) error {
+	return m.shard(sessionId).CommitItem(ctx, sessionId, item)
+}
+
+// Delete removes a session from its shard.
+func (m *ShardedInMemStore) Delete(ctx context.Context, sessionId string) error {
+	return m.shard(sessionId).Delete(ctx, sessionId)
+}
+
+// CommitBatch adds every session to its shard, locking each shard once. The shards are
+// not locked together, so a reader may see part of the batch.
+func (m *ShardedInMemStore) CommitBatch(ctx context.Context, items map[string]Item) error {
+	batches := make(map[*InMemStore]map[string]Item)
+	for sessionId, item := range items {
+		shard := m.shard(sessionId)
+		if batches[shard] == nil {
+			batches[shard] = make(map[string]Item)
+		}
+		batches[shard][sessionId] = item
+	}
+	for shard, batch := range batches {
+		if err := shard.CommitBatch(ctx, batch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteBatch removes the sessions from their shard, locking each shard once, and returns
+// the live sessions that were removed
+func (m *ShardedInMemStore) DeleteBatch(ctx context.Context, sessionIds []string) (map[string]Item, error) {
+	batches := make(map[*InMemStore][]string)
+	for _, sessionId := range sessionIds {
+		shard := m.shard(sessionId)
+		batches[shard] = append(batches[shard], sessionId)
+	}
+	deleted := make(map[string]Item)
+	for shard, batch := range batches {
+		items, err := shard.DeleteBatch(ctx, batch)
+		if err != nil {
+			return nil, err
+		}
+		for sessionId, item := range items {
+			deleted[sessionId] = item
+		}
+	}
+	return deleted, nil
+}
+
+// DeleteBySubject removes every session of the subject from every shard and returns
+// the ids of the live sessions that were removed. The shards are not locked together,
+// so a session of the subject committed meanwhile may be kept.
+func (m *ShardedInMemStore) DeleteBySubject(ctx context.Context, subject string) ([]string, error) {
+	var sessionIds []string
+	for _, shard := range m.shards {
+		ids, err := shard.DeleteBySubject(ctx, subject)
+		if err != nil {
+			return nil, err
+		}
+		sessionIds = append(sessionIds, ids...)
+	}
+	return sessionIds, nil
+}
+
+// ListBySubject returns the live sessions of the subject from every shard.
+func (m *ShardedInMemStore) ListBySubject(ctx context.Context, subject string) (map[string]Item, error) {
+	items := make(map[string]Item)
+	for _, shard := range m.shards {
+		shardItems, err := shard.ListBySubject(ctx, subject)
+		if err != nil {
+			return nil, err
+		}
+		for sessionId, item := range shardItems {
+			items[sessionId] = item
+		}
+	}
+	return items, nil
+}
+
+// Reset extend a session ttl in its shard.
+func (m *ShardedInMemStore) Reset(ctx context.Context, sessionId string, expiration time.Time) ([]byte, bool, error) {
+	return m.shard(sessionId).Reset(ctx, sessionId, expiration)
+}
+
+// ResetBatch extends the live sessions of their shard, locking each shard once, and
+// returns the sessions that were extended
+func (m *ShardedInMemStore) ResetBatch(ctx context.Context, expirations map[string]time.Time) (map[string]Item, error) {
+	batches := make(map[*InMemStore]map[string]time.Time)
+	for sessionId, expiration := range expirations {
+		shard := m.shard(sessionId)
+		if batches[shard] == nil {
+			batches[shard] = make(map[string]time.Time)
+		}
+		batches[shard][sessionId] = expiration
+	}
+	extended := make(map[string]Item)
+	for shard, batch := range batches {
+		items, err := shard.ResetBatch(ctx, batch)
+		if err != nil {
+			return nil, err
+		}
+		for sessionId, item := range items {
+			extended[sessionId] = item
+		}
+	}
+	return extended, nil
+}
+
+// Update replaces the data of a live session in its shard.
+func (m *ShardedInMemStore) Update(ctx context.Context, sessionId string, b []byte) (bool, error) {
+	return m.shard(sessionId).Update(ctx, sessionId, b)
+}
+
+// List return a list of all the sessions from every shard
+func (m *ShardedInMemStore) List(ctx context.Context) (map[string]Item, error) {
+	items := make(map[string]Item)
+	for _, shard := range m.shards {
+		shardItems, err := shard.List(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for sessionId, item := range shardItems {
+			items[sessionId] = item
+		}
+	}
+	return items, nil
+}
+
+// Query returns the page of the live sessions selected by the query from every shard. Each
+// shard returns its own first page, which are merged and cut to the limit of the query.
+func (m *ShardedInMemStore) Query(ctx context.Context, query Query) (Page, error) {
+	var selected []QueryItem
+	more := false
+	for _, shard := range m.shards {
+		page, err := shard.Query(ctx, query)
+		if err != nil {
+			return Page{}, err
+		}
+		selected = append(selected, page.Items...)
+		more = more || page.NextCursor != ""
+	}
+	return query.Paginate(selected, more), nil
+}
+
+// Get returns a copy of the sessions of every shard
+func (m *ShardedInMemStore) Get(ctx context.Context) map[string]Item {
+	items, _ := m.List(ctx)
+	return items
+}
+
+// StopSessionCleanup terminates the background cleanup goroutine for the ShardedInMemStore instance.
+func (m *ShardedInMemStore) StopSessionCleanup() {
+	m.logger.Log("stopCleanup")
+	if m.stopCleanup != nil {
+		m.stopOnce.Do(func() { m.stopCleanup <- true })
+	}
+}
+
+// Close stops the background cleanup goroutine of the ShardedInMemStore instance and closes
+// its shards.
+func (m *ShardedInMemStore) Close(ctx context.Context) error {
+	m.StopSessionCleanup()
+	for _, shard := range m.shards {
+		if err := shard.Close(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Ping reports whether the ShardedInMemStore instance is reachable, which it always is
+func (m *ShardedInMemStore) Ping(ctx context.Context) error {
+	return nil
+}
+
+// CheckCleanup reports whether the background cleanup goroutine of the ShardedInMemStore
+// instance answers before the context is done.
+func (m *ShardedInMemStore) CheckCleanup(ctx context.Context) error {
+	return ProbeCleanup(ctx, m.probeCleanup)
+}
+
+// startSessionCleanup sweeps the expired sessions of the shards one by one on every tick
+func (m *ShardedInMemStore) startSessionCleanup(interval time.Duration) {
+	m.logger.Log("method", "startSessionCleanup", "shards", len(m.shards))
+	ticker := time.NewTicker(interval)
+	for {
+		select {
+		case <-ticker.C:
+			for _, shard := range m.shards {
+				shard.deleteSessionExpired()
+			}
+			m.metrics.CleanupSweeps.Add(1)
+		case <-m.probeCleanup:
+		case <-m.stopCleanup:
+			ticker.Stop()
+			return
+		}
+	}
+}
+
+// shard returns the shard of the session, chosen by the 32-bit FNV-1a hash of its id
+func (m *ShardedInMemStore) shard(sessionId string) *InMemStore {
+	hash := uint32(2166136261)
+	for i := 0; i < len(sessionId); i++ {
+		hash ^= uint32(sessionId[i])
+		hash *= 16777619
+	}
+	return m.shards[hash%uint32(len(m.shards))]
+}
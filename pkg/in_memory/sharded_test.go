@@ -0,0 +1,130 @@
+package in_memory_test
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-kit/kit/metrics/generic"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/hecomp/session-management/pkg/in_memory"
+	"github.com/hecomp/session-management/pkg/test"
+)
+
+var _ = Describe("ShardedInMemory", func() {
+
+	s := &InMemStoreSuite{}
+
+	BeforeEach(func() {
+		s.logger = test.GetLogger()
+		s.mem = NewShardedInMemStore(4, 0, s.logger)
+	})
+
+	Describe("Sessions", func() {
+		sessionIds := make([]string, 16)
+		for i := range sessionIds {
+			sessionIds[i] = fmt.Sprintf("90660b89-100e-4f8f-9801-2524df6fbe%02d", i)
+		}
+		BeforeEach(func() {
+			for _, sessionId := range sessionIds {
+				err := s.mem.CommitWithSubject(ctx, sessionId, "user-42", []byte(sessionId), time.Now().Add(time.Minute))
+				Expect(err).To(BeNil())
+			}
+		})
+
+		Context("Find()", func() {
+			It("finds every sessionId across the shards", func() {
+				for _, sessionId := range sessionIds {
+					obj, found, err := s.mem.Find(ctx, sessionId)
+					Expect(err).To(BeNil())
+					Expect(found).To(BeTrue())
+					Expect(string(obj)).To(Equal(sessionId))
+				}
+			})
+		})
+
+		Context("Delete()", func() {
+			It("deletes the sessionId from its shard", func() {
+				Expect(s.mem.Delete(ctx, sessionIds[0])).To(Succeed())
+				_, found, err := s.mem.Find(ctx, sessionIds[0])
+				Expect(err).To(BeNil())
+				Expect(found).ToNot(BeTrue())
+			})
+		})
+
+		Context("List()", func() {
+			It("lists the sessionIds of every shard", func() {
+				sessionMap, err := s.mem.List(ctx)
+				Expect(err).To(BeNil())
+				Expect(sessionMap).To(HaveLen(len(sessionIds)))
+			})
+		})
+
+		Context("ListBySubject()", func() {
+			It("lists the sessions of the subject across the shards", func() {
+				sessionMap, err := s.mem.ListBySubject(ctx, "user-42")
+				Expect(err).To(BeNil())
+				Expect(sessionMap).To(HaveLen(len(sessionIds)))
+			})
+		})
+
+		Context("DeleteBySubject()", func() {
+			It("deletes the sessions of the subject across the shards", func() {
+				deleted, err := s.mem.DeleteBySubject(ctx, "user-42")
+				Expect(err).To(BeNil())
+				Expect(deleted).To(ConsistOf(sessionIds))
+				Expect(s.mem.Get(ctx)).To(BeEmpty())
+			})
+		})
+	})
+
+	Describe("Store metrics", func() {
+		uniqueUUID1 := "90660b89-100e-4f8f-9801-2524df6fbe34"
+		uniqueUUID2 := "90660b89-100e-4f8f-9801-2524df6fbe99"
+		var metrics Metrics
+		BeforeEach(func() {
+			metrics = Metrics{
+				ActiveSessions:  generic.NewGauge("active_sessions"),
+				ExpiredSessions: generic.NewCounter("expired_sessions_total"),
+				CleanupSweeps:   generic.NewCounter("cleanup_sweeps_total"),
+			}
+			s.mem = NewInstrumentedShardedInMemStore(4, 101*time.Millisecond, metrics, s.logger)
+			err := s.mem.Commit(ctx, uniqueUUID1, []byte(uniqueUUID1), time.Now().Add(time.Minute))
+			Expect(err).To(BeNil())
+			err = s.mem.Commit(ctx, uniqueUUID2, []byte(uniqueUUID2), time.Now().Add(100*time.Millisecond))
+			Expect(err).To(BeNil())
+		})
+
+		AfterEach(func() {
+			s.mem.(*ShardedInMemStore).StopSessionCleanup()
+		})
+
+		Context("DeleteSessionExpired()", func() {
+			When("the cleanup sweeps the shards", func() {
+				It("reports active and expired sessions of every shard", func() {
+					Expect(metrics.ActiveSessions.(*generic.Gauge).Value()).To(Equal(float64(2)))
+					Eventually(metrics.CleanupSweeps.(*generic.Counter).Value).Should(BeNumerically(">=", 1))
+					Eventually(metrics.ExpiredSessions.(*generic.Counter).Value).Should(Equal(float64(1)))
+					Eventually(metrics.ActiveSessions.(*generic.Gauge).Value).Should(Equal(float64(1)))
+					_, found, err := s.mem.Find(ctx, uniqueUUID1)
+					Expect(err).To(BeNil())
+					Expect(found).To(BeTrue())
+				})
+			})
+		})
+	})
+	Describe("Close store", func() {
+		Context("Close()", func() {
+			It("stops the cleanup goroutine", func() {
+				s.mem = NewShardedInMemStore(4, 10*time.Millisecond, s.logger)
+				Expect(s.mem.Close(ctx)).To(Succeed())
+
+				checkCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+				defer cancel()
+				Expect(s.mem.(HealthChecker).CheckCleanup(checkCtx)).To(Equal(ErrCleanupStalled))
+			})
+		})
+	})
+})
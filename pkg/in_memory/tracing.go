@@ -0,0 +1,206 @@
+package in_memory
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	. "github.com/hecomp/session-management/internal/models"
+)
+
+// TracingMemStore runs every call to the store it wraps in a span, so the time spent
+// waiting on the store lock or a remote backend shows in the trace of the request.
+type TracingMemStore struct {
+	tracer trace.Tracer
+	store  MemStore
+}
+
+// NewTracingMemStore returns the store wrapped in a TracingMemStore
+func NewTracingMemStore(tracer trace.Tracer, store MemStore) MemStore {
+	return &TracingMemStore{tracer: tracer, store: store}
+}
+
+// Find returns the data for a given session from the wrapped store.
+func (t *TracingMemStore) Find(ctx context.Context, sessionId string) (b []byte, found bool, err error) {
+	ctx, span := t.tracer.Start(ctx, "MemStore.Find")
+	defer func() {
+		span.SetAttributes(attribute.Bool("store.found", found))
+		endSpan(span, err)
+	}()
+	return t.store.Find(ctx, sessionId)
+}
+
+// Lookup returns the item for a given session from the wrapped store.
+func (t *TracingMemStore) Lookup(ctx context.Context, sessionId string) (item Item, found bool, err error) {
+	ctx, span := t.tracer.Start(ctx, "MemStore.Lookup")
+	defer func() {
+		span.SetAttributes(attribute.Bool("store.found", found))
+		endSpan(span, err)
+	}()
+	return t.store.Lookup(ctx, sessionId)
+}
+
+// Commit adds a session to the wrapped store.
+func (t *TracingMemStore) Commit(ctx context.Context, sessionId string, b []byte, expiration time.Time) (err error) {
+	ctx, span := t.tracer.Start(ctx, "MemStore.Commit")
+	defer func() { endSpan(span, err) }()
+	return t.store.Commit(ctx, sessionId, b, expiration)
+}
+
+// CommitWithSubject adds a session to the wrapped store along with its subject.
+func (t *TracingMemStore) CommitWithSubject(ctx context.Context, sessionId string, subject string, b []byte, expiration time.Time) (err error) {
+	ctx, span := t.tracer.Start(ctx, "MemStore.CommitWithSubject")
+	defer func() { endSpan(span, err) }()
+	return t.store.CommitWithSubject(ctx, sessionId, subject, b, expiration)
+}
+
+// CommitItem adds a session to the wrapped store, keeping its idle timeout and maximum
+// expiration.
+func (t *TracingMemStore) CommitItem(ctx context.Context, sessionId string, item Item) (err error) {
+	ctx, span := t.tracer.Start(ctx, "MemStore.CommitItem")
+	defer func() { endSpan(span, err) }()
+	return t.store.CommitItem(ctx, sessionId, item)
+}
+
+// CommitBatch adds the sessions to the wrapped store.
+func (t *TracingMemStore) CommitBatch(ctx context.Context, items map[string]Item) (err error) {
+	ctx, span := t.tracer.Start(ctx, "MemStore.CommitBatch",
+		trace.WithAttributes(attribute.Int("store.batch", len(items))))
+	defer func() { endSpan(span, err) }()
+	return t.store.CommitBatch(ctx, items)
+}
+
+// Delete removes a session from the wrapped store.
+func (t *TracingMemStore) Delete(ctx context.Context, sessionId string) (err error) {
+	ctx, span := t.tracer.Start(ctx, "MemStore.Delete")
+	defer func() { endSpan(span, err) }()
+	return t.store.Delete(ctx, sessionId)
+}
+
+// DeleteBatch removes the sessions from the wrapped store.
+func (t *TracingMemStore) DeleteBatch(ctx context.Context, sessionIds []string) (deleted map[string]Item, err error) {
+	ctx, span := t.tracer.Start(ctx, "MemStore.DeleteBatch",
+		trace.WithAttributes(attribute.Int("store.batch", len(sessionIds))))
+	defer func() {
+		span.SetAttributes(attribute.Int("store.sessions", len(deleted)))
+		endSpan(span, err)
+	}()
+	return t.store.DeleteBatch(ctx, sessionIds)
+}
+
+// DeleteBySubject removes every session of the subject from the wrapped store.
+func (t *TracingMemStore) DeleteBySubject(ctx context.Context, subject string) (sessionIds []string, err error) {
+	ctx, span := t.tracer.Start(ctx, "MemStore.DeleteBySubject")
+	defer func() {
+		span.SetAttributes(attribute.Int("store.sessions", len(sessionIds)))
+		endSpan(span, err)
+	}()
+	return t.store.DeleteBySubject(ctx, subject)
+}
+
+// ListBySubject returns the live sessions of the subject from the wrapped store.
+func (t *TracingMemStore) ListBySubject(ctx context.Context, subject string) (items map[string]Item, err error) {
+	ctx, span := t.tracer.Start(ctx, "MemStore.ListBySubject")
+	defer func() {
+		span.SetAttributes(attribute.Int("store.sessions", len(items)))
+		endSpan(span, err)
+	}()
+	return t.store.ListBySubject(ctx, subject)
+}
+
+// Reset extend a session ttl in the wrapped store.
+func (t *TracingMemStore) Reset(ctx context.Context, sessionId string, expiration time.Time) (b []byte, found bool, err error) {
+	ctx, span := t.tracer.Start(ctx, "MemStore.Reset")
+	defer func() {
+		span.SetAttributes(attribute.Bool("store.found", found))
+		endSpan(span, err)
+	}()
+	return t.store.Reset(ctx, sessionId, expiration)
+}
+
+// ResetBatch extends the live sessions of the wrapped store.
+func (t *TracingMemStore) ResetBatch(ctx context.Context, expirations map[string]time.Time) (extended map[string]Item, err error) {
+	ctx, span := t.tracer.Start(ctx, "MemStore.ResetBatch",
+		trace.WithAttributes(attribute.Int("store.batch", len(expirations))))
+	defer func() {
+		span.SetAttributes(attribute.Int("store.sessions", len(extended)))
+		endSpan(span, err)
+	}()
+	return t.store.ResetBatch(ctx, expirations)
+}
+
+// Update replaces the data of a live session in the wrapped store.
+func (t *TracingMemStore) Update(ctx context.Context, sessionId string, b []byte) (found bool, err error) {
+	ctx, span := t.tracer.Start(ctx, "MemStore.Update")
+	defer func() {
+		span.SetAttributes(attribute.Bool("store.found", found))
+		endSpan(span, err)
+	}()
+	return t.store.Update(ctx, sessionId, b)
+}
+
+// List return a list of all the sessions from the wrapped store
+func (t *TracingMemStore) List(ctx context.Context) (items map[string]Item, err error) {
+	ctx, span := t.tracer.Start(ctx, "MemStore.List")
+	defer func() {
+		span.SetAttributes(attribute.Int("store.sessions", len(items)))
+		endSpan(span, err)
+	}()
+	return t.store.List(ctx)
+}
+
+// Query returns the page of the sessions selected by the query from the wrapped store
+func (t *TracingMemStore) Query(ctx context.Context, query Query) (page Page, err error) {
+	ctx, span := t.tracer.Start(ctx, "MemStore.Query")
+	defer func() {
+		span.SetAttributes(
+			attribute.String("store.sort", string(query.sortBy())),
+			attribute.Int("store.limit", query.Limit),
+			attribute.Int("store.sessions", len(page.Items)))
+		endSpan(span, err)
+	}()
+	return t.store.Query(ctx, query)
+}
+
+// Get returns the sessions of the wrapped store
+func (t *TracingMemStore) Get(ctx context.Context) map[string]Item {
+	ctx, span := t.tracer.Start(ctx, "MemStore.Get")
+	defer span.End()
+	return t.store.Get(ctx)
+}
+
+// Close closes the wrapped store, flushing its final state.
+func (t *TracingMemStore) Close(ctx context.Context) (err error) {
+	ctx, span := t.tracer.Start(ctx, "MemStore.Close")
+	defer func() { endSpan(span, err) }()
+	return t.store.Close(ctx)
+}
+
+// Ping reports whether the backend of the wrapped store is reachable, the probes of the
+// health checks are not traced.
+func (t *TracingMemStore) Ping(ctx context.Context) error {
+	if checker, ok := t.store.(HealthChecker); ok {
+		return checker.Ping(ctx)
+	}
+	return nil
+}
+
+// CheckCleanup reports whether the cleanup goroutine of the wrapped store answers.
+func (t *TracingMemStore) CheckCleanup(ctx context.Context) error {
+	if checker, ok := t.store.(HealthChecker); ok {
+		return checker.CheckCleanup(ctx)
+	}
+	return nil
+}
+
+// endSpan records the error of the call, if any, and ends its span
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
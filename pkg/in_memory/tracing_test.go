@@ -0,0 +1,131 @@
+package in_memory_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/hecomp/session-management/internal/models"
+	. "github.com/hecomp/session-management/pkg/in_memory"
+	"github.com/hecomp/session-management/pkg/in_memory/in_memoryfakes"
+	"github.com/hecomp/session-management/pkg/test"
+)
+
+type TracingMemStoreSuite struct {
+	recorder *tracetest.SpanRecorder
+	tracer   trace.Tracer
+}
+
+var _ = Describe("TracingMemStore", func() {
+
+	const uniqueUUID = "90660b89-100e-4f8f-9801-2524df6fbe34"
+
+	s := &TracingMemStoreSuite{}
+
+	BeforeEach(func() {
+		s.recorder = tracetest.NewSpanRecorder()
+		s.tracer = sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(s.recorder)).Tracer("test")
+	})
+
+	It("runs every call in a child span of the request", func() {
+		mem := NewTracingMemStore(s.tracer, NewInMemStore(0, test.GetLogger()))
+		requestCtx, request := s.tracer.Start(ctx, "request")
+		Expect(mem.Commit(requestCtx, uniqueUUID, []byte(uniqueUUID), time.Now().Add(time.Minute))).To(Succeed())
+		_, found, err := mem.Find(requestCtx, uniqueUUID)
+		Expect(err).To(BeNil())
+		Expect(found).To(BeTrue())
+		request.End()
+
+		spans := s.recorder.Ended()
+		Expect(spans).To(HaveLen(3))
+		Expect(spans[0].Name()).To(Equal("MemStore.Commit"))
+		Expect(spans[1].Name()).To(Equal("MemStore.Find"))
+		Expect(spans[1].Attributes()).To(ContainElement(attribute.Bool("store.found", true)))
+		for _, span := range spans[:2] {
+			Expect(span.Parent().SpanID()).To(Equal(request.SpanContext().SpanID()))
+			Expect(span.Status().Code).To(Equal(codes.Unset))
+		}
+	})
+
+	It("passes the context of the span to the wrapped store", func() {
+		fake := new(in_memoryfakes.FakeMemStore)
+		mem := NewTracingMemStore(s.tracer, fake)
+		_, _, err := mem.Lookup(ctx, uniqueUUID)
+		Expect(err).To(BeNil())
+
+		storeCtx, sessionId := fake.LookupArgsForCall(0)
+		Expect(sessionId).To(Equal(uniqueUUID))
+		Expect(trace.SpanContextFromContext(storeCtx)).To(Equal(s.recorder.Ended()[0].SpanContext()))
+	})
+
+	It("records the error of the call", func() {
+		fake := new(in_memoryfakes.FakeMemStore)
+		fake.ListReturns(nil, errors.New("error list"))
+		_, err := NewTracingMemStore(s.tracer, fake).List(ctx)
+		Expect(err).ToNot(BeNil())
+
+		spans := s.recorder.Ended()
+		Expect(spans).To(HaveLen(1))
+		Expect(spans[0].Name()).To(Equal("MemStore.List"))
+		Expect(spans[0].Status().Code).To(Equal(codes.Error))
+		Expect(spans[0].Status().Description).To(Equal("error list"))
+	})
+
+	It("records the ordering and the size of a queried page", func() {
+		fake := new(in_memoryfakes.FakeMemStore)
+		fake.QueryReturns(Page{Items: []QueryItem{{SessionId: uniqueUUID}}}, nil)
+		_, err := NewTracingMemStore(s.tracer, fake).Query(ctx, Query{Limit: 10})
+		Expect(err).To(BeNil())
+
+		spans := s.recorder.Ended()
+		Expect(spans).To(HaveLen(1))
+		Expect(spans[0].Name()).To(Equal("MemStore.Query"))
+		Expect(spans[0].Attributes()).To(ContainElements(
+			attribute.String("store.sort", string(SortByCreation)),
+			attribute.Int("store.limit", 10),
+			attribute.Int("store.sessions", 1)))
+	})
+
+	It("records the size of a batch and the sessions it removed", func() {
+		fake := new(in_memoryfakes.FakeMemStore)
+		fake.DeleteBatchReturns(map[string]models.Item{uniqueUUID: {}}, nil)
+		_, err := NewTracingMemStore(s.tracer, fake).DeleteBatch(ctx, []string{uniqueUUID, "unknown"})
+		Expect(err).To(BeNil())
+
+		spans := s.recorder.Ended()
+		Expect(spans).To(HaveLen(1))
+		Expect(spans[0].Name()).To(Equal("MemStore.DeleteBatch"))
+		Expect(spans[0].Attributes()).To(ContainElements(
+			attribute.Int("store.batch", 2),
+			attribute.Int("store.sessions", 1)))
+	})
+
+	It("closes the wrapped store in a span", func() {
+		fake := new(in_memoryfakes.FakeMemStore)
+		Expect(NewTracingMemStore(s.tracer, fake).Close(ctx)).To(Succeed())
+		Expect(fake.CloseCallCount()).To(Equal(1))
+
+		spans := s.recorder.Ended()
+		Expect(spans).To(HaveLen(1))
+		Expect(spans[0].Name()).To(Equal("MemStore.Close"))
+	})
+
+	It("forwards the health checks to the wrapped store without a span", func() {
+		mem := NewInMemStore(10*time.Millisecond, test.GetLogger())
+		mem.(*InMemStore).StopSessionCleanup()
+		checker := NewTracingMemStore(s.tracer, mem).(HealthChecker)
+		checkCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+		Expect(checker.Ping(checkCtx)).To(Succeed())
+		Expect(checker.CheckCleanup(checkCtx)).To(Equal(ErrCleanupStalled))
+		Expect(s.recorder.Ended()).To(BeEmpty())
+	})
+})
@@ -0,0 +1,15 @@
+#!/usr/bin/env sh
+
+# Install proto3 from source
+#  brew install autoconf automake libtool
+#  git clone https://github.com/google/protobuf
+#  ./autogen.sh ; ./configure ; make ; make install
+#
+# Update protoc Go bindings via
+#  go get -u google.golang.org/protobuf/cmd/protoc-gen-go
+#  go get -u google.golang.org/grpc/cmd/protoc-gen-go-grpc
+#
+# See also
+#  https://grpc.io/docs/languages/go/quickstart/
+
+protoc session_management.proto --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative
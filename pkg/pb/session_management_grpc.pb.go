@@ -0,0 +1,371 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// SessionManagementClient is the client API for SessionManagement service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type SessionManagementClient interface {
+	// Create a session and return a unique session-id
+	Create(ctx context.Context, in *CreateRequest, opts ...grpc.CallOption) (*CreateReply, error)
+	// Destroy remove the session from its cache
+	Destroy(ctx context.Context, in *DestroyRequest, opts ...grpc.CallOption) (*DestroyReply, error)
+	// Extend session id with the provided TTL
+	Extend(ctx context.Context, in *ExtendRequest, opts ...grpc.CallOption) (*ExtendReply, error)
+	// List return a list of all the sessions currently tracked
+	List(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListReply, error)
+	// Get validate the session and return its details
+	Get(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*GetReply, error)
+	// CreateBatch create the sessions of the batch and return the session-id or the error of
+	// each of them
+	CreateBatch(ctx context.Context, in *CreateBatchRequest, opts ...grpc.CallOption) (*BatchReply, error)
+	// ExtendBatch extend the sessions of the batch and return the error of each of them
+	ExtendBatch(ctx context.Context, in *ExtendBatchRequest, opts ...grpc.CallOption) (*BatchReply, error)
+	// DestroyBatch remove the sessions of the batch and return the error of each of them
+	DestroyBatch(ctx context.Context, in *DestroyBatchRequest, opts ...grpc.CallOption) (*BatchReply, error)
+}
+
+type sessionManagementClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSessionManagementClient(cc grpc.ClientConnInterface) SessionManagementClient {
+	return &sessionManagementClient{cc}
+}
+
+func (c *sessionManagementClient) Create(ctx context.Context, in *CreateRequest, opts ...grpc.CallOption) (*CreateReply, error) {
+	out := new(CreateReply)
+	err := c.cc.Invoke(ctx, "/pb.SessionManagement/Create", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sessionManagementClient) Destroy(ctx context.Context, in *DestroyRequest, opts ...grpc.CallOption) (*DestroyReply, error) {
+	out := new(DestroyReply)
+	err := c.cc.Invoke(ctx, "/pb.SessionManagement/Destroy", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sessionManagementClient) Extend(ctx context.Context, in *ExtendRequest, opts ...grpc.CallOption) (*ExtendReply, error) {
+	out := new(ExtendReply)
+	err := c.cc.Invoke(ctx, "/pb.SessionManagement/Extend", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sessionManagementClient) List(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListReply, error) {
+	out := new(ListReply)
+	err := c.cc.Invoke(ctx, "/pb.SessionManagement/List", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sessionManagementClient) Get(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*GetReply, error) {
+	out := new(GetReply)
+	err := c.cc.Invoke(ctx, "/pb.SessionManagement/Get", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sessionManagementClient) CreateBatch(ctx context.Context, in *CreateBatchRequest, opts ...grpc.CallOption) (*BatchReply, error) {
+	out := new(BatchReply)
+	err := c.cc.Invoke(ctx, "/pb.SessionManagement/CreateBatch", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sessionManagementClient) ExtendBatch(ctx context.Context, in *ExtendBatchRequest, opts ...grpc.CallOption) (*BatchReply, error) {
+	out := new(BatchReply)
+	err := c.cc.Invoke(ctx, "/pb.SessionManagement/ExtendBatch", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sessionManagementClient) DestroyBatch(ctx context.Context, in *DestroyBatchRequest, opts ...grpc.CallOption) (*BatchReply, error) {
+	out := new(BatchReply)
+	err := c.cc.Invoke(ctx, "/pb.SessionManagement/DestroyBatch", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SessionManagementServer is the server API for SessionManagement service.
+// All implementations must embed UnimplementedSessionManagementServer
+// for forward compatibility
+type SessionManagementServer interface {
+	// Create a session and return a unique session-id
+	Create(context.Context, *CreateRequest) (*CreateReply, error)
+	// Destroy remove the session from its cache
+	Destroy(context.Context, *DestroyRequest) (*DestroyReply, error)
+	// Extend session id with the provided TTL
+	Extend(context.Context, *ExtendRequest) (*ExtendReply, error)
+	// List return a list of all the sessions currently tracked
+	List(context.Context, *ListRequest) (*ListReply, error)
+	// Get validate the session and return its details
+	Get(context.Context, *GetRequest) (*GetReply, error)
+	// CreateBatch create the sessions of the batch and return the session-id or the error of
+	// each of them
+	CreateBatch(context.Context, *CreateBatchRequest) (*BatchReply, error)
+	// ExtendBatch extend the sessions of the batch and return the error of each of them
+	ExtendBatch(context.Context, *ExtendBatchRequest) (*BatchReply, error)
+	// DestroyBatch remove the sessions of the batch and return the error of each of them
+	DestroyBatch(context.Context, *DestroyBatchRequest) (*BatchReply, error)
+	mustEmbedUnimplementedSessionManagementServer()
+}
+
+// UnimplementedSessionManagementServer must be embedded to have forward compatible implementations.
+type UnimplementedSessionManagementServer struct {
+}
+
+func (UnimplementedSessionManagementServer) Create(context.Context, *CreateRequest) (*CreateReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Create not implemented")
+}
+func (UnimplementedSessionManagementServer) Destroy(context.Context, *DestroyRequest) (*DestroyReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Destroy not implemented")
+}
+func (UnimplementedSessionManagementServer) Extend(context.Context, *ExtendRequest) (*ExtendReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Extend not implemented")
+}
+func (UnimplementedSessionManagementServer) List(context.Context, *ListRequest) (*ListReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method List not implemented")
+}
+func (UnimplementedSessionManagementServer) Get(context.Context, *GetRequest) (*GetReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Get not implemented")
+}
+func (UnimplementedSessionManagementServer) CreateBatch(context.Context, *CreateBatchRequest) (*BatchReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateBatch not implemented")
+}
+func (UnimplementedSessionManagementServer) ExtendBatch(context.Context, *ExtendBatchRequest) (*BatchReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExtendBatch not implemented")
+}
+func (UnimplementedSessionManagementServer) DestroyBatch(context.Context, *DestroyBatchRequest) (*BatchReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DestroyBatch not implemented")
+}
+func (UnimplementedSessionManagementServer) mustEmbedUnimplementedSessionManagementServer() {}
+
+// UnsafeSessionManagementServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to SessionManagementServer will
+// result in compilation errors.
+type UnsafeSessionManagementServer interface {
+	mustEmbedUnimplementedSessionManagementServer()
+}
+
+func RegisterSessionManagementServer(s grpc.ServiceRegistrar, srv SessionManagementServer) {
+	s.RegisterService(&SessionManagement_ServiceDesc, srv)
+}
+
+func _SessionManagement_Create_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SessionManagementServer).Create(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.SessionManagement/Create",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SessionManagementServer).Create(ctx, req.(*CreateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SessionManagement_Destroy_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DestroyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SessionManagementServer).Destroy(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.SessionManagement/Destroy",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SessionManagementServer).Destroy(ctx, req.(*DestroyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SessionManagement_Extend_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExtendRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SessionManagementServer).Extend(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.SessionManagement/Extend",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SessionManagementServer).Extend(ctx, req.(*ExtendRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SessionManagement_List_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SessionManagementServer).List(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.SessionManagement/List",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SessionManagementServer).List(ctx, req.(*ListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SessionManagement_Get_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SessionManagementServer).Get(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.SessionManagement/Get",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SessionManagementServer).Get(ctx, req.(*GetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SessionManagement_CreateBatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateBatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SessionManagementServer).CreateBatch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.SessionManagement/CreateBatch",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SessionManagementServer).CreateBatch(ctx, req.(*CreateBatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SessionManagement_ExtendBatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExtendBatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SessionManagementServer).ExtendBatch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.SessionManagement/ExtendBatch",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SessionManagementServer).ExtendBatch(ctx, req.(*ExtendBatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SessionManagement_DestroyBatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DestroyBatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SessionManagementServer).DestroyBatch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.SessionManagement/DestroyBatch",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SessionManagementServer).DestroyBatch(ctx, req.(*DestroyBatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// SessionManagement_ServiceDesc is the grpc.ServiceDesc for SessionManagement service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var SessionManagement_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "pb.SessionManagement",
+	HandlerType: (*SessionManagementServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Create",
+			Handler:    _SessionManagement_Create_Handler,
+		},
+		{
+			MethodName: "Destroy",
+			Handler:    _SessionManagement_Destroy_Handler,
+		},
+		{
+			MethodName: "Extend",
+			Handler:    _SessionManagement_Extend_Handler,
+		},
+		{
+			MethodName: "List",
+			Handler:    _SessionManagement_List_Handler,
+		},
+		{
+			MethodName: "Get",
+			Handler:    _SessionManagement_Get_Handler,
+		},
+		{
+			MethodName: "CreateBatch",
+			Handler:    _SessionManagement_CreateBatch_Handler,
+		},
+		{
+			MethodName: "ExtendBatch",
+			Handler:    _SessionManagement_ExtendBatch_Handler,
+		},
+		{
+			MethodName: "DestroyBatch",
+			Handler:    _SessionManagement_DestroyBatch_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "session_management.proto",
+}
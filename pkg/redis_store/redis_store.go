@@ -0,0 +1,634 @@
+package redis_store
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-redis/redis/v8"
+
+	. "github.com/hecomp/session-management/internal/models"
+	"github.com/hecomp/session-management/pkg/in_memory"
+)
+
+// DefaultPrefix is prepended to every key written by the RedisStore
+const DefaultPrefix = "session-management:"
+
+// maxRetries bounds how many times an optimistic transaction is retried when a watched
+// key was changed by another replica
+const maxRetries = 10
+
+// scanCount is the number of keys asked to Redis by every SCAN and fetched by every
+// pipeline of List
+const scanCount = 1000
+
+const (
+	fieldObject        = "object"
+	fieldExpiration    = "expiration"
+	fieldSubject       = "subject"
+	fieldLastAccess    = "last_access"
+	fieldIdleTimeout   = "idle_timeout"
+	fieldMaxExpiration = "max_expiration"
+	fieldCreatedAt     = "created_at"
+)
+
+// ErrConflict is returned when a transaction kept conflicting with other writers of the session.
+var ErrConflict = errors.New("session changed concurrently")
+
+// RedisStore represents a session store shared by every replica of the service. Each session
+// is a Redis hash expired natively by Redis with PEXPIREAT at its deadline, and the sessions of a subject are
+// indexed in a Redis set. Set members of sessions that expired are removed lazily when the
+// subject is listed or deleted.
+type RedisStore struct {
+	logger log.Logger
+	client *redis.Client
+	prefix string
+}
+
+// NewRedisStore returns a new RedisStore instance storing its keys under the given prefix.
+func NewRedisStore(client *redis.Client, prefix string, logger log.Logger) in_memory.MemStore {
+	return &RedisStore{logger: logger, client: client, prefix: prefix}
+}
+
+// Find returns the data for a given session from Redis. If the session id is not found
+// or is expired, the returned exists flag will be set to false.
+func (r *RedisStore) Find(ctx context.Context, sessionId string) ([]byte, bool, error) {
+	item, found, err := r.Lookup(ctx, sessionId)
+	if err != nil || !found {
+		return nil, false, err
+	}
+	return item.Oject, true, nil
+}
+
+// Lookup returns the item for a given session from Redis. Only the lookups of a session
+// with an idle timeout write to Redis, refreshing its last access time and moving its
+// deadline in a transaction; the other sessions keep the last access time of their last
+// write, so reads are never turned into writes.
+func (r *RedisStore) Lookup(ctx context.Context, sessionId string) (Item, bool, error) {
+	r.logger.Log("method", "lookup", "sessionId", sessionId)
+	key := r.sessionKey(sessionId)
+
+	item, found, err := r.get(ctx, r.client, key)
+	if err != nil || !found || item.IdleTimeout == 0 {
+		return item, found, err
+	}
+
+	err = r.watch(ctx, func(tx *redis.Tx) error {
+		var err error
+		item, found, err = r.get(ctx, tx, key)
+		if err != nil || !found {
+			return err
+		}
+
+		item.LastAccess = time.Now().UnixNano()
+		if item.LastAccess+item.IdleTimeout > item.Expiration {
+			item.Expiration = capExpiration(item, item.LastAccess+item.IdleTimeout)
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.HSet(ctx, key, fieldLastAccess, item.LastAccess, fieldExpiration, item.Expiration)
+			pipe.PExpireAt(ctx, key, time.Unix(0, item.Deadline()))
+			return nil
+		})
+		return err
+	}, key)
+	if err != nil || !found {
+		return Item{}, false, err
+	}
+	return item, true, nil
+}
+
+// Commit adds a session sessionId and data to Redis with the given expiration time. If the
+// session sessionId already exists, then the data and expiration time are updated.
+func (r *RedisStore) Commit(ctx context.Context, sessionId string, b []byte, expiration time.Time) error {
+	return r.CommitWithSubject(ctx, sessionId, "", b, expiration)
+}
+
+// CommitWithSubject adds a session like Commit and indexes it under the given subject.
+func (r *RedisStore) CommitWithSubject(ctx context.Context, sessionId string, subject string, b []byte, expiration time.Time) error {
+	return r.CommitItem(ctx, sessionId, Item{Oject: b, Expiration: expiration.UnixNano(), Subject: subject})
+}
+
+// CommitItem adds a session like CommitWithSubject, keeping the idle timeout, maximum
+// expiration and creation time of the item. The expiration is capped at the maximum
+// expiration.
+func (r *RedisStore) CommitItem(ctx context.Context, sessionId string, item Item) error {
+	r.logger.Log("method", "commit", "sessionId", sessionId)
+	key := r.sessionKey(sessionId)
+	item.Expiration = capExpiration(item, item.Expiration)
+	item.LastAccess = time.Now().UnixNano()
+	if item.CreatedAt == 0 {
+		item.CreatedAt = item.LastAccess
+	}
+
+	return r.watch(ctx, func(tx *redis.Tx) error {
+		previous, err := tx.HGet(ctx, key, fieldSubject).Result()
+		if err != nil && err != redis.Nil {
+			return err
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Del(ctx, key)
+			pipe.HSet(ctx, key,
+				fieldObject, item.Oject,
+				fieldExpiration, item.Expiration,
+				fieldSubject, item.Subject,
+				fieldLastAccess, item.LastAccess,
+				fieldIdleTimeout, item.IdleTimeout,
+				fieldMaxExpiration, item.MaxExpiration,
+				fieldCreatedAt, item.CreatedAt)
+			pipe.PExpireAt(ctx, key, time.Unix(0, item.Deadline()))
+			if previous != "" && previous != item.Subject {
+				pipe.SRem(ctx, r.subjectKey(previous), sessionId)
+			}
+			if item.Subject != "" {
+				pipe.SAdd(ctx, r.subjectKey(item.Subject), sessionId)
+			}
+			return nil
+		})
+		return err
+	}, key)
+}
+
+// CommitBatch adds every session like CommitItem in a single transaction
+func (r *RedisStore) CommitBatch(ctx context.Context, items map[string]Item) error {
+	r.logger.Log("method", "commitBatch", "sessions", len(items))
+	now := time.Now().UnixNano()
+	sessionIds := make([]string, 0, len(items))
+	keys := make([]string, 0, len(items))
+	for sessionId := range items {
+		sessionIds = append(sessionIds, sessionId)
+		keys = append(keys, r.sessionKey(sessionId))
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+
+	return r.watch(ctx, func(tx *redis.Tx) error {
+		previous := make([]*redis.StringCmd, len(keys))
+		tx.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			for i, key := range keys {
+				previous[i] = pipe.HGet(ctx, key, fieldSubject)
+			}
+			return nil
+		})
+		for _, cmd := range previous {
+			if err := cmd.Err(); err != nil && err != redis.Nil {
+				return err
+			}
+		}
+
+		_, err := tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			for i, sessionId := range sessionIds {
+				item := items[sessionId]
+				item.Expiration = capExpiration(item, item.Expiration)
+				item.LastAccess = now
+				if item.CreatedAt == 0 {
+					item.CreatedAt = now
+				}
+				pipe.Del(ctx, keys[i])
+				pipe.HSet(ctx, keys[i],
+					fieldObject, item.Oject,
+					fieldExpiration, item.Expiration,
+					fieldSubject, item.Subject,
+					fieldLastAccess, item.LastAccess,
+					fieldIdleTimeout, item.IdleTimeout,
+					fieldMaxExpiration, item.MaxExpiration,
+					fieldCreatedAt, item.CreatedAt)
+				pipe.PExpireAt(ctx, keys[i], time.Unix(0, item.Deadline()))
+				if subject := previous[i].Val(); subject != "" && subject != item.Subject {
+					pipe.SRem(ctx, r.subjectKey(subject), sessionId)
+				}
+				if item.Subject != "" {
+					pipe.SAdd(ctx, r.subjectKey(item.Subject), sessionId)
+				}
+			}
+			return nil
+		})
+		return err
+	}, keys...)
+}
+
+// Delete removes a session sessionId and corresponding data from Redis.
+func (r *RedisStore) Delete(ctx context.Context, sessionId string) error {
+	r.logger.Log("method", "delete", "sessionId", sessionId)
+	key := r.sessionKey(sessionId)
+
+	return r.watch(ctx, func(tx *redis.Tx) error {
+		subject, err := tx.HGet(ctx, key, fieldSubject).Result()
+		if err != nil && err != redis.Nil {
+			return err
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Del(ctx, key)
+			if subject != "" {
+				pipe.SRem(ctx, r.subjectKey(subject), sessionId)
+			}
+			return nil
+		})
+		return err
+	}, key)
+}
+
+// DeleteBatch removes the sessions from Redis in a single transaction and returns the live
+// sessions that were removed
+func (r *RedisStore) DeleteBatch(ctx context.Context, sessionIds []string) (map[string]Item, error) {
+	r.logger.Log("method", "deleteBatch", "sessions", len(sessionIds))
+	keys := make([]string, len(sessionIds))
+	for i, sessionId := range sessionIds {
+		keys[i] = r.sessionKey(sessionId)
+	}
+	if len(keys) == 0 {
+		return map[string]Item{}, nil
+	}
+
+	var deleted map[string]Item
+	err := r.watch(ctx, func(tx *redis.Tx) error {
+		deleted = make(map[string]Item)
+		if err := r.getAll(ctx, tx, keys, deleted); err != nil {
+			return err
+		}
+
+		_, err := tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Del(ctx, keys...)
+			for sessionId, item := range deleted {
+				if item.Subject != "" {
+					pipe.SRem(ctx, r.subjectKey(item.Subject), sessionId)
+				}
+			}
+			return nil
+		})
+		return err
+	}, keys...)
+	if err != nil {
+		return nil, err
+	}
+	return deleted, nil
+}
+
+// DeleteBySubject removes every session of the subject from Redis and returns the ids of
+// the live sessions that were removed.
+func (r *RedisStore) DeleteBySubject(ctx context.Context, subject string) ([]string, error) {
+	r.logger.Log("method", "deleteBySubject", "subject", subject)
+	subjectKey := r.subjectKey(subject)
+
+	var sessionIds []string
+	err := r.watch(ctx, func(tx *redis.Tx) error {
+		items, _, err := r.listBySubject(ctx, tx, subject)
+		if err != nil {
+			return err
+		}
+
+		sessionIds = sessionIds[:0]
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			for sessionId := range items {
+				sessionIds = append(sessionIds, sessionId)
+				pipe.Del(ctx, r.sessionKey(sessionId))
+			}
+			pipe.Del(ctx, subjectKey)
+			return nil
+		})
+		return err
+	}, subjectKey)
+	if err != nil {
+		return nil, err
+	}
+	return sessionIds, nil
+}
+
+// ListBySubject returns the live sessions of the subject from Redis, without refreshing
+// their last access time. Index entries of expired sessions are removed.
+func (r *RedisStore) ListBySubject(ctx context.Context, subject string) (map[string]Item, error) {
+	r.logger.Log("method", "listBySubject", "subject", subject)
+
+	items, stale, err := r.listBySubject(ctx, r.client, subject)
+	if err != nil {
+		return nil, err
+	}
+	if len(stale) > 0 {
+		if err := r.client.SRem(ctx, r.subjectKey(subject), stale...).Err(); err != nil {
+			return nil, err
+		}
+	}
+	return items, nil
+}
+
+// Reset extend a session ttl in Redis. The new expiration is capped at the maximum
+// expiration of the session.
+func (r *RedisStore) Reset(ctx context.Context, sessionId string, expiration time.Time) ([]byte, bool, error) {
+	r.logger.Log("method", "reset")
+	key := r.sessionKey(sessionId)
+
+	var item Item
+	var found bool
+	err := r.watch(ctx, func(tx *redis.Tx) error {
+		var err error
+		item, found, err = r.get(ctx, tx, key)
+		if err != nil || !found {
+			return err
+		}
+
+		item.Expiration = capExpiration(item, expiration.UnixNano())
+		item.LastAccess = time.Now().UnixNano()
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.HSet(ctx, key, fieldExpiration, item.Expiration, fieldLastAccess, item.LastAccess)
+			pipe.PExpireAt(ctx, key, time.Unix(0, item.Deadline()))
+			return nil
+		})
+		return err
+	}, key)
+	if err != nil || !found {
+		return nil, false, err
+	}
+	return item.Oject, true, nil
+}
+
+// ResetBatch extends the live sessions to their expiration like Reset in a single
+// transaction and returns the sessions that were extended
+func (r *RedisStore) ResetBatch(ctx context.Context, expirations map[string]time.Time) (map[string]Item, error) {
+	r.logger.Log("method", "resetBatch", "sessions", len(expirations))
+	keys := make([]string, 0, len(expirations))
+	for sessionId := range expirations {
+		keys = append(keys, r.sessionKey(sessionId))
+	}
+	if len(keys) == 0 {
+		return map[string]Item{}, nil
+	}
+
+	var extended map[string]Item
+	err := r.watch(ctx, func(tx *redis.Tx) error {
+		extended = make(map[string]Item)
+		if err := r.getAll(ctx, tx, keys, extended); err != nil {
+			return err
+		}
+
+		now := time.Now().UnixNano()
+		_, err := tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			for sessionId, item := range extended {
+				key := r.sessionKey(sessionId)
+				item.Expiration = capExpiration(item, expirations[sessionId].UnixNano())
+				item.LastAccess = now
+				extended[sessionId] = item
+				pipe.HSet(ctx, key, fieldExpiration, item.Expiration, fieldLastAccess, item.LastAccess)
+				pipe.PExpireAt(ctx, key, time.Unix(0, item.Deadline()))
+			}
+			return nil
+		})
+		return err
+	}, keys...)
+	if err != nil {
+		return nil, err
+	}
+	return extended, nil
+}
+
+// Update replaces the data of a live session in Redis, keeping its expiration time.
+func (r *RedisStore) Update(ctx context.Context, sessionId string, b []byte) (bool, error) {
+	r.logger.Log("method", "update", "sessionId", sessionId)
+	key := r.sessionKey(sessionId)
+
+	var found bool
+	err := r.watch(ctx, func(tx *redis.Tx) error {
+		item, live, err := r.get(ctx, tx, key)
+		found = live
+		if err != nil || !found {
+			return err
+		}
+
+		item.LastAccess = time.Now().UnixNano()
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.HSet(ctx, key, fieldObject, b, fieldLastAccess, item.LastAccess)
+			if item.IdleTimeout > 0 {
+				pipe.PExpireAt(ctx, key, time.Unix(0, item.Deadline()))
+			}
+			return nil
+		})
+		return err
+	}, key)
+	if err != nil {
+		return false, err
+	}
+	return found, nil
+}
+
+// List return a list of all the sessions from Redis, walking the keys with SCAN so that
+// Redis is never blocked by a single large command.
+func (r *RedisStore) List(ctx context.Context) (map[string]Item, error) {
+	r.logger.Log("method", "list")
+	pattern := r.sessionKey("*")
+
+	items := make(map[string]Item)
+	var cursor uint64
+	for {
+		keys, next, err := r.client.Scan(ctx, cursor, pattern, scanCount).Result()
+		if err != nil {
+			return nil, err
+		}
+		if err := r.getAll(ctx, r.client, keys, items); err != nil {
+			return nil, err
+		}
+		if next == 0 {
+			return items, nil
+		}
+		cursor = next
+	}
+}
+
+// Query returns the page of the live sessions selected by the query from Redis. The
+// sessions of a subject are read from its index, the other queries walk the keys with
+// SCAN and only keep the sessions of the page between two batches, as Redis can neither
+// filter nor sort the session hashes.
+func (r *RedisStore) Query(ctx context.Context, query in_memory.Query) (in_memory.Page, error) {
+	r.logger.Log("method", "query", "subject", query.Subject, "limit", query.Limit)
+	if query.Subject != "" {
+		items, err := r.ListBySubject(ctx, query.Subject)
+		if err != nil {
+			return in_memory.Page{}, err
+		}
+		return query.Filter(items, time.Now().UnixNano())
+	}
+
+	pattern := r.sessionKey("*")
+	var selected []in_memory.QueryItem
+	more := false
+	var cursor uint64
+	for {
+		keys, next, err := r.client.Scan(ctx, cursor, pattern, scanCount).Result()
+		if err != nil {
+			return in_memory.Page{}, err
+		}
+		items := make(map[string]Item, len(keys))
+		if err := r.getAll(ctx, r.client, keys, items); err != nil {
+			return in_memory.Page{}, err
+		}
+		batch, err := query.Filter(items, time.Now().UnixNano())
+		if err != nil {
+			return in_memory.Page{}, err
+		}
+
+		page := query.Paginate(append(selected, batch.Items...), more || batch.NextCursor != "")
+		if next == 0 {
+			return page, nil
+		}
+		selected, more = page.Items, page.NextCursor != ""
+		cursor = next
+	}
+}
+
+// Get returns every session from Redis
+func (r *RedisStore) Get(ctx context.Context) map[string]Item {
+	items, err := r.List(ctx)
+	if err != nil {
+		r.logger.Log("method", "get", "err", err)
+	}
+	return items
+}
+
+// Ping reports whether the Redis server is reachable
+func (r *RedisStore) Ping(ctx context.Context) error {
+	return r.client.Ping(ctx).Err()
+}
+
+// Close does nothing, every write is already sent to Redis and the client is left open as
+// it may be shared with other stores.
+func (r *RedisStore) Close(ctx context.Context) error {
+	return nil
+}
+
+// CheckCleanup always succeeds, the expired sessions are removed by Redis itself
+func (r *RedisStore) CheckCleanup(ctx context.Context) error {
+	return nil
+}
+
+// watch runs fn in an optimistic transaction watching the keys, retrying it when a key
+// was changed before the transaction was executed
+func (r *RedisStore) watch(ctx context.Context, fn func(tx *redis.Tx) error, keys ...string) error {
+	for i := 0; i < maxRetries; i++ {
+		err := r.client.Watch(ctx, fn, keys...)
+		if err != redis.TxFailedErr {
+			return err
+		}
+	}
+	return ErrConflict
+}
+
+// get returns the live item stored in the hash key
+func (r *RedisStore) get(ctx context.Context, c redis.Cmdable, key string) (Item, bool, error) {
+	fields, err := c.HGetAll(ctx, key).Result()
+	if err != nil {
+		return Item{}, false, err
+	}
+	return decodeItem(fields)
+}
+
+// getAll adds the live items of the session keys to items, fetching them in a single pipeline
+func (r *RedisStore) getAll(ctx context.Context, c redis.Cmdable, keys []string, items map[string]Item) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	cmds := make([]*redis.StringStringMapCmd, len(keys))
+	_, err := c.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, key := range keys {
+			cmds[i] = pipe.HGetAll(ctx, key)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for i, cmd := range cmds {
+		item, found, err := decodeItem(cmd.Val())
+		if err != nil {
+			return err
+		}
+		if found {
+			items[keys[i][len(r.sessionKey("")):]] = item
+		}
+	}
+	return nil
+}
+
+// listBySubject returns the live sessions of the subject and the ids of the index entries
+// of the other ones. Inside a transaction the session keys are watched before being read.
+func (r *RedisStore) listBySubject(ctx context.Context, c redis.Cmdable, subject string) (map[string]Item, []interface{}, error) {
+	sessionIds, err := c.SMembers(ctx, r.subjectKey(subject)).Result()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	keys := make([]string, len(sessionIds))
+	for i, sessionId := range sessionIds {
+		keys[i] = r.sessionKey(sessionId)
+	}
+	if tx, ok := c.(*redis.Tx); ok && len(keys) > 0 {
+		if err := tx.Watch(ctx, keys...).Err(); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	items := make(map[string]Item, len(sessionIds))
+	if err := r.getAll(ctx, c, keys, items); err != nil {
+		return nil, nil, err
+	}
+
+	var stale []interface{}
+	for _, sessionId := range sessionIds {
+		if item, found := items[sessionId]; !found || item.Subject != subject {
+			delete(items, sessionId)
+			stale = append(stale, sessionId)
+		}
+	}
+	return items, stale, nil
+}
+
+func (r *RedisStore) sessionKey(sessionId string) string {
+	return r.prefix + "session:" + sessionId
+}
+
+func (r *RedisStore) subjectKey(subject string) string {
+	return r.prefix + "subject:" + subject
+}
+
+// decodeItem converts the fields of a session hash to an item, an empty or expired hash
+// is reported as not found
+func decodeItem(fields map[string]string) (Item, bool, error) {
+	if len(fields) == 0 {
+		return Item{}, false, nil
+	}
+
+	item := Item{Oject: []byte(fields[fieldObject]), Subject: fields[fieldSubject]}
+	for name, value := range map[string]*int64{
+		fieldExpiration:    &item.Expiration,
+		fieldLastAccess:    &item.LastAccess,
+		fieldIdleTimeout:   &item.IdleTimeout,
+		fieldMaxExpiration: &item.MaxExpiration,
+		fieldCreatedAt:     &item.CreatedAt,
+	} {
+		if fields[name] == "" {
+			continue
+		}
+		n, err := strconv.ParseInt(fields[name], 10, 64)
+		if err != nil {
+			return Item{}, false, err
+		}
+		*value = n
+	}
+
+	if time.Now().UnixNano() > item.Deadline() {
+		return Item{}, false, nil
+	}
+	return item, true, nil
+}
+
+// capExpiration returns the expiration limited to the maximum expiration of the item
+func capExpiration(item Item, expiration int64) int64 {
+	if item.MaxExpiration > 0 && expiration > item.MaxExpiration {
+		return item.MaxExpiration
+	}
+	return expiration
+}
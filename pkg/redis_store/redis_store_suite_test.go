@@ -0,0 +1,17 @@
+package redis_store_test
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// ctx is the context of the calls made by the specs
+var ctx = context.Background()
+
+func TestRedisStore(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "RedisStore Suite")
+}
@@ -0,0 +1,434 @@
+package redis_store_test
+
+import (
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-kit/kit/log"
+	"github.com/go-redis/redis/v8"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/hecomp/session-management/internal/models"
+	. "github.com/hecomp/session-management/pkg/in_memory"
+	. "github.com/hecomp/session-management/pkg/redis_store"
+	"github.com/hecomp/session-management/pkg/test"
+)
+
+type RedisStoreSuite struct {
+	mem    MemStore
+	server *miniredis.Miniredis
+	client *redis.Client
+	logger log.Logger
+}
+
+var _ = Describe("RedisStore", func() {
+
+	s := &RedisStoreSuite{}
+
+	BeforeEach(func() {
+		var err error
+		s.logger = test.GetLogger()
+		s.server, err = miniredis.Run()
+		Expect(err).To(BeNil())
+		s.client = redis.NewClient(&redis.Options{Addr: s.server.Addr()})
+		s.mem = NewRedisStore(s.client, DefaultPrefix, s.logger)
+	})
+
+	AfterEach(func() {
+		s.client.Close()
+		s.server.Close()
+	})
+
+	Describe("Create session", func() {
+		inMemResponse := "90660b89-100e-4f8f-9801-2524df6fbe34"
+
+		Context("Commit()", func() {
+			When("the API os called with TTL as param", func() {
+				It("stores an unique sessionId in redis with a native TTL", func() {
+					uniqueUUID := "90660b89-100e-4f8f-9801-2524df6fbe34"
+					err := s.mem.Commit(ctx, uniqueUUID, []byte(uniqueUUID), time.Now().Add(time.Minute))
+					Expect(err).To(BeNil())
+
+					sessionMap, err := s.mem.List(ctx)
+					Expect(err).To(BeNil())
+					Expect(string(sessionMap[uniqueUUID].Oject)).To(Equal(inMemResponse))
+					Expect(s.server.TTL(DefaultPrefix + "session:" + uniqueUUID)).To(BeNumerically("~", time.Minute, time.Second))
+				})
+			})
+		})
+	})
+
+	Describe("Find session", func() {
+		uniqueUUID := "90660b89-100e-4f8f-9801-2524df6fbe34"
+		BeforeEach(func() {
+			err := s.mem.Commit(ctx, uniqueUUID, []byte(uniqueUUID), time.Now().Add(time.Minute))
+			Expect(err).To(BeNil())
+		})
+
+		Context("Find()", func() {
+			When("the API os called", func() {
+				It("finds sessionId in redis", func() {
+					obj, found, err := s.mem.Find(ctx, uniqueUUID)
+					Expect(err).To(BeNil())
+					Expect(found).To(BeTrue())
+					Expect(string(obj)).To(Equal(uniqueUUID))
+				})
+				It("does not matches sessionId in redis", func() {
+					_, found, err := s.mem.Find(ctx, "90660b89-100e-4f8f-9801-2524df6fbe99")
+					Expect(err).To(BeNil())
+					Expect(found).ToNot(BeTrue())
+				})
+				It("expired sessionId in redis", func() {
+					s.server.FastForward(time.Minute)
+					_, found, err := s.mem.Find(ctx, uniqueUUID)
+					Expect(err).To(BeNil())
+					Expect(found).ToNot(BeTrue())
+				})
+				It("finds the sessionId from another replica", func() {
+					replica := NewRedisStore(redis.NewClient(&redis.Options{Addr: s.server.Addr()}), DefaultPrefix, s.logger)
+					_, found, err := replica.Find(ctx, uniqueUUID)
+					Expect(err).To(BeNil())
+					Expect(found).To(BeTrue())
+				})
+			})
+		})
+	})
+
+	Describe("Destroy session", func() {
+		uniqueUUID := "90660b89-100e-4f8f-9801-2524df6fbe34"
+		BeforeEach(func() {
+			err := s.mem.CommitWithSubject(ctx, uniqueUUID, "user-42", []byte(uniqueUUID), time.Now().Add(time.Minute))
+			Expect(err).To(BeNil())
+		})
+
+		Context("Delete()", func() {
+			It("deletes sessionId and its subject index entry in redis", func() {
+				Expect(s.mem.Delete(ctx, uniqueUUID)).To(Succeed())
+				_, found, err := s.mem.Find(ctx, uniqueUUID)
+				Expect(err).To(BeNil())
+				Expect(found).ToNot(BeTrue())
+				Expect(s.server.Exists(DefaultPrefix + "subject:user-42")).ToNot(BeTrue())
+			})
+		})
+	})
+
+	Describe("Extend session", func() {
+		uniqueUUID := "90660b89-100e-4f8f-9801-2524df6fbe34"
+		BeforeEach(func() {
+			err := s.mem.Commit(ctx, uniqueUUID, []byte(uniqueUUID), time.Now().Add(time.Minute))
+			Expect(err).To(BeNil())
+		})
+
+		Context("Reset()", func() {
+			It("extends sessionId and its TTL in redis", func() {
+				obj, found, err := s.mem.Reset(ctx, uniqueUUID, time.Now().Add(5*time.Minute))
+				Expect(err).To(BeNil())
+				Expect(found).To(BeTrue())
+				Expect(string(obj)).To(Equal(uniqueUUID))
+				Expect(s.server.TTL(DefaultPrefix + "session:" + uniqueUUID)).To(BeNumerically("~", 5*time.Minute, time.Second))
+			})
+			It("caps the expiration at the maximum expiration", func() {
+				maxExpiration := time.Now().Add(2 * time.Minute)
+				err := s.mem.CommitItem(ctx, uniqueUUID, models.Item{
+					Oject:         []byte(uniqueUUID),
+					Expiration:    time.Now().Add(time.Minute).UnixNano(),
+					MaxExpiration: maxExpiration.UnixNano(),
+				})
+				Expect(err).To(BeNil())
+				_, found, err := s.mem.Reset(ctx, uniqueUUID, time.Now().Add(time.Hour))
+				Expect(err).To(BeNil())
+				Expect(found).To(BeTrue())
+
+				item, _, err := s.mem.Lookup(ctx, uniqueUUID)
+				Expect(err).To(BeNil())
+				Expect(item.Expiration).To(Equal(maxExpiration.UnixNano()))
+			})
+			It("extend sessionId in redis not found", func() {
+				_, found, err := s.mem.Reset(ctx, "90660b89-100e-4f8f-9801-2524df6fbe99", time.Now().Add(time.Minute))
+				Expect(err).To(BeNil())
+				Expect(found).ToNot(BeTrue())
+			})
+		})
+
+		Context("Lookup()", func() {
+			It("slides the expiration of a session with an idle timeout", func() {
+				err := s.mem.CommitItem(ctx, uniqueUUID, models.Item{
+					Oject:       []byte(uniqueUUID),
+					Expiration:  time.Now().Add(time.Second).UnixNano(),
+					IdleTimeout: int64(time.Minute),
+				})
+				Expect(err).To(BeNil())
+				item, found, err := s.mem.Lookup(ctx, uniqueUUID)
+				Expect(err).To(BeNil())
+				Expect(found).To(BeTrue())
+				Expect(item.Expiration).To(BeNumerically("~", time.Now().Add(time.Minute).UnixNano(), int64(time.Second)))
+				Expect(s.server.TTL(DefaultPrefix + "session:" + uniqueUUID)).To(BeNumerically("~", time.Minute, time.Second))
+			})
+			It("reads a session without an idle timeout with a single command", func() {
+				err := s.mem.Commit(ctx, uniqueUUID, []byte(uniqueUUID), time.Now().Add(time.Minute))
+				Expect(err).To(BeNil())
+				commands := s.server.CommandCount()
+				item, found, err := s.mem.Lookup(ctx, uniqueUUID)
+				Expect(err).To(BeNil())
+				Expect(found).To(BeTrue())
+				Expect(string(item.Oject)).To(Equal(uniqueUUID))
+				Expect(s.server.CommandCount() - commands).To(Equal(1))
+			})
+			It("expires a session idle for its idle timeout before its expiration", func() {
+				err := s.mem.CommitItem(ctx, uniqueUUID, models.Item{
+					Oject:       []byte(uniqueUUID),
+					Expiration:  time.Now().Add(time.Hour).UnixNano(),
+					IdleTimeout: int64(100 * time.Millisecond),
+				})
+				Expect(err).To(BeNil())
+				Expect(s.server.TTL(DefaultPrefix + "session:" + uniqueUUID)).To(BeNumerically("<=", 100*time.Millisecond))
+
+				time.Sleep(150 * time.Millisecond)
+				_, found, err := s.mem.Lookup(ctx, uniqueUUID)
+				Expect(err).To(BeNil())
+				Expect(found).ToNot(BeTrue())
+			})
+		})
+	})
+
+	Describe("Update session", func() {
+		uniqueUUID := "90660b89-100e-4f8f-9801-2524df6fbe34"
+		BeforeEach(func() {
+			err := s.mem.Commit(ctx, uniqueUUID, []byte(uniqueUUID), time.Now().Add(time.Minute))
+			Expect(err).To(BeNil())
+		})
+
+		Context("Update()", func() {
+			It("replaces the data and keeps the TTL", func() {
+				found, err := s.mem.Update(ctx, uniqueUUID, []byte("data"))
+				Expect(err).To(BeNil())
+				Expect(found).To(BeTrue())
+				obj, _, err := s.mem.Find(ctx, uniqueUUID)
+				Expect(err).To(BeNil())
+				Expect(string(obj)).To(Equal("data"))
+				Expect(s.server.TTL(DefaultPrefix + "session:" + uniqueUUID)).To(BeNumerically("~", time.Minute, time.Second))
+			})
+		})
+	})
+
+	Describe("List session", func() {
+		It("scans every sessionId in redis", func() {
+			for _, uniqueUUID := range []string{"90660b89-100e-4f8f-9801-2524df6fbe34", "90660b89-100e-4f8f-9801-2524df6fbe99"} {
+				err := s.mem.Commit(ctx, uniqueUUID, []byte(uniqueUUID), time.Now().Add(time.Minute))
+				Expect(err).To(BeNil())
+			}
+			sessionMap, err := s.mem.List(ctx)
+			Expect(err).To(BeNil())
+			Expect(sessionMap).To(HaveLen(2))
+			Expect(s.mem.Get(ctx)).To(Equal(sessionMap))
+		})
+	})
+
+	Describe("Subject sessions", func() {
+		uniqueUUID1 := "90660b89-100e-4f8f-9801-2524df6fbe34"
+		uniqueUUID2 := "90660b89-100e-4f8f-9801-2524df6fbe99"
+		uniqueUUID3 := "90660b89-100e-4f8f-9801-2524df6fbe88"
+		BeforeEach(func() {
+			err := s.mem.CommitWithSubject(ctx, uniqueUUID1, "user-42", []byte(uniqueUUID1), time.Now().Add(time.Minute))
+			Expect(err).To(BeNil())
+			err = s.mem.CommitWithSubject(ctx, uniqueUUID2, "user-42", []byte(uniqueUUID2), time.Now().Add(time.Hour))
+			Expect(err).To(BeNil())
+			err = s.mem.CommitWithSubject(ctx, uniqueUUID3, "user-7", []byte(uniqueUUID3), time.Now().Add(time.Hour))
+			Expect(err).To(BeNil())
+		})
+
+		Context("ListBySubject()", func() {
+			It("lists the sessions of the subject", func() {
+				sessionMap, err := s.mem.ListBySubject(ctx, "user-42")
+				Expect(err).To(BeNil())
+				Expect(sessionMap).To(HaveLen(2))
+				Expect(sessionMap).To(HaveKey(uniqueUUID1))
+				Expect(sessionMap).To(HaveKey(uniqueUUID2))
+			})
+			It("removes expired sessions from the index", func() {
+				s.server.FastForward(2 * time.Minute)
+				sessionMap, err := s.mem.ListBySubject(ctx, "user-42")
+				Expect(err).To(BeNil())
+				Expect(sessionMap).To(HaveLen(1))
+				members, err := s.server.Members(DefaultPrefix + "subject:user-42")
+				Expect(err).To(BeNil())
+				Expect(members).To(ConsistOf(uniqueUUID2))
+			})
+			It("moves a re-committed session to its new subject", func() {
+				err := s.mem.CommitWithSubject(ctx, uniqueUUID1, "user-7", []byte(uniqueUUID1), time.Now().Add(time.Minute))
+				Expect(err).To(BeNil())
+				sessionMap, err := s.mem.ListBySubject(ctx, "user-42")
+				Expect(err).To(BeNil())
+				Expect(sessionMap).To(HaveLen(1))
+				Expect(sessionMap).To(HaveKey(uniqueUUID2))
+			})
+		})
+
+		Context("DeleteBySubject()", func() {
+			It("deletes every session of the subject", func() {
+				sessionIds, err := s.mem.DeleteBySubject(ctx, "user-42")
+				Expect(err).To(BeNil())
+				Expect(sessionIds).To(ConsistOf(uniqueUUID1, uniqueUUID2))
+
+				sessionMap, err := s.mem.List(ctx)
+				Expect(err).To(BeNil())
+				Expect(sessionMap).To(HaveLen(1))
+				Expect(sessionMap).To(HaveKey(uniqueUUID3))
+			})
+		})
+	})
+
+	Describe("Batch sessions", func() {
+		uniqueUUID1 := "90660b89-100e-4f8f-9801-2524df6fbe34"
+		uniqueUUID2 := "90660b89-100e-4f8f-9801-2524df6fbe99"
+		uniqueUUID3 := "90660b89-100e-4f8f-9801-2524df6fbe88"
+		BeforeEach(func() {
+			err := s.mem.CommitWithSubject(ctx, uniqueUUID1, "user-7", []byte(uniqueUUID1), time.Now().Add(time.Minute))
+			Expect(err).To(BeNil())
+			err = s.mem.CommitBatch(ctx, map[string]models.Item{
+				uniqueUUID1: {Oject: []byte(uniqueUUID1), Subject: "user-42", Expiration: time.Now().Add(time.Minute).UnixNano()},
+				uniqueUUID2: {Oject: []byte(uniqueUUID2), Subject: "user-42", Expiration: time.Now().Add(time.Minute).UnixNano()},
+			})
+			Expect(err).To(BeNil())
+		})
+
+		Context("CommitBatch()", func() {
+			It("stores the sessions and moves them to their new subject", func() {
+				sessionMap, err := s.mem.ListBySubject(ctx, "user-42")
+				Expect(err).To(BeNil())
+				Expect(sessionMap).To(HaveLen(2))
+				members, err := s.server.Members(DefaultPrefix + "subject:user-7")
+				Expect(err).ToNot(BeNil())
+				Expect(members).To(BeEmpty())
+				Expect(s.server.TTL(DefaultPrefix + "session:" + uniqueUUID2)).To(BeNumerically("~", time.Minute, time.Second))
+			})
+		})
+
+		Context("ResetBatch()", func() {
+			It("extends the live sessions and their TTL", func() {
+				extended, err := s.mem.ResetBatch(ctx, map[string]time.Time{
+					uniqueUUID1: time.Now().Add(time.Hour),
+					uniqueUUID3: time.Now().Add(time.Hour),
+				})
+				Expect(err).To(BeNil())
+				Expect(extended).To(HaveLen(1))
+				Expect(extended).To(HaveKey(uniqueUUID1))
+				Expect(s.server.TTL(DefaultPrefix + "session:" + uniqueUUID1)).To(BeNumerically("~", time.Hour, time.Second))
+			})
+		})
+
+		Context("DeleteBatch()", func() {
+			It("deletes the sessions and their subject index entries", func() {
+				deleted, err := s.mem.DeleteBatch(ctx, []string{uniqueUUID1, uniqueUUID3})
+				Expect(err).To(BeNil())
+				Expect(deleted).To(HaveLen(1))
+				Expect(string(deleted[uniqueUUID1].Oject)).To(Equal(uniqueUUID1))
+
+				members, err := s.server.Members(DefaultPrefix + "subject:user-42")
+				Expect(err).To(BeNil())
+				Expect(members).To(ConsistOf(uniqueUUID2))
+			})
+		})
+	})
+
+	Describe("Query sessions", func() {
+		base := time.Now().Add(-time.Hour).UnixNano()
+		sessionIds := []string{
+			"90660b89-100e-4f8f-9801-2524df6fbe00",
+			"90660b89-100e-4f8f-9801-2524df6fbe01",
+			"90660b89-100e-4f8f-9801-2524df6fbe02",
+			"90660b89-100e-4f8f-9801-2524df6fbe03",
+			"90660b89-100e-4f8f-9801-2524df6fbe04",
+		}
+		BeforeEach(func() {
+			for i, sessionId := range sessionIds {
+				subject := "user-42"
+				if i%2 == 1 {
+					subject = "user-7"
+				}
+				err := s.mem.CommitItem(ctx, sessionId, models.Item{
+					Oject:      []byte(sessionId),
+					Subject:    subject,
+					CreatedAt:  base + int64(i)*int64(time.Second),
+					Expiration: time.Now().Add(time.Duration(10-i) * time.Minute).UnixNano(),
+				})
+				Expect(err).To(BeNil())
+			}
+		})
+
+		ids := func(page Page) []string {
+			var list []string
+			for _, item := range page.Items {
+				list = append(list, item.SessionId)
+			}
+			return list
+		}
+
+		Context("Query()", func() {
+			It("walks the pages in a stable order", func() {
+				query := Query{Limit: 2}
+				var list []string
+				for {
+					page, err := s.mem.Query(ctx, query)
+					Expect(err).To(BeNil())
+					Expect(len(page.Items)).To(BeNumerically("<=", 2))
+					list = append(list, ids(page)...)
+					if page.NextCursor == "" {
+						break
+					}
+					query.Cursor = page.NextCursor
+				}
+				Expect(list).To(Equal(sessionIds))
+			})
+			It("orders the sessions by expiration", func() {
+				page, err := s.mem.Query(ctx, Query{SortBy: SortByExpiration})
+				Expect(err).To(BeNil())
+				Expect(ids(page)).To(Equal([]string{sessionIds[4], sessionIds[3], sessionIds[2], sessionIds[1], sessionIds[0]}))
+				page, err = s.mem.Query(ctx, Query{Limit: 2, SortBy: SortByExpiration, Descending: true})
+				Expect(err).To(BeNil())
+				page, err = s.mem.Query(ctx, Query{Limit: 2, SortBy: SortByExpiration, Descending: true, Cursor: page.NextCursor})
+				Expect(err).To(BeNil())
+				Expect(ids(page)).To(Equal(sessionIds[2:4]))
+			})
+			It("filters the sessions", func() {
+				page, err := s.mem.Query(ctx, Query{Subject: "user-42", CreatedAfter: base + int64(time.Second)})
+				Expect(err).To(BeNil())
+				Expect(ids(page)).To(Equal([]string{sessionIds[2], sessionIds[4]}))
+				page, err = s.mem.Query(ctx, Query{
+					CreatedBefore: base + 3*int64(time.Second),
+					ExpiresBefore: time.Now().Add(9*time.Minute + 30*time.Second).UnixNano(),
+				})
+				Expect(err).To(BeNil())
+				Expect(ids(page)).To(Equal(sessionIds[1:3]))
+			})
+			It("refuses an invalid cursor", func() {
+				_, err := s.mem.Query(ctx, Query{Cursor: "not a cursor"})
+				Expect(err).To(Equal(ErrInvalidCursor))
+			})
+			It("skips the expired sessions", func() {
+				s.server.FastForward(7 * time.Minute)
+				page, err := s.mem.Query(ctx, Query{})
+				Expect(err).To(BeNil())
+				Expect(ids(page)).To(Equal(sessionIds[:3]))
+			})
+		})
+	})
+
+	Describe("Health checks", func() {
+		Context("Ping()", func() {
+			It("succeeds while the server is reachable", func() {
+				Expect(s.mem.(HealthChecker).Ping(ctx)).To(Succeed())
+			})
+			It("fails once the server is down", func() {
+				s.server.Close()
+				Expect(s.mem.(HealthChecker).Ping(ctx)).ToNot(Succeed())
+			})
+		})
+		Context("CheckCleanup()", func() {
+			It("succeeds as Redis expires the sessions itself", func() {
+				Expect(s.mem.(HealthChecker).CheckCleanup(ctx)).To(Succeed())
+			})
+		})
+	})
+})
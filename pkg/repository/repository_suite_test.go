@@ -1,12 +1,16 @@
 package repository_test
 
 import (
+	"context"
 	"testing"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 )
 
+// ctx is the context of the calls made by the specs
+var ctx = context.Background()
+
 func TestRepository(t *testing.T) {
 	RegisterFailHandler(Fail)
 	RunSpecs(t, "Repository Suite")
@@ -1,6 +1,8 @@
 package repository_test
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"time"
 
@@ -8,6 +10,7 @@ import (
 	. "github.com/onsi/gomega"
 
 	"github.com/hecomp/session-management/internal/models"
+	"github.com/hecomp/session-management/pkg/in_memory"
 	"github.com/hecomp/session-management/pkg/in_memory/in_memoryfakes"
 	. "github.com/hecomp/session-management/pkg/repository"
 	"github.com/hecomp/session-management/pkg/test"
@@ -34,15 +37,58 @@ var _ = Describe("Repository", func() {
 				It("stores an unique sessionId in-memory store", func() {
 					uniqueUUID := "90660b89-100e-4f8f-9801-2524df6fbe34"
 					expiration := time.Now().Add(time.Second * time.Duration(40))
-					s.fakeMemStore.CommitReturns(nil)
-					err := s.repo.Create(uniqueUUID, expiration)
+					s.fakeMemStore.CommitItemReturns(nil)
+					err := s.repo.Create(ctx, uniqueUUID, &models.SessionRequest{}, expiration)
 					Expect(err).To(BeNil())
 				})
+				It("stores the session data along with the sessionId", func() {
+					uniqueUUID := "90660b89-100e-4f8f-9801-2524df6fbe34"
+					expiration := time.Now().Add(time.Second * time.Duration(40))
+					s.fakeMemStore.CommitItemReturns(nil)
+					err := s.repo.Create(ctx, uniqueUUID, &models.SessionRequest{
+						Subject: "user-42",
+						Data:    map[string]interface{}{"user_id": "42"},
+					}, expiration)
+					Expect(err).To(BeNil())
+
+					_, sessionId, item := s.fakeMemStore.CommitItemArgsForCall(0)
+					var record models.Record
+					Expect(json.Unmarshal(item.Oject, &record)).To(Succeed())
+					Expect(sessionId).To(Equal(uniqueUUID))
+					Expect(item.Subject).To(Equal("user-42"))
+					Expect(item.Expiration).To(Equal(expiration.UnixNano()))
+					Expect(item.IdleTimeout).To(BeZero())
+					Expect(item.MaxExpiration).To(BeZero())
+					Expect(record.SessionId).To(Equal(uniqueUUID))
+					Expect(record.Subject).To(Equal("user-42"))
+					Expect(record.CreatedAt).ToNot(BeZero())
+					Expect(record.Data).To(Equal(map[string]interface{}{"user_id": "42"}))
+				})
+				It("stores the idle timeout and maximum expiration of the session", func() {
+					uniqueUUID := "90660b89-100e-4f8f-9801-2524df6fbe34"
+					expiration := time.Now().Add(time.Second * time.Duration(40))
+					s.fakeMemStore.CommitItemReturns(nil)
+					err := s.repo.Create(ctx, uniqueUUID, &models.SessionRequest{IdleTimeout: 40, MaxLifetime: 3600}, expiration)
+					Expect(err).To(BeNil())
+
+					_, _, item := s.fakeMemStore.CommitItemArgsForCall(0)
+					var record models.Record
+					Expect(json.Unmarshal(item.Oject, &record)).To(Succeed())
+					Expect(item.IdleTimeout).To(Equal(int64(40 * time.Second)))
+					Expect(item.MaxExpiration).To(Equal(time.Unix(0, record.CreatedAt).Add(time.Hour).UnixNano()))
+				})
+				It("passes the context of the call to the store", func() {
+					type key struct{}
+					requestCtx := context.WithValue(ctx, key{}, "request")
+					Expect(s.repo.Create(requestCtx, "90660b89-100e-4f8f-9801-2524df6fbe34", &models.SessionRequest{}, time.Now())).To(Succeed())
+					storeCtx, _, _ := s.fakeMemStore.CommitItemArgsForCall(0)
+					Expect(storeCtx.Value(key{})).To(Equal("request"))
+				})
 				It("error stores an unique sessionId in-memory store", func() {
 					uniqueUUID := "90660b89-100e-4f8f-9801-2524df6fbe34"
 					expiration := time.Now().Add(time.Second * time.Duration(40))
-					s.fakeMemStore.CommitReturns(errors.New("Error commit"))
-					err := s.repo.Create(uniqueUUID, expiration)
+					s.fakeMemStore.CommitItemReturns(errors.New("Error commit"))
+					err := s.repo.Create(ctx, uniqueUUID, &models.SessionRequest{}, expiration)
 					Expect(err).ToNot(BeNil())
 				})
 			})
@@ -58,7 +104,7 @@ var _ = Describe("Repository", func() {
 						SessionId: uniqueUUID,
 					}
 					s.fakeMemStore.DeleteReturns(nil)
-					err := s.repo.Destroy(session)
+					err := s.repo.Destroy(ctx, session)
 					Expect(err).To(BeNil())
 				})
 				It("error destroy an unique sessionId in-memory store", func() {
@@ -67,13 +113,230 @@ var _ = Describe("Repository", func() {
 						SessionId: uniqueUUID,
 					}
 					s.fakeMemStore.DeleteReturns(errors.New("Error destroy"))
-					err := s.repo.Destroy(session)
+					err := s.repo.Destroy(ctx, session)
 					Expect(err).ToNot(BeNil())
 				})
 			})
 		})
 	})
 
+	Describe("Rotate Session", func() {
+		oldUUID := "90660b89-100e-4f8f-9801-2524df6fbe34"
+		newUUID := "5b6d0be2-d47b-4d0b-9e4c-6e0a5f1a0d11"
+
+		var items map[string]models.Item
+		put := func(record *models.Record, expiration time.Time) {
+			b, err := json.Marshal(record)
+			Expect(err).To(BeNil())
+			items[record.SessionId] = models.Item{Oject: b, Subject: record.Subject, Expiration: expiration.UnixNano()}
+		}
+
+		BeforeEach(func() {
+			items = make(map[string]models.Item)
+			s.fakeMemStore.LookupStub = func(_ context.Context, sessionId string) (models.Item, bool, error) {
+				item, found := items[sessionId]
+				return item, found, nil
+			}
+			s.fakeMemStore.FindStub = func(_ context.Context, sessionId string) ([]byte, bool, error) {
+				item, found := items[sessionId]
+				return item.Oject, found, nil
+			}
+		})
+
+		Context("Rotate()", func() {
+			It("moves the session to the new id and leaves a tombstone for the grace period", func() {
+				expiration := time.Now().Add(time.Hour)
+				put(&models.Record{SessionId: oldUUID, Subject: "user-42", CreatedAt: 42, Data: map[string]interface{}{"cart": "a"}}, expiration)
+				found, err := s.repo.Rotate(ctx, oldUUID, newUUID, 10*time.Second)
+				Expect(err).To(BeNil())
+				Expect(found).To(BeTrue())
+				Expect(s.fakeMemStore.CommitItemCallCount()).To(BeZero())
+				Expect(s.fakeMemStore.CommitBatchCallCount()).To(Equal(1))
+
+				_, batch := s.fakeMemStore.CommitBatchArgsForCall(0)
+				Expect(batch).To(HaveLen(2))
+				moved := batch[newUUID]
+				var record models.Record
+				Expect(json.Unmarshal(moved.Oject, &record)).To(Succeed())
+				Expect(moved.Subject).To(Equal("user-42"))
+				Expect(moved.Expiration).To(Equal(expiration.UnixNano()))
+				Expect(record.SessionId).To(Equal(newUUID))
+				Expect(record.CreatedAt).To(Equal(int64(42)))
+				Expect(record.Data).To(Equal(map[string]interface{}{"cart": "a"}))
+
+				tombstone := batch[oldUUID]
+				var tombstoneRecord models.Record
+				Expect(json.Unmarshal(tombstone.Oject, &tombstoneRecord)).To(Succeed())
+				Expect(tombstone.Subject).To(BeEmpty())
+				Expect(tombstone.Expiration).To(BeNumerically("~", time.Now().Add(10*time.Second).UnixNano(), int64(time.Second)))
+				Expect(tombstone.MaxExpiration).To(Equal(tombstone.Expiration))
+				Expect(tombstoneRecord.RotatedTo).To(Equal(newUUID))
+				Expect(tombstoneRecord.Data).To(BeNil())
+			})
+			It("does not keep the tombstone past the expiration of the session", func() {
+				expiration := time.Now().Add(time.Second)
+				put(&models.Record{SessionId: oldUUID}, expiration)
+				_, err := s.repo.Rotate(ctx, oldUUID, newUUID, time.Minute)
+				Expect(err).To(BeNil())
+				_, batch := s.fakeMemStore.CommitBatchArgsForCall(0)
+				Expect(batch[oldUUID].Expiration).To(Equal(expiration.UnixNano()))
+			})
+			It("leaves the session untouched when the rotation can not be committed", func() {
+				put(&models.Record{SessionId: oldUUID}, time.Now().Add(time.Minute))
+				s.fakeMemStore.CommitBatchReturns(errors.New("error commit"))
+				found, err := s.repo.Rotate(ctx, oldUUID, newUUID, time.Minute)
+				Expect(err).ToNot(BeNil())
+				Expect(found).To(BeFalse())
+				Expect(s.fakeMemStore.CommitItemCallCount()).To(BeZero())
+			})
+			It("does not rotate a tombstone", func() {
+				put(&models.Record{SessionId: oldUUID, RotatedTo: newUUID}, time.Now().Add(time.Second))
+				found, err := s.repo.Rotate(ctx, oldUUID, "e7d0c54e-3a5e-4c07-a5b1-2f7b8a1e7a7e", time.Minute)
+				Expect(err).To(BeNil())
+				Expect(found).To(BeFalse())
+				Expect(s.fakeMemStore.CommitBatchCallCount()).To(BeZero())
+			})
+			It("not found sessionId in-memory store", func() {
+				found, err := s.repo.Rotate(ctx, oldUUID, newUUID, time.Minute)
+				Expect(err).To(BeNil())
+				Expect(found).To(BeFalse())
+			})
+		})
+
+		Context("a session rotated by the store", func() {
+			It("does not disclose the new id to the holders of the old one", func() {
+				repo := NewSessionMgmntRepository(in_memory.NewInMemStore(0, test.GetLogger()), test.GetLogger())
+				Expect(repo.Create(ctx, oldUUID, &models.SessionRequest{Subject: "user-42"}, time.Now().Add(time.Minute))).To(Succeed())
+				Expect(repo.Rotate(ctx, oldUUID, newUUID, time.Minute)).To(BeTrue())
+
+				details, found, err := repo.Get(ctx, oldUUID)
+				Expect(err).To(BeNil())
+				Expect(found).To(BeTrue())
+				Expect(details.SessionId).ToNot(Equal(newUUID))
+				Expect(details.SessionId).To(Equal(oldUUID))
+				Expect(details.Subject).To(Equal("user-42"))
+			})
+		})
+
+		Context("a session rotated twice", func() {
+			It("is destroyed in a batch at the end of the chain", func() {
+				lastUUID := "e7d0c54e-3a5e-4c07-a5b1-2f7b8a1e7a7e"
+				repo := NewSessionMgmntRepository(in_memory.NewInMemStore(0, test.GetLogger()), test.GetLogger())
+				Expect(repo.Create(ctx, oldUUID, &models.SessionRequest{}, time.Now().Add(time.Minute))).To(Succeed())
+				Expect(repo.Rotate(ctx, oldUUID, newUUID, time.Minute)).To(BeTrue())
+				Expect(repo.Rotate(ctx, newUUID, lastUUID, time.Minute)).To(BeTrue())
+
+				found, err := repo.DestroyBatch(ctx, []string{oldUUID})
+				Expect(err).To(BeNil())
+				Expect(found).To(Equal([]bool{true}))
+				for _, sessionId := range []string{oldUUID, newUUID, lastUUID} {
+					found, err := repo.Exist(ctx, sessionId)
+					Expect(err).To(BeNil())
+					Expect(found).To(BeFalse())
+				}
+			})
+		})
+
+		Context("a rotation racing a logout", func() {
+			It("never leaves the rotated session alive", func() {
+				store := &pausingMemStore{MemStore: in_memory.NewInMemStore(0, test.GetLogger()), sessionId: newUUID, paused: make(chan struct{})}
+				repo := NewSessionMgmntRepository(store, test.GetLogger())
+				Expect(repo.Create(ctx, oldUUID, &models.SessionRequest{}, time.Now().Add(time.Minute))).To(Succeed())
+
+				done := make(chan struct{})
+				go func() {
+					defer GinkgoRecover()
+					defer close(done)
+					_, err := repo.Rotate(ctx, oldUUID, newUUID, time.Minute)
+					Expect(err).To(BeNil())
+				}()
+				<-store.paused
+				Expect(repo.Destroy(ctx, &models.DestroyRequest{SessionId: oldUUID})).To(Succeed())
+				<-done
+
+				for _, sessionId := range []string{oldUUID, newUUID} {
+					found, err := repo.Exist(ctx, sessionId)
+					Expect(err).To(BeNil())
+					Expect(found).To(BeFalse(), "the session survived the logout")
+				}
+			})
+		})
+
+		Context("a rotated session id", func() {
+			BeforeEach(func() {
+				put(&models.Record{SessionId: oldUUID, RotatedTo: newUUID}, time.Now().Add(10*time.Second))
+				put(&models.Record{SessionId: newUUID, Subject: "user-42", Data: map[string]interface{}{"cart": "a"}}, time.Now().Add(time.Hour))
+			})
+
+			It("resolves to the session that replaced it", func() {
+				details, found, err := s.repo.Get(ctx, oldUUID)
+				Expect(err).To(BeNil())
+				Expect(found).To(BeTrue())
+				Expect(details.SessionId).To(Equal(oldUUID))
+				Expect(details.Subject).To(Equal("user-42"))
+
+				data, found, err := s.repo.GetData(ctx, oldUUID)
+				Expect(err).To(BeNil())
+				Expect(found).To(BeTrue())
+				Expect(data).To(Equal(map[string]interface{}{"cart": "a"}))
+			})
+			It("updates the data of the session that replaced it", func() {
+				s.fakeMemStore.UpdateReturns(true, nil)
+				found, err := s.repo.SetData(ctx, &models.SessionDataRequest{SessionId: oldUUID, Data: map[string]interface{}{"cart": "b"}})
+				Expect(err).To(BeNil())
+				Expect(found).To(BeTrue())
+				_, sessionId, _ := s.fakeMemStore.UpdateArgsForCall(0)
+				Expect(sessionId).To(Equal(newUUID))
+			})
+			It("extends the session that replaced it", func() {
+				s.fakeMemStore.ResetStub = func(_ context.Context, sessionId string, _ time.Time) ([]byte, bool, error) {
+					return items[sessionId].Oject, true, nil
+				}
+				found, err := s.repo.Extend(ctx, &models.ExtendRequest{SessionId: oldUUID, TTL: 60})
+				Expect(err).To(BeNil())
+				Expect(found).To(BeTrue())
+				Expect(s.fakeMemStore.ResetCallCount()).To(Equal(2))
+				_, sessionId, _ := s.fakeMemStore.ResetArgsForCall(1)
+				Expect(sessionId).To(Equal(newUUID))
+			})
+			It("destroys the session that replaced it along with the tombstone", func() {
+				Expect(s.repo.Destroy(ctx, &models.DestroyRequest{SessionId: oldUUID})).To(Succeed())
+				Expect(s.fakeMemStore.DeleteCallCount()).To(Equal(2))
+				_, deleted := s.fakeMemStore.DeleteArgsForCall(0)
+				Expect(deleted).To(Equal(oldUUID))
+				_, deleted = s.fakeMemStore.DeleteArgsForCall(1)
+				Expect(deleted).To(Equal(newUUID))
+			})
+			It("extends in a batch the session that replaced it", func() {
+				s.fakeMemStore.ResetBatchStub = func(_ context.Context, expirations map[string]time.Time) (map[string]models.Item, error) {
+					extended := make(map[string]models.Item)
+					for sessionId := range expirations {
+						if item, found := items[sessionId]; found {
+							extended[sessionId] = item
+						}
+					}
+					return extended, nil
+				}
+				found, err := s.repo.ExtendBatch(ctx, []models.ExtendRequest{{SessionId: oldUUID, TTL: 60}, {SessionId: "unknown", TTL: 60}})
+				Expect(err).To(BeNil())
+				Expect(found).To(Equal([]bool{true, false}))
+				Expect(s.fakeMemStore.ResetBatchCallCount()).To(Equal(2))
+				_, expirations := s.fakeMemStore.ResetBatchArgsForCall(1)
+				Expect(expirations).To(HaveLen(1))
+				Expect(expirations).To(HaveKey(newUUID))
+			})
+			It("destroys in a batch the session that replaced it along with the tombstone", func() {
+				s.fakeMemStore.DeleteBatchReturnsOnCall(0, map[string]models.Item{oldUUID: items[oldUUID]}, nil)
+				found, err := s.repo.DestroyBatch(ctx, []string{oldUUID, "unknown"})
+				Expect(err).To(BeNil())
+				Expect(found).To(Equal([]bool{true, false}))
+				Expect(s.fakeMemStore.DeleteBatchCallCount()).To(Equal(2))
+				_, deleted := s.fakeMemStore.DeleteBatchArgsForCall(1)
+				Expect(deleted).To(Equal([]string{newUUID}))
+			})
+		})
+	})
+
 	Describe("Extend Session", func() {
 		Context("Extend()", func() {
 			When("the API os called with TTL as param", func() {
@@ -83,8 +346,8 @@ var _ = Describe("Repository", func() {
 						TTL: 100,
 						SessionId: uniqueUUID,
 					}
-					s.fakeMemStore.ResetReturns([]byte(uniqueUUID), true, nil)
-					err := s.repo.Extend(session)
+					s.fakeMemStore.ResetReturns(test.MarshalRecord(uniqueUUID, nil), true, nil)
+					_, err := s.repo.Extend(ctx, session)
 					Expect(err).To(BeNil())
 				})
 				It("error extend an unique sessionId in-memory store", func() {
@@ -94,27 +357,105 @@ var _ = Describe("Repository", func() {
 						SessionId: uniqueUUID,
 					}
 					s.fakeMemStore.ResetReturns([]byte(uniqueUUID), false, errors.New("Error destroy"))
-					err := s.repo.Extend(session)
+					_, err := s.repo.Extend(ctx, session)
 					Expect(err).ToNot(BeNil())
 				})
 			})
 		})
 	})
 
+	Describe("Batch Sessions", func() {
+		uniqueUUID1 := "90660b89-100e-4f8f-9801-2524df6fbe34"
+		uniqueUUID2 := "90660b89-100e-4f8f-9801-2524df6fbe99"
+
+		Context("CreateBatch()", func() {
+			It("stores the sessions in a single batch", func() {
+				expiration := time.Now().Add(time.Minute)
+				err := s.repo.CreateBatch(ctx, []NewSession{
+					{SessionId: uniqueUUID1, Request: &models.SessionRequest{Subject: "user-42"}, Expiration: expiration},
+					{SessionId: uniqueUUID2, Request: &models.SessionRequest{MaxLifetime: 3600}, Expiration: expiration},
+				})
+				Expect(err).To(BeNil())
+				Expect(s.fakeMemStore.CommitBatchCallCount()).To(Equal(1))
+
+				_, items := s.fakeMemStore.CommitBatchArgsForCall(0)
+				Expect(items).To(HaveLen(2))
+				var record models.Record
+				Expect(json.Unmarshal(items[uniqueUUID1].Oject, &record)).To(Succeed())
+				Expect(record.SessionId).To(Equal(uniqueUUID1))
+				Expect(items[uniqueUUID1].Subject).To(Equal("user-42"))
+				Expect(items[uniqueUUID1].Expiration).To(Equal(expiration.UnixNano()))
+				Expect(items[uniqueUUID1].CreatedAt).To(Equal(items[uniqueUUID2].CreatedAt))
+				Expect(items[uniqueUUID2].MaxExpiration).To(Equal(items[uniqueUUID2].CreatedAt + int64(time.Hour)))
+			})
+			It("error an empty sessionId", func() {
+				err := s.repo.CreateBatch(ctx, []NewSession{{Request: &models.SessionRequest{}, Expiration: time.Now()}})
+				Expect(err).To(Equal(ErrEmpty))
+				Expect(s.fakeMemStore.CommitBatchCallCount()).To(BeZero())
+			})
+		})
+
+		Context("ExtendBatch()", func() {
+			It("reports the extended sessions", func() {
+				b, err := json.Marshal(&models.Record{SessionId: uniqueUUID1})
+				Expect(err).To(BeNil())
+				s.fakeMemStore.ResetBatchReturns(map[string]models.Item{uniqueUUID1: {Oject: b}}, nil)
+				found, err := s.repo.ExtendBatch(ctx, []models.ExtendRequest{{SessionId: uniqueUUID1, TTL: 60}, {SessionId: uniqueUUID2, TTL: 30}})
+				Expect(err).To(BeNil())
+				Expect(found).To(Equal([]bool{true, false}))
+
+				_, expirations := s.fakeMemStore.ResetBatchArgsForCall(0)
+				Expect(expirations[uniqueUUID1]).To(BeTemporally("~", time.Now().Add(time.Minute), time.Second))
+				Expect(expirations[uniqueUUID2]).To(BeTemporally("~", time.Now().Add(30*time.Second), time.Second))
+			})
+			It("error reset batch in-memory store", func() {
+				s.fakeMemStore.ResetBatchReturns(nil, errors.New("error reset"))
+				_, err := s.repo.ExtendBatch(ctx, []models.ExtendRequest{{SessionId: uniqueUUID1}})
+				Expect(err).ToNot(BeNil())
+			})
+		})
+
+		Context("DestroyBatch()", func() {
+			It("reports the destroyed sessions", func() {
+				b, err := json.Marshal(&models.Record{SessionId: uniqueUUID2})
+				Expect(err).To(BeNil())
+				s.fakeMemStore.DeleteBatchReturns(map[string]models.Item{uniqueUUID2: {Oject: b}}, nil)
+				found, err := s.repo.DestroyBatch(ctx, []string{uniqueUUID1, uniqueUUID2})
+				Expect(err).To(BeNil())
+				Expect(found).To(Equal([]bool{false, true}))
+				Expect(s.fakeMemStore.DeleteBatchCallCount()).To(Equal(1))
+				Expect(s.fakeMemStore.LookupCallCount()).To(BeZero())
+			})
+			It("error delete batch in-memory store", func() {
+				s.fakeMemStore.DeleteBatchReturns(nil, errors.New("error delete"))
+				_, err := s.repo.DestroyBatch(ctx, []string{uniqueUUID1})
+				Expect(err).ToNot(BeNil())
+			})
+		})
+	})
+
 	Describe("Exist Session", func() {
 		Context("Exist()", func() {
 			When("the API os called with TTL as param", func() {
 				It("exist an unique sessionId in-memory store", func() {
 					uniqueUUID := "90660b89-100e-4f8f-9801-2524df6fbe34"
-					s.fakeMemStore.FindReturns([]byte(uniqueUUID), true, nil)
-					found, err := s.repo.Exist(uniqueUUID)
+					s.fakeMemStore.FindReturns(test.MarshalRecord(uniqueUUID, nil), true, nil)
+					found, err := s.repo.Exist(ctx, uniqueUUID)
 					Expect(err).To(BeNil())
 					Expect(found).To(BeTrue())
 				})
+				It("invalid sessionId in-memory store", func() {
+					uniqueUUID := "90660b89-100e-4f8f-9801-2524df6fbe34"
+					uniqueUUID2 := "90660b89-100e-4f8f-9801-2524df6fbe99"
+					s.fakeMemStore.FindReturns(test.MarshalRecord(uniqueUUID2, nil), true, nil)
+					found, err := s.repo.Exist(ctx, uniqueUUID)
+					Expect(err).To(Equal(ErrInvalidSessionId))
+					Expect(found).ToNot(BeTrue())
+				})
 				It("error extend an unique sessionId in-memory store", func() {
 					uniqueUUID := "90660b89-100e-4f8f-9801-2524df6fbe34"
 					s.fakeMemStore.FindReturns([]byte(uniqueUUID), false, errors.New("Error Exist"))
-					found, err := s.repo.Exist(uniqueUUID)
+					found, err := s.repo.Exist(ctx, uniqueUUID)
 					Expect(err).ToNot(BeNil())
 					Expect(found).ToNot(BeTrue())
 				})
@@ -122,32 +463,353 @@ var _ = Describe("Repository", func() {
 		})
 	})
 
+	Describe("Get Session", func() {
+		Context("Get()", func() {
+			When("the API os called with a sessionId", func() {
+				It("returns the session details", func() {
+					uniqueUUID := "90660b89-100e-4f8f-9801-2524df6fbe34"
+					createdAt := time.Now().Add(-time.Minute)
+					expiration := time.Now().Add(time.Minute * time.Duration(5))
+					b, _ := json.Marshal(&models.Record{SessionId: uniqueUUID, CreatedAt: createdAt.UnixNano()})
+					s.fakeMemStore.LookupReturns(models.Item{Oject: b, Expiration: expiration.UnixNano()}, true, nil)
+					details, found, err := s.repo.Get(ctx, uniqueUUID)
+					Expect(err).To(BeNil())
+					Expect(found).To(BeTrue())
+					Expect(details.SessionId).To(Equal(uniqueUUID))
+					Expect(details.Exists).To(BeTrue())
+					Expect(details.CreatedAt.UnixNano()).To(Equal(createdAt.UnixNano()))
+					Expect(details.ExpiresAt.UnixNano()).To(Equal(expiration.UnixNano()))
+					Expect(details.TTL).To(BeNumerically("~", 300, 1))
+				})
+				It("not found sessionId in-memory store", func() {
+					s.fakeMemStore.LookupReturns(models.Item{}, false, nil)
+					details, found, err := s.repo.Get(ctx, "90660b89-100e-4f8f-9801-2524df6fbe34")
+					Expect(err).To(BeNil())
+					Expect(found).ToNot(BeTrue())
+					Expect(details).To(BeNil())
+				})
+				It("error lookup in-memory store", func() {
+					s.fakeMemStore.LookupReturns(models.Item{}, false, errors.New("Error lookup"))
+					_, _, err := s.repo.Get(ctx, "90660b89-100e-4f8f-9801-2524df6fbe34")
+					Expect(err).ToNot(BeNil())
+				})
+			})
+		})
+	})
+
 	Describe("List Session", func() {
 		Context("List()", func() {
-			When("the API os called with TTL as param", func() {
-				It("list an unique sessionId in-memory store", func() {
+			When("the API os called with a list request", func() {
+				It("list the page of the sessions in-memory store", func() {
 					expiration := time.Now().Add(time.Minute * time.Duration(5))
 					uniqueUUID := "90660b89-100e-4f8f-9801-2524df6fbe34"
-					sessionMap := map[string]models.Item{
-						"90660b89-100e-4f8f-9801-2524df6fbe34": {
-								Oject: []byte(uniqueUUID),
-								Expiration: expiration.UnixNano(),
+					page := in_memory.Page{
+						Items: []in_memory.QueryItem{
+							{SessionId: uniqueUUID, Item: models.Item{Oject: []byte(uniqueUUID), Expiration: expiration.UnixNano()}},
 						},
-
+						NextCursor: "next",
 					}
-					s.fakeMemStore.ListReturns(sessionMap, nil)
-					sessions, err := s.repo.List()
-					sessionsList := test.ConvertMapToList(sessionMap)
+					s.fakeMemStore.QueryReturns(page, nil)
+					sessions, err := s.repo.List(ctx, &models.ListRequest{Limit: 1})
+					Expect(err).To(BeNil())
+					Expect(sessions).To(Equal(&models.Sessions{List: []string{uniqueUUID}, NextCursor: "next"}))
+				})
+				It("converts the request to a store query", func() {
+					createdAfter := time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+					s.fakeMemStore.QueryReturns(in_memory.Page{}, nil)
+					before := time.Now()
+					_, err := s.repo.List(ctx, &models.ListRequest{
+						Cursor:       "cursor",
+						Limit:        10,
+						Subject:      "user-42",
+						CreatedAfter: createdAfter,
+						MinTTL:       60,
+						Sort:         "expires_at",
+						Order:        "desc",
+					})
 					Expect(err).To(BeNil())
-					Expect(sessions).To(Equal(sessionsList))
+					_, query := s.fakeMemStore.QueryArgsForCall(0)
+					Expect(query.Subject).To(Equal("user-42"))
+					Expect(query.Cursor).To(Equal("cursor"))
+					Expect(query.Limit).To(Equal(10))
+					Expect(query.SortBy).To(Equal(in_memory.SortByExpiration))
+					Expect(query.Descending).To(BeTrue())
+					Expect(query.CreatedAfter).To(Equal(createdAfter.UnixNano()))
+					Expect(query.CreatedBefore).To(BeZero())
+					Expect(query.ExpiresAfter).To(BeNumerically(">=", before.Add(time.Minute).UnixNano()))
+					Expect(query.ExpiresBefore).To(BeZero())
 				})
 				It("error list an unique sessionId in-memory store", func() {
-					sessionMap := map[string]models.Item{}
-					s.fakeMemStore.ListReturns(sessionMap, errors.New("Error list"))
-					_, err := s.repo.List()
+					s.fakeMemStore.QueryReturns(in_memory.Page{}, errors.New("Error list"))
+					_, err := s.repo.List(ctx, &models.ListRequest{})
+					Expect(err).ToNot(BeNil())
+				})
+			})
+		})
+	})
+
+	Describe("Subject Sessions", func() {
+		subject := "user-42"
+		sessionIds := []string{"90660b89-100e-4f8f-9801-2524df6fbe34", "90660b89-100e-4f8f-9801-2524df6fbe99"}
+
+		Context("ListBySubject()", func() {
+			When("the API os called with a subject", func() {
+				It("lists the sessionIds of the subject", func() {
+					s.fakeMemStore.ListBySubjectReturns(map[string]models.Item{
+						sessionIds[0]: {Oject: test.MarshalRecord(sessionIds[0], nil), Subject: subject},
+						sessionIds[1]: {Oject: test.MarshalRecord(sessionIds[1], nil), Subject: subject},
+					}, nil)
+					sessions, err := s.repo.ListBySubject(ctx, subject)
+					Expect(err).To(BeNil())
+					Expect(sessions.List).To(ConsistOf(sessionIds))
+					_, listed := s.fakeMemStore.ListBySubjectArgsForCall(0)
+					Expect(listed).To(Equal(subject))
+				})
+				It("error list by subject in-memory store", func() {
+					s.fakeMemStore.ListBySubjectReturns(nil, errors.New("Error list"))
+					_, err := s.repo.ListBySubject(ctx, subject)
+					Expect(err).ToNot(BeNil())
+				})
+			})
+		})
+
+		Context("ListSubjectDetails()", func() {
+			When("the API os called with a subject", func() {
+				It("returns the details of the sessions of the subject", func() {
+					lastAccess := time.Now().Add(-time.Minute)
+					s.fakeMemStore.ListBySubjectReturns(map[string]models.Item{
+						sessionIds[0]: {
+							Oject:      test.MarshalRecord(sessionIds[0], nil),
+							Expiration: time.Now().Add(time.Minute).UnixNano(),
+							Subject:    subject,
+							LastAccess: lastAccess.UnixNano(),
+						},
+					}, nil)
+					sessions, err := s.repo.ListSubjectDetails(ctx, subject)
+					Expect(err).To(BeNil())
+					Expect(sessions).To(HaveLen(1))
+					Expect(sessions[0].SessionId).To(Equal(sessionIds[0]))
+					Expect(sessions[0].LastAccess).To(Equal(time.Unix(0, lastAccess.UnixNano()).UTC()))
+				})
+				It("error a record of another session", func() {
+					s.fakeMemStore.ListBySubjectReturns(map[string]models.Item{
+						sessionIds[0]: {Oject: test.MarshalRecord(sessionIds[1], nil), Subject: subject},
+					}, nil)
+					_, err := s.repo.ListSubjectDetails(ctx, subject)
+					Expect(err).To(Equal(ErrInvalidSessionId))
+				})
+			})
+		})
+
+		Context("DestroyBySubject()", func() {
+			When("the API os called with a subject", func() {
+				It("destroys every session of the subject", func() {
+					s.fakeMemStore.DeleteBySubjectReturns(sessionIds, nil)
+					sessions, err := s.repo.DestroyBySubject(ctx, subject)
+					Expect(err).To(BeNil())
+					Expect(sessions.List).To(Equal(sessionIds))
+					_, deleted := s.fakeMemStore.DeleteBySubjectArgsForCall(0)
+					Expect(deleted).To(Equal(subject))
+				})
+				It("error delete by subject in-memory store", func() {
+					s.fakeMemStore.DeleteBySubjectReturns(nil, errors.New("Error delete"))
+					_, err := s.repo.DestroyBySubject(ctx, subject)
 					Expect(err).ToNot(BeNil())
 				})
 			})
 		})
 	})
+
+	Describe("Session Data", func() {
+		uniqueUUID := "90660b89-100e-4f8f-9801-2524df6fbe34"
+
+		Context("GetData()", func() {
+			When("the API os called with a sessionId", func() {
+				It("returns the session data", func() {
+					s.fakeMemStore.FindReturns(test.MarshalRecord(uniqueUUID, map[string]interface{}{"user_id": "42"}), true, nil)
+					data, found, err := s.repo.GetData(ctx, uniqueUUID)
+					Expect(err).To(BeNil())
+					Expect(found).To(BeTrue())
+					Expect(data).To(Equal(map[string]interface{}{"user_id": "42"}))
+				})
+				It("not found sessionId in-memory store", func() {
+					s.fakeMemStore.FindReturns(nil, false, nil)
+					data, found, err := s.repo.GetData(ctx, uniqueUUID)
+					Expect(err).To(BeNil())
+					Expect(found).ToNot(BeTrue())
+					Expect(data).To(BeNil())
+				})
+			})
+		})
+
+		Context("SetData()", func() {
+			When("the API os called with data", func() {
+				It("replaces the session data", func() {
+					s.fakeMemStore.FindReturns(test.MarshalRecord(uniqueUUID, map[string]interface{}{"user_id": "42"}), true, nil)
+					s.fakeMemStore.UpdateReturns(true, nil)
+					found, err := s.repo.SetData(ctx, &models.SessionDataRequest{
+						SessionId: uniqueUUID,
+						Data:      map[string]interface{}{"roles": "admin"},
+					})
+					Expect(err).To(BeNil())
+					Expect(found).To(BeTrue())
+
+					_, sessionId, b := s.fakeMemStore.UpdateArgsForCall(0)
+					Expect(sessionId).To(Equal(uniqueUUID))
+					Expect(b).To(Equal(test.MarshalRecord(uniqueUUID, map[string]interface{}{"roles": "admin"})))
+				})
+				It("error update in-memory store", func() {
+					s.fakeMemStore.FindReturns(test.MarshalRecord(uniqueUUID, nil), true, nil)
+					s.fakeMemStore.UpdateReturns(false, errors.New("Error update"))
+					_, err := s.repo.SetData(ctx, &models.SessionDataRequest{SessionId: uniqueUUID})
+					Expect(err).ToNot(BeNil())
+				})
+			})
+		})
+
+		Context("PatchData()", func() {
+			When("the API os called with data", func() {
+				It("merges the keys into the session data", func() {
+					s.fakeMemStore.FindReturns(test.MarshalRecord(uniqueUUID, map[string]interface{}{"user_id": "42", "cart": "a"}), true, nil)
+					s.fakeMemStore.UpdateReturns(true, nil)
+					found, err := s.repo.PatchData(ctx, &models.SessionDataRequest{
+						SessionId: uniqueUUID,
+						Data:      map[string]interface{}{"roles": "admin", "cart": nil},
+					})
+					Expect(err).To(BeNil())
+					Expect(found).To(BeTrue())
+
+					_, _, b := s.fakeMemStore.UpdateArgsForCall(0)
+					Expect(b).To(Equal(test.MarshalRecord(uniqueUUID, map[string]interface{}{"user_id": "42", "roles": "admin"})))
+				})
+				It("not found sessionId in-memory store", func() {
+					s.fakeMemStore.FindReturns(nil, false, nil)
+					found, err := s.repo.PatchData(ctx, &models.SessionDataRequest{SessionId: uniqueUUID})
+					Expect(err).To(BeNil())
+					Expect(found).ToNot(BeTrue())
+					Expect(s.fakeMemStore.UpdateCallCount()).To(BeZero())
+				})
+			})
+		})
+	})
+
+	Describe("Revocation List", func() {
+		uniqueUUID := "90660b89-100e-4f8f-9801-2524df6fbe34"
+		var fakeRevocations *in_memoryfakes.FakeMemStore
+
+		item := func(expiration, createdWith time.Time) models.Item {
+			b, err := json.Marshal(&models.Record{SessionId: uniqueUUID, Subject: "user-42", ExpiresAt: createdWith.UnixNano()})
+			Expect(err).To(BeNil())
+			return models.Item{Oject: b, Subject: "user-42", Expiration: expiration.UnixNano()}
+		}
+
+		BeforeEach(func() {
+			fakeRevocations = new(in_memoryfakes.FakeMemStore)
+			s.repo = NewRevokingSessionMgmntRepository(s.fakeMemStore, fakeRevocations, test.GetLogger())
+		})
+
+		Context("Destroy()", func() {
+			It("revokes the session until the later of its expirations, rounded up to the second", func() {
+				createdWith := time.Unix(1700000100, 500)
+				s.fakeMemStore.LookupReturns(item(time.Unix(1700000050, 0), createdWith), true, nil)
+				Expect(s.repo.Destroy(ctx, &models.DestroyRequest{SessionId: uniqueUUID})).To(Succeed())
+
+				_, sessionId, _, expiration := fakeRevocations.CommitArgsForCall(0)
+				Expect(sessionId).To(Equal(uniqueUUID))
+				Expect(expiration).To(Equal(time.Unix(1700000101, 0)))
+				_, deleted := s.fakeMemStore.DeleteArgsForCall(0)
+				Expect(deleted).To(Equal(uniqueUUID))
+			})
+			It("does not remove the session when it cannot be revoked", func() {
+				s.fakeMemStore.LookupReturns(item(time.Now().Add(time.Minute), time.Now()), true, nil)
+				fakeRevocations.CommitReturns(errors.New("error commit"))
+				Expect(s.repo.Destroy(ctx, &models.DestroyRequest{SessionId: uniqueUUID})).ToNot(Succeed())
+				Expect(s.fakeMemStore.DeleteCallCount()).To(BeZero())
+			})
+		})
+
+		Context("DestroyBatch()", func() {
+			It("revokes the sessions in a single batch before removing them", func() {
+				createdWith := time.Unix(1700000100, 500)
+				s.fakeMemStore.LookupReturns(item(time.Unix(1700000050, 0), createdWith), true, nil)
+				s.fakeMemStore.DeleteBatchReturns(map[string]models.Item{uniqueUUID: item(time.Unix(1700000050, 0), createdWith)}, nil)
+				found, err := s.repo.DestroyBatch(ctx, []string{uniqueUUID})
+				Expect(err).To(BeNil())
+				Expect(found).To(Equal([]bool{true}))
+
+				Expect(fakeRevocations.CommitBatchCallCount()).To(Equal(1))
+				_, revocations := fakeRevocations.CommitBatchArgsForCall(0)
+				Expect(revocations).To(Equal(map[string]models.Item{
+					uniqueUUID: {Oject: []byte(uniqueUUID), Expiration: time.Unix(1700000101, 0).UnixNano()},
+				}))
+				Expect(s.fakeMemStore.DeleteBatchCallCount()).To(Equal(1))
+			})
+			It("does not remove the sessions when they cannot be revoked", func() {
+				s.fakeMemStore.LookupReturns(item(time.Now().Add(time.Minute), time.Now()), true, nil)
+				fakeRevocations.CommitBatchReturns(errors.New("error commit"))
+				_, err := s.repo.DestroyBatch(ctx, []string{uniqueUUID})
+				Expect(err).ToNot(BeNil())
+				Expect(s.fakeMemStore.DeleteBatchCallCount()).To(BeZero())
+			})
+		})
+
+		Context("DestroyBySubject()", func() {
+			It("revokes every session of the subject before removing it", func() {
+				s.fakeMemStore.ListBySubjectReturns(map[string]models.Item{uniqueUUID: item(time.Now().Add(time.Minute), time.Now())}, nil)
+				sessions, err := s.repo.DestroyBySubject(ctx, "user-42")
+				Expect(err).To(BeNil())
+				Expect(sessions.List).To(ConsistOf(uniqueUUID))
+				Expect(fakeRevocations.CommitCallCount()).To(Equal(1))
+				_, deleted := s.fakeMemStore.DeleteArgsForCall(0)
+				Expect(deleted).To(Equal(uniqueUUID))
+				Expect(s.fakeMemStore.DeleteBySubjectCallCount()).To(BeZero())
+			})
+		})
+
+		Context("Revoked() and ListRevoked()", func() {
+			It("reports the revoked sessions that have not expired", func() {
+				fakeRevocations.FindReturns([]byte(uniqueUUID), true, nil)
+				revoked, err := s.repo.Revoked(ctx, uniqueUUID)
+				Expect(err).To(BeNil())
+				Expect(revoked).To(BeTrue())
+
+				expiration := time.Now().Add(time.Minute).Truncate(time.Second)
+				fakeRevocations.ListReturns(map[string]models.Item{
+					uniqueUUID: {Expiration: expiration.UnixNano()},
+					"expired":  {Expiration: time.Now().Add(-time.Minute).UnixNano()},
+				}, nil)
+				revocations, err := s.repo.ListRevoked(ctx)
+				Expect(err).To(BeNil())
+				Expect(revocations.List).To(Equal([]models.Revocation{{SessionId: uniqueUUID, ExpiresAt: expiration.Unix()}}))
+			})
+			It("is empty without a revocations store", func() {
+				repo := NewSessionMgmntRepository(s.fakeMemStore, test.GetLogger())
+				revoked, err := repo.Revoked(ctx, uniqueUUID)
+				Expect(err).To(BeNil())
+				Expect(revoked).To(BeFalse())
+				revocations, err := repo.ListRevoked(ctx)
+				Expect(err).To(BeNil())
+				Expect(revocations.List).To(BeEmpty())
+				Expect(repo.Revoke(ctx, uniqueUUID, time.Now())).To(Succeed())
+			})
+		})
+	})
 })
+
+// pausingMemStore commits the batch of the session like its store after giving a
+// concurrent call the time to run, to widen the race between the lookup and the write of
+// a rotation
+type pausingMemStore struct {
+	in_memory.MemStore
+	sessionId string
+	paused    chan struct{}
+}
+
+func (p *pausingMemStore) CommitBatch(ctx context.Context, items map[string]models.Item) error {
+	if _, found := items[p.sessionId]; !found {
+		return p.MemStore.CommitBatch(ctx, items)
+	}
+	close(p.paused)
+	time.Sleep(50 * time.Millisecond)
+	return p.MemStore.CommitBatch(ctx, items)
+}
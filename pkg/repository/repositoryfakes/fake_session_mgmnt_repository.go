@@ -2,6 +2,7 @@
 package repositoryfakes
 
 import (
+	"context"
 	"sync"
 	"time"
 
@@ -10,11 +11,13 @@ import (
 )
 
 type FakeSessionMgmntRepository struct {
-	CreateStub        func(string, time.Time) error
+	CreateStub        func(context.Context, string, *models.SessionRequest, time.Time) error
 	createMutex       sync.RWMutex
 	createArgsForCall []struct {
-		arg1 string
-		arg2 time.Time
+		arg1 context.Context
+		arg2 string
+		arg3 *models.SessionRequest
+		arg4 time.Time
 	}
 	createReturns struct {
 		result1 error
@@ -22,10 +25,23 @@ type FakeSessionMgmntRepository struct {
 	createReturnsOnCall map[int]struct {
 		result1 error
 	}
-	DestroyStub        func(*models.DestroyRequest) error
+	CreateBatchStub        func(context.Context, []repository.NewSession) error
+	createBatchMutex       sync.RWMutex
+	createBatchArgsForCall []struct {
+		arg1 context.Context
+		arg2 []repository.NewSession
+	}
+	createBatchReturns struct {
+		result1 error
+	}
+	createBatchReturnsOnCall map[int]struct {
+		result1 error
+	}
+	DestroyStub        func(context.Context, *models.DestroyRequest) error
 	destroyMutex       sync.RWMutex
 	destroyArgsForCall []struct {
-		arg1 *models.DestroyRequest
+		arg1 context.Context
+		arg2 *models.DestroyRequest
 	}
 	destroyReturns struct {
 		result1 error
@@ -33,10 +49,39 @@ type FakeSessionMgmntRepository struct {
 	destroyReturnsOnCall map[int]struct {
 		result1 error
 	}
-	ExistStub        func(string) (bool, error)
+	DestroyBatchStub        func(context.Context, []string) ([]bool, error)
+	destroyBatchMutex       sync.RWMutex
+	destroyBatchArgsForCall []struct {
+		arg1 context.Context
+		arg2 []string
+	}
+	destroyBatchReturns struct {
+		result1 []bool
+		result2 error
+	}
+	destroyBatchReturnsOnCall map[int]struct {
+		result1 []bool
+		result2 error
+	}
+	DestroyBySubjectStub        func(context.Context, string) (*models.Sessions, error)
+	destroyBySubjectMutex       sync.RWMutex
+	destroyBySubjectArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+	}
+	destroyBySubjectReturns struct {
+		result1 *models.Sessions
+		result2 error
+	}
+	destroyBySubjectReturnsOnCall map[int]struct {
+		result1 *models.Sessions
+		result2 error
+	}
+	ExistStub        func(context.Context, string) (bool, error)
 	existMutex       sync.RWMutex
 	existArgsForCall []struct {
-		arg1 string
+		arg1 context.Context
+		arg2 string
 	}
 	existReturns struct {
 		result1 bool
@@ -46,10 +91,11 @@ type FakeSessionMgmntRepository struct {
 		result1 bool
 		result2 error
 	}
-	ExtendStub        func(*models.ExtendRequest) (bool, error)
+	ExtendStub        func(context.Context, *models.ExtendRequest) (bool, error)
 	extendMutex       sync.RWMutex
 	extendArgsForCall []struct {
-		arg1 *models.ExtendRequest
+		arg1 context.Context
+		arg2 *models.ExtendRequest
 	}
 	extendReturns struct {
 		result1 bool
@@ -59,9 +105,57 @@ type FakeSessionMgmntRepository struct {
 		result1 bool
 		result2 error
 	}
-	ListStub        func() (*models.Sessions, error)
+	ExtendBatchStub        func(context.Context, []models.ExtendRequest) ([]bool, error)
+	extendBatchMutex       sync.RWMutex
+	extendBatchArgsForCall []struct {
+		arg1 context.Context
+		arg2 []models.ExtendRequest
+	}
+	extendBatchReturns struct {
+		result1 []bool
+		result2 error
+	}
+	extendBatchReturnsOnCall map[int]struct {
+		result1 []bool
+		result2 error
+	}
+	GetStub        func(context.Context, string) (*models.SessionDetails, bool, error)
+	getMutex       sync.RWMutex
+	getArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+	}
+	getReturns struct {
+		result1 *models.SessionDetails
+		result2 bool
+		result3 error
+	}
+	getReturnsOnCall map[int]struct {
+		result1 *models.SessionDetails
+		result2 bool
+		result3 error
+	}
+	GetDataStub        func(context.Context, string) (map[string]interface{}, bool, error)
+	getDataMutex       sync.RWMutex
+	getDataArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+	}
+	getDataReturns struct {
+		result1 map[string]interface{}
+		result2 bool
+		result3 error
+	}
+	getDataReturnsOnCall map[int]struct {
+		result1 map[string]interface{}
+		result2 bool
+		result3 error
+	}
+	ListStub        func(context.Context, *models.ListRequest) (*models.Sessions, error)
 	listMutex       sync.RWMutex
 	listArgsForCall []struct {
+		arg1 context.Context
+		arg2 *models.ListRequest
 	}
 	listReturns struct {
 		result1 *models.Sessions
@@ -71,23 +165,137 @@ type FakeSessionMgmntRepository struct {
 		result1 *models.Sessions
 		result2 error
 	}
+	ListBySubjectStub        func(context.Context, string) (*models.Sessions, error)
+	listBySubjectMutex       sync.RWMutex
+	listBySubjectArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+	}
+	listBySubjectReturns struct {
+		result1 *models.Sessions
+		result2 error
+	}
+	listBySubjectReturnsOnCall map[int]struct {
+		result1 *models.Sessions
+		result2 error
+	}
+	ListRevokedStub        func(context.Context) (*models.Revocations, error)
+	listRevokedMutex       sync.RWMutex
+	listRevokedArgsForCall []struct {
+		arg1 context.Context
+	}
+	listRevokedReturns struct {
+		result1 *models.Revocations
+		result2 error
+	}
+	listRevokedReturnsOnCall map[int]struct {
+		result1 *models.Revocations
+		result2 error
+	}
+	ListSubjectDetailsStub        func(context.Context, string) ([]*models.SessionDetails, error)
+	listSubjectDetailsMutex       sync.RWMutex
+	listSubjectDetailsArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+	}
+	listSubjectDetailsReturns struct {
+		result1 []*models.SessionDetails
+		result2 error
+	}
+	listSubjectDetailsReturnsOnCall map[int]struct {
+		result1 []*models.SessionDetails
+		result2 error
+	}
+	PatchDataStub        func(context.Context, *models.SessionDataRequest) (bool, error)
+	patchDataMutex       sync.RWMutex
+	patchDataArgsForCall []struct {
+		arg1 context.Context
+		arg2 *models.SessionDataRequest
+	}
+	patchDataReturns struct {
+		result1 bool
+		result2 error
+	}
+	patchDataReturnsOnCall map[int]struct {
+		result1 bool
+		result2 error
+	}
+	RevokeStub        func(context.Context, string, time.Time) error
+	revokeMutex       sync.RWMutex
+	revokeArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 time.Time
+	}
+	revokeReturns struct {
+		result1 error
+	}
+	revokeReturnsOnCall map[int]struct {
+		result1 error
+	}
+	RevokedStub        func(context.Context, string) (bool, error)
+	revokedMutex       sync.RWMutex
+	revokedArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+	}
+	revokedReturns struct {
+		result1 bool
+		result2 error
+	}
+	revokedReturnsOnCall map[int]struct {
+		result1 bool
+		result2 error
+	}
+	RotateStub        func(context.Context, string, string, time.Duration) (bool, error)
+	rotateMutex       sync.RWMutex
+	rotateArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+		arg4 time.Duration
+	}
+	rotateReturns struct {
+		result1 bool
+		result2 error
+	}
+	rotateReturnsOnCall map[int]struct {
+		result1 bool
+		result2 error
+	}
+	SetDataStub        func(context.Context, *models.SessionDataRequest) (bool, error)
+	setDataMutex       sync.RWMutex
+	setDataArgsForCall []struct {
+		arg1 context.Context
+		arg2 *models.SessionDataRequest
+	}
+	setDataReturns struct {
+		result1 bool
+		result2 error
+	}
+	setDataReturnsOnCall map[int]struct {
+		result1 bool
+		result2 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
 
-func (fake *FakeSessionMgmntRepository) Create(arg1 string, arg2 time.Time) error {
+func (fake *FakeSessionMgmntRepository) Create(arg1 context.Context, arg2 string, arg3 *models.SessionRequest, arg4 time.Time) error {
 	fake.createMutex.Lock()
 	ret, specificReturn := fake.createReturnsOnCall[len(fake.createArgsForCall)]
 	fake.createArgsForCall = append(fake.createArgsForCall, struct {
-		arg1 string
-		arg2 time.Time
-	}{arg1, arg2})
+		arg1 context.Context
+		arg2 string
+		arg3 *models.SessionRequest
+		arg4 time.Time
+	}{arg1, arg2, arg3, arg4})
 	stub := fake.CreateStub
 	fakeReturns := fake.createReturns
-	fake.recordInvocation("Create", []interface{}{arg1, arg2})
+	fake.recordInvocation("Create", []interface{}{arg1, arg2, arg3, arg4})
 	fake.createMutex.Unlock()
 	if stub != nil {
-		return stub(arg1, arg2)
+		return stub(arg1, arg2, arg3, arg4)
 	}
 	if specificReturn {
 		return ret.result1
@@ -101,17 +309,17 @@ func (fake *FakeSessionMgmntRepository) CreateCallCount() int {
 	return len(fake.createArgsForCall)
 }
 
-func (fake *FakeSessionMgmntRepository) CreateCalls(stub func(string, time.Time) error) {
+func (fake *FakeSessionMgmntRepository) CreateCalls(stub func(context.Context, string, *models.SessionRequest, time.Time) error) {
 	fake.createMutex.Lock()
 	defer fake.createMutex.Unlock()
 	fake.CreateStub = stub
 }
 
-func (fake *FakeSessionMgmntRepository) CreateArgsForCall(i int) (string, time.Time) {
+func (fake *FakeSessionMgmntRepository) CreateArgsForCall(i int) (context.Context, string, *models.SessionRequest, time.Time) {
 	fake.createMutex.RLock()
 	defer fake.createMutex.RUnlock()
 	argsForCall := fake.createArgsForCall[i]
-	return argsForCall.arg1, argsForCall.arg2
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
 }
 
 func (fake *FakeSessionMgmntRepository) CreateReturns(result1 error) {
@@ -137,18 +345,86 @@ func (fake *FakeSessionMgmntRepository) CreateReturnsOnCall(i int, result1 error
 	}{result1}
 }
 
-func (fake *FakeSessionMgmntRepository) Destroy(arg1 *models.DestroyRequest) error {
+func (fake *FakeSessionMgmntRepository) CreateBatch(arg1 context.Context, arg2 []repository.NewSession) error {
+	var arg2Copy []repository.NewSession
+	if arg2 != nil {
+		arg2Copy = make([]repository.NewSession, len(arg2))
+		copy(arg2Copy, arg2)
+	}
+	fake.createBatchMutex.Lock()
+	ret, specificReturn := fake.createBatchReturnsOnCall[len(fake.createBatchArgsForCall)]
+	fake.createBatchArgsForCall = append(fake.createBatchArgsForCall, struct {
+		arg1 context.Context
+		arg2 []repository.NewSession
+	}{arg1, arg2Copy})
+	stub := fake.CreateBatchStub
+	fakeReturns := fake.createBatchReturns
+	fake.recordInvocation("CreateBatch", []interface{}{arg1, arg2Copy})
+	fake.createBatchMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeSessionMgmntRepository) CreateBatchCallCount() int {
+	fake.createBatchMutex.RLock()
+	defer fake.createBatchMutex.RUnlock()
+	return len(fake.createBatchArgsForCall)
+}
+
+func (fake *FakeSessionMgmntRepository) CreateBatchCalls(stub func(context.Context, []repository.NewSession) error) {
+	fake.createBatchMutex.Lock()
+	defer fake.createBatchMutex.Unlock()
+	fake.CreateBatchStub = stub
+}
+
+func (fake *FakeSessionMgmntRepository) CreateBatchArgsForCall(i int) (context.Context, []repository.NewSession) {
+	fake.createBatchMutex.RLock()
+	defer fake.createBatchMutex.RUnlock()
+	argsForCall := fake.createBatchArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeSessionMgmntRepository) CreateBatchReturns(result1 error) {
+	fake.createBatchMutex.Lock()
+	defer fake.createBatchMutex.Unlock()
+	fake.CreateBatchStub = nil
+	fake.createBatchReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeSessionMgmntRepository) CreateBatchReturnsOnCall(i int, result1 error) {
+	fake.createBatchMutex.Lock()
+	defer fake.createBatchMutex.Unlock()
+	fake.CreateBatchStub = nil
+	if fake.createBatchReturnsOnCall == nil {
+		fake.createBatchReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.createBatchReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeSessionMgmntRepository) Destroy(arg1 context.Context, arg2 *models.DestroyRequest) error {
 	fake.destroyMutex.Lock()
 	ret, specificReturn := fake.destroyReturnsOnCall[len(fake.destroyArgsForCall)]
 	fake.destroyArgsForCall = append(fake.destroyArgsForCall, struct {
-		arg1 *models.DestroyRequest
-	}{arg1})
+		arg1 context.Context
+		arg2 *models.DestroyRequest
+	}{arg1, arg2})
 	stub := fake.DestroyStub
 	fakeReturns := fake.destroyReturns
-	fake.recordInvocation("Destroy", []interface{}{arg1})
+	fake.recordInvocation("Destroy", []interface{}{arg1, arg2})
 	fake.destroyMutex.Unlock()
 	if stub != nil {
-		return stub(arg1)
+		return stub(arg1, arg2)
 	}
 	if specificReturn {
 		return ret.result1
@@ -162,17 +438,17 @@ func (fake *FakeSessionMgmntRepository) DestroyCallCount() int {
 	return len(fake.destroyArgsForCall)
 }
 
-func (fake *FakeSessionMgmntRepository) DestroyCalls(stub func(*models.DestroyRequest) error) {
+func (fake *FakeSessionMgmntRepository) DestroyCalls(stub func(context.Context, *models.DestroyRequest) error) {
 	fake.destroyMutex.Lock()
 	defer fake.destroyMutex.Unlock()
 	fake.DestroyStub = stub
 }
 
-func (fake *FakeSessionMgmntRepository) DestroyArgsForCall(i int) *models.DestroyRequest {
+func (fake *FakeSessionMgmntRepository) DestroyArgsForCall(i int) (context.Context, *models.DestroyRequest) {
 	fake.destroyMutex.RLock()
 	defer fake.destroyMutex.RUnlock()
 	argsForCall := fake.destroyArgsForCall[i]
-	return argsForCall.arg1
+	return argsForCall.arg1, argsForCall.arg2
 }
 
 func (fake *FakeSessionMgmntRepository) DestroyReturns(result1 error) {
@@ -198,18 +474,154 @@ func (fake *FakeSessionMgmntRepository) DestroyReturnsOnCall(i int, result1 erro
 	}{result1}
 }
 
-func (fake *FakeSessionMgmntRepository) Exist(arg1 string) (bool, error) {
+func (fake *FakeSessionMgmntRepository) DestroyBatch(arg1 context.Context, arg2 []string) ([]bool, error) {
+	var arg2Copy []string
+	if arg2 != nil {
+		arg2Copy = make([]string, len(arg2))
+		copy(arg2Copy, arg2)
+	}
+	fake.destroyBatchMutex.Lock()
+	ret, specificReturn := fake.destroyBatchReturnsOnCall[len(fake.destroyBatchArgsForCall)]
+	fake.destroyBatchArgsForCall = append(fake.destroyBatchArgsForCall, struct {
+		arg1 context.Context
+		arg2 []string
+	}{arg1, arg2Copy})
+	stub := fake.DestroyBatchStub
+	fakeReturns := fake.destroyBatchReturns
+	fake.recordInvocation("DestroyBatch", []interface{}{arg1, arg2Copy})
+	fake.destroyBatchMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeSessionMgmntRepository) DestroyBatchCallCount() int {
+	fake.destroyBatchMutex.RLock()
+	defer fake.destroyBatchMutex.RUnlock()
+	return len(fake.destroyBatchArgsForCall)
+}
+
+func (fake *FakeSessionMgmntRepository) DestroyBatchCalls(stub func(context.Context, []string) ([]bool, error)) {
+	fake.destroyBatchMutex.Lock()
+	defer fake.destroyBatchMutex.Unlock()
+	fake.DestroyBatchStub = stub
+}
+
+func (fake *FakeSessionMgmntRepository) DestroyBatchArgsForCall(i int) (context.Context, []string) {
+	fake.destroyBatchMutex.RLock()
+	defer fake.destroyBatchMutex.RUnlock()
+	argsForCall := fake.destroyBatchArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeSessionMgmntRepository) DestroyBatchReturns(result1 []bool, result2 error) {
+	fake.destroyBatchMutex.Lock()
+	defer fake.destroyBatchMutex.Unlock()
+	fake.DestroyBatchStub = nil
+	fake.destroyBatchReturns = struct {
+		result1 []bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSessionMgmntRepository) DestroyBatchReturnsOnCall(i int, result1 []bool, result2 error) {
+	fake.destroyBatchMutex.Lock()
+	defer fake.destroyBatchMutex.Unlock()
+	fake.DestroyBatchStub = nil
+	if fake.destroyBatchReturnsOnCall == nil {
+		fake.destroyBatchReturnsOnCall = make(map[int]struct {
+			result1 []bool
+			result2 error
+		})
+	}
+	fake.destroyBatchReturnsOnCall[i] = struct {
+		result1 []bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSessionMgmntRepository) DestroyBySubject(arg1 context.Context, arg2 string) (*models.Sessions, error) {
+	fake.destroyBySubjectMutex.Lock()
+	ret, specificReturn := fake.destroyBySubjectReturnsOnCall[len(fake.destroyBySubjectArgsForCall)]
+	fake.destroyBySubjectArgsForCall = append(fake.destroyBySubjectArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.DestroyBySubjectStub
+	fakeReturns := fake.destroyBySubjectReturns
+	fake.recordInvocation("DestroyBySubject", []interface{}{arg1, arg2})
+	fake.destroyBySubjectMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeSessionMgmntRepository) DestroyBySubjectCallCount() int {
+	fake.destroyBySubjectMutex.RLock()
+	defer fake.destroyBySubjectMutex.RUnlock()
+	return len(fake.destroyBySubjectArgsForCall)
+}
+
+func (fake *FakeSessionMgmntRepository) DestroyBySubjectCalls(stub func(context.Context, string) (*models.Sessions, error)) {
+	fake.destroyBySubjectMutex.Lock()
+	defer fake.destroyBySubjectMutex.Unlock()
+	fake.DestroyBySubjectStub = stub
+}
+
+func (fake *FakeSessionMgmntRepository) DestroyBySubjectArgsForCall(i int) (context.Context, string) {
+	fake.destroyBySubjectMutex.RLock()
+	defer fake.destroyBySubjectMutex.RUnlock()
+	argsForCall := fake.destroyBySubjectArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeSessionMgmntRepository) DestroyBySubjectReturns(result1 *models.Sessions, result2 error) {
+	fake.destroyBySubjectMutex.Lock()
+	defer fake.destroyBySubjectMutex.Unlock()
+	fake.DestroyBySubjectStub = nil
+	fake.destroyBySubjectReturns = struct {
+		result1 *models.Sessions
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSessionMgmntRepository) DestroyBySubjectReturnsOnCall(i int, result1 *models.Sessions, result2 error) {
+	fake.destroyBySubjectMutex.Lock()
+	defer fake.destroyBySubjectMutex.Unlock()
+	fake.DestroyBySubjectStub = nil
+	if fake.destroyBySubjectReturnsOnCall == nil {
+		fake.destroyBySubjectReturnsOnCall = make(map[int]struct {
+			result1 *models.Sessions
+			result2 error
+		})
+	}
+	fake.destroyBySubjectReturnsOnCall[i] = struct {
+		result1 *models.Sessions
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSessionMgmntRepository) Exist(arg1 context.Context, arg2 string) (bool, error) {
 	fake.existMutex.Lock()
 	ret, specificReturn := fake.existReturnsOnCall[len(fake.existArgsForCall)]
 	fake.existArgsForCall = append(fake.existArgsForCall, struct {
-		arg1 string
-	}{arg1})
+		arg1 context.Context
+		arg2 string
+	}{arg1, arg2})
 	stub := fake.ExistStub
 	fakeReturns := fake.existReturns
-	fake.recordInvocation("Exist", []interface{}{arg1})
+	fake.recordInvocation("Exist", []interface{}{arg1, arg2})
 	fake.existMutex.Unlock()
 	if stub != nil {
-		return stub(arg1)
+		return stub(arg1, arg2)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
@@ -223,17 +635,17 @@ func (fake *FakeSessionMgmntRepository) ExistCallCount() int {
 	return len(fake.existArgsForCall)
 }
 
-func (fake *FakeSessionMgmntRepository) ExistCalls(stub func(string) (bool, error)) {
+func (fake *FakeSessionMgmntRepository) ExistCalls(stub func(context.Context, string) (bool, error)) {
 	fake.existMutex.Lock()
 	defer fake.existMutex.Unlock()
 	fake.ExistStub = stub
 }
 
-func (fake *FakeSessionMgmntRepository) ExistArgsForCall(i int) string {
+func (fake *FakeSessionMgmntRepository) ExistArgsForCall(i int) (context.Context, string) {
 	fake.existMutex.RLock()
 	defer fake.existMutex.RUnlock()
 	argsForCall := fake.existArgsForCall[i]
-	return argsForCall.arg1
+	return argsForCall.arg1, argsForCall.arg2
 }
 
 func (fake *FakeSessionMgmntRepository) ExistReturns(result1 bool, result2 error) {
@@ -262,18 +674,19 @@ func (fake *FakeSessionMgmntRepository) ExistReturnsOnCall(i int, result1 bool,
 	}{result1, result2}
 }
 
-func (fake *FakeSessionMgmntRepository) Extend(arg1 *models.ExtendRequest) (bool, error) {
+func (fake *FakeSessionMgmntRepository) Extend(arg1 context.Context, arg2 *models.ExtendRequest) (bool, error) {
 	fake.extendMutex.Lock()
 	ret, specificReturn := fake.extendReturnsOnCall[len(fake.extendArgsForCall)]
 	fake.extendArgsForCall = append(fake.extendArgsForCall, struct {
-		arg1 *models.ExtendRequest
-	}{arg1})
+		arg1 context.Context
+		arg2 *models.ExtendRequest
+	}{arg1, arg2})
 	stub := fake.ExtendStub
 	fakeReturns := fake.extendReturns
-	fake.recordInvocation("Extend", []interface{}{arg1})
+	fake.recordInvocation("Extend", []interface{}{arg1, arg2})
 	fake.extendMutex.Unlock()
 	if stub != nil {
-		return stub(arg1)
+		return stub(arg1, arg2)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
@@ -287,17 +700,17 @@ func (fake *FakeSessionMgmntRepository) ExtendCallCount() int {
 	return len(fake.extendArgsForCall)
 }
 
-func (fake *FakeSessionMgmntRepository) ExtendCalls(stub func(*models.ExtendRequest) (bool, error)) {
+func (fake *FakeSessionMgmntRepository) ExtendCalls(stub func(context.Context, *models.ExtendRequest) (bool, error)) {
 	fake.extendMutex.Lock()
 	defer fake.extendMutex.Unlock()
 	fake.ExtendStub = stub
 }
 
-func (fake *FakeSessionMgmntRepository) ExtendArgsForCall(i int) *models.ExtendRequest {
+func (fake *FakeSessionMgmntRepository) ExtendArgsForCall(i int) (context.Context, *models.ExtendRequest) {
 	fake.extendMutex.RLock()
 	defer fake.extendMutex.RUnlock()
 	argsForCall := fake.extendArgsForCall[i]
-	return argsForCall.arg1
+	return argsForCall.arg1, argsForCall.arg2
 }
 
 func (fake *FakeSessionMgmntRepository) ExtendReturns(result1 bool, result2 error) {
@@ -326,17 +739,225 @@ func (fake *FakeSessionMgmntRepository) ExtendReturnsOnCall(i int, result1 bool,
 	}{result1, result2}
 }
 
-func (fake *FakeSessionMgmntRepository) List() (*models.Sessions, error) {
+func (fake *FakeSessionMgmntRepository) ExtendBatch(arg1 context.Context, arg2 []models.ExtendRequest) ([]bool, error) {
+	var arg2Copy []models.ExtendRequest
+	if arg2 != nil {
+		arg2Copy = make([]models.ExtendRequest, len(arg2))
+		copy(arg2Copy, arg2)
+	}
+	fake.extendBatchMutex.Lock()
+	ret, specificReturn := fake.extendBatchReturnsOnCall[len(fake.extendBatchArgsForCall)]
+	fake.extendBatchArgsForCall = append(fake.extendBatchArgsForCall, struct {
+		arg1 context.Context
+		arg2 []models.ExtendRequest
+	}{arg1, arg2Copy})
+	stub := fake.ExtendBatchStub
+	fakeReturns := fake.extendBatchReturns
+	fake.recordInvocation("ExtendBatch", []interface{}{arg1, arg2Copy})
+	fake.extendBatchMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeSessionMgmntRepository) ExtendBatchCallCount() int {
+	fake.extendBatchMutex.RLock()
+	defer fake.extendBatchMutex.RUnlock()
+	return len(fake.extendBatchArgsForCall)
+}
+
+func (fake *FakeSessionMgmntRepository) ExtendBatchCalls(stub func(context.Context, []models.ExtendRequest) ([]bool, error)) {
+	fake.extendBatchMutex.Lock()
+	defer fake.extendBatchMutex.Unlock()
+	fake.ExtendBatchStub = stub
+}
+
+func (fake *FakeSessionMgmntRepository) ExtendBatchArgsForCall(i int) (context.Context, []models.ExtendRequest) {
+	fake.extendBatchMutex.RLock()
+	defer fake.extendBatchMutex.RUnlock()
+	argsForCall := fake.extendBatchArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeSessionMgmntRepository) ExtendBatchReturns(result1 []bool, result2 error) {
+	fake.extendBatchMutex.Lock()
+	defer fake.extendBatchMutex.Unlock()
+	fake.ExtendBatchStub = nil
+	fake.extendBatchReturns = struct {
+		result1 []bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSessionMgmntRepository) ExtendBatchReturnsOnCall(i int, result1 []bool, result2 error) {
+	fake.extendBatchMutex.Lock()
+	defer fake.extendBatchMutex.Unlock()
+	fake.ExtendBatchStub = nil
+	if fake.extendBatchReturnsOnCall == nil {
+		fake.extendBatchReturnsOnCall = make(map[int]struct {
+			result1 []bool
+			result2 error
+		})
+	}
+	fake.extendBatchReturnsOnCall[i] = struct {
+		result1 []bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSessionMgmntRepository) Get(arg1 context.Context, arg2 string) (*models.SessionDetails, bool, error) {
+	fake.getMutex.Lock()
+	ret, specificReturn := fake.getReturnsOnCall[len(fake.getArgsForCall)]
+	fake.getArgsForCall = append(fake.getArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.GetStub
+	fakeReturns := fake.getReturns
+	fake.recordInvocation("Get", []interface{}{arg1, arg2})
+	fake.getMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2, ret.result3
+	}
+	return fakeReturns.result1, fakeReturns.result2, fakeReturns.result3
+}
+
+func (fake *FakeSessionMgmntRepository) GetCallCount() int {
+	fake.getMutex.RLock()
+	defer fake.getMutex.RUnlock()
+	return len(fake.getArgsForCall)
+}
+
+func (fake *FakeSessionMgmntRepository) GetCalls(stub func(context.Context, string) (*models.SessionDetails, bool, error)) {
+	fake.getMutex.Lock()
+	defer fake.getMutex.Unlock()
+	fake.GetStub = stub
+}
+
+func (fake *FakeSessionMgmntRepository) GetArgsForCall(i int) (context.Context, string) {
+	fake.getMutex.RLock()
+	defer fake.getMutex.RUnlock()
+	argsForCall := fake.getArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeSessionMgmntRepository) GetReturns(result1 *models.SessionDetails, result2 bool, result3 error) {
+	fake.getMutex.Lock()
+	defer fake.getMutex.Unlock()
+	fake.GetStub = nil
+	fake.getReturns = struct {
+		result1 *models.SessionDetails
+		result2 bool
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeSessionMgmntRepository) GetReturnsOnCall(i int, result1 *models.SessionDetails, result2 bool, result3 error) {
+	fake.getMutex.Lock()
+	defer fake.getMutex.Unlock()
+	fake.GetStub = nil
+	if fake.getReturnsOnCall == nil {
+		fake.getReturnsOnCall = make(map[int]struct {
+			result1 *models.SessionDetails
+			result2 bool
+			result3 error
+		})
+	}
+	fake.getReturnsOnCall[i] = struct {
+		result1 *models.SessionDetails
+		result2 bool
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeSessionMgmntRepository) GetData(arg1 context.Context, arg2 string) (map[string]interface{}, bool, error) {
+	fake.getDataMutex.Lock()
+	ret, specificReturn := fake.getDataReturnsOnCall[len(fake.getDataArgsForCall)]
+	fake.getDataArgsForCall = append(fake.getDataArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.GetDataStub
+	fakeReturns := fake.getDataReturns
+	fake.recordInvocation("GetData", []interface{}{arg1, arg2})
+	fake.getDataMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2, ret.result3
+	}
+	return fakeReturns.result1, fakeReturns.result2, fakeReturns.result3
+}
+
+func (fake *FakeSessionMgmntRepository) GetDataCallCount() int {
+	fake.getDataMutex.RLock()
+	defer fake.getDataMutex.RUnlock()
+	return len(fake.getDataArgsForCall)
+}
+
+func (fake *FakeSessionMgmntRepository) GetDataCalls(stub func(context.Context, string) (map[string]interface{}, bool, error)) {
+	fake.getDataMutex.Lock()
+	defer fake.getDataMutex.Unlock()
+	fake.GetDataStub = stub
+}
+
+func (fake *FakeSessionMgmntRepository) GetDataArgsForCall(i int) (context.Context, string) {
+	fake.getDataMutex.RLock()
+	defer fake.getDataMutex.RUnlock()
+	argsForCall := fake.getDataArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeSessionMgmntRepository) GetDataReturns(result1 map[string]interface{}, result2 bool, result3 error) {
+	fake.getDataMutex.Lock()
+	defer fake.getDataMutex.Unlock()
+	fake.GetDataStub = nil
+	fake.getDataReturns = struct {
+		result1 map[string]interface{}
+		result2 bool
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeSessionMgmntRepository) GetDataReturnsOnCall(i int, result1 map[string]interface{}, result2 bool, result3 error) {
+	fake.getDataMutex.Lock()
+	defer fake.getDataMutex.Unlock()
+	fake.GetDataStub = nil
+	if fake.getDataReturnsOnCall == nil {
+		fake.getDataReturnsOnCall = make(map[int]struct {
+			result1 map[string]interface{}
+			result2 bool
+			result3 error
+		})
+	}
+	fake.getDataReturnsOnCall[i] = struct {
+		result1 map[string]interface{}
+		result2 bool
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeSessionMgmntRepository) List(arg1 context.Context, arg2 *models.ListRequest) (*models.Sessions, error) {
 	fake.listMutex.Lock()
 	ret, specificReturn := fake.listReturnsOnCall[len(fake.listArgsForCall)]
 	fake.listArgsForCall = append(fake.listArgsForCall, struct {
-	}{})
+		arg1 context.Context
+		arg2 *models.ListRequest
+	}{arg1, arg2})
 	stub := fake.ListStub
 	fakeReturns := fake.listReturns
-	fake.recordInvocation("List", []interface{}{})
+	fake.recordInvocation("List", []interface{}{arg1, arg2})
 	fake.listMutex.Unlock()
 	if stub != nil {
-		return stub()
+		return stub(arg1, arg2)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
@@ -350,12 +971,19 @@ func (fake *FakeSessionMgmntRepository) ListCallCount() int {
 	return len(fake.listArgsForCall)
 }
 
-func (fake *FakeSessionMgmntRepository) ListCalls(stub func() (*models.Sessions, error)) {
+func (fake *FakeSessionMgmntRepository) ListCalls(stub func(context.Context, *models.ListRequest) (*models.Sessions, error)) {
 	fake.listMutex.Lock()
 	defer fake.listMutex.Unlock()
 	fake.ListStub = stub
 }
 
+func (fake *FakeSessionMgmntRepository) ListArgsForCall(i int) (context.Context, *models.ListRequest) {
+	fake.listMutex.RLock()
+	defer fake.listMutex.RUnlock()
+	argsForCall := fake.listArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
 func (fake *FakeSessionMgmntRepository) ListReturns(result1 *models.Sessions, result2 error) {
 	fake.listMutex.Lock()
 	defer fake.listMutex.Unlock()
@@ -382,19 +1010,566 @@ func (fake *FakeSessionMgmntRepository) ListReturnsOnCall(i int, result1 *models
 	}{result1, result2}
 }
 
+func (fake *FakeSessionMgmntRepository) ListBySubject(arg1 context.Context, arg2 string) (*models.Sessions, error) {
+	fake.listBySubjectMutex.Lock()
+	ret, specificReturn := fake.listBySubjectReturnsOnCall[len(fake.listBySubjectArgsForCall)]
+	fake.listBySubjectArgsForCall = append(fake.listBySubjectArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.ListBySubjectStub
+	fakeReturns := fake.listBySubjectReturns
+	fake.recordInvocation("ListBySubject", []interface{}{arg1, arg2})
+	fake.listBySubjectMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeSessionMgmntRepository) ListBySubjectCallCount() int {
+	fake.listBySubjectMutex.RLock()
+	defer fake.listBySubjectMutex.RUnlock()
+	return len(fake.listBySubjectArgsForCall)
+}
+
+func (fake *FakeSessionMgmntRepository) ListBySubjectCalls(stub func(context.Context, string) (*models.Sessions, error)) {
+	fake.listBySubjectMutex.Lock()
+	defer fake.listBySubjectMutex.Unlock()
+	fake.ListBySubjectStub = stub
+}
+
+func (fake *FakeSessionMgmntRepository) ListBySubjectArgsForCall(i int) (context.Context, string) {
+	fake.listBySubjectMutex.RLock()
+	defer fake.listBySubjectMutex.RUnlock()
+	argsForCall := fake.listBySubjectArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeSessionMgmntRepository) ListBySubjectReturns(result1 *models.Sessions, result2 error) {
+	fake.listBySubjectMutex.Lock()
+	defer fake.listBySubjectMutex.Unlock()
+	fake.ListBySubjectStub = nil
+	fake.listBySubjectReturns = struct {
+		result1 *models.Sessions
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSessionMgmntRepository) ListBySubjectReturnsOnCall(i int, result1 *models.Sessions, result2 error) {
+	fake.listBySubjectMutex.Lock()
+	defer fake.listBySubjectMutex.Unlock()
+	fake.ListBySubjectStub = nil
+	if fake.listBySubjectReturnsOnCall == nil {
+		fake.listBySubjectReturnsOnCall = make(map[int]struct {
+			result1 *models.Sessions
+			result2 error
+		})
+	}
+	fake.listBySubjectReturnsOnCall[i] = struct {
+		result1 *models.Sessions
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSessionMgmntRepository) ListRevoked(arg1 context.Context) (*models.Revocations, error) {
+	fake.listRevokedMutex.Lock()
+	ret, specificReturn := fake.listRevokedReturnsOnCall[len(fake.listRevokedArgsForCall)]
+	fake.listRevokedArgsForCall = append(fake.listRevokedArgsForCall, struct {
+		arg1 context.Context
+	}{arg1})
+	stub := fake.ListRevokedStub
+	fakeReturns := fake.listRevokedReturns
+	fake.recordInvocation("ListRevoked", []interface{}{arg1})
+	fake.listRevokedMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeSessionMgmntRepository) ListRevokedCallCount() int {
+	fake.listRevokedMutex.RLock()
+	defer fake.listRevokedMutex.RUnlock()
+	return len(fake.listRevokedArgsForCall)
+}
+
+func (fake *FakeSessionMgmntRepository) ListRevokedCalls(stub func(context.Context) (*models.Revocations, error)) {
+	fake.listRevokedMutex.Lock()
+	defer fake.listRevokedMutex.Unlock()
+	fake.ListRevokedStub = stub
+}
+
+func (fake *FakeSessionMgmntRepository) ListRevokedArgsForCall(i int) context.Context {
+	fake.listRevokedMutex.RLock()
+	defer fake.listRevokedMutex.RUnlock()
+	argsForCall := fake.listRevokedArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeSessionMgmntRepository) ListRevokedReturns(result1 *models.Revocations, result2 error) {
+	fake.listRevokedMutex.Lock()
+	defer fake.listRevokedMutex.Unlock()
+	fake.ListRevokedStub = nil
+	fake.listRevokedReturns = struct {
+		result1 *models.Revocations
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSessionMgmntRepository) ListRevokedReturnsOnCall(i int, result1 *models.Revocations, result2 error) {
+	fake.listRevokedMutex.Lock()
+	defer fake.listRevokedMutex.Unlock()
+	fake.ListRevokedStub = nil
+	if fake.listRevokedReturnsOnCall == nil {
+		fake.listRevokedReturnsOnCall = make(map[int]struct {
+			result1 *models.Revocations
+			result2 error
+		})
+	}
+	fake.listRevokedReturnsOnCall[i] = struct {
+		result1 *models.Revocations
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSessionMgmntRepository) ListSubjectDetails(arg1 context.Context, arg2 string) ([]*models.SessionDetails, error) {
+	fake.listSubjectDetailsMutex.Lock()
+	ret, specificReturn := fake.listSubjectDetailsReturnsOnCall[len(fake.listSubjectDetailsArgsForCall)]
+	fake.listSubjectDetailsArgsForCall = append(fake.listSubjectDetailsArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.ListSubjectDetailsStub
+	fakeReturns := fake.listSubjectDetailsReturns
+	fake.recordInvocation("ListSubjectDetails", []interface{}{arg1, arg2})
+	fake.listSubjectDetailsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeSessionMgmntRepository) ListSubjectDetailsCallCount() int {
+	fake.listSubjectDetailsMutex.RLock()
+	defer fake.listSubjectDetailsMutex.RUnlock()
+	return len(fake.listSubjectDetailsArgsForCall)
+}
+
+func (fake *FakeSessionMgmntRepository) ListSubjectDetailsCalls(stub func(context.Context, string) ([]*models.SessionDetails, error)) {
+	fake.listSubjectDetailsMutex.Lock()
+	defer fake.listSubjectDetailsMutex.Unlock()
+	fake.ListSubjectDetailsStub = stub
+}
+
+func (fake *FakeSessionMgmntRepository) ListSubjectDetailsArgsForCall(i int) (context.Context, string) {
+	fake.listSubjectDetailsMutex.RLock()
+	defer fake.listSubjectDetailsMutex.RUnlock()
+	argsForCall := fake.listSubjectDetailsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeSessionMgmntRepository) ListSubjectDetailsReturns(result1 []*models.SessionDetails, result2 error) {
+	fake.listSubjectDetailsMutex.Lock()
+	defer fake.listSubjectDetailsMutex.Unlock()
+	fake.ListSubjectDetailsStub = nil
+	fake.listSubjectDetailsReturns = struct {
+		result1 []*models.SessionDetails
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSessionMgmntRepository) ListSubjectDetailsReturnsOnCall(i int, result1 []*models.SessionDetails, result2 error) {
+	fake.listSubjectDetailsMutex.Lock()
+	defer fake.listSubjectDetailsMutex.Unlock()
+	fake.ListSubjectDetailsStub = nil
+	if fake.listSubjectDetailsReturnsOnCall == nil {
+		fake.listSubjectDetailsReturnsOnCall = make(map[int]struct {
+			result1 []*models.SessionDetails
+			result2 error
+		})
+	}
+	fake.listSubjectDetailsReturnsOnCall[i] = struct {
+		result1 []*models.SessionDetails
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSessionMgmntRepository) PatchData(arg1 context.Context, arg2 *models.SessionDataRequest) (bool, error) {
+	fake.patchDataMutex.Lock()
+	ret, specificReturn := fake.patchDataReturnsOnCall[len(fake.patchDataArgsForCall)]
+	fake.patchDataArgsForCall = append(fake.patchDataArgsForCall, struct {
+		arg1 context.Context
+		arg2 *models.SessionDataRequest
+	}{arg1, arg2})
+	stub := fake.PatchDataStub
+	fakeReturns := fake.patchDataReturns
+	fake.recordInvocation("PatchData", []interface{}{arg1, arg2})
+	fake.patchDataMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeSessionMgmntRepository) PatchDataCallCount() int {
+	fake.patchDataMutex.RLock()
+	defer fake.patchDataMutex.RUnlock()
+	return len(fake.patchDataArgsForCall)
+}
+
+func (fake *FakeSessionMgmntRepository) PatchDataCalls(stub func(context.Context, *models.SessionDataRequest) (bool, error)) {
+	fake.patchDataMutex.Lock()
+	defer fake.patchDataMutex.Unlock()
+	fake.PatchDataStub = stub
+}
+
+func (fake *FakeSessionMgmntRepository) PatchDataArgsForCall(i int) (context.Context, *models.SessionDataRequest) {
+	fake.patchDataMutex.RLock()
+	defer fake.patchDataMutex.RUnlock()
+	argsForCall := fake.patchDataArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeSessionMgmntRepository) PatchDataReturns(result1 bool, result2 error) {
+	fake.patchDataMutex.Lock()
+	defer fake.patchDataMutex.Unlock()
+	fake.PatchDataStub = nil
+	fake.patchDataReturns = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSessionMgmntRepository) PatchDataReturnsOnCall(i int, result1 bool, result2 error) {
+	fake.patchDataMutex.Lock()
+	defer fake.patchDataMutex.Unlock()
+	fake.PatchDataStub = nil
+	if fake.patchDataReturnsOnCall == nil {
+		fake.patchDataReturnsOnCall = make(map[int]struct {
+			result1 bool
+			result2 error
+		})
+	}
+	fake.patchDataReturnsOnCall[i] = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSessionMgmntRepository) Revoke(arg1 context.Context, arg2 string, arg3 time.Time) error {
+	fake.revokeMutex.Lock()
+	ret, specificReturn := fake.revokeReturnsOnCall[len(fake.revokeArgsForCall)]
+	fake.revokeArgsForCall = append(fake.revokeArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 time.Time
+	}{arg1, arg2, arg3})
+	stub := fake.RevokeStub
+	fakeReturns := fake.revokeReturns
+	fake.recordInvocation("Revoke", []interface{}{arg1, arg2, arg3})
+	fake.revokeMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeSessionMgmntRepository) RevokeCallCount() int {
+	fake.revokeMutex.RLock()
+	defer fake.revokeMutex.RUnlock()
+	return len(fake.revokeArgsForCall)
+}
+
+func (fake *FakeSessionMgmntRepository) RevokeCalls(stub func(context.Context, string, time.Time) error) {
+	fake.revokeMutex.Lock()
+	defer fake.revokeMutex.Unlock()
+	fake.RevokeStub = stub
+}
+
+func (fake *FakeSessionMgmntRepository) RevokeArgsForCall(i int) (context.Context, string, time.Time) {
+	fake.revokeMutex.RLock()
+	defer fake.revokeMutex.RUnlock()
+	argsForCall := fake.revokeArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeSessionMgmntRepository) RevokeReturns(result1 error) {
+	fake.revokeMutex.Lock()
+	defer fake.revokeMutex.Unlock()
+	fake.RevokeStub = nil
+	fake.revokeReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeSessionMgmntRepository) RevokeReturnsOnCall(i int, result1 error) {
+	fake.revokeMutex.Lock()
+	defer fake.revokeMutex.Unlock()
+	fake.RevokeStub = nil
+	if fake.revokeReturnsOnCall == nil {
+		fake.revokeReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.revokeReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeSessionMgmntRepository) Revoked(arg1 context.Context, arg2 string) (bool, error) {
+	fake.revokedMutex.Lock()
+	ret, specificReturn := fake.revokedReturnsOnCall[len(fake.revokedArgsForCall)]
+	fake.revokedArgsForCall = append(fake.revokedArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.RevokedStub
+	fakeReturns := fake.revokedReturns
+	fake.recordInvocation("Revoked", []interface{}{arg1, arg2})
+	fake.revokedMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeSessionMgmntRepository) RevokedCallCount() int {
+	fake.revokedMutex.RLock()
+	defer fake.revokedMutex.RUnlock()
+	return len(fake.revokedArgsForCall)
+}
+
+func (fake *FakeSessionMgmntRepository) RevokedCalls(stub func(context.Context, string) (bool, error)) {
+	fake.revokedMutex.Lock()
+	defer fake.revokedMutex.Unlock()
+	fake.RevokedStub = stub
+}
+
+func (fake *FakeSessionMgmntRepository) RevokedArgsForCall(i int) (context.Context, string) {
+	fake.revokedMutex.RLock()
+	defer fake.revokedMutex.RUnlock()
+	argsForCall := fake.revokedArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeSessionMgmntRepository) RevokedReturns(result1 bool, result2 error) {
+	fake.revokedMutex.Lock()
+	defer fake.revokedMutex.Unlock()
+	fake.RevokedStub = nil
+	fake.revokedReturns = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSessionMgmntRepository) RevokedReturnsOnCall(i int, result1 bool, result2 error) {
+	fake.revokedMutex.Lock()
+	defer fake.revokedMutex.Unlock()
+	fake.RevokedStub = nil
+	if fake.revokedReturnsOnCall == nil {
+		fake.revokedReturnsOnCall = make(map[int]struct {
+			result1 bool
+			result2 error
+		})
+	}
+	fake.revokedReturnsOnCall[i] = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSessionMgmntRepository) Rotate(arg1 context.Context, arg2 string, arg3 string, arg4 time.Duration) (bool, error) {
+	fake.rotateMutex.Lock()
+	ret, specificReturn := fake.rotateReturnsOnCall[len(fake.rotateArgsForCall)]
+	fake.rotateArgsForCall = append(fake.rotateArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+		arg4 time.Duration
+	}{arg1, arg2, arg3, arg4})
+	stub := fake.RotateStub
+	fakeReturns := fake.rotateReturns
+	fake.recordInvocation("Rotate", []interface{}{arg1, arg2, arg3, arg4})
+	fake.rotateMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeSessionMgmntRepository) RotateCallCount() int {
+	fake.rotateMutex.RLock()
+	defer fake.rotateMutex.RUnlock()
+	return len(fake.rotateArgsForCall)
+}
+
+func (fake *FakeSessionMgmntRepository) RotateCalls(stub func(context.Context, string, string, time.Duration) (bool, error)) {
+	fake.rotateMutex.Lock()
+	defer fake.rotateMutex.Unlock()
+	fake.RotateStub = stub
+}
+
+func (fake *FakeSessionMgmntRepository) RotateArgsForCall(i int) (context.Context, string, string, time.Duration) {
+	fake.rotateMutex.RLock()
+	defer fake.rotateMutex.RUnlock()
+	argsForCall := fake.rotateArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *FakeSessionMgmntRepository) RotateReturns(result1 bool, result2 error) {
+	fake.rotateMutex.Lock()
+	defer fake.rotateMutex.Unlock()
+	fake.RotateStub = nil
+	fake.rotateReturns = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSessionMgmntRepository) RotateReturnsOnCall(i int, result1 bool, result2 error) {
+	fake.rotateMutex.Lock()
+	defer fake.rotateMutex.Unlock()
+	fake.RotateStub = nil
+	if fake.rotateReturnsOnCall == nil {
+		fake.rotateReturnsOnCall = make(map[int]struct {
+			result1 bool
+			result2 error
+		})
+	}
+	fake.rotateReturnsOnCall[i] = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSessionMgmntRepository) SetData(arg1 context.Context, arg2 *models.SessionDataRequest) (bool, error) {
+	fake.setDataMutex.Lock()
+	ret, specificReturn := fake.setDataReturnsOnCall[len(fake.setDataArgsForCall)]
+	fake.setDataArgsForCall = append(fake.setDataArgsForCall, struct {
+		arg1 context.Context
+		arg2 *models.SessionDataRequest
+	}{arg1, arg2})
+	stub := fake.SetDataStub
+	fakeReturns := fake.setDataReturns
+	fake.recordInvocation("SetData", []interface{}{arg1, arg2})
+	fake.setDataMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeSessionMgmntRepository) SetDataCallCount() int {
+	fake.setDataMutex.RLock()
+	defer fake.setDataMutex.RUnlock()
+	return len(fake.setDataArgsForCall)
+}
+
+func (fake *FakeSessionMgmntRepository) SetDataCalls(stub func(context.Context, *models.SessionDataRequest) (bool, error)) {
+	fake.setDataMutex.Lock()
+	defer fake.setDataMutex.Unlock()
+	fake.SetDataStub = stub
+}
+
+func (fake *FakeSessionMgmntRepository) SetDataArgsForCall(i int) (context.Context, *models.SessionDataRequest) {
+	fake.setDataMutex.RLock()
+	defer fake.setDataMutex.RUnlock()
+	argsForCall := fake.setDataArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeSessionMgmntRepository) SetDataReturns(result1 bool, result2 error) {
+	fake.setDataMutex.Lock()
+	defer fake.setDataMutex.Unlock()
+	fake.SetDataStub = nil
+	fake.setDataReturns = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSessionMgmntRepository) SetDataReturnsOnCall(i int, result1 bool, result2 error) {
+	fake.setDataMutex.Lock()
+	defer fake.setDataMutex.Unlock()
+	fake.SetDataStub = nil
+	if fake.setDataReturnsOnCall == nil {
+		fake.setDataReturnsOnCall = make(map[int]struct {
+			result1 bool
+			result2 error
+		})
+	}
+	fake.setDataReturnsOnCall[i] = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeSessionMgmntRepository) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
 	fake.createMutex.RLock()
 	defer fake.createMutex.RUnlock()
+	fake.createBatchMutex.RLock()
+	defer fake.createBatchMutex.RUnlock()
 	fake.destroyMutex.RLock()
 	defer fake.destroyMutex.RUnlock()
+	fake.destroyBatchMutex.RLock()
+	defer fake.destroyBatchMutex.RUnlock()
+	fake.destroyBySubjectMutex.RLock()
+	defer fake.destroyBySubjectMutex.RUnlock()
 	fake.existMutex.RLock()
 	defer fake.existMutex.RUnlock()
 	fake.extendMutex.RLock()
 	defer fake.extendMutex.RUnlock()
+	fake.extendBatchMutex.RLock()
+	defer fake.extendBatchMutex.RUnlock()
+	fake.getMutex.RLock()
+	defer fake.getMutex.RUnlock()
+	fake.getDataMutex.RLock()
+	defer fake.getDataMutex.RUnlock()
 	fake.listMutex.RLock()
 	defer fake.listMutex.RUnlock()
+	fake.listBySubjectMutex.RLock()
+	defer fake.listBySubjectMutex.RUnlock()
+	fake.listRevokedMutex.RLock()
+	defer fake.listRevokedMutex.RUnlock()
+	fake.listSubjectDetailsMutex.RLock()
+	defer fake.listSubjectDetailsMutex.RUnlock()
+	fake.patchDataMutex.RLock()
+	defer fake.patchDataMutex.RUnlock()
+	fake.revokeMutex.RLock()
+	defer fake.revokeMutex.RUnlock()
+	fake.revokedMutex.RLock()
+	defer fake.revokedMutex.RUnlock()
+	fake.rotateMutex.RLock()
+	defer fake.rotateMutex.RUnlock()
+	fake.setDataMutex.RLock()
+	defer fake.setDataMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
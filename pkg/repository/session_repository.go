@@ -1,8 +1,10 @@
 package repository
 
 import (
-	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
+	"sync"
 	"time"
 
 	"github.com/go-kit/kit/log"
@@ -22,17 +24,45 @@ var (
 // SessionMgmntRepository
 //go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 . SessionMgmntRepository
 type SessionMgmntRepository interface {
-	Create(sessionId string, expiration time.Time) error
-	Destroy(session *DestroyRequest) error
-	Extend(request *ExtendRequest) (bool, error)
-	Exist(sessionId string) (bool, error)
-	List() (*Sessions, error)
+	Create(ctx context.Context, sessionId string, session *SessionRequest, expiration time.Time) error
+	CreateBatch(ctx context.Context, sessions []NewSession) error
+	Destroy(ctx context.Context, session *DestroyRequest) error
+	DestroyBatch(ctx context.Context, sessionIds []string) ([]bool, error)
+	DestroyBySubject(ctx context.Context, subject string) (*Sessions, error)
+	Extend(ctx context.Context, request *ExtendRequest) (bool, error)
+	ExtendBatch(ctx context.Context, requests []ExtendRequest) ([]bool, error)
+	Exist(ctx context.Context, sessionId string) (bool, error)
+	Get(ctx context.Context, sessionId string) (*SessionDetails, bool, error)
+	List(ctx context.Context, request *ListRequest) (*Sessions, error)
+	ListBySubject(ctx context.Context, subject string) (*Sessions, error)
+	ListSubjectDetails(ctx context.Context, subject string) ([]*SessionDetails, error)
+	GetData(ctx context.Context, sessionId string) (map[string]interface{}, bool, error)
+	SetData(ctx context.Context, request *SessionDataRequest) (bool, error)
+	PatchData(ctx context.Context, request *SessionDataRequest) (bool, error)
+	Rotate(ctx context.Context, sessionId string, newSessionId string, grace time.Duration) (bool, error)
+	RevocationList
+}
+
+// NewSession is a session stored by CreateBatch
+type NewSession struct {
+	SessionId  string
+	Request    *SessionRequest
+	Expiration time.Time
+}
+
+// RevocationList is the list of the destroyed sessions whose tokens have not expired yet
+type RevocationList interface {
+	Revoke(ctx context.Context, sessionId string, expiration time.Time) error
+	Revoked(ctx context.Context, sessionId string) (bool, error)
+	ListRevoked(ctx context.Context) (*Revocations, error)
 }
 
 // AuthRepository has the implementation of the db methods.
 type sessionMgmntRepository struct {
 	store in_memory.MemStore
+	revocations in_memory.MemStore
 	logger log.Logger
+	mu sync.Mutex
 }
 
 // NewSessionMgmntRepository create a instance of session management repository
@@ -40,67 +70,618 @@ func NewSessionMgmntRepository(store in_memory.MemStore, logger log.Logger) Sess
 	return &sessionMgmntRepository{store: store, logger: logger}
 }
 
-// Create session is stored in-memory
-func (s *sessionMgmntRepository) Create(sessionId string, expiration time.Time) error {
+// NewRevokingSessionMgmntRepository create a instance of session management repository that
+// records every destroyed session in the revocations store until its tokens expire
+func NewRevokingSessionMgmntRepository(store, revocations in_memory.MemStore, logger log.Logger) SessionMgmntRepository {
+	return &sessionMgmntRepository{store: store, revocations: revocations, logger: logger}
+}
+
+// Create session is stored in-memory along with its subject, data, idle timeout and
+// maximum lifetime
+func (s *sessionMgmntRepository) Create(ctx context.Context, sessionId string, session *SessionRequest, expiration time.Time) error {
 	if sessionId == "" {
 		return ErrEmpty
 	}
 
-	if err := s.store.Commit(sessionId, []byte(sessionId), expiration); err != nil {
+	item, err := newItem(sessionId, session, expiration, time.Now())
+	if err != nil {
+		return err
+	}
+	if err := s.store.CommitItem(ctx, sessionId, item); err != nil {
 		return err
 	}
 	return nil
 }
 
-// Destroy remove the session from its cache
-func (s *sessionMgmntRepository) Destroy(session *DestroyRequest) error {
-	if err := s.store.Delete(session.SessionId); err != nil {
+// CreateBatch stores the sessions like Create, committing them to the store in a single
+// batch
+func (s *sessionMgmntRepository) CreateBatch(ctx context.Context, sessions []NewSession) error {
+	createdAt := time.Now()
+	items := make(map[string]Item, len(sessions))
+	for _, session := range sessions {
+		if session.SessionId == "" {
+			return ErrEmpty
+		}
+		item, err := newItem(session.SessionId, session.Request, session.Expiration, createdAt)
+		if err != nil {
+			return err
+		}
+		items[session.SessionId] = item
+	}
+	return s.store.CommitBatch(ctx, items)
+}
+
+// newItem builds the stored item of a new session
+func newItem(sessionId string, session *SessionRequest, expiration, createdAt time.Time) (Item, error) {
+	b, err := json.Marshal(&Record{
+		SessionId: sessionId,
+		Subject:   session.Subject,
+		CreatedAt: createdAt.UnixNano(),
+		ExpiresAt: expiration.UnixNano(),
+		Data:      session.Data,
+	})
+	if err != nil {
+		return Item{}, err
+	}
+
+	item := Item{
+		Oject:       b,
+		Expiration:  expiration.UnixNano(),
+		Subject:     session.Subject,
+		IdleTimeout: int64(time.Duration(session.IdleTimeout) * time.Second),
+		CreatedAt:   createdAt.UnixNano(),
+	}
+	if session.MaxLifetime > 0 {
+		item.MaxExpiration = createdAt.Add(time.Duration(session.MaxLifetime) * time.Second).UnixNano()
+	}
+	return item, nil
+}
+
+// Destroy remove the session from its cache. Destroying a rotated session id during its
+// grace period removes the session that replaced it as well. It holds the lock of Rotate
+// so that a rotation in progress can not recreate the session.
+func (s *sessionMgmntRepository) Destroy(ctx context.Context, session *DestroyRequest) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sessionIds := []string{session.SessionId}
+	record, found, err := s.find(ctx, session.SessionId)
+	if err != nil {
 		return err
 	}
+	if found && record.SessionId != session.SessionId {
+		sessionIds = append(sessionIds, record.SessionId)
+	}
+
+	for _, sessionId := range sessionIds {
+		if s.revocations != nil {
+			if err := s.revoke(ctx, sessionId); err != nil {
+				return err
+			}
+		}
+		if err := s.store.Delete(ctx, sessionId); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-// Extend session id with the provided TTL
-func (s *sessionMgmntRepository) Extend(request *ExtendRequest) (bool, error) {
-	expiration := time.Now().Add(time.Second * time.Duration(request.TTL))
-	obj, found, err := s.store.Reset(request.SessionId, expiration)
+// DestroyBatch removes the sessions like Destroy, deleting them from the store in a single
+// batch, and reports which of them existed. The sessions are revoked before any of them is
+// removed.
+func (s *sessionMgmntRepository) DestroyBatch(ctx context.Context, sessionIds []string) ([]bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.revocations != nil {
+		if err := s.revokeBatch(ctx, sessionIds); err != nil {
+			return nil, err
+		}
+	}
+	deleted, err := s.store.DeleteBatch(ctx, sessionIds)
 	if err != nil {
-		return false, err
+		return nil, err
+	}
+
+	// follow the tombstones of the rotated sessions to the end of their chain
+	for tombstones := deleted; len(tombstones) > 0; {
+		var rotated []string
+		for sessionId, item := range tombstones {
+			record, err := decodeRecord(sessionId, item.Oject)
+			if err != nil {
+				return nil, err
+			}
+			if record.RotatedTo != "" {
+				rotated = append(rotated, record.RotatedTo)
+			}
+		}
+		if len(rotated) == 0 {
+			break
+		}
+		if tombstones, err = s.store.DeleteBatch(ctx, rotated); err != nil {
+			return nil, err
+		}
 	}
-	if found != true {
+
+	found := make([]bool, len(sessionIds))
+	for i, sessionId := range sessionIds {
+		_, found[i] = deleted[sessionId]
+	}
+	return found, nil
+}
+
+// DestroyBySubject remove every session of the subject from its cache
+func (s *sessionMgmntRepository) DestroyBySubject(ctx context.Context, subject string) (*Sessions, error) {
+	if s.revocations != nil {
+		return s.revokeBySubject(ctx, subject)
+	}
+	sessionIds, err := s.store.DeleteBySubject(ctx, subject)
+	if err != nil {
+		return nil, err
+	}
+	return &Sessions{List: sessionIds}, nil
+}
+
+// Revoke records the session in the revocations store until the expiration, so its tokens
+// are refused even when the session itself already expired
+func (s *sessionMgmntRepository) Revoke(ctx context.Context, sessionId string, expiration time.Time) error {
+	if s.revocations == nil {
+		return nil
+	}
+	return s.revocations.Commit(ctx, sessionId, []byte(sessionId), expiration)
+}
+
+// Revoked if the session was destroyed and its tokens have not expired yet
+func (s *sessionMgmntRepository) Revoked(ctx context.Context, sessionId string) (bool, error) {
+	if s.revocations == nil {
 		return false, nil
 	}
-	if bytes.Equal(obj, []byte(request.SessionId)) == false {
-		return false, ErrInvalidSessionId
+	_, found, err := s.revocations.Find(ctx, sessionId)
+	return found, err
+}
+
+// ListRevoked returns the destroyed sessions whose tokens have not expired yet
+func (s *sessionMgmntRepository) ListRevoked(ctx context.Context) (*Revocations, error) {
+	revocations := &Revocations{List: []Revocation{}}
+	if s.revocations == nil {
+		return revocations, nil
 	}
-	return true, nil
+	items, err := s.revocations.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UnixNano()
+	for sessionId, item := range items {
+		if item.Expiration < now {
+			continue
+		}
+		revocations.List = append(revocations.List, Revocation{
+			SessionId: sessionId,
+			ExpiresAt: time.Unix(0, item.Expiration).Unix(),
+		})
+	}
+	return revocations, nil
+}
+
+// Extend session id with the provided TTL
+func (s *sessionMgmntRepository) Extend(ctx context.Context, request *ExtendRequest) (bool, error) {
+	expiration := time.Now().Add(time.Second * time.Duration(request.TTL))
+	sessionId := request.SessionId
+	for {
+		obj, found, err := s.store.Reset(ctx, sessionId, expiration)
+		if err != nil {
+			return false, err
+		}
+		if found != true {
+			return false, nil
+		}
+		record, err := decodeRecord(sessionId, obj)
+		if err != nil {
+			return false, err
+		}
+		if record.RotatedTo == "" {
+			return true, nil
+		}
+		sessionId = record.RotatedTo
+	}
+}
+
+// ExtendBatch extends the sessions like Extend, resetting them in the store in a single
+// batch per level of rotation, and reports which of them exist. A session listed twice is
+// extended with the last TTL.
+func (s *sessionMgmntRepository) ExtendBatch(ctx context.Context, requests []ExtendRequest) ([]bool, error) {
+	now := time.Now()
+	found := make([]bool, len(requests))
+	sessionIds := make([]string, len(requests))
+	pending := make([]int, len(requests))
+	for i, request := range requests {
+		sessionIds[i] = request.SessionId
+		pending[i] = i
+	}
+
+	for len(pending) > 0 {
+		expirations := make(map[string]time.Time, len(pending))
+		for _, i := range pending {
+			expirations[sessionIds[i]] = now.Add(time.Second * time.Duration(requests[i].TTL))
+		}
+		extended, err := s.store.ResetBatch(ctx, expirations)
+		if err != nil {
+			return nil, err
+		}
+
+		var next []int
+		for _, i := range pending {
+			item, ok := extended[sessionIds[i]]
+			if !ok {
+				continue
+			}
+			record, err := decodeRecord(sessionIds[i], item.Oject)
+			if err != nil {
+				return nil, err
+			}
+			if record.RotatedTo == "" {
+				found[i] = true
+				continue
+			}
+			sessionIds[i] = record.RotatedTo
+			next = append(next, i)
+		}
+		pending = next
+	}
+	return found, nil
 }
 
 // Exist if the session exists
-func (s *sessionMgmntRepository) Exist(sessionId string) (bool, error) {
-	b, found, err := s.store.Find(sessionId)
+func (s *sessionMgmntRepository) Exist(ctx context.Context, sessionId string) (bool, error) {
+	_, found, err := s.find(ctx, sessionId)
+	return found, err
+}
+
+// Get returns the details of the session if it exists and has not expired. The details
+// of a rotated session id are those of the session that replaced it.
+func (s *sessionMgmntRepository) Get(ctx context.Context, sessionId string) (*SessionDetails, bool, error) {
+	requested := sessionId
+	for {
+		item, found, err := s.store.Lookup(ctx, sessionId)
+		if err != nil {
+			return nil, false, err
+		}
+		if found != true {
+			return nil, false, nil
+		}
+		record, err := decodeRecord(sessionId, item.Oject)
+		if err != nil {
+			return nil, false, err
+		}
+		if record.RotatedTo != "" {
+			sessionId = record.RotatedTo
+			continue
+		}
+		details, err := newSessionDetails(sessionId, item)
+		if err != nil {
+			return nil, false, err
+		}
+		// the id that replaced a rotated session is never disclosed to the holders of
+		// the old id
+		details.SessionId = requested
+		return details, true, nil
+	}
+}
+
+// List returns a page of the sessions that the service is currently tracking, selected and
+// ordered by the request
+func (s *sessionMgmntRepository) List(ctx context.Context, request *ListRequest) (*Sessions, error) {
+	page, err := s.store.Query(ctx, newQuery(request, time.Now()))
+	if err != nil {
+		return nil, err
+	}
+	session := &Sessions{NextCursor: page.NextCursor}
+	for _, item := range page.Items {
+		session.List = append(session.List, item.SessionId)
+	}
+	return session, nil
+}
+
+// newQuery converts the list request into the store query, the TTL bounds becoming an
+// expiration window starting now
+func newQuery(request *ListRequest, now time.Time) in_memory.Query {
+	query := in_memory.Query{
+		Subject:    request.Subject,
+		SortBy:     in_memory.SortField(request.Sort),
+		Descending: request.Order == "desc",
+		Cursor:     request.Cursor,
+		Limit:      request.Limit,
+	}
+	if !request.CreatedAfter.IsZero() {
+		query.CreatedAfter = request.CreatedAfter.UnixNano()
+	}
+	if !request.CreatedBefore.IsZero() {
+		query.CreatedBefore = request.CreatedBefore.UnixNano()
+	}
+	if request.MinTTL > 0 {
+		query.ExpiresAfter = now.Add(time.Duration(request.MinTTL) * time.Second).UnixNano()
+	}
+	if request.MaxTTL > 0 {
+		query.ExpiresBefore = now.Add(time.Duration(request.MaxTTL) * time.Second).UnixNano()
+	}
+	return query
+}
+
+// ListBySubject returns a list of the live sessions of the subject
+func (s *sessionMgmntRepository) ListBySubject(ctx context.Context, subject string) (*Sessions, error) {
+	sessionMap, err := s.store.ListBySubject(ctx, subject)
 	if err != nil {
+		return nil, err
+	}
+	session := &Sessions{}
+	for sessionId := range sessionMap {
+		session.List = append(session.List, sessionId)
+	}
+	return session, nil
+}
+
+// ListSubjectDetails returns the details of the live sessions of the subject
+// without counting it as an access to them
+func (s *sessionMgmntRepository) ListSubjectDetails(ctx context.Context, subject string) ([]*SessionDetails, error) {
+	sessionMap, err := s.store.ListBySubject(ctx, subject)
+	if err != nil {
+		return nil, err
+	}
+	var list []*SessionDetails
+	for sessionId, item := range sessionMap {
+		details, err := newSessionDetails(sessionId, item)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, details)
+	}
+	return list, nil
+}
+
+// GetData returns the data attached to the session
+func (s *sessionMgmntRepository) GetData(ctx context.Context, sessionId string) (map[string]interface{}, bool, error) {
+	record, found, err := s.find(ctx, sessionId)
+	if err != nil || !found {
+		return nil, false, err
+	}
+	return record.Data, true, nil
+}
+
+// SetData replaces the data attached to the session
+func (s *sessionMgmntRepository) SetData(ctx context.Context, request *SessionDataRequest) (bool, error) {
+	return s.update(ctx, request.SessionId, func(record *Record) {
+		record.Data = request.Data
+	})
+}
+
+// PatchData merges the given keys into the data attached to the session, keys
+// with a null value are removed
+func (s *sessionMgmntRepository) PatchData(ctx context.Context, request *SessionDataRequest) (bool, error) {
+	return s.update(ctx, request.SessionId, func(record *Record) {
+		if record.Data == nil {
+			record.Data = make(map[string]interface{})
+		}
+		for key, value := range request.Data {
+			if value == nil {
+				delete(record.Data, key)
+				continue
+			}
+			record.Data[key] = value
+		}
+	})
+}
+
+// revoke records the session in the revocations store until both the session and the
+// token it was created with expired, since Extend may have moved the session either way
+func (s *sessionMgmntRepository) revoke(ctx context.Context, sessionId string) error {
+	item, found, err := s.store.Lookup(ctx, sessionId)
+	if err != nil || !found {
+		return err
+	}
+	return s.revokeItem(ctx, sessionId, item)
+}
+
+// revokeBySubject revokes and removes the live sessions of the subject one at a time, so
+// no session is removed before it is revoked
+func (s *sessionMgmntRepository) revokeBySubject(ctx context.Context, subject string) (*Sessions, error) {
+	items, err := s.store.ListBySubject(ctx, subject)
+	if err != nil {
+		return nil, err
+	}
+	sessions := &Sessions{}
+	for sessionId, item := range items {
+		if err := s.revokeItem(ctx, sessionId, item); err != nil {
+			return nil, err
+		}
+		if err := s.store.Delete(ctx, sessionId); err != nil {
+			return nil, err
+		}
+		sessions.List = append(sessions.List, sessionId)
+	}
+	return sessions, nil
+}
+
+// revokeItem records the session in the revocations store until its revocation expiration
+func (s *sessionMgmntRepository) revokeItem(ctx context.Context, sessionId string, item Item) error {
+	expiration, err := revocationExpiration(sessionId, item)
+	if err != nil {
+		return err
+	}
+	return s.Revoke(ctx, sessionId, expiration)
+}
+
+// revokeBatch records the live sessions, and the sessions replacing the rotated ones, in
+// the revocations store in a single batch
+func (s *sessionMgmntRepository) revokeBatch(ctx context.Context, sessionIds []string) error {
+	revocations := make(map[string]Item)
+	for _, sessionId := range sessionIds {
+		for sessionId != "" {
+			item, found, err := s.store.Lookup(ctx, sessionId)
+			if err != nil {
+				return err
+			}
+			if !found {
+				break
+			}
+			expiration, err := revocationExpiration(sessionId, item)
+			if err != nil {
+				return err
+			}
+			revocations[sessionId] = Item{Oject: []byte(sessionId), Expiration: expiration.UnixNano()}
+
+			record, err := decodeRecord(sessionId, item.Oject)
+			if err != nil {
+				return err
+			}
+			sessionId = record.RotatedTo
+		}
+	}
+	if len(revocations) == 0 {
+		return nil
+	}
+	return s.revocations.CommitBatch(ctx, revocations)
+}
+
+// revocationExpiration returns the later of the expiration of the session and the
+// expiration it was created with, rounded up to the second like the exp claim of the JWTs
+func revocationExpiration(sessionId string, item Item) (time.Time, error) {
+	record, err := decodeRecord(sessionId, item.Oject)
+	if err != nil {
+		return time.Time{}, err
+	}
+	expiration := item.Expiration
+	if record.ExpiresAt > expiration {
+		expiration = record.ExpiresAt
+	}
+	return time.Unix(0, expiration).Truncate(time.Second).Add(time.Second), nil
+}
+
+// Rotate replaces the session id by newSessionId, keeping the data and expiration of the
+// session. The old session id becomes a tombstone that resolves to the new one for the
+// grace period, so requests in flight with the old id do not fail. A tombstone cannot be
+// rotated again.
+func (s *sessionMgmntRepository) Rotate(ctx context.Context, sessionId string, newSessionId string, grace time.Duration) (bool, error) {
+	if sessionId == "" || newSessionId == "" {
+		return false, ErrEmpty
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	item, found, err := s.store.Lookup(ctx, sessionId)
+	if err != nil || !found {
 		return false, err
 	}
-	if found != true {
+	record, err := decodeRecord(sessionId, item.Oject)
+	if err != nil {
+		return false, err
+	}
+	if record.RotatedTo != "" {
 		return false, nil
 	}
-	if bytes.Equal(b, []byte(sessionId)) == false {
-		return false, ErrInvalidSessionId
+
+	record.SessionId = newSessionId
+	b, err := json.Marshal(record)
+	if err != nil {
+		return false, err
+	}
+	moved := item
+	moved.Oject = b
+
+	tombstone, err := json.Marshal(&Record{SessionId: sessionId, CreatedAt: record.CreatedAt, RotatedTo: newSessionId})
+	if err != nil {
+		return false, err
+	}
+	expiration := time.Now().Add(grace).UnixNano()
+	if expiration > item.Expiration {
+		expiration = item.Expiration
+	}
+
+	// the session and its tombstone are committed in a single batch, so that the old id
+	// is never left a live session next to the new one
+	err = s.store.CommitBatch(ctx, map[string]Item{
+		newSessionId: moved,
+		sessionId:    {Oject: tombstone, Expiration: expiration, MaxExpiration: expiration, CreatedAt: item.CreatedAt},
+	})
+	if err != nil {
+		return false, err
 	}
 	return true, nil
 }
 
-//List returns a list of all the sessions that the service is currently tracking
-func (s *sessionMgmntRepository) List() (*Sessions, error) {
-	session := &Sessions{}
-	sessionMap, err := s.store.List()
+// find returns the decoded record of the session, following the tombstones of rotated
+// session ids to the record of the session that replaced them
+func (s *sessionMgmntRepository) find(ctx context.Context, sessionId string) (*Record, bool, error) {
+	for {
+		b, found, err := s.store.Find(ctx, sessionId)
+		if err != nil {
+			return nil, false, err
+		}
+		if found != true {
+			return nil, false, nil
+		}
+		record, err := decodeRecord(sessionId, b)
+		if err != nil {
+			return nil, false, err
+		}
+		if record.RotatedTo == "" {
+			return record, true, nil
+		}
+		sessionId = record.RotatedTo
+	}
+}
+
+// update applies fn to the record of the session and stores it back
+func (s *sessionMgmntRepository) update(ctx context.Context, sessionId string, fn func(record *Record)) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record, found, err := s.find(ctx, sessionId)
+	if err != nil || !found {
+		return false, err
+	}
+
+	fn(record)
+	b, err := json.Marshal(record)
+	if err != nil {
+		return false, err
+	}
+	return s.store.Update(ctx, record.SessionId, b)
+}
+
+// newSessionDetails builds the details of the session from its stored item
+func newSessionDetails(sessionId string, item Item) (*SessionDetails, error) {
+	record, err := decodeRecord(sessionId, item.Oject)
 	if err != nil {
 		return nil, err
 	}
-	// put session map values into sessions list
-	for _, value := range sessionMap {
-		session.List = append(session.List, string(value.Oject))
+
+	expiresAt := time.Unix(0, item.Deadline())
+	details := &SessionDetails{
+		SessionId:   sessionId,
+		Subject:     record.Subject,
+		Exists:      true,
+		CreatedAt:   time.Unix(0, record.CreatedAt).UTC(),
+		ExpiresAt:   expiresAt.UTC(),
+		LastAccess:  time.Unix(0, item.LastAccess).UTC(),
+		TTL:         int64(time.Until(expiresAt) / time.Second),
+		IdleTimeout: int64(time.Duration(item.IdleTimeout) / time.Second),
 	}
-	return session, nil
+	if item.MaxExpiration > 0 {
+		maxExpiresAt := time.Unix(0, item.MaxExpiration).UTC()
+		details.MaxExpiresAt = &maxExpiresAt
+	}
+	return details, nil
+}
+
+// decodeRecord decodes the stored record and checks it belongs to the session id
+func decodeRecord(sessionId string, b []byte) (*Record, error) {
+	var record Record
+	if err := json.Unmarshal(b, &record); err != nil {
+		return nil, ErrInvalidSessionId
+	}
+	if record.SessionId != sessionId {
+		return nil, ErrInvalidSessionId
+	}
+	return &record, nil
 }
@@ -0,0 +1,72 @@
+package session_management
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/hecomp/session-management/pkg/auth"
+)
+
+// APIKeyHeader carries the API key of a request, which can also be sent as a bearer token
+const APIKeyHeader = "X-API-Key"
+
+var (
+	// ErrUnauthenticated is returned when the request carries no valid API key
+	ErrUnauthenticated = errors.New("missing or invalid API key")
+	// ErrForbidden is returned when the API key of the request lacks the scope of the route
+	ErrForbidden = errors.New("API key lacks the scope of the route")
+)
+
+// Authenticate returns a handler letting through to h the requests whose API key, or
+// else whose client certificate subject, was granted the scope, with its principal
+// attached to the request context. The other requests are refused before their body is
+// decoded, with the error encoded like the errors of the service. A nil key store
+// disables the authentication, the client certificate subject is attached to the request
+// context either way.
+func Authenticate(keys *auth.KeyStore, scope auth.Scope, h http.Handler) http.Handler {
+	if keys == nil {
+		return ClientCertificate(h)
+	}
+	return ClientCertificate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, found := keys.Authenticate(apiKey(r))
+		if subject, verified := auth.ClientFromContext(r.Context()); !found && verified {
+			principal, found = keys.AuthenticateSubject(subject)
+		}
+		if !found {
+			encodeError(r.Context(), ErrUnauthenticated, w)
+			return
+		}
+		if !principal.HasScope(scope) {
+			encodeError(r.Context(), ErrForbidden, w)
+			return
+		}
+		h.ServeHTTP(w, r.WithContext(auth.NewContext(r.Context(), principal)))
+	}))
+}
+
+// ClientCertificate returns a handler attaching to the request context the subject of
+// the client certificate of the TLS connection before calling h. The listener only
+// requests a client certificate when it verifies it against the client CA bundle, so a
+// certificate sent by the client is a verified one.
+func ClientCertificate(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.TLS != nil && len(r.TLS.PeerCertificates) > 0 {
+			subject := r.TLS.PeerCertificates[0].Subject.String()
+			r = r.WithContext(auth.NewClientContext(r.Context(), subject))
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// apiKey returns the API key of the request, from the X-API-Key header or else from a
+// bearer token
+func apiKey(r *http.Request) string {
+	if key := r.Header.Get(APIKeyHeader); key != "" {
+		return key
+	}
+	if authorization := r.Header.Get("Authorization"); strings.HasPrefix(authorization, "Bearer ") {
+		return strings.TrimPrefix(authorization, "Bearer ")
+	}
+	return ""
+}
@@ -0,0 +1,114 @@
+package session_management_test
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/hecomp/session-management/pkg/auth"
+	. "github.com/hecomp/session-management/pkg/session_management"
+)
+
+var _ = Describe("Authenticate", func() {
+
+	const frontendKey = "frontend-0123456789abcdef0123456789"
+
+	var keys *auth.KeyStore
+	var principal auth.Principal
+	var client string
+	var handler http.Handler
+
+	BeforeEach(func() {
+		var err error
+		keys, err = auth.NewKeyStore(
+			auth.APIKey{Name: "frontend", Hash: auth.HashKey(frontendKey), Scopes: []auth.Scope{auth.ScopeCreate}},
+			auth.APIKey{Name: "backoffice", Subject: "CN=backoffice,O=Example", Scopes: []auth.Scope{auth.ScopeCreate}},
+		)
+		Expect(err).To(BeNil())
+		principal = auth.Principal{}
+		client = ""
+		handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, _ = auth.FromContext(r.Context())
+			client, _ = auth.ClientFromContext(r.Context())
+			w.WriteHeader(http.StatusCreated)
+		})
+	})
+
+	serve := func(h http.Handler, header, value string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/create", nil)
+		if header != "" {
+			req.Header.Set(header, value)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	serveTLS := func(h http.Handler, subject pkix.Name) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/create", nil)
+		req.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{{Subject: subject}}}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	decodeError := func(rec *httptest.ResponseRecorder) map[string]interface{} {
+		var body map[string]interface{}
+		Expect(json.NewDecoder(rec.Body).Decode(&body)).To(Succeed())
+		return body
+	}
+
+	It("lets through a key granted the scope", func() {
+		rec := serve(Authenticate(keys, auth.ScopeCreate, handler), APIKeyHeader, frontendKey)
+		Expect(rec.Code).To(Equal(http.StatusCreated))
+		Expect(principal.Name).To(Equal("frontend"))
+	})
+	It("accepts the key as a bearer token", func() {
+		rec := serve(Authenticate(keys, auth.ScopeCreate, handler), "Authorization", "Bearer "+frontendKey)
+		Expect(rec.Code).To(Equal(http.StatusCreated))
+	})
+	It("refuses a request without a valid key with a 401", func() {
+		for _, value := range []string{"", "unknown"} {
+			rec := serve(Authenticate(keys, auth.ScopeCreate, handler), APIKeyHeader, value)
+			Expect(rec.Code).To(Equal(http.StatusUnauthorized))
+			Expect(rec.Header().Get("WWW-Authenticate")).To(HavePrefix("Bearer"))
+			Expect(decodeError(rec)).To(Equal(map[string]interface{}{
+				"error":       ErrUnauthenticated.Error(),
+				"status_code": float64(http.StatusUnauthorized),
+			}))
+		}
+	})
+	It("refuses a key lacking the scope with a 403", func() {
+		rec := serve(Authenticate(keys, auth.ScopeAdmin, handler), APIKeyHeader, frontendKey)
+		Expect(rec.Code).To(Equal(http.StatusForbidden))
+		Expect(decodeError(rec)).To(Equal(map[string]interface{}{
+			"error":       ErrForbidden.Error(),
+			"status_code": float64(http.StatusForbidden),
+		}))
+	})
+	It("lets everything through without a key store", func() {
+		rec := serve(Authenticate(nil, auth.ScopeAdmin, handler), "", "")
+		Expect(rec.Code).To(Equal(http.StatusCreated))
+	})
+	It("lets through a client certificate subject granted the scope", func() {
+		rec := serveTLS(Authenticate(keys, auth.ScopeCreate, handler), pkix.Name{CommonName: "backoffice", Organization: []string{"Example"}})
+		Expect(rec.Code).To(Equal(http.StatusCreated))
+		Expect(principal.Name).To(Equal("backoffice"))
+		Expect(client).To(Equal("CN=backoffice,O=Example"))
+	})
+	It("refuses an unknown client certificate subject with a 401", func() {
+		rec := serveTLS(Authenticate(keys, auth.ScopeCreate, handler), pkix.Name{CommonName: "unknown"})
+		Expect(rec.Code).To(Equal(http.StatusUnauthorized))
+	})
+	It("attaches the client certificate subject without a key store", func() {
+		rec := serveTLS(Authenticate(nil, auth.ScopeAdmin, handler), pkix.Name{CommonName: "backoffice"})
+		Expect(rec.Code).To(Equal(http.StatusCreated))
+		Expect(client).To(Equal("CN=backoffice"))
+	})
+})
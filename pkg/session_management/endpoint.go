@@ -10,7 +10,7 @@ import (
 
 	. "github.com/hecomp/session-management/internal/models"
 	. "github.com/hecomp/session-management/pkg/repository"
-
+	"github.com/hecomp/session-management/pkg/token"
 )
 
 var (
@@ -19,6 +19,16 @@ var (
 	DestroySessionSuccess = fmt.Sprintf("session destroyed successfully")
 	ExtendSessionSuccess  = fmt.Sprintf("session extended successfully")
 	ListSessionSuccess    = fmt.Sprintf("session listed successfully")
+	GetSessionSuccess     = fmt.Sprintf("session retrieved successfully")
+	ListSubjectSuccess    = fmt.Sprintf("subject sessions listed successfully")
+	DestroySubjectSuccess = fmt.Sprintf("subject sessions destroyed successfully")
+	GetDataSuccess        = fmt.Sprintf("session data retrieved successfully")
+	SetDataSuccess        = fmt.Sprintf("session data set successfully")
+	PatchDataSuccess      = fmt.Sprintf("session data patched successfully")
+	RotateSessionSuccess  = fmt.Sprintf("session rotated successfully")
+	CreateBatchSuccess    = fmt.Sprintf("session batch created")
+	ExtendBatchSuccess    = fmt.Sprintf("session batch extended")
+	DestroyBatchSuccess   = fmt.Sprintf("session batch destroyed")
 )
 
 // SessionMgmntResponse collects the response values for the Create API.
@@ -34,10 +44,13 @@ func (r SessionMgmntResponse) Failed() error { return r.Err }
 
 // MakeCreateEndpoint create a session and return a unique session-id
 func MakeCreateEndpoint(service SessionMgmntService) endpoint.Endpoint {
-	return func(_ context.Context, request interface{})(interface{}, error) {
+	return func(ctx context.Context, request interface{})(interface{}, error) {
 		session := request.(SessionRequest)
 
-		uuid, err := service.Create(&session)
+		uuid, err := service.Create(ctx, &session)
+		if err == ErrSessionLimit || err == ErrInvalidArgument {
+			return &SessionMgmntResponse{ Message: err.Error(), Err: err, StatusCode: getStatusCode(err)  }, nil
+		}
 		if err != nil {
 			return &SessionMgmntResponse{ Message: ErrCreate.Error(), Err: ErrCreate, StatusCode: http.StatusInternalServerError  }, nil
 		}
@@ -49,10 +62,10 @@ func MakeCreateEndpoint(service SessionMgmntService) endpoint.Endpoint {
 
 // MakeDestroyEndpoint remove the session from its cache
 func MakeDestroyEndpoint(service SessionMgmntService) endpoint.Endpoint {
-	return func(_ context.Context, request interface{})(interface{}, error) {
+	return func(ctx context.Context, request interface{})(interface{}, error) {
 		session := request.(DestroyRequest)
 
-		err := service.Destroy(&session)
+		err := service.Destroy(ctx, &session)
 		if err != nil {
 			return &SessionMgmntResponse{ Message: err.Error(), Err: err, StatusCode: getStatusCode(err)  }, nil
 		}
@@ -62,10 +75,10 @@ func MakeDestroyEndpoint(service SessionMgmntService) endpoint.Endpoint {
 
 // MakeExtendEndpoint session id with the provided TTL
 func MakeExtendEndpoint(service SessionMgmntService) endpoint.Endpoint {
-	return func(_ context.Context, request interface{})(interface{}, error) {
+	return func(ctx context.Context, request interface{})(interface{}, error) {
 		session := request.(ExtendRequest)
 
-		err := service.Extend(&session)
+		err := service.Extend(ctx, &session)
 		if err != nil {
 			return &SessionMgmntResponse{ Message: err.Error(), Err: err, StatusCode: getStatusCode(err)}, nil
 		}
@@ -73,17 +86,197 @@ func MakeExtendEndpoint(service SessionMgmntService) endpoint.Endpoint {
 	}
 }
 
-// MakeListEndpoint return a list of all the sessions that the sessionMgmntService is currently tracking
+// MakeGetEndpoint validate the session and return its details
+func MakeGetEndpoint(service SessionMgmntService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{})(interface{}, error) {
+		session := request.(Session)
+
+		details, err := service.Get(ctx, &session)
+		if err != nil {
+			return &SessionMgmntResponse{ Message: err.Error(), Err: err, StatusCode: getStatusCode(err)}, nil
+		}
+		return &SessionMgmntResponse{Message: GetSessionSuccess, Data: details, StatusCode: http.StatusOK}, nil
+	}
+}
+
+// MakeListEndpoint return a page of the sessions that the sessionMgmntService is currently tracking
 func MakeListEndpoint(service SessionMgmntService) endpoint.Endpoint {
-	return func(_ context.Context, request interface{})(interface{},  error) {
-		sessions, err := service.List()
+	return func(ctx context.Context, request interface{})(interface{},  error) {
+		listRequest := request.(ListRequest)
+
+		sessions, err := service.List(ctx, &listRequest)
 		if err != nil {
-			return &SessionMgmntResponse{ Message: err.Error(), Err: err, StatusCode: http.StatusInternalServerError }, nil
+			return &SessionMgmntResponse{ Message: err.Error(), Err: err, StatusCode: getStatusCode(err) }, nil
 		}
 		return &SessionMgmntResponse{Message: ListSessionSuccess, Data: sessions, StatusCode: http.StatusOK}, nil
 	}
 }
 
+// MakeListSubjectEndpoint return a list of the sessions of the subject
+func MakeListSubjectEndpoint(service SessionMgmntService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{})(interface{}, error) {
+		subjectRequest := request.(SubjectRequest)
+
+		sessions, err := service.ListSubject(ctx, &subjectRequest)
+		if err != nil {
+			return &SessionMgmntResponse{ Message: err.Error(), Err: err, StatusCode: getStatusCode(err)}, nil
+		}
+		return &SessionMgmntResponse{Message: ListSubjectSuccess, Data: sessions, StatusCode: http.StatusOK}, nil
+	}
+}
+
+// MakeDestroySubjectEndpoint remove every session of the subject
+func MakeDestroySubjectEndpoint(service SessionMgmntService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{})(interface{}, error) {
+		subjectRequest := request.(SubjectRequest)
+
+		sessions, err := service.DestroySubject(ctx, &subjectRequest)
+		if err != nil {
+			return &SessionMgmntResponse{ Message: err.Error(), Err: err, StatusCode: getStatusCode(err)}, nil
+		}
+		return &SessionMgmntResponse{Message: DestroySubjectSuccess, Data: sessions, StatusCode: http.StatusOK}, nil
+	}
+}
+
+// MakeGetDataEndpoint return the data attached to the session
+func MakeGetDataEndpoint(service SessionMgmntService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{})(interface{}, error) {
+		session := request.(Session)
+
+		data, err := service.GetData(ctx, &session)
+		if err != nil {
+			return &SessionMgmntResponse{ Message: err.Error(), Err: err, StatusCode: getStatusCode(err)}, nil
+		}
+		return &SessionMgmntResponse{Message: GetDataSuccess, Data: data, StatusCode: http.StatusOK}, nil
+	}
+}
+
+// MakeSetDataEndpoint replace the data attached to the session
+func MakeSetDataEndpoint(service SessionMgmntService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{})(interface{}, error) {
+		dataRequest := request.(SessionDataRequest)
+
+		err := service.SetData(ctx, &dataRequest)
+		if err != nil {
+			return &SessionMgmntResponse{ Message: err.Error(), Err: err, StatusCode: getStatusCode(err)}, nil
+		}
+		return &SessionMgmntResponse{Message: SetDataSuccess, StatusCode: http.StatusOK}, nil
+	}
+}
+
+// MakePatchDataEndpoint update individual keys of the data attached to the session
+func MakePatchDataEndpoint(service SessionMgmntService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{})(interface{}, error) {
+		dataRequest := request.(SessionDataRequest)
+
+		err := service.PatchData(ctx, &dataRequest)
+		if err != nil {
+			return &SessionMgmntResponse{ Message: err.Error(), Err: err, StatusCode: getStatusCode(err)}, nil
+		}
+		return &SessionMgmntResponse{Message: PatchDataSuccess, StatusCode: http.StatusOK}, nil
+	}
+}
+
+// MakeRotateEndpoint replace the session id by a fresh one keeping its data and expiry
+func MakeRotateEndpoint(service SessionMgmntService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{})(interface{}, error) {
+		rotateRequest := request.(RotateRequest)
+
+		sessionId, err := service.Rotate(ctx, &rotateRequest)
+		if err != nil {
+			return &SessionMgmntResponse{ Message: err.Error(), Err: err, StatusCode: getStatusCode(err)}, nil
+		}
+		return &SessionMgmntResponse{Message: RotateSessionSuccess, Data: &Session{
+			SessionId: sessionId,
+		}, StatusCode: http.StatusOK }, nil
+	}
+}
+
+// MakeCreateBatchEndpoint create the sessions of the batch and return the session-id or
+// the error of each of them
+func MakeCreateBatchEndpoint(service SessionMgmntService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{})(interface{}, error) {
+		batchRequest := request.(BatchCreateRequest)
+
+		results, err := service.CreateBatch(ctx, &batchRequest)
+		if err != nil {
+			return &SessionMgmntResponse{ Message: err.Error(), Err: err, StatusCode: getStatusCode(err)}, nil
+		}
+		return &SessionMgmntResponse{Message: CreateBatchSuccess, Data: batchResults(results, http.StatusCreated), StatusCode: http.StatusOK}, nil
+	}
+}
+
+// MakeExtendBatchEndpoint extend the sessions of the batch and return the error of each of them
+func MakeExtendBatchEndpoint(service SessionMgmntService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{})(interface{}, error) {
+		batchRequest := request.(BatchExtendRequest)
+
+		results, err := service.ExtendBatch(ctx, &batchRequest)
+		if err != nil {
+			return &SessionMgmntResponse{ Message: err.Error(), Err: err, StatusCode: getStatusCode(err)}, nil
+		}
+		return &SessionMgmntResponse{Message: ExtendBatchSuccess, Data: batchResults(results, http.StatusOK), StatusCode: http.StatusOK}, nil
+	}
+}
+
+// MakeDestroyBatchEndpoint remove the sessions of the batch and return the error of each of them
+func MakeDestroyBatchEndpoint(service SessionMgmntService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{})(interface{}, error) {
+		batchRequest := request.(BatchDestroyRequest)
+
+		results, err := service.DestroyBatch(ctx, &batchRequest)
+		if err != nil {
+			return &SessionMgmntResponse{ Message: err.Error(), Err: err, StatusCode: getStatusCode(err)}, nil
+		}
+		return &SessionMgmntResponse{Message: DestroyBatchSuccess, Data: batchResults(results, http.StatusOK), StatusCode: http.StatusOK}, nil
+	}
+}
+
+// MakeJWKSEndpoint return the public keys verifying the session JWTs
+func MakeJWKSEndpoint(issuer *token.JWTIssuer) endpoint.Endpoint {
+	return func(_ context.Context, _ interface{}) (interface{}, error) {
+		return issuer.JWKS(), nil
+	}
+}
+
+// MakeRevocationsEndpoint return the destroyed sessions whose JWTs have not expired yet
+func MakeRevocationsEndpoint(revocations RevocationList) endpoint.Endpoint {
+	return func(ctx context.Context, _ interface{}) (interface{}, error) {
+		list, err := revocations.ListRevoked(ctx)
+		if err != nil {
+			return nil, ErrRevocationList
+		}
+		return list, nil
+	}
+}
+
+// MakeLivenessEndpoint return the state of the components whose failure requires a restart
+func MakeLivenessEndpoint(health *Health) endpoint.Endpoint {
+	return func(ctx context.Context, _ interface{}) (interface{}, error) {
+		return health.Liveness(ctx), nil
+	}
+}
+
+// MakeReadinessEndpoint return the state of the components the service needs to serve requests
+func MakeReadinessEndpoint(health *Health) endpoint.Endpoint {
+	return func(ctx context.Context, _ interface{}) (interface{}, error) {
+		return health.Readiness(ctx), nil
+	}
+}
+
+// batchResults fills the message and status code of every result of the batch from its
+// error, the batch itself succeeding even when some of its sessions failed
+func batchResults(results *BatchResults, success int) *BatchResults {
+	for i := range results.Results {
+		result := &results.Results[i]
+		result.Error, result.StatusCode = "", success
+		if result.Err != nil {
+			result.Error, result.StatusCode = result.Err.Error(), getStatusCode(result.Err)
+		}
+	}
+	return results
+}
+
 // getStatusCode will return a respective status code
 // based on given error
 func getStatusCode(err error) int {
@@ -94,6 +287,10 @@ func getStatusCode(err error) int {
 		statusCode = http.StatusNotFound
 	case ErrInvalidArgument:
 		statusCode = http.StatusBadRequest
+	case ErrSessionLimit:
+		statusCode = http.StatusConflict
+	case ErrInvalidToken:
+		statusCode = http.StatusUnauthorized
 	default:
 		statusCode = http.StatusInternalServerError
 	}
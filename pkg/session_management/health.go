@@ -0,0 +1,125 @@
+package session_management
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/hecomp/session-management/internal/models"
+)
+
+const (
+	// HealthUp is the status of a healthy component or service
+	HealthUp = "up"
+	// HealthDown is the status of a failing component or service
+	HealthDown = "down"
+	// HealthDraining is the readiness status of a service shutting down
+	HealthDraining = "draining"
+)
+
+// HealthCheck reports whether a component of the service is able to serve requests
+type HealthCheck func(ctx context.Context) error
+
+// Health runs the checks of the components of the service behind the liveness and
+// readiness probes. A failing liveness check means the process should be restarted,
+// a failing readiness check only that it should not receive requests for now, so the
+// readiness probe runs both.
+type Health struct {
+	timeout   time.Duration
+	mu        sync.RWMutex
+	liveness  map[string]HealthCheck
+	readiness map[string]HealthCheck
+	draining  int32
+}
+
+// NewHealth create a instance of health, every check must answer within timeout
+func NewHealth(timeout time.Duration) *Health {
+	return &Health{
+		timeout:   timeout,
+		liveness:  make(map[string]HealthCheck),
+		readiness: make(map[string]HealthCheck),
+	}
+}
+
+// AddLivenessCheck adds the check of a component whose failure requires a restart
+func (h *Health) AddLivenessCheck(name string, check HealthCheck) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.liveness[name] = check
+}
+
+// AddReadinessCheck adds the check of a component the service needs to serve requests
+func (h *Health) AddReadinessCheck(name string, check HealthCheck) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.readiness[name] = check
+}
+
+// Drain flips the readiness to false for good, so the load balancers stop sending
+// requests before the listeners are closed.
+func (h *Health) Drain() {
+	atomic.StoreInt32(&h.draining, 1)
+}
+
+// Draining reports whether Drain was called
+func (h *Health) Draining() bool {
+	return atomic.LoadInt32(&h.draining) == 1
+}
+
+// Liveness runs the liveness checks
+func (h *Health) Liveness(ctx context.Context) models.HealthStatus {
+	h.mu.RLock()
+	checks := make(map[string]HealthCheck, len(h.liveness))
+	for name, check := range h.liveness {
+		checks[name] = check
+	}
+	h.mu.RUnlock()
+	return h.run(ctx, checks)
+}
+
+// Readiness runs the liveness and readiness checks, the service is not ready once it
+// is draining whatever the state of its components.
+func (h *Health) Readiness(ctx context.Context) models.HealthStatus {
+	h.mu.RLock()
+	checks := make(map[string]HealthCheck, len(h.liveness)+len(h.readiness))
+	for name, check := range h.liveness {
+		checks[name] = check
+	}
+	for name, check := range h.readiness {
+		checks[name] = check
+	}
+	h.mu.RUnlock()
+	status := h.run(ctx, checks)
+	if h.Draining() {
+		status.Status = HealthDraining
+	}
+	return status
+}
+
+// run runs the checks concurrently, each one within the timeout of the Health instance
+func (h *Health) run(ctx context.Context, checks map[string]HealthCheck) models.HealthStatus {
+	status := models.HealthStatus{Status: HealthUp, Components: make(map[string]models.ComponentStatus, len(checks))}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, check := range checks {
+		wg.Add(1)
+		go func(name string, check HealthCheck) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(ctx, h.timeout)
+			defer cancel()
+			component := models.ComponentStatus{Status: HealthUp}
+			if err := check(ctx); err != nil {
+				component = models.ComponentStatus{Status: HealthDown, Error: err.Error()}
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			status.Components[name] = component
+			if component.Status != HealthUp {
+				status.Status = HealthDown
+			}
+		}(name, check)
+	}
+	wg.Wait()
+	return status
+}
@@ -0,0 +1,95 @@
+package session_management_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/hecomp/session-management/internal/models"
+	. "github.com/hecomp/session-management/pkg/session_management"
+)
+
+var _ = Describe("Health", func() {
+
+	var health *Health
+	var handler http.Handler
+
+	up := func(context.Context) error { return nil }
+	down := func(context.Context) error { return errors.New("connection refused") }
+
+	BeforeEach(func() {
+		health = NewHealth(50 * time.Millisecond)
+		handler = MakeHealthHandler(health)
+	})
+
+	probe := func(path string) (int, HealthStatus) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		var status HealthStatus
+		Expect(json.NewDecoder(rec.Body).Decode(&status)).To(Succeed())
+		return rec.Code, status
+	}
+
+	Context("MakeHealthHandler()", func() {
+		It("reports every component as up", func() {
+			health.AddLivenessCheck("store_cleanup", up)
+			health.AddReadinessCheck("store", up)
+
+			code, status := probe(LivenessPath)
+			Expect(code).To(Equal(http.StatusOK))
+			Expect(status).To(Equal(HealthStatus{Status: HealthUp, Components: map[string]ComponentStatus{
+				"store_cleanup": {Status: HealthUp},
+			}}))
+
+			code, status = probe(ReadinessPath)
+			Expect(code).To(Equal(http.StatusOK))
+			Expect(status).To(Equal(HealthStatus{Status: HealthUp, Components: map[string]ComponentStatus{
+				"store":         {Status: HealthUp},
+				"store_cleanup": {Status: HealthUp},
+			}}))
+		})
+		It("reports an unreachable store as not ready but alive", func() {
+			health.AddLivenessCheck("store_cleanup", up)
+			health.AddReadinessCheck("store", down)
+
+			code, _ := probe(LivenessPath)
+			Expect(code).To(Equal(http.StatusOK))
+
+			code, status := probe(ReadinessPath)
+			Expect(code).To(Equal(http.StatusServiceUnavailable))
+			Expect(status.Status).To(Equal(HealthDown))
+			Expect(status.Components["store"]).To(Equal(ComponentStatus{Status: HealthDown, Error: "connection refused"}))
+			Expect(status.Components["store_cleanup"].Status).To(Equal(HealthUp))
+		})
+		It("reports a check that does not answer in time as down", func() {
+			health.AddLivenessCheck("store_cleanup", func(ctx context.Context) error {
+				<-ctx.Done()
+				return ctx.Err()
+			})
+
+			code, status := probe(LivenessPath)
+			Expect(code).To(Equal(http.StatusServiceUnavailable))
+			Expect(status.Components["store_cleanup"]).To(Equal(ComponentStatus{Status: HealthDown, Error: context.DeadlineExceeded.Error()}))
+		})
+		It("reports the service as draining once Drain is called", func() {
+			health.AddReadinessCheck("store", up)
+			Expect(health.Draining()).To(BeFalse())
+			health.Drain()
+			Expect(health.Draining()).To(BeTrue())
+
+			code, status := probe(ReadinessPath)
+			Expect(code).To(Equal(http.StatusServiceUnavailable))
+			Expect(status.Status).To(Equal(HealthDraining))
+			Expect(status.Components["store"].Status).To(Equal(HealthUp))
+
+			code, _ = probe(LivenessPath)
+			Expect(code).To(Equal(http.StatusOK))
+		})
+	})
+})
@@ -0,0 +1,130 @@
+package session_management
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-kit/kit/metrics"
+
+	"github.com/hecomp/session-management/internal/models"
+)
+
+// instrumentingService has the implementation of the instrumenting middleware methods.
+type instrumentingService struct {
+	requestCount   metrics.Counter
+	requestLatency metrics.Histogram
+	SessionMgmntService
+}
+
+// NewInstrumentingService create a instance of instrumenting service
+func NewInstrumentingService(counter metrics.Counter, latency metrics.Histogram, s SessionMgmntService) SessionMgmntService {
+	return &instrumentingService{
+		requestCount:        counter,
+		requestLatency:      latency,
+		SessionMgmntService: s,
+	}
+}
+
+// Create session is stored in-memory
+func (s *instrumentingService) Create(ctx context.Context, session *models.SessionRequest) (sig string, err error) {
+	defer func(begin time.Time) {
+		s.observe("create", begin, err)
+	}(time.Now())
+	return s.SessionMgmntService.Create(ctx, session)
+}
+
+// Destroy remove the session from its cache
+func (s *instrumentingService) Destroy(ctx context.Context, session *models.DestroyRequest) (err error) {
+	defer func(begin time.Time) {
+		s.observe("destroy", begin, err)
+	}(time.Now())
+	return s.SessionMgmntService.Destroy(ctx, session)
+}
+
+// Extend session id with the provided TTL
+func (s *instrumentingService) Extend(ctx context.Context, session *models.ExtendRequest) (err error) {
+	defer func(begin time.Time) {
+		s.observe("extend", begin, err)
+	}(time.Now())
+	return s.SessionMgmntService.Extend(ctx, session)
+}
+
+// Get validate the session and return its details
+func (s *instrumentingService) Get(ctx context.Context, session *models.Session) (details *models.SessionDetails, err error) {
+	defer func(begin time.Time) {
+		s.observe("get", begin, err)
+	}(time.Now())
+	return s.SessionMgmntService.Get(ctx, session)
+}
+
+// List
+func (s *instrumentingService) List(ctx context.Context, request *models.ListRequest) (sig *models.Sessions, err error) {
+	defer func(begin time.Time) {
+		s.observe("list", begin, err)
+	}(time.Now())
+	return s.SessionMgmntService.List(ctx, request)
+}
+
+// GetData return the data attached to the session
+func (s *instrumentingService) GetData(ctx context.Context, session *models.Session) (data *models.SessionData, err error) {
+	defer func(begin time.Time) {
+		s.observe("getData", begin, err)
+	}(time.Now())
+	return s.SessionMgmntService.GetData(ctx, session)
+}
+
+// SetData replace the data attached to the session
+func (s *instrumentingService) SetData(ctx context.Context, request *models.SessionDataRequest) (err error) {
+	defer func(begin time.Time) {
+		s.observe("setData", begin, err)
+	}(time.Now())
+	return s.SessionMgmntService.SetData(ctx, request)
+}
+
+// PatchData update individual keys of the data attached to the session
+func (s *instrumentingService) PatchData(ctx context.Context, request *models.SessionDataRequest) (err error) {
+	defer func(begin time.Time) {
+		s.observe("patchData", begin, err)
+	}(time.Now())
+	return s.SessionMgmntService.PatchData(ctx, request)
+}
+
+// Rotate replace the session id by a fresh one
+func (s *instrumentingService) Rotate(ctx context.Context, request *models.RotateRequest) (sessionId string, err error) {
+	defer func(begin time.Time) {
+		s.observe("rotate", begin, err)
+	}(time.Now())
+	return s.SessionMgmntService.Rotate(ctx, request)
+}
+
+// CreateBatch create the sessions of the batch
+func (s *instrumentingService) CreateBatch(ctx context.Context, request *models.BatchCreateRequest) (results *models.BatchResults, err error) {
+	defer func(begin time.Time) {
+		s.observe("create_batch", begin, err)
+	}(time.Now())
+	return s.SessionMgmntService.CreateBatch(ctx, request)
+}
+
+// ExtendBatch extend the sessions of the batch
+func (s *instrumentingService) ExtendBatch(ctx context.Context, request *models.BatchExtendRequest) (results *models.BatchResults, err error) {
+	defer func(begin time.Time) {
+		s.observe("extend_batch", begin, err)
+	}(time.Now())
+	return s.SessionMgmntService.ExtendBatch(ctx, request)
+}
+
+// DestroyBatch remove the sessions of the batch
+func (s *instrumentingService) DestroyBatch(ctx context.Context, request *models.BatchDestroyRequest) (results *models.BatchResults, err error) {
+	defer func(begin time.Time) {
+		s.observe("destroy_batch", begin, err)
+	}(time.Now())
+	return s.SessionMgmntService.DestroyBatch(ctx, request)
+}
+
+// observe counts the request and records its latency, labelled by method and error
+func (s *instrumentingService) observe(method string, begin time.Time, err error) {
+	lvs := []string{"method", method, "error", fmt.Sprint(err != nil)}
+	s.requestCount.With(lvs...).Add(1)
+	s.requestLatency.With(lvs...).Observe(time.Since(begin).Seconds())
+}
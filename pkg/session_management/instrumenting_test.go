@@ -0,0 +1,61 @@
+package session_management_test
+
+import (
+	"errors"
+
+	"github.com/go-kit/kit/metrics/prometheus"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	stdprometheus "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	. "github.com/hecomp/session-management/internal/models"
+	. "github.com/hecomp/session-management/pkg/session_management"
+	"github.com/hecomp/session-management/pkg/session_management/session_managementfakes"
+)
+
+type InstrumentingSuite struct {
+	service     SessionMgmntService
+	fakeService *session_managementfakes.FakeSessionMgmntService
+	counter     *stdprometheus.CounterVec
+	latency     *stdprometheus.HistogramVec
+}
+
+var _ = Describe("Instrumenting", func() {
+
+	s := &InstrumentingSuite{}
+
+	BeforeEach(func() {
+		s.fakeService = new(session_managementfakes.FakeSessionMgmntService)
+		s.counter = stdprometheus.NewCounterVec(stdprometheus.CounterOpts{Name: "request_count"}, []string{"method", "error"})
+		s.latency = stdprometheus.NewHistogramVec(stdprometheus.HistogramOpts{Name: "request_latency_seconds"}, []string{"method", "error"})
+		s.service = NewInstrumentingService(prometheus.NewCounter(s.counter), prometheus.NewHistogram(s.latency), s.fakeService)
+	})
+
+	Context("Create()", func() {
+		It("counts requests per method split by error", func() {
+			s.fakeService.CreateReturns("90660b89-100e-4f8f-9801-2524df6fbe34", nil)
+			_, err := s.service.Create(ctx, &SessionRequest{TTL: 50})
+			Expect(err).To(BeNil())
+			s.fakeService.CreateReturns("", errors.New("error create"))
+			_, err = s.service.Create(ctx, &SessionRequest{TTL: 50})
+			Expect(err).ToNot(BeNil())
+
+			Expect(testutil.ToFloat64(s.counter.WithLabelValues("create", "false"))).To(Equal(float64(1)))
+			Expect(testutil.ToFloat64(s.counter.WithLabelValues("create", "true"))).To(Equal(float64(1)))
+			Expect(testutil.CollectAndCount(s.latency)).To(Equal(2))
+		})
+	})
+
+	Context("Destroy()", func() {
+		It("passes the call through to the next service", func() {
+			session := &DestroyRequest{SessionId: "90660b89-100e-4f8f-9801-2524df6fbe34"}
+			s.fakeService.DestroyReturns(nil)
+			err := s.service.Destroy(ctx, session)
+			Expect(err).To(BeNil())
+			_, destroyRequest := s.fakeService.DestroyArgsForCall(0)
+			Expect(destroyRequest).To(Equal(session))
+			Expect(testutil.ToFloat64(s.counter.WithLabelValues("destroy", "false"))).To(Equal(float64(1)))
+		})
+	})
+})
@@ -0,0 +1,270 @@
+package session_management
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/hecomp/session-management/internal/models"
+	. "github.com/hecomp/session-management/pkg/repository"
+	"github.com/hecomp/session-management/pkg/token"
+)
+
+// ErrRevocationList is returned when the revocation list cannot be read or written
+var ErrRevocationList = errors.New("error checking the revocation list")
+
+// jwtService has the implementation of the JWT middleware methods. It hands out JWTs
+// carrying the session id, subject and expiration, so edge services verify sessions with
+// the JWKS alone, and checks the revocation list so destroyed sessions are refused even
+// though their JWTs have not expired.
+type jwtService struct {
+	issuer      *token.JWTIssuer
+	revocations RevocationList
+	SessionMgmntService
+}
+
+// NewJWTService create a instance of JWT service
+func NewJWTService(issuer *token.JWTIssuer, revocations RevocationList, s SessionMgmntService) SessionMgmntService {
+	return &jwtService{issuer: issuer, revocations: revocations, SessionMgmntService: s}
+}
+
+// Create session and return its JWT
+func (s *jwtService) Create(ctx context.Context, session *models.SessionRequest) (string, error) {
+	sessionId, err := s.SessionMgmntService.Create(ctx, session)
+	if err != nil {
+		return "", err
+	}
+	details, err := s.SessionMgmntService.Get(ctx, &models.Session{SessionId: sessionId})
+	if err != nil {
+		return "", err
+	}
+	return s.issuer.Issue(token.Claims{
+		SessionId: sessionId,
+		Subject:   details.Subject,
+		ExpiresAt: details.ExpiresAt.Unix(),
+	})
+}
+
+// Destroy revoke the JWT and remove its session. The JWT is revoked even when its session
+// already expired on an idle timeout or was extended by a shorter TTL.
+func (s *jwtService) Destroy(ctx context.Context, session *models.DestroyRequest) error {
+	claims, err := s.parse(session.SessionId)
+	if err != nil {
+		return err
+	}
+	if err := s.revocations.Revoke(ctx, claims.SessionId, time.Unix(claims.ExpiresAt, 0)); err != nil {
+		return ErrRevocationList
+	}
+	err = s.SessionMgmntService.Destroy(ctx, &models.DestroyRequest{SessionId: claims.SessionId})
+	if err == ErrNotFound {
+		return nil
+	}
+	return err
+}
+
+// Extend verify the JWT and extend its session. The exp claim of the JWT is unchanged.
+func (s *jwtService) Extend(ctx context.Context, request *models.ExtendRequest) error {
+	sessionId, err := s.verify(ctx, request.SessionId)
+	if err != nil {
+		return err
+	}
+	extend := *request
+	extend.SessionId = sessionId
+	return s.SessionMgmntService.Extend(ctx, &extend)
+}
+
+// Get verify the JWT and return the details of its session
+func (s *jwtService) Get(ctx context.Context, session *models.Session) (*models.SessionDetails, error) {
+	sessionId, err := s.verify(ctx, session.SessionId)
+	if err != nil {
+		return nil, err
+	}
+	details, err := s.SessionMgmntService.Get(ctx, &models.Session{SessionId: sessionId})
+	if err != nil {
+		return nil, err
+	}
+	details.SessionId = session.SessionId
+	return details, nil
+}
+
+// GetData verify the JWT and return the payload of its session
+func (s *jwtService) GetData(ctx context.Context, session *models.Session) (*models.SessionData, error) {
+	sessionId, err := s.verify(ctx, session.SessionId)
+	if err != nil {
+		return nil, err
+	}
+	data, err := s.SessionMgmntService.GetData(ctx, &models.Session{SessionId: sessionId})
+	if err != nil {
+		return nil, err
+	}
+	data.SessionId = session.SessionId
+	return data, nil
+}
+
+// SetData verify the JWT and replace the payload of its session
+func (s *jwtService) SetData(ctx context.Context, request *models.SessionDataRequest) error {
+	sessionId, err := s.verify(ctx, request.SessionId)
+	if err != nil {
+		return err
+	}
+	return s.SessionMgmntService.SetData(ctx, &models.SessionDataRequest{SessionId: sessionId, Data: request.Data})
+}
+
+// PatchData verify the JWT and merge the payload of its session
+func (s *jwtService) PatchData(ctx context.Context, request *models.SessionDataRequest) error {
+	sessionId, err := s.verify(ctx, request.SessionId)
+	if err != nil {
+		return err
+	}
+	return s.SessionMgmntService.PatchData(ctx, &models.SessionDataRequest{SessionId: sessionId, Data: request.Data})
+}
+
+// Rotate verify the JWT and return the JWT of the session id replacing it. Verifiers cannot
+// see the tombstone of the old session id, so the old JWT is revoked at once instead of
+// after the grace period.
+func (s *jwtService) Rotate(ctx context.Context, request *models.RotateRequest) (string, error) {
+	claims, err := s.verifyClaims(ctx, request.SessionId)
+	if err != nil {
+		return "", err
+	}
+	sessionId, err := s.SessionMgmntService.Rotate(ctx, &models.RotateRequest{SessionId: claims.SessionId})
+	if err != nil {
+		return "", err
+	}
+	if err := s.revocations.Revoke(ctx, claims.SessionId, time.Unix(claims.ExpiresAt, 0)); err != nil {
+		return "", ErrRevocationList
+	}
+	return s.issuer.Issue(token.Claims{
+		SessionId: sessionId,
+		Subject:   claims.Subject,
+		ExpiresAt: claims.ExpiresAt,
+	})
+}
+
+// CreateBatch create the sessions of the batch and return their JWTs
+func (s *jwtService) CreateBatch(ctx context.Context, request *models.BatchCreateRequest) (*models.BatchResults, error) {
+	results, err := s.SessionMgmntService.CreateBatch(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	for i := range results.Results {
+		result := &results.Results[i]
+		if result.Err != nil {
+			continue
+		}
+		details, err := s.SessionMgmntService.Get(ctx, &models.Session{SessionId: result.SessionId})
+		if err == nil {
+			result.SessionId, err = s.issuer.Issue(token.Claims{
+				SessionId: result.SessionId,
+				Subject:   details.Subject,
+				ExpiresAt: details.ExpiresAt.Unix(),
+			})
+		}
+		if err != nil {
+			result.SessionId, result.Err = "", err
+		}
+	}
+	return results, nil
+}
+
+// ExtendBatch verify the JWTs and extend their sessions, an invalid or revoked JWT fails
+// its own session only
+func (s *jwtService) ExtendBatch(ctx context.Context, request *models.BatchExtendRequest) (*models.BatchResults, error) {
+	if err := checkBatchSize(len(request.Sessions)); err != nil {
+		return nil, err
+	}
+	results := make([]models.BatchResult, len(request.Sessions))
+	forward := &models.BatchExtendRequest{}
+	var indexes []int
+	for i, extend := range request.Sessions {
+		results[i].SessionId = extend.SessionId
+		sessionId, err := s.verify(ctx, extend.SessionId)
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+		extend.SessionId = sessionId
+		forward.Sessions = append(forward.Sessions, extend)
+		indexes = append(indexes, i)
+	}
+	if len(indexes) == 0 {
+		return &models.BatchResults{Results: results}, nil
+	}
+	forwarded, err := s.SessionMgmntService.ExtendBatch(ctx, forward)
+	return mergeBatch(results, indexes, forwarded, err)
+}
+
+// DestroyBatch revoke the JWTs and remove their sessions like Destroy, an invalid JWT or
+// a failed revocation fails its own session only
+func (s *jwtService) DestroyBatch(ctx context.Context, request *models.BatchDestroyRequest) (*models.BatchResults, error) {
+	if err := checkBatchSize(len(request.Sessions)); err != nil {
+		return nil, err
+	}
+	results := make([]models.BatchResult, len(request.Sessions))
+	forward := &models.BatchDestroyRequest{}
+	var indexes []int
+	for i, session := range request.Sessions {
+		results[i].SessionId = session.SessionId
+		claims, err := s.parse(session.SessionId)
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+		if err := s.revocations.Revoke(ctx, claims.SessionId, time.Unix(claims.ExpiresAt, 0)); err != nil {
+			results[i].Err = ErrRevocationList
+			continue
+		}
+		forward.Sessions = append(forward.Sessions, models.DestroyRequest{SessionId: claims.SessionId})
+		indexes = append(indexes, i)
+	}
+	if len(indexes) == 0 {
+		return &models.BatchResults{Results: results}, nil
+	}
+	forwarded, err := s.SessionMgmntService.DestroyBatch(ctx, forward)
+	merged, err := mergeBatch(results, indexes, forwarded, err)
+	if err != nil {
+		return nil, err
+	}
+	for i := range merged.Results {
+		if merged.Results[i].Err == ErrNotFound {
+			merged.Results[i].Err = nil
+		}
+	}
+	return merged, nil
+}
+
+// parse return the claims of a JWT issued by the issuer that has not expired
+func (s *jwtService) parse(t string) (token.Claims, error) {
+	claims, err := s.issuer.Parse(t, time.Now())
+	if err != nil {
+		return token.Claims{}, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+// verify return the session id of a JWT issued by the issuer that has neither expired
+// nor been revoked
+func (s *jwtService) verify(ctx context.Context, t string) (string, error) {
+	claims, err := s.verifyClaims(ctx, t)
+	if err != nil {
+		return "", err
+	}
+	return claims.SessionId, nil
+}
+
+// verifyClaims return the claims of a JWT issued by the issuer that has neither expired
+// nor been revoked
+func (s *jwtService) verifyClaims(ctx context.Context, t string) (token.Claims, error) {
+	claims, err := s.parse(t)
+	if err != nil {
+		return token.Claims{}, err
+	}
+	revoked, err := s.revocations.Revoked(ctx, claims.SessionId)
+	if err != nil {
+		return token.Claims{}, ErrRevocationList
+	}
+	if revoked {
+		return token.Claims{}, ErrInvalidToken
+	}
+	return claims, nil
+}
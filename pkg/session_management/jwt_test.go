@@ -0,0 +1,263 @@
+package session_management_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/hecomp/session-management/internal/models"
+	"github.com/hecomp/session-management/pkg/repository"
+	"github.com/hecomp/session-management/pkg/repository/repositoryfakes"
+	. "github.com/hecomp/session-management/pkg/session_management"
+	"github.com/hecomp/session-management/pkg/session_management/session_managementfakes"
+	"github.com/hecomp/session-management/pkg/token"
+)
+
+type JWTSuite struct {
+	service         SessionMgmntService
+	fakeService     *session_managementfakes.FakeSessionMgmntService
+	fakeRevocations *repositoryfakes.FakeSessionMgmntRepository
+	issuer          *token.JWTIssuer
+	expiresAt       time.Time
+}
+
+var _ = Describe("JWT", func() {
+
+	const sessionId = "90660b89-100e-4f8f-9801-2524df6fbe34"
+
+	s := &JWTSuite{}
+
+	issue := func() string {
+		jwt, err := s.issuer.Issue(token.Claims{SessionId: sessionId, Subject: "user-42", ExpiresAt: s.expiresAt.Unix()})
+		Expect(err).To(BeNil())
+		return jwt
+	}
+
+	BeforeEach(func() {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).To(BeNil())
+		s.issuer, err = token.NewJWTIssuer("session-management", token.JWTKey{Id: "primary", PrivateKey: key})
+		Expect(err).To(BeNil())
+		s.expiresAt = time.Now().Add(time.Minute)
+		s.fakeService = new(session_managementfakes.FakeSessionMgmntService)
+		s.fakeRevocations = new(repositoryfakes.FakeSessionMgmntRepository)
+		s.service = NewJWTService(s.issuer, s.fakeRevocations, s.fakeService)
+	})
+
+	Context("Create()", func() {
+		It("returns a JWT carrying the session id, subject and expiration", func() {
+			s.fakeService.CreateReturns(sessionId, nil)
+			s.fakeService.GetReturns(&SessionDetails{SessionId: sessionId, Subject: "user-42", ExpiresAt: s.expiresAt}, nil)
+			jwt, err := s.service.Create(ctx, &SessionRequest{TTL: 60, Subject: "user-42"})
+			Expect(err).To(BeNil())
+
+			claims, err := s.issuer.Parse(jwt, time.Now())
+			Expect(err).To(BeNil())
+			Expect(claims.SessionId).To(Equal(sessionId))
+			Expect(claims.Subject).To(Equal("user-42"))
+			Expect(claims.ExpiresAt).To(Equal(s.expiresAt.Unix()))
+		})
+		It("passes create errors through", func() {
+			s.fakeService.CreateReturns("", ErrSessionLimit)
+			_, err := s.service.Create(ctx, &SessionRequest{Subject: "user-42"})
+			Expect(err).To(Equal(ErrSessionLimit))
+		})
+	})
+
+	Context("Destroy()", func() {
+		It("revokes the JWT until it expires and removes its session", func() {
+			Expect(s.service.Destroy(ctx, &DestroyRequest{SessionId: issue()})).To(Succeed())
+			_, revoked, expiration := s.fakeRevocations.RevokeArgsForCall(0)
+			Expect(revoked).To(Equal(sessionId))
+			Expect(expiration).To(Equal(time.Unix(s.expiresAt.Unix(), 0)))
+			_, destroyRequest := s.fakeService.DestroyArgsForCall(0)
+			Expect(destroyRequest.SessionId).To(Equal(sessionId))
+		})
+		It("revokes the JWT of a session that already expired", func() {
+			s.fakeService.DestroyReturns(repository.ErrNotFound)
+			Expect(s.service.Destroy(ctx, &DestroyRequest{SessionId: issue()})).To(Succeed())
+			Expect(s.fakeRevocations.RevokeCallCount()).To(Equal(1))
+		})
+		It("rejects a forged JWT before the next service", func() {
+			err := s.service.Destroy(ctx, &DestroyRequest{SessionId: sessionId})
+			Expect(err).To(Equal(ErrInvalidToken))
+			Expect(s.fakeRevocations.RevokeCallCount()).To(Equal(0))
+			Expect(s.fakeService.DestroyCallCount()).To(Equal(0))
+		})
+		It("does not remove the session when the JWT cannot be revoked", func() {
+			s.fakeRevocations.RevokeReturns(errors.New("error revoke"))
+			err := s.service.Destroy(ctx, &DestroyRequest{SessionId: issue()})
+			Expect(err).To(Equal(ErrRevocationList))
+			Expect(s.fakeService.DestroyCallCount()).To(Equal(0))
+		})
+	})
+
+	Context("Get()", func() {
+		It("returns the details under the JWT", func() {
+			jwt := issue()
+			s.fakeService.GetReturns(&SessionDetails{SessionId: sessionId, Exists: true}, nil)
+			details, err := s.service.Get(ctx, &Session{SessionId: jwt})
+			Expect(err).To(BeNil())
+			Expect(details.SessionId).To(Equal(jwt))
+			_, revokedId := s.fakeRevocations.RevokedArgsForCall(0)
+			Expect(revokedId).To(Equal(sessionId))
+			_, getRequest := s.fakeService.GetArgsForCall(0)
+			Expect(getRequest.SessionId).To(Equal(sessionId))
+		})
+		It("rejects a revoked JWT", func() {
+			s.fakeRevocations.RevokedReturns(true, nil)
+			_, err := s.service.Get(ctx, &Session{SessionId: issue()})
+			Expect(err).To(Equal(ErrInvalidToken))
+			Expect(s.fakeService.GetCallCount()).To(Equal(0))
+		})
+		It("rejects an expired JWT", func() {
+			s.expiresAt = time.Now().Add(-time.Second)
+			_, err := s.service.Get(ctx, &Session{SessionId: issue()})
+			Expect(err).To(Equal(ErrInvalidToken))
+			Expect(s.fakeService.GetCallCount()).To(Equal(0))
+		})
+	})
+
+	Context("Extend(), GetData(), SetData() and PatchData()", func() {
+		It("pass the session id of a valid JWT", func() {
+			jwt := issue()
+			data := map[string]interface{}{"role": "admin"}
+			Expect(s.service.Extend(ctx, &ExtendRequest{SessionId: jwt, TTL: 60})).To(Succeed())
+			_, extendRequest := s.fakeService.ExtendArgsForCall(0)
+			Expect(extendRequest).To(Equal(&ExtendRequest{SessionId: sessionId, TTL: 60}))
+			s.fakeService.GetDataReturns(&SessionData{SessionId: sessionId, Data: data}, nil)
+			got, err := s.service.GetData(ctx, &Session{SessionId: jwt})
+			Expect(err).To(BeNil())
+			Expect(got).To(Equal(&SessionData{SessionId: jwt, Data: data}))
+			Expect(s.service.SetData(ctx, &SessionDataRequest{SessionId: jwt, Data: data})).To(Succeed())
+			_, setRequest := s.fakeService.SetDataArgsForCall(0)
+			Expect(setRequest.SessionId).To(Equal(sessionId))
+			Expect(s.service.PatchData(ctx, &SessionDataRequest{SessionId: jwt, Data: data})).To(Succeed())
+			_, patchRequest := s.fakeService.PatchDataArgsForCall(0)
+			Expect(patchRequest.SessionId).To(Equal(sessionId))
+		})
+		It("fail when the revocation list cannot be read", func() {
+			s.fakeRevocations.RevokedReturns(false, errors.New("error find"))
+			Expect(s.service.Extend(ctx, &ExtendRequest{SessionId: issue()})).To(Equal(ErrRevocationList))
+			Expect(s.fakeService.ExtendCallCount()).To(Equal(0))
+		})
+	})
+
+	Context("Rotate()", func() {
+		It("revokes the old JWT and returns a JWT of the new session id with the same expiration", func() {
+			s.fakeService.RotateReturns("5b6d0be2-d47b-4d0b-9e4c-6e0a5f1a0d11", nil)
+			jwt, err := s.service.Rotate(ctx, &RotateRequest{SessionId: issue()})
+			Expect(err).To(BeNil())
+			_, rotateRequest := s.fakeService.RotateArgsForCall(0)
+			Expect(rotateRequest.SessionId).To(Equal(sessionId))
+
+			_, revoked, expiration := s.fakeRevocations.RevokeArgsForCall(0)
+			Expect(revoked).To(Equal(sessionId))
+			Expect(expiration).To(Equal(time.Unix(s.expiresAt.Unix(), 0)))
+
+			claims, err := s.issuer.Parse(jwt, time.Now())
+			Expect(err).To(BeNil())
+			Expect(claims.SessionId).To(Equal("5b6d0be2-d47b-4d0b-9e4c-6e0a5f1a0d11"))
+			Expect(claims.Subject).To(Equal("user-42"))
+			Expect(claims.ExpiresAt).To(Equal(s.expiresAt.Unix()))
+		})
+		It("rejects a revoked JWT", func() {
+			s.fakeRevocations.RevokedReturns(true, nil)
+			_, err := s.service.Rotate(ctx, &RotateRequest{SessionId: issue()})
+			Expect(err).To(Equal(ErrInvalidToken))
+			Expect(s.fakeService.RotateCallCount()).To(Equal(0))
+		})
+	})
+
+	Context("CreateBatch()", func() {
+		It("returns the JWTs of the created sessions", func() {
+			s.fakeService.CreateBatchReturns(&BatchResults{Results: []BatchResult{
+				{SessionId: sessionId},
+				{Err: ErrSessionLimit},
+			}}, nil)
+			s.fakeService.GetReturns(&SessionDetails{SessionId: sessionId, Subject: "user-42", ExpiresAt: s.expiresAt}, nil)
+			results, err := s.service.CreateBatch(ctx, &BatchCreateRequest{Sessions: []SessionRequest{{Subject: "user-42"}, {Subject: "user-42"}}})
+			Expect(err).To(BeNil())
+			Expect(results.Results[1]).To(Equal(BatchResult{Err: ErrSessionLimit}))
+
+			claims, err := s.issuer.Parse(results.Results[0].SessionId, time.Now())
+			Expect(err).To(BeNil())
+			Expect(claims.SessionId).To(Equal(sessionId))
+			Expect(claims.ExpiresAt).To(Equal(s.expiresAt.Unix()))
+		})
+	})
+
+	Context("ExtendBatch()", func() {
+		It("fails the revoked JWTs and passes the session ids of the valid ones", func() {
+			valid, revoked := issue(), issue()
+			s.fakeRevocations.RevokedReturnsOnCall(1, true, nil)
+			s.fakeService.ExtendBatchReturns(&BatchResults{Results: []BatchResult{{SessionId: sessionId}}}, nil)
+			results, err := s.service.ExtendBatch(ctx, &BatchExtendRequest{Sessions: []ExtendRequest{{SessionId: valid}, {SessionId: revoked}}})
+			Expect(err).To(BeNil())
+			Expect(results.Results).To(Equal([]BatchResult{
+				{SessionId: valid},
+				{SessionId: revoked, Err: ErrInvalidToken},
+			}))
+			_, extendRequest := s.fakeService.ExtendBatchArgsForCall(0)
+			Expect(extendRequest.Sessions).To(Equal([]ExtendRequest{{SessionId: sessionId}}))
+		})
+	})
+
+	Context("DestroyBatch()", func() {
+		It("revokes the JWTs and removes their sessions, even when they already expired", func() {
+			jwt := issue()
+			s.fakeService.DestroyBatchReturns(&BatchResults{Results: []BatchResult{{SessionId: sessionId, Err: repository.ErrNotFound}}}, nil)
+			results, err := s.service.DestroyBatch(ctx, &BatchDestroyRequest{Sessions: []DestroyRequest{{SessionId: jwt}, {SessionId: sessionId}}})
+			Expect(err).To(BeNil())
+			Expect(results.Results).To(Equal([]BatchResult{
+				{SessionId: jwt},
+				{SessionId: sessionId, Err: ErrInvalidToken},
+			}))
+			Expect(s.fakeRevocations.RevokeCallCount()).To(Equal(1))
+			_, destroyRequest := s.fakeService.DestroyBatchArgsForCall(0)
+			Expect(destroyRequest.Sessions).To(Equal([]DestroyRequest{{SessionId: sessionId}}))
+		})
+		It("does not remove the sessions whose JWT cannot be revoked", func() {
+			s.fakeRevocations.RevokeReturns(errors.New("error revoke"))
+			results, err := s.service.DestroyBatch(ctx, &BatchDestroyRequest{Sessions: []DestroyRequest{{SessionId: issue()}}})
+			Expect(err).To(BeNil())
+			Expect(results.Results[0].Err).To(Equal(ErrRevocationList))
+			Expect(s.fakeService.DestroyBatchCallCount()).To(Equal(0))
+		})
+	})
+
+	Context("MakeJWTHandler()", func() {
+		It("serves the JWKS and the revocation list", func() {
+			s.fakeRevocations.ListRevokedReturns(&Revocations{List: []Revocation{{SessionId: sessionId, ExpiresAt: s.expiresAt.Unix()}}}, nil)
+			handler := MakeJWTHandler(s.issuer, s.fakeRevocations)
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, JWKSPath, nil))
+			Expect(rec.Code).To(Equal(http.StatusOK))
+			var jwks token.JWKS
+			Expect(json.NewDecoder(rec.Body).Decode(&jwks)).To(Succeed())
+			Expect(jwks).To(Equal(s.issuer.JWKS()))
+
+			rec = httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, RevocationsPath, nil))
+			Expect(rec.Code).To(Equal(http.StatusOK))
+			var revocations Revocations
+			Expect(json.NewDecoder(rec.Body).Decode(&revocations)).To(Succeed())
+			Expect(revocations.List).To(Equal([]Revocation{{SessionId: sessionId, ExpiresAt: s.expiresAt.Unix()}}))
+		})
+		It("fails when the revocation list cannot be read", func() {
+			s.fakeRevocations.ListRevokedReturns(nil, errors.New("error list"))
+			rec := httptest.NewRecorder()
+			MakeJWTHandler(s.issuer, s.fakeRevocations).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, RevocationsPath, nil))
+			Expect(rec.Code).To(Equal(http.StatusInternalServerError))
+		})
+	})
+})
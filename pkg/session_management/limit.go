@@ -0,0 +1,80 @@
+package session_management
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	. "github.com/hecomp/session-management/internal/models"
+)
+
+// LimitPolicy decides what happens when a subject already holds the maximum number
+// of active sessions and asks for a new one.
+type LimitPolicy string
+
+const (
+	// PolicyReject refuses the new session with ErrSessionLimit
+	PolicyReject LimitPolicy = "reject"
+	// PolicyEvictOldest destroys the session of the subject that was created first
+	PolicyEvictOldest LimitPolicy = "evict-oldest"
+	// PolicyEvictLRU destroys the session of the subject that was least recently used
+	PolicyEvictLRU LimitPolicy = "evict-lru"
+)
+
+var (
+	// ErrSessionLimit is returned when the subject reached its concurrent session limit.
+	ErrSessionLimit = errors.New("session limit reached")
+)
+
+// SessionLimit is the per-subject concurrent session limit enforced by Create. A Max
+// of zero disables the limit. The limit is only enforced within a process: the count of
+// the sessions of a subject and the creation of the new one are not atomic in the store,
+// so replicas sharing a Redis or SQL store may let a subject exceed it under concurrent
+// creates.
+type SessionLimit struct {
+	Max    int
+	Policy LimitPolicy
+}
+
+// ParseLimitPolicy returns the LimitPolicy named by s
+func ParseLimitPolicy(s string) (LimitPolicy, error) {
+	switch policy := LimitPolicy(s); policy {
+	case PolicyReject, PolicyEvictOldest, PolicyEvictLRU:
+		return policy, nil
+	}
+	return "", fmt.Errorf("unknown session limit policy %q", s)
+}
+
+// enforceLimit makes room for one more session of the subject according to the
+// limit policy, the caller must hold s.mu
+func (s sessionMgmntService) enforceLimit(ctx context.Context, subject string) error {
+	sessions, err := s.repo.ListSubjectDetails(ctx, subject)
+	if err != nil {
+		return err
+	}
+	if len(sessions) < s.limit.Max {
+		return nil
+	}
+
+	switch s.limit.Policy {
+	case PolicyEvictOldest:
+		sort.Slice(sessions, func(i, j int) bool {
+			return sessions[i].CreatedAt.Before(sessions[j].CreatedAt)
+		})
+	case PolicyEvictLRU:
+		sort.Slice(sessions, func(i, j int) bool {
+			return sessions[i].LastAccess.Before(sessions[j].LastAccess)
+		})
+	default:
+		return ErrSessionLimit
+	}
+
+	for _, session := range sessions[:len(sessions)-s.limit.Max+1] {
+		s.logger.Log("message", "evicting session over the subject limit", "subject", subject, "policy", s.limit.Policy)
+		if err := s.repo.Destroy(ctx, &DestroyRequest{SessionId: session.SessionId}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -1,11 +1,13 @@
 package session_management
 
 import (
+	"context"
 	"time"
 
 	"github.com/go-kit/kit/log"
 
 	"github.com/hecomp/session-management/internal/models"
+	"github.com/hecomp/session-management/pkg/auth"
 )
 
 //loggingService has the implementation of the logging middleware methods.
@@ -20,50 +22,204 @@ func NewLoggingService(logger log.Logger, s SessionMgmntService) SessionMgmntSer
 }
 
 // Create session is stored in-memory
-func (s *loggingService) Create(session *models.SessionRequest) (sig string, err error)  {
+func (s *loggingService) Create(ctx context.Context, session *models.SessionRequest) (sig string, err error)  {
 	defer func(begin time.Time) {
-		s.logger.Log(
+		s.with(ctx).Log(
 			"method", "create",
 			"took", time.Since(begin),
 			"err", err,
 		)
 	}(time.Now())
-	return s.SessionMgmntService.Create(session)
+	return s.SessionMgmntService.Create(ctx, session)
 }
 
 //Destroy remove the session from its cache
-func (s *loggingService) Destroy(session *models.DestroyRequest) (err error)  {
+func (s *loggingService) Destroy(ctx context.Context, session *models.DestroyRequest) (err error)  {
 	defer func(begin time.Time) {
-		s.logger.Log(
+		s.with(ctx).Log(
 			"method", "destroy",
 			"took", time.Since(begin),
 			"err", err,
 		)
 	}(time.Now())
-	return s.SessionMgmntService.Destroy(session)
+	return s.SessionMgmntService.Destroy(ctx, session)
 }
 
 //Extend session id with the provided TTL
-func (s *loggingService) Extend(session *models.ExtendRequest) (err error)  {
+func (s *loggingService) Extend(ctx context.Context, session *models.ExtendRequest) (err error)  {
 	defer func(begin time.Time) {
-		s.logger.Log(
+		s.with(ctx).Log(
 			"method", "extend",
 			"took", time.Since(begin),
 			"err", err,
 		)
 	}(time.Now())
-	return s.SessionMgmntService.Extend(session)
+	return s.SessionMgmntService.Extend(ctx, session)
+}
+
+//Get validate the session and return its details
+func (s *loggingService) Get(ctx context.Context, session *models.Session) (details *models.SessionDetails, err error)  {
+	defer func(begin time.Time) {
+		s.with(ctx).Log(
+			"method", "get",
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.SessionMgmntService.Get(ctx, session)
 }
 
 //List
-func (s *loggingService) List() (sig *models.Sessions, err error)  {
+func (s *loggingService) List(ctx context.Context, request *models.ListRequest) (sig *models.Sessions, err error)  {
 	defer func(begin time.Time) {
-		s.logger.Log(
+		s.with(ctx).Log(
 			"method", "list",
+			"subject", request.Subject,
+			"limit", request.Limit,
 			"took", time.Since(begin),
 			"err", err,
 		)
 	}(time.Now())
-	return s.SessionMgmntService.List()
+	return s.SessionMgmntService.List(ctx, request)
 }
 
+//ListSubject return a list of the sessions of the subject
+func (s *loggingService) ListSubject(ctx context.Context, request *models.SubjectRequest) (sig *models.Sessions, err error)  {
+	defer func(begin time.Time) {
+		s.with(ctx).Log(
+			"method", "listSubject",
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.SessionMgmntService.ListSubject(ctx, request)
+}
+
+//DestroySubject remove every session of the subject
+func (s *loggingService) DestroySubject(ctx context.Context, request *models.SubjectRequest) (sig *models.Sessions, err error)  {
+	defer func(begin time.Time) {
+		s.with(ctx).Log(
+			"method", "destroySubject",
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.SessionMgmntService.DestroySubject(ctx, request)
+}
+
+//GetData return the data attached to the session
+func (s *loggingService) GetData(ctx context.Context, session *models.Session) (data *models.SessionData, err error)  {
+	defer func(begin time.Time) {
+		s.with(ctx).Log(
+			"method", "getData",
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.SessionMgmntService.GetData(ctx, session)
+}
+
+//SetData replace the data attached to the session
+func (s *loggingService) SetData(ctx context.Context, request *models.SessionDataRequest) (err error)  {
+	defer func(begin time.Time) {
+		s.with(ctx).Log(
+			"method", "setData",
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.SessionMgmntService.SetData(ctx, request)
+}
+
+//PatchData update individual keys of the data attached to the session
+func (s *loggingService) PatchData(ctx context.Context, request *models.SessionDataRequest) (err error)  {
+	defer func(begin time.Time) {
+		s.with(ctx).Log(
+			"method", "patchData",
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.SessionMgmntService.PatchData(ctx, request)
+}
+
+//Rotate replace the session id by a fresh one
+func (s *loggingService) Rotate(ctx context.Context, request *models.RotateRequest) (sessionId string, err error)  {
+	defer func(begin time.Time) {
+		s.with(ctx).Log(
+			"method", "rotate",
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.SessionMgmntService.Rotate(ctx, request)
+}
+
+//CreateBatch create the sessions of the batch
+func (s *loggingService) CreateBatch(ctx context.Context, request *models.BatchCreateRequest) (results *models.BatchResults, err error)  {
+	defer func(begin time.Time) {
+		s.with(ctx).Log(
+			"method", "create_batch",
+			"sessions", len(request.Sessions),
+			"failed", failures(results),
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.SessionMgmntService.CreateBatch(ctx, request)
+}
+
+//ExtendBatch extend the sessions of the batch
+func (s *loggingService) ExtendBatch(ctx context.Context, request *models.BatchExtendRequest) (results *models.BatchResults, err error)  {
+	defer func(begin time.Time) {
+		s.with(ctx).Log(
+			"method", "extend_batch",
+			"sessions", len(request.Sessions),
+			"failed", failures(results),
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.SessionMgmntService.ExtendBatch(ctx, request)
+}
+
+//DestroyBatch remove the sessions of the batch
+func (s *loggingService) DestroyBatch(ctx context.Context, request *models.BatchDestroyRequest) (results *models.BatchResults, err error)  {
+	defer func(begin time.Time) {
+		s.with(ctx).Log(
+			"method", "destroy_batch",
+			"sessions", len(request.Sessions),
+			"failed", failures(results),
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.SessionMgmntService.DestroyBatch(ctx, request)
+}
+
+// failures counts the sessions of the batch that failed
+func failures(results *models.BatchResults) int {
+	if results == nil {
+		return 0
+	}
+	failed := 0
+	for _, result := range results.Results {
+		if result.Err != nil {
+			failed++
+		}
+	}
+	return failed
+}
+
+// with returns the logger of the service annotated with the authenticated principal and
+// the client certificate subject of the request, when known, for the audit trail
+func (s *loggingService) with(ctx context.Context) log.Logger {
+	logger := s.logger
+	if principal, found := auth.FromContext(ctx); found {
+		logger = log.With(logger, "principal", principal.Name)
+	}
+	if subject, found := auth.ClientFromContext(ctx); found {
+		logger = log.With(logger, "client", subject)
+	}
+	return logger
+}
@@ -1,13 +1,16 @@
 package session_management
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/go-kit/kit/log"
 	"github.com/google/uuid"
+	"sync"
 	"time"
 
 	. "github.com/hecomp/session-management/internal/models"
+	"github.com/hecomp/session-management/pkg/in_memory"
 	. "github.com/hecomp/session-management/pkg/repository"
 )
 
@@ -15,6 +18,14 @@ import (
 const (
 	DefaultTime = 30
 	MaxTTL      = 300
+	// RotateGrace is how long a rotated session id keeps resolving to the session that replaced it
+	RotateGrace = 10 * time.Second
+	// DefaultListLimit is the number of sessions of a listed page without a limit
+	DefaultListLimit = 100
+	// MaxListLimit caps the number of sessions of a listed page
+	MaxListLimit = 1000
+	// MaxBatchSize caps the number of sessions of a batch
+	MaxBatchSize = 1000
 )
 
 var (
@@ -24,38 +35,98 @@ var (
 	ErrDestroy            = errors.New("error destroying session id")
 	ErrExtend             = errors.New("error extending session")
 	ErrList               = errors.New("error listing session")
+	ErrGet                = errors.New("error getting session")
+	ErrListSubject        = errors.New("error listing subject sessions")
+	ErrDestroySubject     = errors.New("error destroying subject sessions")
+	ErrGetData            = errors.New("error getting session data")
+	ErrSetData            = errors.New("error setting session data")
+	ErrRotate             = errors.New("error rotating session id")
 )
 
 
 // SessionMgmntService is the interface that provides session management APIs.
 //go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 . SessionMgmntService
 type SessionMgmntService interface {
-	Create(session *SessionRequest) (string, error)
-	Destroy(session *DestroyRequest) error
-	Extend(request *ExtendRequest) error
-	List() (*Sessions, error)
+	Create(ctx context.Context, session *SessionRequest) (string, error)
+	Destroy(ctx context.Context, session *DestroyRequest) error
+	Extend(ctx context.Context, request *ExtendRequest) error
+	Get(ctx context.Context, session *Session) (*SessionDetails, error)
+	List(ctx context.Context, request *ListRequest) (*Sessions, error)
+	ListSubject(ctx context.Context, request *SubjectRequest) (*Sessions, error)
+	DestroySubject(ctx context.Context, request *SubjectRequest) (*Sessions, error)
+	GetData(ctx context.Context, session *Session) (*SessionData, error)
+	SetData(ctx context.Context, request *SessionDataRequest) error
+	PatchData(ctx context.Context, request *SessionDataRequest) error
+	Rotate(ctx context.Context, request *RotateRequest) (string, error)
+	CreateBatch(ctx context.Context, request *BatchCreateRequest) (*BatchResults, error)
+	ExtendBatch(ctx context.Context, request *BatchExtendRequest) (*BatchResults, error)
+	DestroyBatch(ctx context.Context, request *BatchDestroyRequest) (*BatchResults, error)
+}
+
+// SessionTTL holds the TTL defaults and caps of the sessions, in seconds. A zero field
+// falls back to DefaultTime and MaxTTL.
+type SessionTTL struct {
+	// Default is the TTL of the sessions created or extended without one
+	Default int64
+	// Max caps the TTL of an extension
+	Max int64
 }
 
 // sessionMgmntService has the implementation of the service methods
 type sessionMgmntService struct {
 	logger log.Logger
 	repo   SessionMgmntRepository
+	limit  SessionLimit
+	ttl    SessionTTL
+	mu     *sync.Mutex
 }
 
 // NewService create a instance of session management service
 func NewService(repo SessionMgmntRepository, logger log.Logger) SessionMgmntService {
-	return &sessionMgmntService{repo: repo, logger: logger}
+	return NewServiceWithLimit(repo, SessionLimit{}, logger)
+}
+
+// NewServiceWithLimit create a instance of session management service that enforces
+// the given per-subject concurrent session limit on Create
+func NewServiceWithLimit(repo SessionMgmntRepository, limit SessionLimit, logger log.Logger) SessionMgmntService {
+	return NewServiceWithTTL(repo, limit, SessionTTL{}, logger)
+}
+
+// NewServiceWithTTL create a instance of session management service like NewServiceWithLimit,
+// with the given TTL defaults and caps
+func NewServiceWithTTL(repo SessionMgmntRepository, limit SessionLimit, ttl SessionTTL, logger log.Logger) SessionMgmntService {
+	if ttl.Default == 0 {
+		ttl.Default = DefaultTime
+	}
+	if ttl.Max == 0 {
+		ttl.Max = MaxTTL
+	}
+	return &sessionMgmntService{repo: repo, limit: limit, ttl: ttl, mu: &sync.Mutex{}, logger: logger}
 }
 
 // Create session is stored in-memory
-func (s sessionMgmntService) Create(session *SessionRequest) (string, error) {
-	if session.TTL == 0 {// default should be 30 seconds
-		session.TTL = DefaultTime
+func (s sessionMgmntService) Create(ctx context.Context, session *SessionRequest) (string, error) {
+	if err := s.sessionTTL(session); err != nil {
+		return "", err
+	}
+
+	// the creates of this process are serialized, those of other replicas are not
+	if session.Subject != "" && s.limit.Max > 0 {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		if err := s.enforceLimit(ctx, session.Subject); err != nil {
+			s.logger.Log("message", "unable to enforce the subject session limit", "error", err)
+			if err == ErrSessionLimit {
+				return "", err
+			}
+			return "", ErrCreate
+		}
 	}
 
 	sessionId := s.GenerateSessionId()
 	expiration := time.Now().Add(time.Second * time.Duration(session.TTL))
-	if err := s.repo.Create(sessionId, expiration); err != nil {
+	if err := s.repo.Create(ctx, sessionId, session, expiration); err != nil {
 		s.logger.Log("message", "unable to create session to in-memory store", "error", err)
 		return "", ErrEmpty
 	}
@@ -64,12 +135,12 @@ func (s sessionMgmntService) Create(session *SessionRequest) (string, error) {
 }
 
 // Destroy remove the session from its cache
-func (s sessionMgmntService) Destroy(session *DestroyRequest) error {
+func (s sessionMgmntService) Destroy(ctx context.Context, session *DestroyRequest) error {
 	if session.SessionId == "" {
 		return ErrEmpty
 	}
 
-	found, err := s.repo.Exist(session.SessionId)
+	found, err := s.repo.Exist(ctx, session.SessionId)
 	if err != nil {
 		s.logger.Log("message", "unable to find session to in-memory store", "error", err)
 		return ErrExist
@@ -79,7 +150,7 @@ func (s sessionMgmntService) Destroy(session *DestroyRequest) error {
 		return ErrNotFound
 	}
 
-	if err := s.repo.Destroy(session); err != nil {
+	if err := s.repo.Destroy(ctx, session); err != nil {
 		s.logger.Log("message", "unable to destroy session in the in-memory store", "error", err)
 		return ErrDestroy
 	}
@@ -87,20 +158,14 @@ func (s sessionMgmntService) Destroy(session *DestroyRequest) error {
 }
 
 // Extend with the provided TTL or if the TTL is not provided then by 30 seconds
-func (s sessionMgmntService) Extend(request *ExtendRequest) error {
+func (s sessionMgmntService) Extend(ctx context.Context, request *ExtendRequest) error {
 	if request.SessionId == "" {
 		return ErrEmpty
 	}
 
-	if request.TTL == 0 {
-		request.TTL = DefaultTime
-	}
-
-	if request.TTL > MaxTTL {
-		request.TTL = MaxTTL
-	}
+	s.extendTTL(request)
 
-	found, err := s.repo.Extend(request)
+	found, err := s.repo.Extend(ctx, request)
 	if err != nil {
 		s.logger.Log("message", "unable to extend session to in-memory store", "error", err)
 		return ErrExtend
@@ -112,20 +177,339 @@ func (s sessionMgmntService) Extend(request *ExtendRequest) error {
 	return nil
 }
 
-// List return a list of all the sessions that the service is currently tracking
-func (s sessionMgmntService) List() (*Sessions, error) {
-	sessions, err := s.repo.List()
+// CreateBatch creates the sessions in a single batch and reports the session id or the
+// error of each of them. Sessions of a subject are created one at a time when a subject
+// limit is set, so the limit counts the sessions created before them.
+func (s sessionMgmntService) CreateBatch(ctx context.Context, request *BatchCreateRequest) (*BatchResults, error) {
+	if err := checkBatchSize(len(request.Sessions)); err != nil {
+		return nil, err
+	}
+
+	results := make([]BatchResult, len(request.Sessions))
+	var sessions []NewSession
+	var indexes []int
+	now := time.Now()
+	for i := range request.Sessions {
+		session := &request.Sessions[i]
+		if session.Subject != "" && s.limit.Max > 0 {
+			results[i].SessionId, results[i].Err = s.Create(ctx, session)
+			continue
+		}
+		if err := s.sessionTTL(session); err != nil {
+			results[i].Err = err
+			continue
+		}
+		sessions = append(sessions, NewSession{
+			SessionId:  s.GenerateSessionId(),
+			Request:    session,
+			Expiration: now.Add(time.Second * time.Duration(session.TTL)),
+		})
+		indexes = append(indexes, i)
+	}
+	if len(sessions) == 0 {
+		return &BatchResults{Results: results}, nil
+	}
+
+	if err := s.repo.CreateBatch(ctx, sessions); err != nil {
+		s.logger.Log("message", "unable to create sessions to in-memory store", "error", err)
+		for _, i := range indexes {
+			results[i].Err = ErrCreate
+		}
+		return &BatchResults{Results: results}, nil
+	}
+	for j, i := range indexes {
+		results[i].SessionId = sessions[j].SessionId
+	}
+	return &BatchResults{Results: results}, nil
+}
+
+// ExtendBatch extends the sessions in a single batch like Extend and reports the error of
+// each of them
+func (s sessionMgmntService) ExtendBatch(ctx context.Context, request *BatchExtendRequest) (*BatchResults, error) {
+	if err := checkBatchSize(len(request.Sessions)); err != nil {
+		return nil, err
+	}
+
+	results := make([]BatchResult, len(request.Sessions))
+	var requests []ExtendRequest
+	var indexes []int
+	for i := range request.Sessions {
+		extend := &request.Sessions[i]
+		results[i].SessionId = extend.SessionId
+		if extend.SessionId == "" {
+			results[i].Err = ErrInvalidArgument
+			continue
+		}
+		s.extendTTL(extend)
+		requests = append(requests, *extend)
+		indexes = append(indexes, i)
+	}
+	if len(requests) == 0 {
+		return &BatchResults{Results: results}, nil
+	}
+
+	found, err := s.repo.ExtendBatch(ctx, requests)
+	if err != nil {
+		s.logger.Log("message", "unable to extend sessions to in-memory store", "error", err)
+	}
+	for j, i := range indexes {
+		switch {
+		case err != nil:
+			results[i].Err = ErrExtend
+		case !found[j]:
+			results[i].Err = ErrNotFound
+		}
+	}
+	return &BatchResults{Results: results}, nil
+}
+
+// DestroyBatch removes the sessions in a single batch like Destroy and reports the error
+// of each of them
+func (s sessionMgmntService) DestroyBatch(ctx context.Context, request *BatchDestroyRequest) (*BatchResults, error) {
+	if err := checkBatchSize(len(request.Sessions)); err != nil {
+		return nil, err
+	}
+
+	results := make([]BatchResult, len(request.Sessions))
+	var sessionIds []string
+	var indexes []int
+	for i, session := range request.Sessions {
+		results[i].SessionId = session.SessionId
+		if session.SessionId == "" {
+			results[i].Err = ErrInvalidArgument
+			continue
+		}
+		sessionIds = append(sessionIds, session.SessionId)
+		indexes = append(indexes, i)
+	}
+	if len(sessionIds) == 0 {
+		return &BatchResults{Results: results}, nil
+	}
+
+	found, err := s.repo.DestroyBatch(ctx, sessionIds)
+	if err != nil {
+		s.logger.Log("message", "unable to destroy sessions in the in-memory store", "error", err)
+	}
+	for j, i := range indexes {
+		switch {
+		case err != nil:
+			results[i].Err = ErrDestroy
+		case !found[j]:
+			results[i].Err = ErrNotFound
+		}
+	}
+	return &BatchResults{Results: results}, nil
+}
+
+// sessionTTL validates the TTLs of the new session and applies the default TTL
+func (s sessionMgmntService) sessionTTL(session *SessionRequest) error {
+	if session.TTL < 0 || session.IdleTimeout < 0 || session.MaxLifetime < 0 {
+		return ErrInvalidArgument
+	}
+
+	if session.TTL == 0 {// default should be 30 seconds, or the idle timeout when there is one
+		session.TTL = s.ttl.Default
+		if session.IdleTimeout > 0 {
+			session.TTL = session.IdleTimeout
+		}
+	}
+	return nil
+}
+
+// extendTTL applies the default TTL and the cap to the extension
+func (s sessionMgmntService) extendTTL(request *ExtendRequest) {
+	if request.TTL == 0 {
+		request.TTL = s.ttl.Default
+	}
+
+	if request.TTL > s.ttl.Max {
+		request.TTL = s.ttl.Max
+	}
+}
+
+// checkBatchSize refuses empty batches and batches over MaxBatchSize
+func checkBatchSize(size int) error {
+	if size == 0 || size > MaxBatchSize {
+		return ErrInvalidArgument
+	}
+	return nil
+}
+
+// Get validate the session and return when it was created and when it expires
+func (s sessionMgmntService) Get(ctx context.Context, session *Session) (*SessionDetails, error) {
+	if session.SessionId == "" {
+		return nil, ErrEmpty
+	}
+
+	details, found, err := s.repo.Get(ctx, session.SessionId)
+	if err != nil {
+		s.logger.Log("message", "unable to get session from in-memory store", "error", err)
+		return nil, ErrGet
+	}
+	if !found {
+		s.logger.Log("message", "not found session to in-memory store", "error", ErrNotFound.Error())
+		return nil, ErrNotFound
+	}
+	return details, nil
+}
+
+// List return a page of the sessions that the service is currently tracking, selected and
+// ordered by the request. The page holds DefaultListLimit sessions unless the request
+// limits it, up to MaxListLimit.
+func (s sessionMgmntService) List(ctx context.Context, request *ListRequest) (*Sessions, error) {
+	if request.Limit < 0 || request.MinTTL < 0 || request.MaxTTL < 0 {
+		return nil, ErrInvalidArgument
+	}
+	switch request.Sort {
+	case "", string(in_memory.SortByCreation), string(in_memory.SortByExpiration):
+	default:
+		return nil, ErrInvalidArgument
+	}
+	switch request.Order {
+	case "", "asc", "desc":
+	default:
+		return nil, ErrInvalidArgument
+	}
+
+	listRequest := *request
+	if listRequest.Limit == 0 {
+		listRequest.Limit = DefaultListLimit
+	}
+	if listRequest.Limit > MaxListLimit {
+		listRequest.Limit = MaxListLimit
+	}
+
+	sessions, err := s.repo.List(ctx, &listRequest)
+	if err == in_memory.ErrInvalidCursor {
+		return nil, ErrInvalidArgument
+	}
 	if err != nil {
 		s.logger.Log("message", "unable to list sessions from in-memory store", "error", err)
 		return nil, ErrList
 	}
 
+	// an empty page is not an error, the filters matched nothing or the cursor was past
+	// the last page
+	if sessions.List == nil {
+		sessions.List = []string{}
+	}
+	return sessions, nil
+}
+
+// ListSubject return a list of the sessions of the subject
+func (s sessionMgmntService) ListSubject(ctx context.Context, request *SubjectRequest) (*Sessions, error) {
+	if request.Subject == "" {
+		return nil, ErrInvalidArgument
+	}
+
+	sessions, err := s.repo.ListBySubject(ctx, request.Subject)
+	if err != nil {
+		s.logger.Log("message", "unable to list subject sessions from in-memory store", "error", err)
+		return nil, ErrListSubject
+	}
+
+	if len(sessions.List) == 0 {
+		return nil, ErrNotFound
+	}
+	return sessions, nil
+}
+
+// DestroySubject remove every session of the subject, logging it out everywhere
+func (s sessionMgmntService) DestroySubject(ctx context.Context, request *SubjectRequest) (*Sessions, error) {
+	if request.Subject == "" {
+		return nil, ErrInvalidArgument
+	}
+
+	sessions, err := s.repo.DestroyBySubject(ctx, request.Subject)
+	if err != nil {
+		s.logger.Log("message", "unable to destroy subject sessions in the in-memory store", "error", err)
+		return nil, ErrDestroySubject
+	}
+
 	if len(sessions.List) == 0 {
 		return nil, ErrNotFound
 	}
 	return sessions, nil
 }
 
+// GetData return the data attached to the session
+func (s sessionMgmntService) GetData(ctx context.Context, session *Session) (*SessionData, error) {
+	if session.SessionId == "" {
+		return nil, ErrEmpty
+	}
+
+	data, found, err := s.repo.GetData(ctx, session.SessionId)
+	if err != nil {
+		s.logger.Log("message", "unable to get session data from in-memory store", "error", err)
+		return nil, ErrGetData
+	}
+	if !found {
+		s.logger.Log("message", "not found session to in-memory store", "error", ErrNotFound.Error())
+		return nil, ErrNotFound
+	}
+	return &SessionData{SessionId: session.SessionId, Data: data}, nil
+}
+
+// SetData replace the data attached to the session
+func (s sessionMgmntService) SetData(ctx context.Context, request *SessionDataRequest) error {
+	if request.SessionId == "" {
+		return ErrEmpty
+	}
+
+	found, err := s.repo.SetData(ctx, request)
+	if err != nil {
+		s.logger.Log("message", "unable to set session data to in-memory store", "error", err)
+		return ErrSetData
+	}
+	if !found {
+		s.logger.Log("message", "not found session to in-memory store", "error", ErrNotFound.Error())
+		return ErrNotFound
+	}
+	return nil
+}
+
+// PatchData update individual keys of the data attached to the session
+func (s sessionMgmntService) PatchData(ctx context.Context, request *SessionDataRequest) error {
+	if request.SessionId == "" {
+		return ErrEmpty
+	}
+
+	if len(request.Data) == 0 {
+		return ErrInvalidArgument
+	}
+
+	found, err := s.repo.PatchData(ctx, request)
+	if err != nil {
+		s.logger.Log("message", "unable to patch session data to in-memory store", "error", err)
+		return ErrSetData
+	}
+	if !found {
+		s.logger.Log("message", "not found session to in-memory store", "error", ErrNotFound.Error())
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Rotate replace the session id by a fresh one keeping its data and expiry, the old id
+// keeps working for RotateGrace so requests in flight do not fail
+func (s sessionMgmntService) Rotate(ctx context.Context, request *RotateRequest) (string, error) {
+	if request.SessionId == "" {
+		return "", ErrEmpty
+	}
+
+	sessionId := s.GenerateSessionId()
+	found, err := s.repo.Rotate(ctx, request.SessionId, sessionId, RotateGrace)
+	if err != nil {
+		s.logger.Log("message", "unable to rotate session in the in-memory store", "error", err)
+		return "", ErrRotate
+	}
+	if !found {
+		s.logger.Log("message", "not found session to in-memory store", "error", ErrNotFound.Error())
+		return "", ErrNotFound
+	}
+	return sessionId, nil
+}
+
 // GenerateSessionId a unique session-id which should be UUID based
 func (s *sessionMgmntService) GenerateSessionId() string {
 	return uuid.Must(uuid.NewRandom()).String()
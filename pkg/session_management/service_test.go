@@ -1,14 +1,17 @@
 package session_management_test
 
 import (
+	"context"
 	"errors"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"sync"
 	"testing"
 	"time"
 
 	. "github.com/hecomp/session-management/internal/models"
+	"github.com/hecomp/session-management/pkg/in_memory"
 	. "github.com/hecomp/session-management/pkg/repository"
 	"github.com/hecomp/session-management/pkg/repository/repositoryfakes"
 	. "github.com/hecomp/session-management/pkg/session_management"
@@ -20,6 +23,21 @@ func TestService(t *testing.T) {
 	RunSpecs(t, "Service Suite")
 }
 
+// ctx is the context of the calls made by the specs
+var ctx = context.Background()
+
+// slowListStore widens the window between the count of the sessions of a subject and
+// the creation of a new one
+type slowListStore struct {
+	*in_memory.InMemStore
+}
+
+func (m *slowListStore) ListBySubject(ctx context.Context, subject string) (map[string]Item, error) {
+	items, err := m.InMemStore.ListBySubject(ctx, subject)
+	time.Sleep(10 * time.Millisecond)
+	return items, err
+}
+
 type ServiceSuite struct {
 	service SessionMgmntService
 	fakeRepo *repositoryfakes.FakeSessionMgmntRepository
@@ -44,7 +62,7 @@ var _ = Describe("Service", func() {
 					}
 
 					s.fakeRepo.CreateReturns(nil)
-					sessionId, err := s.service.Create(req)
+					sessionId, err := s.service.Create(ctx, req)
 					Expect(err).To(BeNil())
 					Expect(sessionId).ToNot(BeEmpty())
 				})
@@ -53,10 +71,281 @@ var _ = Describe("Service", func() {
 						TTL: 50,
 					}
 					s.fakeRepo.CreateReturns(errors.New("Error create"))
-					sessionId, err := s.service.Create(req)
+					sessionId, err := s.service.Create(ctx, req)
 					Expect(err).ToNot(BeNil())
 					Expect(sessionId).To(BeEmpty())
 				})
+				It("defaults the TTL to the idle timeout", func() {
+					req := &SessionRequest{IdleTimeout: 120, MaxLifetime: 3600}
+					s.fakeRepo.CreateReturns(nil)
+					_, err := s.service.Create(ctx, req)
+					Expect(err).To(BeNil())
+
+					_, _, session, expiration := s.fakeRepo.CreateArgsForCall(0)
+					Expect(session.TTL).To(Equal(int64(120)))
+					Expect(expiration).To(BeTemporally("~", time.Now().Add(120*time.Second), time.Second))
+				})
+				It("error negative idle timeout or maximum lifetime", func() {
+					_, err := s.service.Create(ctx, &SessionRequest{IdleTimeout: -1})
+					Expect(err).To(Equal(ErrInvalidArgument))
+					_, err = s.service.Create(ctx, &SessionRequest{MaxLifetime: -1})
+					Expect(err).To(Equal(ErrInvalidArgument))
+					Expect(s.fakeRepo.CreateCallCount()).To(BeZero())
+				})
+			})
+		})
+	})
+
+	Describe("Session TTL", func() {
+		BeforeEach(func() {
+			s.service = NewServiceWithTTL(s.fakeRepo, SessionLimit{}, SessionTTL{Default: 60, Max: 120}, test.GetLogger())
+		})
+
+		Context("Create()", func() {
+			It("defaults the TTL to the configured one", func() {
+				s.fakeRepo.CreateReturns(nil)
+				_, err := s.service.Create(ctx, &SessionRequest{})
+				Expect(err).To(BeNil())
+
+				_, _, session, expiration := s.fakeRepo.CreateArgsForCall(0)
+				Expect(session.TTL).To(Equal(int64(60)))
+				Expect(expiration).To(BeTemporally("~", time.Now().Add(60*time.Second), time.Second))
+			})
+		})
+		Context("Extend()", func() {
+			It("defaults the TTL to the configured one", func() {
+				s.fakeRepo.ExtendReturns(true, nil)
+				Expect(s.service.Extend(ctx, &ExtendRequest{SessionId: "90660b89-100e-4f8f-9801-2524df6fbe34"})).To(Succeed())
+
+				_, request := s.fakeRepo.ExtendArgsForCall(0)
+				Expect(request.TTL).To(Equal(int64(60)))
+			})
+			It("caps the TTL to the configured maximum", func() {
+				s.fakeRepo.ExtendReturns(true, nil)
+				Expect(s.service.Extend(ctx, &ExtendRequest{SessionId: "90660b89-100e-4f8f-9801-2524df6fbe34", TTL: 600})).To(Succeed())
+
+				_, request := s.fakeRepo.ExtendArgsForCall(0)
+				Expect(request.TTL).To(Equal(int64(120)))
+			})
+		})
+	})
+
+	Describe("Session Limit", func() {
+		var sessions []*SessionDetails
+		BeforeEach(func() {
+			now := time.Now()
+			sessions = []*SessionDetails{
+				{SessionId: "90660b89-100e-4f8f-9801-2524df6fbe34", CreatedAt: now.Add(-2 * time.Minute), LastAccess: now},
+				{SessionId: "90660b89-100e-4f8f-9801-2524df6fbe99", CreatedAt: now.Add(-time.Minute), LastAccess: now.Add(-time.Minute)},
+			}
+		})
+
+		Context("Create()", func() {
+			When("the subject is under the limit", func() {
+				It("creates the session", func() {
+					s.service = NewServiceWithLimit(s.fakeRepo, SessionLimit{Max: 3, Policy: PolicyReject}, test.GetLogger())
+					s.fakeRepo.ListSubjectDetailsReturns(sessions, nil)
+					sessionId, err := s.service.Create(ctx, &SessionRequest{Subject: "user-42"})
+					Expect(err).To(BeNil())
+					Expect(sessionId).ToNot(BeEmpty())
+					Expect(s.fakeRepo.DestroyCallCount()).To(BeZero())
+				})
+			})
+			When("the subject reached the limit with the reject policy", func() {
+				It("error session limit", func() {
+					s.service = NewServiceWithLimit(s.fakeRepo, SessionLimit{Max: 2, Policy: PolicyReject}, test.GetLogger())
+					s.fakeRepo.ListSubjectDetailsReturns(sessions, nil)
+					sessionId, err := s.service.Create(ctx, &SessionRequest{Subject: "user-42"})
+					Expect(err).To(Equal(ErrSessionLimit))
+					Expect(sessionId).To(BeEmpty())
+					Expect(s.fakeRepo.CreateCallCount()).To(BeZero())
+				})
+			})
+			When("the subject reached the limit with the evict-oldest policy", func() {
+				It("destroys the oldest session", func() {
+					s.service = NewServiceWithLimit(s.fakeRepo, SessionLimit{Max: 2, Policy: PolicyEvictOldest}, test.GetLogger())
+					s.fakeRepo.ListSubjectDetailsReturns(sessions, nil)
+					_, err := s.service.Create(ctx, &SessionRequest{Subject: "user-42"})
+					Expect(err).To(BeNil())
+					Expect(s.fakeRepo.DestroyCallCount()).To(Equal(1))
+					_, destroyRequest := s.fakeRepo.DestroyArgsForCall(0)
+					Expect(destroyRequest).To(Equal(&DestroyRequest{SessionId: "90660b89-100e-4f8f-9801-2524df6fbe34"}))
+				})
+			})
+			When("the subject reached the limit with the evict-lru policy", func() {
+				It("destroys the least recently used session", func() {
+					s.service = NewServiceWithLimit(s.fakeRepo, SessionLimit{Max: 2, Policy: PolicyEvictLRU}, test.GetLogger())
+					s.fakeRepo.ListSubjectDetailsReturns(sessions, nil)
+					_, err := s.service.Create(ctx, &SessionRequest{Subject: "user-42"})
+					Expect(err).To(BeNil())
+					Expect(s.fakeRepo.DestroyCallCount()).To(Equal(1))
+					_, destroyRequest := s.fakeRepo.DestroyArgsForCall(0)
+					Expect(destroyRequest).To(Equal(&DestroyRequest{SessionId: "90660b89-100e-4f8f-9801-2524df6fbe99"}))
+				})
+			})
+			When("the subject creates sessions concurrently in the process", func() {
+				It("never exceeds the limit", func() {
+					store := &slowListStore{in_memory.NewInMemStore(time.Minute, test.GetLogger()).(*in_memory.InMemStore)}
+					repo := NewSessionMgmntRepository(store, test.GetLogger())
+					s.service = NewServiceWithLimit(repo, SessionLimit{Max: 3, Policy: PolicyReject}, test.GetLogger())
+
+					var wg sync.WaitGroup
+					for i := 0; i < 20; i++ {
+						wg.Add(1)
+						go func() {
+							defer GinkgoRecover()
+							defer wg.Done()
+							_, err := s.service.Create(ctx, &SessionRequest{Subject: "user-42", TTL: 60})
+							if err != nil {
+								Expect(err).To(Equal(ErrSessionLimit))
+							}
+						}()
+					}
+					wg.Wait()
+
+					sessions, err := repo.ListSubjectDetails(ctx, "user-42")
+					Expect(err).To(BeNil())
+					Expect(sessions).To(HaveLen(3))
+				})
+			})
+			When("the session has no subject", func() {
+				It("does not enforce the limit", func() {
+					s.service = NewServiceWithLimit(s.fakeRepo, SessionLimit{Max: 1, Policy: PolicyReject}, test.GetLogger())
+					_, err := s.service.Create(ctx, &SessionRequest{})
+					Expect(err).To(BeNil())
+					Expect(s.fakeRepo.ListSubjectDetailsCallCount()).To(BeZero())
+				})
+			})
+		})
+
+		Context("ParseLimitPolicy()", func() {
+			It("parses a known policy", func() {
+				policy, err := ParseLimitPolicy("evict-lru")
+				Expect(err).To(BeNil())
+				Expect(policy).To(Equal(PolicyEvictLRU))
+			})
+			It("error unknown policy", func() {
+				_, err := ParseLimitPolicy("evict-newest")
+				Expect(err).ToNot(BeNil())
+			})
+		})
+	})
+
+	Describe("Batch Sessions", func() {
+		uniqueUUID1 := "90660b89-100e-4f8f-9801-2524df6fbe34"
+		uniqueUUID2 := "90660b89-100e-4f8f-9801-2524df6fbe99"
+
+		Context("CreateBatch()", func() {
+			It("creates the valid sessions in a single batch", func() {
+				results, err := s.service.CreateBatch(ctx, &BatchCreateRequest{Sessions: []SessionRequest{
+					{TTL: 60},
+					{TTL: -1},
+					{IdleTimeout: 20},
+				}})
+				Expect(err).To(BeNil())
+				Expect(results.Results).To(HaveLen(3))
+				Expect(results.Results[0].Err).To(BeNil())
+				Expect(results.Results[0].SessionId).ToNot(BeEmpty())
+				Expect(results.Results[1].Err).To(Equal(ErrInvalidArgument))
+				Expect(results.Results[1].SessionId).To(BeEmpty())
+				Expect(results.Results[2].Err).To(BeNil())
+
+				Expect(s.fakeRepo.CreateBatchCallCount()).To(Equal(1))
+				_, sessions := s.fakeRepo.CreateBatchArgsForCall(0)
+				Expect(sessions).To(HaveLen(2))
+				Expect(sessions[0].SessionId).To(Equal(results.Results[0].SessionId))
+				Expect(sessions[0].Expiration).To(BeTemporally("~", time.Now().Add(time.Minute), time.Second))
+				Expect(sessions[1].SessionId).To(Equal(results.Results[2].SessionId))
+				Expect(sessions[1].Request.TTL).To(Equal(int64(20)))
+			})
+			It("creates the sessions of a subject one at a time under a subject limit", func() {
+				s.service = NewServiceWithLimit(s.fakeRepo, SessionLimit{Max: 1, Policy: PolicyReject}, test.GetLogger())
+				s.fakeRepo.ListSubjectDetailsReturnsOnCall(1, []*SessionDetails{{SessionId: uniqueUUID1}}, nil)
+				results, err := s.service.CreateBatch(ctx, &BatchCreateRequest{Sessions: []SessionRequest{
+					{Subject: "user-42"},
+					{Subject: "user-42"},
+					{},
+				}})
+				Expect(err).To(BeNil())
+				Expect(results.Results[0].Err).To(BeNil())
+				Expect(results.Results[1].Err).To(Equal(ErrSessionLimit))
+				Expect(results.Results[2].Err).To(BeNil())
+				Expect(s.fakeRepo.CreateCallCount()).To(Equal(1))
+				Expect(s.fakeRepo.CreateBatchCallCount()).To(Equal(1))
+			})
+			It("fails every session of the batch when the store fails", func() {
+				s.fakeRepo.CreateBatchReturns(errors.New("error commit"))
+				results, err := s.service.CreateBatch(ctx, &BatchCreateRequest{Sessions: []SessionRequest{{}, {}}})
+				Expect(err).To(BeNil())
+				for _, result := range results.Results {
+					Expect(result.Err).To(Equal(ErrCreate))
+					Expect(result.SessionId).To(BeEmpty())
+				}
+			})
+			It("error an empty batch or a batch over the maximum size", func() {
+				_, err := s.service.CreateBatch(ctx, &BatchCreateRequest{})
+				Expect(err).To(Equal(ErrInvalidArgument))
+				_, err = s.service.CreateBatch(ctx, &BatchCreateRequest{Sessions: make([]SessionRequest, MaxBatchSize+1)})
+				Expect(err).To(Equal(ErrInvalidArgument))
+				Expect(s.fakeRepo.CreateBatchCallCount()).To(BeZero())
+			})
+		})
+
+		Context("ExtendBatch()", func() {
+			It("reports the sessions that were not found", func() {
+				s.fakeRepo.ExtendBatchReturns([]bool{true, false}, nil)
+				results, err := s.service.ExtendBatch(ctx, &BatchExtendRequest{Sessions: []ExtendRequest{
+					{SessionId: uniqueUUID1, TTL: 600},
+					{},
+					{SessionId: uniqueUUID2},
+				}})
+				Expect(err).To(BeNil())
+				Expect(results.Results).To(Equal([]BatchResult{
+					{SessionId: uniqueUUID1},
+					{Err: ErrInvalidArgument},
+					{SessionId: uniqueUUID2, Err: ErrNotFound},
+				}))
+
+				_, requests := s.fakeRepo.ExtendBatchArgsForCall(0)
+				Expect(requests).To(Equal([]ExtendRequest{
+					{SessionId: uniqueUUID1, TTL: MaxTTL},
+					{SessionId: uniqueUUID2, TTL: DefaultTime},
+				}))
+			})
+			It("fails every session of the batch when the store fails", func() {
+				s.fakeRepo.ExtendBatchReturns(nil, errors.New("error reset"))
+				results, err := s.service.ExtendBatch(ctx, &BatchExtendRequest{Sessions: []ExtendRequest{{SessionId: uniqueUUID1}}})
+				Expect(err).To(BeNil())
+				Expect(results.Results[0].Err).To(Equal(ErrExtend))
+			})
+		})
+
+		Context("DestroyBatch()", func() {
+			It("reports the sessions that were not found", func() {
+				s.fakeRepo.DestroyBatchReturns([]bool{false, true}, nil)
+				results, err := s.service.DestroyBatch(ctx, &BatchDestroyRequest{Sessions: []DestroyRequest{
+					{SessionId: uniqueUUID1},
+					{SessionId: uniqueUUID2},
+				}})
+				Expect(err).To(BeNil())
+				Expect(results.Results).To(Equal([]BatchResult{
+					{SessionId: uniqueUUID1, Err: ErrNotFound},
+					{SessionId: uniqueUUID2},
+				}))
+				_, sessionIds := s.fakeRepo.DestroyBatchArgsForCall(0)
+				Expect(sessionIds).To(Equal([]string{uniqueUUID1, uniqueUUID2}))
+			})
+			It("does not call the store without a valid session", func() {
+				results, err := s.service.DestroyBatch(ctx, &BatchDestroyRequest{Sessions: []DestroyRequest{{}}})
+				Expect(err).To(BeNil())
+				Expect(results.Results[0].Err).To(Equal(ErrInvalidArgument))
+				Expect(s.fakeRepo.DestroyBatchCallCount()).To(BeZero())
+			})
+			It("fails every session of the batch when the store fails", func() {
+				s.fakeRepo.DestroyBatchReturns(nil, errors.New("error delete"))
+				results, err := s.service.DestroyBatch(ctx, &BatchDestroyRequest{Sessions: []DestroyRequest{{SessionId: uniqueUUID1}}})
+				Expect(err).To(BeNil())
+				Expect(results.Results[0].Err).To(Equal(ErrDestroy))
 			})
 		})
 	})
@@ -70,7 +359,7 @@ var _ = Describe("Service", func() {
 				}
 				s.fakeRepo.ExistReturns(true, nil)
 				s.fakeRepo.DestroyReturns(nil)
-				err := s.service.Destroy(session)
+				err := s.service.Destroy(ctx, session)
 				Expect(err).To(BeNil())
 			})
 			It("error empty sessionId sent", func() {
@@ -78,7 +367,7 @@ var _ = Describe("Service", func() {
 					SessionId: "",
 				}
 				s.fakeRepo.ExistReturns(true, errors.New("empty sessionId"))
-				err := s.service.Destroy(session)
+				err := s.service.Destroy(ctx, session)
 				Expect(err).ToNot(BeNil())
 				Expect(err).To(Equal(ErrEmpty))
 			})
@@ -88,7 +377,7 @@ var _ = Describe("Service", func() {
 					SessionId: uniqueUUID,
 				}
 				s.fakeRepo.ExistReturns(true, errors.New("error exist"))
-				err := s.service.Destroy(session)
+				err := s.service.Destroy(ctx, session)
 				Expect(err).ToNot(BeNil())
 				Expect(err).To(Equal(ErrExist))
 			})
@@ -98,7 +387,7 @@ var _ = Describe("Service", func() {
 					SessionId: uniqueUUID,
 				}
 				s.fakeRepo.ExistReturns(false, nil)
-				err := s.service.Destroy(session)
+				err := s.service.Destroy(ctx, session)
 				Expect(err).ToNot(BeNil())
 				Expect(err).To(Equal(ErrNotFound))
 			})
@@ -109,7 +398,7 @@ var _ = Describe("Service", func() {
 				}
 				s.fakeRepo.ExistReturns(true, nil)
 				s.fakeRepo.DestroyReturns(errors.New("error destroy"))
-				err := s.service.Destroy(session)
+				err := s.service.Destroy(ctx, session)
 				Expect(err).ToNot(BeNil())
 				Expect(err).To(Equal(ErrDestroy))
 			})
@@ -125,7 +414,7 @@ var _ = Describe("Service", func() {
 					SessionId: uniqueUUID,
 				}
 				s.fakeRepo.ExtendReturns(true, nil)
-				err := s.service.Extend(session)
+				err := s.service.Extend(ctx, session)
 				Expect(err).To(BeNil())
 			})
 			It("error empty session id extend sent", func() {
@@ -133,7 +422,7 @@ var _ = Describe("Service", func() {
 					SessionId: "",
 				}
 				s.fakeRepo.ExtendReturns(false, errors.New("error error empty session id"))
-				err := s.service.Extend(session)
+				err := s.service.Extend(ctx, session)
 				Expect(err).ToNot(BeNil())
 				Expect(err).To(Equal(ErrEmpty))
 			})
@@ -144,7 +433,7 @@ var _ = Describe("Service", func() {
 				}
 				s.fakeRepo.ExtendReturns(false, nil)
 
-				err := s.service.Extend(session)
+				err := s.service.Extend(ctx, session)
 				Expect(err).ToNot(BeNil())
 				Expect(err).To(Equal(ErrNotFound))
 			})
@@ -154,13 +443,41 @@ var _ = Describe("Service", func() {
 					SessionId: uniqueUUID,
 				}
 				s.fakeRepo.ExtendReturns(false, errors.New("error extend session"))
-				err := s.service.Extend(session)
+				err := s.service.Extend(ctx, session)
 				Expect(err).ToNot(BeNil())
 				Expect(err).To(Equal(ErrExtend))
 			})
 		})
 	})
 
+	Context("Get()", func() {
+		When("the API os called with a sessionId", func() {
+			It("returns the session details", func() {
+				uniqueUUID := "90660b89-100e-4f8f-9801-2524df6fbe34"
+				details := &SessionDetails{SessionId: uniqueUUID, Exists: true, TTL: 30}
+				s.fakeRepo.GetReturns(details, true, nil)
+				res, err := s.service.Get(ctx, &Session{SessionId: uniqueUUID})
+				Expect(err).To(BeNil())
+				Expect(res).To(Equal(details))
+			})
+			It("error empty sessionId sent", func() {
+				_, err := s.service.Get(ctx, &Session{})
+				Expect(err).To(Equal(ErrEmpty))
+			})
+			It("not found or expired sessionId in-memory store", func() {
+				s.fakeRepo.GetReturns(nil, false, nil)
+				res, err := s.service.Get(ctx, &Session{SessionId: "90660b89-100e-4f8f-9801-2524df6fbe34"})
+				Expect(err).To(Equal(ErrNotFound))
+				Expect(res).To(BeNil())
+			})
+			It("error get in-memory store", func() {
+				s.fakeRepo.GetReturns(nil, false, errors.New("error get"))
+				_, err := s.service.Get(ctx, &Session{SessionId: "90660b89-100e-4f8f-9801-2524df6fbe34"})
+				Expect(err).To(Equal(ErrGet))
+			})
+		})
+	})
+
 	Context("List()", func() {
 		When("the API os called with TTL as param", func() {
 			It("list an unique sessionId in-memory store", func() {
@@ -176,28 +493,223 @@ var _ = Describe("Service", func() {
 				sessions := test.ConvertMapToList(sessionMap)
 
 				s.fakeRepo.ListReturns(sessions, nil)
-				res, err := s.service.List()
+				res, err := s.service.List(ctx, &ListRequest{})
 				Expect(err).To(BeNil())
 				Expect(res).To(Equal(sessions))
 			})
-			It("error not found list in-memory store", func() {
-				sessionMap := map[string]Item{}
-				sessions := test.ConvertMapToList(sessionMap)
-				s.fakeRepo.ListReturns(sessions, nil)
-				res, err := s.service.List()
-				Expect(err).ToNot(BeNil())
-				Expect(res).To(BeNil())
-				Expect(err).To(Equal(ErrNotFound))
+			It("returns an empty page when no session matches", func() {
+				s.fakeRepo.ListReturns(&Sessions{}, nil)
+				res, err := s.service.List(ctx, &ListRequest{Subject: "user-42"})
+				Expect(err).To(BeNil())
+				Expect(res).To(Equal(&Sessions{List: []string{}}))
 			})
 			It("error list an unique sessionId in-memory store", func() {
 				sessionMap := map[string]Item{}
 				sessions := test.ConvertMapToList(sessionMap)
 				s.fakeRepo.ListReturns(sessions, errors.New("Error destroy"))
-				res, err := s.service.List()
+				res, err := s.service.List(ctx, &ListRequest{})
 				Expect(err).ToNot(BeNil())
 				Expect(res).To(BeNil())
 			})
 		})
 	})
 
+	Context("List() with a list request", func() {
+		BeforeEach(func() {
+			s.fakeRepo.ListReturns(&Sessions{List: []string{"90660b89-100e-4f8f-9801-2524df6fbe34"}, NextCursor: "next"}, nil)
+		})
+		It("pages the sessions by the default limit", func() {
+			res, err := s.service.List(ctx, &ListRequest{})
+			Expect(err).To(BeNil())
+			Expect(res.NextCursor).To(Equal("next"))
+			_, request := s.fakeRepo.ListArgsForCall(0)
+			Expect(request.Limit).To(Equal(DefaultListLimit))
+		})
+		It("caps the limit", func() {
+			_, err := s.service.List(ctx, &ListRequest{Limit: MaxListLimit + 1})
+			Expect(err).To(BeNil())
+			_, request := s.fakeRepo.ListArgsForCall(0)
+			Expect(request.Limit).To(Equal(MaxListLimit))
+		})
+		It("passes the filters and the ordering", func() {
+			listRequest := &ListRequest{Cursor: "cursor", Limit: 10, Subject: "user-42", MinTTL: 60, Sort: "expires_at", Order: "desc"}
+			_, err := s.service.List(ctx, listRequest)
+			Expect(err).To(BeNil())
+			_, request := s.fakeRepo.ListArgsForCall(0)
+			Expect(request).To(Equal(listRequest))
+		})
+		It("refuses invalid arguments", func() {
+			for _, request := range []*ListRequest{
+				{Limit: -1},
+				{MinTTL: -1},
+				{MaxTTL: -1},
+				{Sort: "subject"},
+				{Order: "up"},
+			} {
+				_, err := s.service.List(ctx, request)
+				Expect(err).To(Equal(ErrInvalidArgument))
+			}
+			Expect(s.fakeRepo.ListCallCount()).To(BeZero())
+		})
+		It("refuses an invalid cursor", func() {
+			s.fakeRepo.ListReturns(nil, in_memory.ErrInvalidCursor)
+			_, err := s.service.List(ctx, &ListRequest{Cursor: "invalid"})
+			Expect(err).To(Equal(ErrInvalidArgument))
+		})
+	})
+
+	Context("ListSubject()", func() {
+		When("the API os called with a subject", func() {
+			It("lists the sessions of the subject", func() {
+				sessions := &Sessions{List: []string{"90660b89-100e-4f8f-9801-2524df6fbe34"}}
+				s.fakeRepo.ListBySubjectReturns(sessions, nil)
+				res, err := s.service.ListSubject(ctx, &SubjectRequest{Subject: "user-42"})
+				Expect(err).To(BeNil())
+				Expect(res).To(Equal(sessions))
+			})
+			It("error empty subject sent", func() {
+				_, err := s.service.ListSubject(ctx, &SubjectRequest{})
+				Expect(err).To(Equal(ErrInvalidArgument))
+			})
+			It("not found sessions of the subject", func() {
+				s.fakeRepo.ListBySubjectReturns(&Sessions{}, nil)
+				res, err := s.service.ListSubject(ctx, &SubjectRequest{Subject: "user-42"})
+				Expect(err).To(Equal(ErrNotFound))
+				Expect(res).To(BeNil())
+			})
+			It("error list subject in-memory store", func() {
+				s.fakeRepo.ListBySubjectReturns(nil, errors.New("error list"))
+				_, err := s.service.ListSubject(ctx, &SubjectRequest{Subject: "user-42"})
+				Expect(err).To(Equal(ErrListSubject))
+			})
+		})
+	})
+
+	Context("DestroySubject()", func() {
+		When("the API os called with a subject", func() {
+			It("destroys every session of the subject", func() {
+				sessions := &Sessions{List: []string{"90660b89-100e-4f8f-9801-2524df6fbe34"}}
+				s.fakeRepo.DestroyBySubjectReturns(sessions, nil)
+				res, err := s.service.DestroySubject(ctx, &SubjectRequest{Subject: "user-42"})
+				Expect(err).To(BeNil())
+				Expect(res).To(Equal(sessions))
+				_, subject := s.fakeRepo.DestroyBySubjectArgsForCall(0)
+				Expect(subject).To(Equal("user-42"))
+			})
+			It("error empty subject sent", func() {
+				_, err := s.service.DestroySubject(ctx, &SubjectRequest{})
+				Expect(err).To(Equal(ErrInvalidArgument))
+				Expect(s.fakeRepo.DestroyBySubjectCallCount()).To(BeZero())
+			})
+			It("error destroy subject in-memory store", func() {
+				s.fakeRepo.DestroyBySubjectReturns(nil, errors.New("error destroy"))
+				_, err := s.service.DestroySubject(ctx, &SubjectRequest{Subject: "user-42"})
+				Expect(err).To(Equal(ErrDestroySubject))
+			})
+		})
+	})
+
+	Context("GetData()", func() {
+		When("the API os called with a sessionId", func() {
+			It("returns the session data", func() {
+				uniqueUUID := "90660b89-100e-4f8f-9801-2524df6fbe34"
+				data := map[string]interface{}{"user_id": "42"}
+				s.fakeRepo.GetDataReturns(data, true, nil)
+				res, err := s.service.GetData(ctx, &Session{SessionId: uniqueUUID})
+				Expect(err).To(BeNil())
+				Expect(res).To(Equal(&SessionData{SessionId: uniqueUUID, Data: data}))
+			})
+			It("error empty sessionId sent", func() {
+				res, err := s.service.GetData(ctx, &Session{})
+				Expect(err).To(Equal(ErrEmpty))
+				Expect(res).To(BeNil())
+			})
+			It("not found an unique sessionId in-memory store", func() {
+				s.fakeRepo.GetDataReturns(nil, false, nil)
+				res, err := s.service.GetData(ctx, &Session{SessionId: "90660b89-100e-4f8f-9801-2524df6fbe34"})
+				Expect(err).To(Equal(ErrNotFound))
+				Expect(res).To(BeNil())
+			})
+			It("error get data in-memory store", func() {
+				s.fakeRepo.GetDataReturns(nil, false, errors.New("error get data"))
+				_, err := s.service.GetData(ctx, &Session{SessionId: "90660b89-100e-4f8f-9801-2524df6fbe34"})
+				Expect(err).To(Equal(ErrGetData))
+			})
+		})
+	})
+
+	Context("SetData()", func() {
+		When("the API os called with data", func() {
+			It("sets the session data", func() {
+				request := &SessionDataRequest{
+					SessionId: "90660b89-100e-4f8f-9801-2524df6fbe34",
+					Data:      map[string]interface{}{"user_id": "42"},
+				}
+				s.fakeRepo.SetDataReturns(true, nil)
+				err := s.service.SetData(ctx, request)
+				Expect(err).To(BeNil())
+				_, setRequest := s.fakeRepo.SetDataArgsForCall(0)
+				Expect(setRequest).To(Equal(request))
+			})
+			It("not found an unique sessionId in-memory store", func() {
+				s.fakeRepo.SetDataReturns(false, nil)
+				err := s.service.SetData(ctx, &SessionDataRequest{SessionId: "90660b89-100e-4f8f-9801-2524df6fbe34"})
+				Expect(err).To(Equal(ErrNotFound))
+			})
+			It("error set data in-memory store", func() {
+				s.fakeRepo.SetDataReturns(false, errors.New("error set data"))
+				err := s.service.SetData(ctx, &SessionDataRequest{SessionId: "90660b89-100e-4f8f-9801-2524df6fbe34"})
+				Expect(err).To(Equal(ErrSetData))
+			})
+		})
+	})
+
+	Context("PatchData()", func() {
+		When("the API os called with data", func() {
+			It("patches the session data", func() {
+				s.fakeRepo.PatchDataReturns(true, nil)
+				err := s.service.PatchData(ctx, &SessionDataRequest{
+					SessionId: "90660b89-100e-4f8f-9801-2524df6fbe34",
+					Data:      map[string]interface{}{"roles": "admin"},
+				})
+				Expect(err).To(BeNil())
+			})
+			It("error no keys to patch", func() {
+				err := s.service.PatchData(ctx, &SessionDataRequest{SessionId: "90660b89-100e-4f8f-9801-2524df6fbe34"})
+				Expect(err).To(Equal(ErrInvalidArgument))
+				Expect(s.fakeRepo.PatchDataCallCount()).To(BeZero())
+			})
+		})
+	})
+
+	Context("Rotate()", func() {
+		When("the API os called with a sessionId", func() {
+			It("replaces the session id by a fresh one", func() {
+				s.fakeRepo.RotateReturns(true, nil)
+				sessionId, err := s.service.Rotate(ctx, &RotateRequest{SessionId: "90660b89-100e-4f8f-9801-2524df6fbe34"})
+				Expect(err).To(BeNil())
+				_, oldId, newId, grace := s.fakeRepo.RotateArgsForCall(0)
+				Expect(oldId).To(Equal("90660b89-100e-4f8f-9801-2524df6fbe34"))
+				Expect(newId).To(Equal(sessionId))
+				Expect(newId).ToNot(Equal(oldId))
+				Expect(grace).To(Equal(RotateGrace))
+			})
+			It("not found sessionId in-memory store", func() {
+				s.fakeRepo.RotateReturns(false, nil)
+				_, err := s.service.Rotate(ctx, &RotateRequest{SessionId: "90660b89-100e-4f8f-9801-2524df6fbe34"})
+				Expect(err).To(Equal(ErrNotFound))
+			})
+			It("error rotate in-memory store", func() {
+				s.fakeRepo.RotateReturns(false, errors.New("Error rotate"))
+				_, err := s.service.Rotate(ctx, &RotateRequest{SessionId: "90660b89-100e-4f8f-9801-2524df6fbe34"})
+				Expect(err).To(Equal(ErrRotate))
+			})
+			It("error empty sessionId", func() {
+				_, err := s.service.Rotate(ctx, &RotateRequest{})
+				Expect(err).To(Equal(ErrEmpty))
+				Expect(s.fakeRepo.RotateCallCount()).To(BeZero())
+			})
+		})
+	})
+
 })
@@ -2,6 +2,7 @@
 package session_managementfakes
 
 import (
+	"context"
 	"sync"
 
 	"github.com/hecomp/session-management/internal/models"
@@ -9,10 +10,11 @@ import (
 )
 
 type FakeSessionMgmntService struct {
-	CreateStub        func(*models.SessionRequest) (string, error)
+	CreateStub        func(context.Context, *models.SessionRequest) (string, error)
 	createMutex       sync.RWMutex
 	createArgsForCall []struct {
-		arg1 *models.SessionRequest
+		arg1 context.Context
+		arg2 *models.SessionRequest
 	}
 	createReturns struct {
 		result1 string
@@ -22,10 +24,25 @@ type FakeSessionMgmntService struct {
 		result1 string
 		result2 error
 	}
-	DestroyStub        func(*models.DestroyRequest) error
+	CreateBatchStub        func(context.Context, *models.BatchCreateRequest) (*models.BatchResults, error)
+	createBatchMutex       sync.RWMutex
+	createBatchArgsForCall []struct {
+		arg1 context.Context
+		arg2 *models.BatchCreateRequest
+	}
+	createBatchReturns struct {
+		result1 *models.BatchResults
+		result2 error
+	}
+	createBatchReturnsOnCall map[int]struct {
+		result1 *models.BatchResults
+		result2 error
+	}
+	DestroyStub        func(context.Context, *models.DestroyRequest) error
 	destroyMutex       sync.RWMutex
 	destroyArgsForCall []struct {
-		arg1 *models.DestroyRequest
+		arg1 context.Context
+		arg2 *models.DestroyRequest
 	}
 	destroyReturns struct {
 		result1 error
@@ -33,47 +50,171 @@ type FakeSessionMgmntService struct {
 	destroyReturnsOnCall map[int]struct {
 		result1 error
 	}
-	ExtendStub        func(*models.ExtendRequest) (*session_management.SessionMgmntResponse, error)
+	DestroyBatchStub        func(context.Context, *models.BatchDestroyRequest) (*models.BatchResults, error)
+	destroyBatchMutex       sync.RWMutex
+	destroyBatchArgsForCall []struct {
+		arg1 context.Context
+		arg2 *models.BatchDestroyRequest
+	}
+	destroyBatchReturns struct {
+		result1 *models.BatchResults
+		result2 error
+	}
+	destroyBatchReturnsOnCall map[int]struct {
+		result1 *models.BatchResults
+		result2 error
+	}
+	DestroySubjectStub        func(context.Context, *models.SubjectRequest) (*models.Sessions, error)
+	destroySubjectMutex       sync.RWMutex
+	destroySubjectArgsForCall []struct {
+		arg1 context.Context
+		arg2 *models.SubjectRequest
+	}
+	destroySubjectReturns struct {
+		result1 *models.Sessions
+		result2 error
+	}
+	destroySubjectReturnsOnCall map[int]struct {
+		result1 *models.Sessions
+		result2 error
+	}
+	ExtendStub        func(context.Context, *models.ExtendRequest) error
 	extendMutex       sync.RWMutex
 	extendArgsForCall []struct {
-		arg1 *models.ExtendRequest
+		arg1 context.Context
+		arg2 *models.ExtendRequest
 	}
 	extendReturns struct {
-		result1 *session_management.SessionMgmntResponse
-		result2 error
+		result1 error
 	}
 	extendReturnsOnCall map[int]struct {
-		result1 *session_management.SessionMgmntResponse
+		result1 error
+	}
+	ExtendBatchStub        func(context.Context, *models.BatchExtendRequest) (*models.BatchResults, error)
+	extendBatchMutex       sync.RWMutex
+	extendBatchArgsForCall []struct {
+		arg1 context.Context
+		arg2 *models.BatchExtendRequest
+	}
+	extendBatchReturns struct {
+		result1 *models.BatchResults
 		result2 error
 	}
-	ListStub        func() (*session_management.SessionMgmntResponse, error)
+	extendBatchReturnsOnCall map[int]struct {
+		result1 *models.BatchResults
+		result2 error
+	}
+	GetStub        func(context.Context, *models.Session) (*models.SessionDetails, error)
+	getMutex       sync.RWMutex
+	getArgsForCall []struct {
+		arg1 context.Context
+		arg2 *models.Session
+	}
+	getReturns struct {
+		result1 *models.SessionDetails
+		result2 error
+	}
+	getReturnsOnCall map[int]struct {
+		result1 *models.SessionDetails
+		result2 error
+	}
+	GetDataStub        func(context.Context, *models.Session) (*models.SessionData, error)
+	getDataMutex       sync.RWMutex
+	getDataArgsForCall []struct {
+		arg1 context.Context
+		arg2 *models.Session
+	}
+	getDataReturns struct {
+		result1 *models.SessionData
+		result2 error
+	}
+	getDataReturnsOnCall map[int]struct {
+		result1 *models.SessionData
+		result2 error
+	}
+	ListStub        func(context.Context, *models.ListRequest) (*models.Sessions, error)
 	listMutex       sync.RWMutex
 	listArgsForCall []struct {
+		arg1 context.Context
+		arg2 *models.ListRequest
 	}
 	listReturns struct {
-		result1 *session_management.SessionMgmntResponse
+		result1 *models.Sessions
 		result2 error
 	}
 	listReturnsOnCall map[int]struct {
-		result1 *session_management.SessionMgmntResponse
+		result1 *models.Sessions
+		result2 error
+	}
+	ListSubjectStub        func(context.Context, *models.SubjectRequest) (*models.Sessions, error)
+	listSubjectMutex       sync.RWMutex
+	listSubjectArgsForCall []struct {
+		arg1 context.Context
+		arg2 *models.SubjectRequest
+	}
+	listSubjectReturns struct {
+		result1 *models.Sessions
+		result2 error
+	}
+	listSubjectReturnsOnCall map[int]struct {
+		result1 *models.Sessions
 		result2 error
 	}
+	PatchDataStub        func(context.Context, *models.SessionDataRequest) error
+	patchDataMutex       sync.RWMutex
+	patchDataArgsForCall []struct {
+		arg1 context.Context
+		arg2 *models.SessionDataRequest
+	}
+	patchDataReturns struct {
+		result1 error
+	}
+	patchDataReturnsOnCall map[int]struct {
+		result1 error
+	}
+	RotateStub        func(context.Context, *models.RotateRequest) (string, error)
+	rotateMutex       sync.RWMutex
+	rotateArgsForCall []struct {
+		arg1 context.Context
+		arg2 *models.RotateRequest
+	}
+	rotateReturns struct {
+		result1 string
+		result2 error
+	}
+	rotateReturnsOnCall map[int]struct {
+		result1 string
+		result2 error
+	}
+	SetDataStub        func(context.Context, *models.SessionDataRequest) error
+	setDataMutex       sync.RWMutex
+	setDataArgsForCall []struct {
+		arg1 context.Context
+		arg2 *models.SessionDataRequest
+	}
+	setDataReturns struct {
+		result1 error
+	}
+	setDataReturnsOnCall map[int]struct {
+		result1 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
 
-func (fake *FakeSessionMgmntService) Create(arg1 *models.SessionRequest) (string, error) {
+func (fake *FakeSessionMgmntService) Create(arg1 context.Context, arg2 *models.SessionRequest) (string, error) {
 	fake.createMutex.Lock()
 	ret, specificReturn := fake.createReturnsOnCall[len(fake.createArgsForCall)]
 	fake.createArgsForCall = append(fake.createArgsForCall, struct {
-		arg1 *models.SessionRequest
-	}{arg1})
+		arg1 context.Context
+		arg2 *models.SessionRequest
+	}{arg1, arg2})
 	stub := fake.CreateStub
 	fakeReturns := fake.createReturns
-	fake.recordInvocation("Create", []interface{}{arg1})
+	fake.recordInvocation("Create", []interface{}{arg1, arg2})
 	fake.createMutex.Unlock()
 	if stub != nil {
-		return stub(arg1)
+		return stub(arg1, arg2)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
@@ -87,17 +228,17 @@ func (fake *FakeSessionMgmntService) CreateCallCount() int {
 	return len(fake.createArgsForCall)
 }
 
-func (fake *FakeSessionMgmntService) CreateCalls(stub func(*models.SessionRequest) (string, error)) {
+func (fake *FakeSessionMgmntService) CreateCalls(stub func(context.Context, *models.SessionRequest) (string, error)) {
 	fake.createMutex.Lock()
 	defer fake.createMutex.Unlock()
 	fake.CreateStub = stub
 }
 
-func (fake *FakeSessionMgmntService) CreateArgsForCall(i int) *models.SessionRequest {
+func (fake *FakeSessionMgmntService) CreateArgsForCall(i int) (context.Context, *models.SessionRequest) {
 	fake.createMutex.RLock()
 	defer fake.createMutex.RUnlock()
 	argsForCall := fake.createArgsForCall[i]
-	return argsForCall.arg1
+	return argsForCall.arg1, argsForCall.arg2
 }
 
 func (fake *FakeSessionMgmntService) CreateReturns(result1 string, result2 error) {
@@ -126,18 +267,84 @@ func (fake *FakeSessionMgmntService) CreateReturnsOnCall(i int, result1 string,
 	}{result1, result2}
 }
 
-func (fake *FakeSessionMgmntService) Destroy(arg1 *models.DestroyRequest) error {
+func (fake *FakeSessionMgmntService) CreateBatch(arg1 context.Context, arg2 *models.BatchCreateRequest) (*models.BatchResults, error) {
+	fake.createBatchMutex.Lock()
+	ret, specificReturn := fake.createBatchReturnsOnCall[len(fake.createBatchArgsForCall)]
+	fake.createBatchArgsForCall = append(fake.createBatchArgsForCall, struct {
+		arg1 context.Context
+		arg2 *models.BatchCreateRequest
+	}{arg1, arg2})
+	stub := fake.CreateBatchStub
+	fakeReturns := fake.createBatchReturns
+	fake.recordInvocation("CreateBatch", []interface{}{arg1, arg2})
+	fake.createBatchMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeSessionMgmntService) CreateBatchCallCount() int {
+	fake.createBatchMutex.RLock()
+	defer fake.createBatchMutex.RUnlock()
+	return len(fake.createBatchArgsForCall)
+}
+
+func (fake *FakeSessionMgmntService) CreateBatchCalls(stub func(context.Context, *models.BatchCreateRequest) (*models.BatchResults, error)) {
+	fake.createBatchMutex.Lock()
+	defer fake.createBatchMutex.Unlock()
+	fake.CreateBatchStub = stub
+}
+
+func (fake *FakeSessionMgmntService) CreateBatchArgsForCall(i int) (context.Context, *models.BatchCreateRequest) {
+	fake.createBatchMutex.RLock()
+	defer fake.createBatchMutex.RUnlock()
+	argsForCall := fake.createBatchArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeSessionMgmntService) CreateBatchReturns(result1 *models.BatchResults, result2 error) {
+	fake.createBatchMutex.Lock()
+	defer fake.createBatchMutex.Unlock()
+	fake.CreateBatchStub = nil
+	fake.createBatchReturns = struct {
+		result1 *models.BatchResults
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSessionMgmntService) CreateBatchReturnsOnCall(i int, result1 *models.BatchResults, result2 error) {
+	fake.createBatchMutex.Lock()
+	defer fake.createBatchMutex.Unlock()
+	fake.CreateBatchStub = nil
+	if fake.createBatchReturnsOnCall == nil {
+		fake.createBatchReturnsOnCall = make(map[int]struct {
+			result1 *models.BatchResults
+			result2 error
+		})
+	}
+	fake.createBatchReturnsOnCall[i] = struct {
+		result1 *models.BatchResults
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSessionMgmntService) Destroy(arg1 context.Context, arg2 *models.DestroyRequest) error {
 	fake.destroyMutex.Lock()
 	ret, specificReturn := fake.destroyReturnsOnCall[len(fake.destroyArgsForCall)]
 	fake.destroyArgsForCall = append(fake.destroyArgsForCall, struct {
-		arg1 *models.DestroyRequest
-	}{arg1})
+		arg1 context.Context
+		arg2 *models.DestroyRequest
+	}{arg1, arg2})
 	stub := fake.DestroyStub
 	fakeReturns := fake.destroyReturns
-	fake.recordInvocation("Destroy", []interface{}{arg1})
+	fake.recordInvocation("Destroy", []interface{}{arg1, arg2})
 	fake.destroyMutex.Unlock()
 	if stub != nil {
-		return stub(arg1)
+		return stub(arg1, arg2)
 	}
 	if specificReturn {
 		return ret.result1
@@ -151,17 +358,17 @@ func (fake *FakeSessionMgmntService) DestroyCallCount() int {
 	return len(fake.destroyArgsForCall)
 }
 
-func (fake *FakeSessionMgmntService) DestroyCalls(stub func(*models.DestroyRequest) error) {
+func (fake *FakeSessionMgmntService) DestroyCalls(stub func(context.Context, *models.DestroyRequest) error) {
 	fake.destroyMutex.Lock()
 	defer fake.destroyMutex.Unlock()
 	fake.DestroyStub = stub
 }
 
-func (fake *FakeSessionMgmntService) DestroyArgsForCall(i int) *models.DestroyRequest {
+func (fake *FakeSessionMgmntService) DestroyArgsForCall(i int) (context.Context, *models.DestroyRequest) {
 	fake.destroyMutex.RLock()
 	defer fake.destroyMutex.RUnlock()
 	argsForCall := fake.destroyArgsForCall[i]
-	return argsForCall.arg1
+	return argsForCall.arg1, argsForCall.arg2
 }
 
 func (fake *FakeSessionMgmntService) DestroyReturns(result1 error) {
@@ -187,23 +394,154 @@ func (fake *FakeSessionMgmntService) DestroyReturnsOnCall(i int, result1 error)
 	}{result1}
 }
 
-func (fake *FakeSessionMgmntService) Extend(arg1 *models.ExtendRequest) (*session_management.SessionMgmntResponse, error) {
+func (fake *FakeSessionMgmntService) DestroyBatch(arg1 context.Context, arg2 *models.BatchDestroyRequest) (*models.BatchResults, error) {
+	fake.destroyBatchMutex.Lock()
+	ret, specificReturn := fake.destroyBatchReturnsOnCall[len(fake.destroyBatchArgsForCall)]
+	fake.destroyBatchArgsForCall = append(fake.destroyBatchArgsForCall, struct {
+		arg1 context.Context
+		arg2 *models.BatchDestroyRequest
+	}{arg1, arg2})
+	stub := fake.DestroyBatchStub
+	fakeReturns := fake.destroyBatchReturns
+	fake.recordInvocation("DestroyBatch", []interface{}{arg1, arg2})
+	fake.destroyBatchMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeSessionMgmntService) DestroyBatchCallCount() int {
+	fake.destroyBatchMutex.RLock()
+	defer fake.destroyBatchMutex.RUnlock()
+	return len(fake.destroyBatchArgsForCall)
+}
+
+func (fake *FakeSessionMgmntService) DestroyBatchCalls(stub func(context.Context, *models.BatchDestroyRequest) (*models.BatchResults, error)) {
+	fake.destroyBatchMutex.Lock()
+	defer fake.destroyBatchMutex.Unlock()
+	fake.DestroyBatchStub = stub
+}
+
+func (fake *FakeSessionMgmntService) DestroyBatchArgsForCall(i int) (context.Context, *models.BatchDestroyRequest) {
+	fake.destroyBatchMutex.RLock()
+	defer fake.destroyBatchMutex.RUnlock()
+	argsForCall := fake.destroyBatchArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeSessionMgmntService) DestroyBatchReturns(result1 *models.BatchResults, result2 error) {
+	fake.destroyBatchMutex.Lock()
+	defer fake.destroyBatchMutex.Unlock()
+	fake.DestroyBatchStub = nil
+	fake.destroyBatchReturns = struct {
+		result1 *models.BatchResults
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSessionMgmntService) DestroyBatchReturnsOnCall(i int, result1 *models.BatchResults, result2 error) {
+	fake.destroyBatchMutex.Lock()
+	defer fake.destroyBatchMutex.Unlock()
+	fake.DestroyBatchStub = nil
+	if fake.destroyBatchReturnsOnCall == nil {
+		fake.destroyBatchReturnsOnCall = make(map[int]struct {
+			result1 *models.BatchResults
+			result2 error
+		})
+	}
+	fake.destroyBatchReturnsOnCall[i] = struct {
+		result1 *models.BatchResults
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSessionMgmntService) DestroySubject(arg1 context.Context, arg2 *models.SubjectRequest) (*models.Sessions, error) {
+	fake.destroySubjectMutex.Lock()
+	ret, specificReturn := fake.destroySubjectReturnsOnCall[len(fake.destroySubjectArgsForCall)]
+	fake.destroySubjectArgsForCall = append(fake.destroySubjectArgsForCall, struct {
+		arg1 context.Context
+		arg2 *models.SubjectRequest
+	}{arg1, arg2})
+	stub := fake.DestroySubjectStub
+	fakeReturns := fake.destroySubjectReturns
+	fake.recordInvocation("DestroySubject", []interface{}{arg1, arg2})
+	fake.destroySubjectMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeSessionMgmntService) DestroySubjectCallCount() int {
+	fake.destroySubjectMutex.RLock()
+	defer fake.destroySubjectMutex.RUnlock()
+	return len(fake.destroySubjectArgsForCall)
+}
+
+func (fake *FakeSessionMgmntService) DestroySubjectCalls(stub func(context.Context, *models.SubjectRequest) (*models.Sessions, error)) {
+	fake.destroySubjectMutex.Lock()
+	defer fake.destroySubjectMutex.Unlock()
+	fake.DestroySubjectStub = stub
+}
+
+func (fake *FakeSessionMgmntService) DestroySubjectArgsForCall(i int) (context.Context, *models.SubjectRequest) {
+	fake.destroySubjectMutex.RLock()
+	defer fake.destroySubjectMutex.RUnlock()
+	argsForCall := fake.destroySubjectArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeSessionMgmntService) DestroySubjectReturns(result1 *models.Sessions, result2 error) {
+	fake.destroySubjectMutex.Lock()
+	defer fake.destroySubjectMutex.Unlock()
+	fake.DestroySubjectStub = nil
+	fake.destroySubjectReturns = struct {
+		result1 *models.Sessions
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSessionMgmntService) DestroySubjectReturnsOnCall(i int, result1 *models.Sessions, result2 error) {
+	fake.destroySubjectMutex.Lock()
+	defer fake.destroySubjectMutex.Unlock()
+	fake.DestroySubjectStub = nil
+	if fake.destroySubjectReturnsOnCall == nil {
+		fake.destroySubjectReturnsOnCall = make(map[int]struct {
+			result1 *models.Sessions
+			result2 error
+		})
+	}
+	fake.destroySubjectReturnsOnCall[i] = struct {
+		result1 *models.Sessions
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSessionMgmntService) Extend(arg1 context.Context, arg2 *models.ExtendRequest) error {
 	fake.extendMutex.Lock()
 	ret, specificReturn := fake.extendReturnsOnCall[len(fake.extendArgsForCall)]
 	fake.extendArgsForCall = append(fake.extendArgsForCall, struct {
-		arg1 *models.ExtendRequest
-	}{arg1})
+		arg1 context.Context
+		arg2 *models.ExtendRequest
+	}{arg1, arg2})
 	stub := fake.ExtendStub
 	fakeReturns := fake.extendReturns
-	fake.recordInvocation("Extend", []interface{}{arg1})
+	fake.recordInvocation("Extend", []interface{}{arg1, arg2})
 	fake.extendMutex.Unlock()
 	if stub != nil {
-		return stub(arg1)
+		return stub(arg1, arg2)
 	}
 	if specificReturn {
-		return ret.result1, ret.result2
+		return ret.result1
 	}
-	return fakeReturns.result1, fakeReturns.result2
+	return fakeReturns.result1
 }
 
 func (fake *FakeSessionMgmntService) ExtendCallCount() int {
@@ -212,56 +550,250 @@ func (fake *FakeSessionMgmntService) ExtendCallCount() int {
 	return len(fake.extendArgsForCall)
 }
 
-func (fake *FakeSessionMgmntService) ExtendCalls(stub func(*models.ExtendRequest) (*session_management.SessionMgmntResponse, error)) {
+func (fake *FakeSessionMgmntService) ExtendCalls(stub func(context.Context, *models.ExtendRequest) error) {
 	fake.extendMutex.Lock()
 	defer fake.extendMutex.Unlock()
 	fake.ExtendStub = stub
 }
 
-func (fake *FakeSessionMgmntService) ExtendArgsForCall(i int) *models.ExtendRequest {
+func (fake *FakeSessionMgmntService) ExtendArgsForCall(i int) (context.Context, *models.ExtendRequest) {
 	fake.extendMutex.RLock()
 	defer fake.extendMutex.RUnlock()
 	argsForCall := fake.extendArgsForCall[i]
-	return argsForCall.arg1
+	return argsForCall.arg1, argsForCall.arg2
 }
 
-func (fake *FakeSessionMgmntService) ExtendReturns(result1 *session_management.SessionMgmntResponse, result2 error) {
+func (fake *FakeSessionMgmntService) ExtendReturns(result1 error) {
 	fake.extendMutex.Lock()
 	defer fake.extendMutex.Unlock()
 	fake.ExtendStub = nil
 	fake.extendReturns = struct {
-		result1 *session_management.SessionMgmntResponse
-		result2 error
-	}{result1, result2}
+		result1 error
+	}{result1}
 }
 
-func (fake *FakeSessionMgmntService) ExtendReturnsOnCall(i int, result1 *session_management.SessionMgmntResponse, result2 error) {
+func (fake *FakeSessionMgmntService) ExtendReturnsOnCall(i int, result1 error) {
 	fake.extendMutex.Lock()
 	defer fake.extendMutex.Unlock()
 	fake.ExtendStub = nil
 	if fake.extendReturnsOnCall == nil {
 		fake.extendReturnsOnCall = make(map[int]struct {
-			result1 *session_management.SessionMgmntResponse
-			result2 error
+			result1 error
 		})
 	}
 	fake.extendReturnsOnCall[i] = struct {
-		result1 *session_management.SessionMgmntResponse
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeSessionMgmntService) ExtendBatch(arg1 context.Context, arg2 *models.BatchExtendRequest) (*models.BatchResults, error) {
+	fake.extendBatchMutex.Lock()
+	ret, specificReturn := fake.extendBatchReturnsOnCall[len(fake.extendBatchArgsForCall)]
+	fake.extendBatchArgsForCall = append(fake.extendBatchArgsForCall, struct {
+		arg1 context.Context
+		arg2 *models.BatchExtendRequest
+	}{arg1, arg2})
+	stub := fake.ExtendBatchStub
+	fakeReturns := fake.extendBatchReturns
+	fake.recordInvocation("ExtendBatch", []interface{}{arg1, arg2})
+	fake.extendBatchMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeSessionMgmntService) ExtendBatchCallCount() int {
+	fake.extendBatchMutex.RLock()
+	defer fake.extendBatchMutex.RUnlock()
+	return len(fake.extendBatchArgsForCall)
+}
+
+func (fake *FakeSessionMgmntService) ExtendBatchCalls(stub func(context.Context, *models.BatchExtendRequest) (*models.BatchResults, error)) {
+	fake.extendBatchMutex.Lock()
+	defer fake.extendBatchMutex.Unlock()
+	fake.ExtendBatchStub = stub
+}
+
+func (fake *FakeSessionMgmntService) ExtendBatchArgsForCall(i int) (context.Context, *models.BatchExtendRequest) {
+	fake.extendBatchMutex.RLock()
+	defer fake.extendBatchMutex.RUnlock()
+	argsForCall := fake.extendBatchArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeSessionMgmntService) ExtendBatchReturns(result1 *models.BatchResults, result2 error) {
+	fake.extendBatchMutex.Lock()
+	defer fake.extendBatchMutex.Unlock()
+	fake.ExtendBatchStub = nil
+	fake.extendBatchReturns = struct {
+		result1 *models.BatchResults
 		result2 error
 	}{result1, result2}
 }
 
-func (fake *FakeSessionMgmntService) List() (*session_management.SessionMgmntResponse, error) {
+func (fake *FakeSessionMgmntService) ExtendBatchReturnsOnCall(i int, result1 *models.BatchResults, result2 error) {
+	fake.extendBatchMutex.Lock()
+	defer fake.extendBatchMutex.Unlock()
+	fake.ExtendBatchStub = nil
+	if fake.extendBatchReturnsOnCall == nil {
+		fake.extendBatchReturnsOnCall = make(map[int]struct {
+			result1 *models.BatchResults
+			result2 error
+		})
+	}
+	fake.extendBatchReturnsOnCall[i] = struct {
+		result1 *models.BatchResults
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSessionMgmntService) Get(arg1 context.Context, arg2 *models.Session) (*models.SessionDetails, error) {
+	fake.getMutex.Lock()
+	ret, specificReturn := fake.getReturnsOnCall[len(fake.getArgsForCall)]
+	fake.getArgsForCall = append(fake.getArgsForCall, struct {
+		arg1 context.Context
+		arg2 *models.Session
+	}{arg1, arg2})
+	stub := fake.GetStub
+	fakeReturns := fake.getReturns
+	fake.recordInvocation("Get", []interface{}{arg1, arg2})
+	fake.getMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeSessionMgmntService) GetCallCount() int {
+	fake.getMutex.RLock()
+	defer fake.getMutex.RUnlock()
+	return len(fake.getArgsForCall)
+}
+
+func (fake *FakeSessionMgmntService) GetCalls(stub func(context.Context, *models.Session) (*models.SessionDetails, error)) {
+	fake.getMutex.Lock()
+	defer fake.getMutex.Unlock()
+	fake.GetStub = stub
+}
+
+func (fake *FakeSessionMgmntService) GetArgsForCall(i int) (context.Context, *models.Session) {
+	fake.getMutex.RLock()
+	defer fake.getMutex.RUnlock()
+	argsForCall := fake.getArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeSessionMgmntService) GetReturns(result1 *models.SessionDetails, result2 error) {
+	fake.getMutex.Lock()
+	defer fake.getMutex.Unlock()
+	fake.GetStub = nil
+	fake.getReturns = struct {
+		result1 *models.SessionDetails
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSessionMgmntService) GetReturnsOnCall(i int, result1 *models.SessionDetails, result2 error) {
+	fake.getMutex.Lock()
+	defer fake.getMutex.Unlock()
+	fake.GetStub = nil
+	if fake.getReturnsOnCall == nil {
+		fake.getReturnsOnCall = make(map[int]struct {
+			result1 *models.SessionDetails
+			result2 error
+		})
+	}
+	fake.getReturnsOnCall[i] = struct {
+		result1 *models.SessionDetails
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSessionMgmntService) GetData(arg1 context.Context, arg2 *models.Session) (*models.SessionData, error) {
+	fake.getDataMutex.Lock()
+	ret, specificReturn := fake.getDataReturnsOnCall[len(fake.getDataArgsForCall)]
+	fake.getDataArgsForCall = append(fake.getDataArgsForCall, struct {
+		arg1 context.Context
+		arg2 *models.Session
+	}{arg1, arg2})
+	stub := fake.GetDataStub
+	fakeReturns := fake.getDataReturns
+	fake.recordInvocation("GetData", []interface{}{arg1, arg2})
+	fake.getDataMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeSessionMgmntService) GetDataCallCount() int {
+	fake.getDataMutex.RLock()
+	defer fake.getDataMutex.RUnlock()
+	return len(fake.getDataArgsForCall)
+}
+
+func (fake *FakeSessionMgmntService) GetDataCalls(stub func(context.Context, *models.Session) (*models.SessionData, error)) {
+	fake.getDataMutex.Lock()
+	defer fake.getDataMutex.Unlock()
+	fake.GetDataStub = stub
+}
+
+func (fake *FakeSessionMgmntService) GetDataArgsForCall(i int) (context.Context, *models.Session) {
+	fake.getDataMutex.RLock()
+	defer fake.getDataMutex.RUnlock()
+	argsForCall := fake.getDataArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeSessionMgmntService) GetDataReturns(result1 *models.SessionData, result2 error) {
+	fake.getDataMutex.Lock()
+	defer fake.getDataMutex.Unlock()
+	fake.GetDataStub = nil
+	fake.getDataReturns = struct {
+		result1 *models.SessionData
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSessionMgmntService) GetDataReturnsOnCall(i int, result1 *models.SessionData, result2 error) {
+	fake.getDataMutex.Lock()
+	defer fake.getDataMutex.Unlock()
+	fake.GetDataStub = nil
+	if fake.getDataReturnsOnCall == nil {
+		fake.getDataReturnsOnCall = make(map[int]struct {
+			result1 *models.SessionData
+			result2 error
+		})
+	}
+	fake.getDataReturnsOnCall[i] = struct {
+		result1 *models.SessionData
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSessionMgmntService) List(arg1 context.Context, arg2 *models.ListRequest) (*models.Sessions, error) {
 	fake.listMutex.Lock()
 	ret, specificReturn := fake.listReturnsOnCall[len(fake.listArgsForCall)]
 	fake.listArgsForCall = append(fake.listArgsForCall, struct {
-	}{})
+		arg1 context.Context
+		arg2 *models.ListRequest
+	}{arg1, arg2})
 	stub := fake.ListStub
 	fakeReturns := fake.listReturns
-	fake.recordInvocation("List", []interface{}{})
+	fake.recordInvocation("List", []interface{}{arg1, arg2})
 	fake.listMutex.Unlock()
 	if stub != nil {
-		return stub()
+		return stub(arg1, arg2)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
@@ -275,49 +807,330 @@ func (fake *FakeSessionMgmntService) ListCallCount() int {
 	return len(fake.listArgsForCall)
 }
 
-func (fake *FakeSessionMgmntService) ListCalls(stub func() (*session_management.SessionMgmntResponse, error)) {
+func (fake *FakeSessionMgmntService) ListCalls(stub func(context.Context, *models.ListRequest) (*models.Sessions, error)) {
 	fake.listMutex.Lock()
 	defer fake.listMutex.Unlock()
 	fake.ListStub = stub
 }
 
-func (fake *FakeSessionMgmntService) ListReturns(result1 *session_management.SessionMgmntResponse, result2 error) {
+func (fake *FakeSessionMgmntService) ListArgsForCall(i int) (context.Context, *models.ListRequest) {
+	fake.listMutex.RLock()
+	defer fake.listMutex.RUnlock()
+	argsForCall := fake.listArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeSessionMgmntService) ListReturns(result1 *models.Sessions, result2 error) {
 	fake.listMutex.Lock()
 	defer fake.listMutex.Unlock()
 	fake.ListStub = nil
 	fake.listReturns = struct {
-		result1 *session_management.SessionMgmntResponse
+		result1 *models.Sessions
 		result2 error
 	}{result1, result2}
 }
 
-func (fake *FakeSessionMgmntService) ListReturnsOnCall(i int, result1 *session_management.SessionMgmntResponse, result2 error) {
+func (fake *FakeSessionMgmntService) ListReturnsOnCall(i int, result1 *models.Sessions, result2 error) {
 	fake.listMutex.Lock()
 	defer fake.listMutex.Unlock()
 	fake.ListStub = nil
 	if fake.listReturnsOnCall == nil {
 		fake.listReturnsOnCall = make(map[int]struct {
-			result1 *session_management.SessionMgmntResponse
+			result1 *models.Sessions
 			result2 error
 		})
 	}
 	fake.listReturnsOnCall[i] = struct {
-		result1 *session_management.SessionMgmntResponse
+		result1 *models.Sessions
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSessionMgmntService) ListSubject(arg1 context.Context, arg2 *models.SubjectRequest) (*models.Sessions, error) {
+	fake.listSubjectMutex.Lock()
+	ret, specificReturn := fake.listSubjectReturnsOnCall[len(fake.listSubjectArgsForCall)]
+	fake.listSubjectArgsForCall = append(fake.listSubjectArgsForCall, struct {
+		arg1 context.Context
+		arg2 *models.SubjectRequest
+	}{arg1, arg2})
+	stub := fake.ListSubjectStub
+	fakeReturns := fake.listSubjectReturns
+	fake.recordInvocation("ListSubject", []interface{}{arg1, arg2})
+	fake.listSubjectMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeSessionMgmntService) ListSubjectCallCount() int {
+	fake.listSubjectMutex.RLock()
+	defer fake.listSubjectMutex.RUnlock()
+	return len(fake.listSubjectArgsForCall)
+}
+
+func (fake *FakeSessionMgmntService) ListSubjectCalls(stub func(context.Context, *models.SubjectRequest) (*models.Sessions, error)) {
+	fake.listSubjectMutex.Lock()
+	defer fake.listSubjectMutex.Unlock()
+	fake.ListSubjectStub = stub
+}
+
+func (fake *FakeSessionMgmntService) ListSubjectArgsForCall(i int) (context.Context, *models.SubjectRequest) {
+	fake.listSubjectMutex.RLock()
+	defer fake.listSubjectMutex.RUnlock()
+	argsForCall := fake.listSubjectArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeSessionMgmntService) ListSubjectReturns(result1 *models.Sessions, result2 error) {
+	fake.listSubjectMutex.Lock()
+	defer fake.listSubjectMutex.Unlock()
+	fake.ListSubjectStub = nil
+	fake.listSubjectReturns = struct {
+		result1 *models.Sessions
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSessionMgmntService) ListSubjectReturnsOnCall(i int, result1 *models.Sessions, result2 error) {
+	fake.listSubjectMutex.Lock()
+	defer fake.listSubjectMutex.Unlock()
+	fake.ListSubjectStub = nil
+	if fake.listSubjectReturnsOnCall == nil {
+		fake.listSubjectReturnsOnCall = make(map[int]struct {
+			result1 *models.Sessions
+			result2 error
+		})
+	}
+	fake.listSubjectReturnsOnCall[i] = struct {
+		result1 *models.Sessions
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSessionMgmntService) PatchData(arg1 context.Context, arg2 *models.SessionDataRequest) error {
+	fake.patchDataMutex.Lock()
+	ret, specificReturn := fake.patchDataReturnsOnCall[len(fake.patchDataArgsForCall)]
+	fake.patchDataArgsForCall = append(fake.patchDataArgsForCall, struct {
+		arg1 context.Context
+		arg2 *models.SessionDataRequest
+	}{arg1, arg2})
+	stub := fake.PatchDataStub
+	fakeReturns := fake.patchDataReturns
+	fake.recordInvocation("PatchData", []interface{}{arg1, arg2})
+	fake.patchDataMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeSessionMgmntService) PatchDataCallCount() int {
+	fake.patchDataMutex.RLock()
+	defer fake.patchDataMutex.RUnlock()
+	return len(fake.patchDataArgsForCall)
+}
+
+func (fake *FakeSessionMgmntService) PatchDataCalls(stub func(context.Context, *models.SessionDataRequest) error) {
+	fake.patchDataMutex.Lock()
+	defer fake.patchDataMutex.Unlock()
+	fake.PatchDataStub = stub
+}
+
+func (fake *FakeSessionMgmntService) PatchDataArgsForCall(i int) (context.Context, *models.SessionDataRequest) {
+	fake.patchDataMutex.RLock()
+	defer fake.patchDataMutex.RUnlock()
+	argsForCall := fake.patchDataArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeSessionMgmntService) PatchDataReturns(result1 error) {
+	fake.patchDataMutex.Lock()
+	defer fake.patchDataMutex.Unlock()
+	fake.PatchDataStub = nil
+	fake.patchDataReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeSessionMgmntService) PatchDataReturnsOnCall(i int, result1 error) {
+	fake.patchDataMutex.Lock()
+	defer fake.patchDataMutex.Unlock()
+	fake.PatchDataStub = nil
+	if fake.patchDataReturnsOnCall == nil {
+		fake.patchDataReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.patchDataReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeSessionMgmntService) Rotate(arg1 context.Context, arg2 *models.RotateRequest) (string, error) {
+	fake.rotateMutex.Lock()
+	ret, specificReturn := fake.rotateReturnsOnCall[len(fake.rotateArgsForCall)]
+	fake.rotateArgsForCall = append(fake.rotateArgsForCall, struct {
+		arg1 context.Context
+		arg2 *models.RotateRequest
+	}{arg1, arg2})
+	stub := fake.RotateStub
+	fakeReturns := fake.rotateReturns
+	fake.recordInvocation("Rotate", []interface{}{arg1, arg2})
+	fake.rotateMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeSessionMgmntService) RotateCallCount() int {
+	fake.rotateMutex.RLock()
+	defer fake.rotateMutex.RUnlock()
+	return len(fake.rotateArgsForCall)
+}
+
+func (fake *FakeSessionMgmntService) RotateCalls(stub func(context.Context, *models.RotateRequest) (string, error)) {
+	fake.rotateMutex.Lock()
+	defer fake.rotateMutex.Unlock()
+	fake.RotateStub = stub
+}
+
+func (fake *FakeSessionMgmntService) RotateArgsForCall(i int) (context.Context, *models.RotateRequest) {
+	fake.rotateMutex.RLock()
+	defer fake.rotateMutex.RUnlock()
+	argsForCall := fake.rotateArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeSessionMgmntService) RotateReturns(result1 string, result2 error) {
+	fake.rotateMutex.Lock()
+	defer fake.rotateMutex.Unlock()
+	fake.RotateStub = nil
+	fake.rotateReturns = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSessionMgmntService) RotateReturnsOnCall(i int, result1 string, result2 error) {
+	fake.rotateMutex.Lock()
+	defer fake.rotateMutex.Unlock()
+	fake.RotateStub = nil
+	if fake.rotateReturnsOnCall == nil {
+		fake.rotateReturnsOnCall = make(map[int]struct {
+			result1 string
+			result2 error
+		})
+	}
+	fake.rotateReturnsOnCall[i] = struct {
+		result1 string
 		result2 error
 	}{result1, result2}
 }
 
+func (fake *FakeSessionMgmntService) SetData(arg1 context.Context, arg2 *models.SessionDataRequest) error {
+	fake.setDataMutex.Lock()
+	ret, specificReturn := fake.setDataReturnsOnCall[len(fake.setDataArgsForCall)]
+	fake.setDataArgsForCall = append(fake.setDataArgsForCall, struct {
+		arg1 context.Context
+		arg2 *models.SessionDataRequest
+	}{arg1, arg2})
+	stub := fake.SetDataStub
+	fakeReturns := fake.setDataReturns
+	fake.recordInvocation("SetData", []interface{}{arg1, arg2})
+	fake.setDataMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeSessionMgmntService) SetDataCallCount() int {
+	fake.setDataMutex.RLock()
+	defer fake.setDataMutex.RUnlock()
+	return len(fake.setDataArgsForCall)
+}
+
+func (fake *FakeSessionMgmntService) SetDataCalls(stub func(context.Context, *models.SessionDataRequest) error) {
+	fake.setDataMutex.Lock()
+	defer fake.setDataMutex.Unlock()
+	fake.SetDataStub = stub
+}
+
+func (fake *FakeSessionMgmntService) SetDataArgsForCall(i int) (context.Context, *models.SessionDataRequest) {
+	fake.setDataMutex.RLock()
+	defer fake.setDataMutex.RUnlock()
+	argsForCall := fake.setDataArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeSessionMgmntService) SetDataReturns(result1 error) {
+	fake.setDataMutex.Lock()
+	defer fake.setDataMutex.Unlock()
+	fake.SetDataStub = nil
+	fake.setDataReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeSessionMgmntService) SetDataReturnsOnCall(i int, result1 error) {
+	fake.setDataMutex.Lock()
+	defer fake.setDataMutex.Unlock()
+	fake.SetDataStub = nil
+	if fake.setDataReturnsOnCall == nil {
+		fake.setDataReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.setDataReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeSessionMgmntService) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
 	fake.createMutex.RLock()
 	defer fake.createMutex.RUnlock()
+	fake.createBatchMutex.RLock()
+	defer fake.createBatchMutex.RUnlock()
 	fake.destroyMutex.RLock()
 	defer fake.destroyMutex.RUnlock()
+	fake.destroyBatchMutex.RLock()
+	defer fake.destroyBatchMutex.RUnlock()
+	fake.destroySubjectMutex.RLock()
+	defer fake.destroySubjectMutex.RUnlock()
 	fake.extendMutex.RLock()
 	defer fake.extendMutex.RUnlock()
+	fake.extendBatchMutex.RLock()
+	defer fake.extendBatchMutex.RUnlock()
+	fake.getMutex.RLock()
+	defer fake.getMutex.RUnlock()
+	fake.getDataMutex.RLock()
+	defer fake.getDataMutex.RUnlock()
 	fake.listMutex.RLock()
 	defer fake.listMutex.RUnlock()
+	fake.listSubjectMutex.RLock()
+	defer fake.listSubjectMutex.RUnlock()
+	fake.patchDataMutex.RLock()
+	defer fake.patchDataMutex.RUnlock()
+	fake.rotateMutex.RLock()
+	defer fake.rotateMutex.RUnlock()
+	fake.setDataMutex.RLock()
+	defer fake.setDataMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
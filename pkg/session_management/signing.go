@@ -0,0 +1,229 @@
+package session_management
+
+import (
+	"context"
+	"errors"
+
+	"github.com/hecomp/session-management/internal/models"
+	"github.com/hecomp/session-management/pkg/token"
+)
+
+// ErrInvalidToken is returned when a session token is not signed by the key ring
+var ErrInvalidToken = errors.New("invalid session token")
+
+// signingService has the implementation of the signing middleware methods. It hands out
+// signed tokens instead of raw session ids and rejects tampered tokens before the request
+// reaches the next service, so a forged id never touches the store.
+type signingService struct {
+	ring *token.KeyRing
+	SessionMgmntService
+}
+
+// NewSigningService create a instance of signing service
+func NewSigningService(ring *token.KeyRing, s SessionMgmntService) SessionMgmntService {
+	return &signingService{ring: ring, SessionMgmntService: s}
+}
+
+// Create session and return its signed token
+func (s *signingService) Create(ctx context.Context, session *models.SessionRequest) (string, error) {
+	sessionId, err := s.SessionMgmntService.Create(ctx, session)
+	if err != nil {
+		return "", err
+	}
+	return s.ring.Sign(sessionId), nil
+}
+
+// Destroy verify the token and remove its session
+func (s *signingService) Destroy(ctx context.Context, session *models.DestroyRequest) error {
+	sessionId, err := s.verify(session.SessionId)
+	if err != nil {
+		return err
+	}
+	return s.SessionMgmntService.Destroy(ctx, &models.DestroyRequest{SessionId: sessionId})
+}
+
+// Extend verify the token and extend its session
+func (s *signingService) Extend(ctx context.Context, request *models.ExtendRequest) error {
+	sessionId, err := s.verify(request.SessionId)
+	if err != nil {
+		return err
+	}
+	extend := *request
+	extend.SessionId = sessionId
+	return s.SessionMgmntService.Extend(ctx, &extend)
+}
+
+// Get verify the token and return the details of its session
+func (s *signingService) Get(ctx context.Context, session *models.Session) (*models.SessionDetails, error) {
+	sessionId, err := s.verify(session.SessionId)
+	if err != nil {
+		return nil, err
+	}
+	details, err := s.SessionMgmntService.Get(ctx, &models.Session{SessionId: sessionId})
+	if err != nil {
+		return nil, err
+	}
+	details.SessionId = session.SessionId
+	return details, nil
+}
+
+// List return the signed tokens of a page of the sessions
+func (s *signingService) List(ctx context.Context, request *models.ListRequest) (*models.Sessions, error) {
+	return s.sign(s.SessionMgmntService.List(ctx, request))
+}
+
+// ListSubject return the signed tokens of the sessions of a subject
+func (s *signingService) ListSubject(ctx context.Context, request *models.SubjectRequest) (*models.Sessions, error) {
+	return s.sign(s.SessionMgmntService.ListSubject(ctx, request))
+}
+
+// DestroySubject return the signed tokens of the destroyed sessions of a subject
+func (s *signingService) DestroySubject(ctx context.Context, request *models.SubjectRequest) (*models.Sessions, error) {
+	return s.sign(s.SessionMgmntService.DestroySubject(ctx, request))
+}
+
+// GetData verify the token and return the payload of its session
+func (s *signingService) GetData(ctx context.Context, session *models.Session) (*models.SessionData, error) {
+	sessionId, err := s.verify(session.SessionId)
+	if err != nil {
+		return nil, err
+	}
+	data, err := s.SessionMgmntService.GetData(ctx, &models.Session{SessionId: sessionId})
+	if err != nil {
+		return nil, err
+	}
+	data.SessionId = session.SessionId
+	return data, nil
+}
+
+// SetData verify the token and replace the payload of its session
+func (s *signingService) SetData(ctx context.Context, request *models.SessionDataRequest) error {
+	sessionId, err := s.verify(request.SessionId)
+	if err != nil {
+		return err
+	}
+	return s.SessionMgmntService.SetData(ctx, &models.SessionDataRequest{SessionId: sessionId, Data: request.Data})
+}
+
+// PatchData verify the token and merge the payload of its session
+func (s *signingService) PatchData(ctx context.Context, request *models.SessionDataRequest) error {
+	sessionId, err := s.verify(request.SessionId)
+	if err != nil {
+		return err
+	}
+	return s.SessionMgmntService.PatchData(ctx, &models.SessionDataRequest{SessionId: sessionId, Data: request.Data})
+}
+
+// Rotate verify the token and return the signed token of the session id replacing it
+func (s *signingService) Rotate(ctx context.Context, request *models.RotateRequest) (string, error) {
+	sessionId, err := s.verify(request.SessionId)
+	if err != nil {
+		return "", err
+	}
+	rotated, err := s.SessionMgmntService.Rotate(ctx, &models.RotateRequest{SessionId: sessionId})
+	if err != nil {
+		return "", err
+	}
+	return s.ring.Sign(rotated), nil
+}
+
+// CreateBatch create the sessions of the batch and return their signed tokens
+func (s *signingService) CreateBatch(ctx context.Context, request *models.BatchCreateRequest) (*models.BatchResults, error) {
+	results, err := s.SessionMgmntService.CreateBatch(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	for i := range results.Results {
+		if results.Results[i].Err == nil {
+			results.Results[i].SessionId = s.ring.Sign(results.Results[i].SessionId)
+		}
+	}
+	return results, nil
+}
+
+// ExtendBatch verify the tokens and extend their sessions, an invalid token fails its own
+// session only
+func (s *signingService) ExtendBatch(ctx context.Context, request *models.BatchExtendRequest) (*models.BatchResults, error) {
+	if err := checkBatchSize(len(request.Sessions)); err != nil {
+		return nil, err
+	}
+	results := make([]models.BatchResult, len(request.Sessions))
+	forward := &models.BatchExtendRequest{}
+	var indexes []int
+	for i, extend := range request.Sessions {
+		results[i].SessionId = extend.SessionId
+		sessionId, err := s.verify(extend.SessionId)
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+		extend.SessionId = sessionId
+		forward.Sessions = append(forward.Sessions, extend)
+		indexes = append(indexes, i)
+	}
+	if len(indexes) == 0 {
+		return &models.BatchResults{Results: results}, nil
+	}
+	forwarded, err := s.SessionMgmntService.ExtendBatch(ctx, forward)
+	return mergeBatch(results, indexes, forwarded, err)
+}
+
+// DestroyBatch verify the tokens and remove their sessions, an invalid token fails its own
+// session only
+func (s *signingService) DestroyBatch(ctx context.Context, request *models.BatchDestroyRequest) (*models.BatchResults, error) {
+	if err := checkBatchSize(len(request.Sessions)); err != nil {
+		return nil, err
+	}
+	results := make([]models.BatchResult, len(request.Sessions))
+	forward := &models.BatchDestroyRequest{}
+	var indexes []int
+	for i, session := range request.Sessions {
+		results[i].SessionId = session.SessionId
+		sessionId, err := s.verify(session.SessionId)
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+		forward.Sessions = append(forward.Sessions, models.DestroyRequest{SessionId: sessionId})
+		indexes = append(indexes, i)
+	}
+	if len(indexes) == 0 {
+		return &models.BatchResults{Results: results}, nil
+	}
+	forwarded, err := s.SessionMgmntService.DestroyBatch(ctx, forward)
+	return mergeBatch(results, indexes, forwarded, err)
+}
+
+// verify return the session id of a token signed by the key ring
+func (s *signingService) verify(t string) (string, error) {
+	sessionId, ok := s.ring.Verify(t)
+	if !ok {
+		return "", ErrInvalidToken
+	}
+	return sessionId, nil
+}
+
+// sign replace the session ids of a list by their signed tokens
+func (s *signingService) sign(sessions *models.Sessions, err error) (*models.Sessions, error) {
+	if err != nil || sessions == nil {
+		return sessions, err
+	}
+	tokens := make([]string, len(sessions.List))
+	for i, sessionId := range sessions.List {
+		tokens[i] = s.ring.Sign(sessionId)
+	}
+	return &models.Sessions{List: tokens, NextCursor: sessions.NextCursor}, nil
+}
+
+// mergeBatch copies the errors of the sessions a middleware forwarded to the next service
+// into the results of the batch, at the indexes the sessions had in the batch, so the
+// results keep the tokens of the request
+func mergeBatch(results []models.BatchResult, indexes []int, forwarded *models.BatchResults, err error) (*models.BatchResults, error) {
+	if err != nil {
+		return nil, err
+	}
+	for j, i := range indexes {
+		results[i].Err = forwarded.Results[j].Err
+	}
+	return &models.BatchResults{Results: results}, nil
+}
@@ -0,0 +1,202 @@
+package session_management_test
+
+import (
+	"bytes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/hecomp/session-management/internal/models"
+	. "github.com/hecomp/session-management/pkg/session_management"
+	"github.com/hecomp/session-management/pkg/session_management/session_managementfakes"
+	"github.com/hecomp/session-management/pkg/token"
+)
+
+type SigningSuite struct {
+	service     SessionMgmntService
+	fakeService *session_managementfakes.FakeSessionMgmntService
+	ring        *token.KeyRing
+}
+
+var _ = Describe("Signing", func() {
+
+	const sessionId = "90660b89-100e-4f8f-9801-2524df6fbe34"
+
+	s := &SigningSuite{}
+
+	BeforeEach(func() {
+		var err error
+		s.ring, err = token.NewKeyRing(token.Key{Id: "primary", Secret: bytes.Repeat([]byte{7}, token.MinKeySize)})
+		Expect(err).To(BeNil())
+		s.fakeService = new(session_managementfakes.FakeSessionMgmntService)
+		s.service = NewSigningService(s.ring, s.fakeService)
+	})
+
+	Context("Create()", func() {
+		It("returns a signed token", func() {
+			s.fakeService.CreateReturns(sessionId, nil)
+			t, err := s.service.Create(ctx, &SessionRequest{TTL: 50})
+			Expect(err).To(BeNil())
+			Expect(t).To(Equal(s.ring.Sign(sessionId)))
+		})
+	})
+
+	Context("Destroy()", func() {
+		It("passes the session id of a valid token", func() {
+			err := s.service.Destroy(ctx, &DestroyRequest{SessionId: s.ring.Sign(sessionId)})
+			Expect(err).To(BeNil())
+			_, destroyRequest := s.fakeService.DestroyArgsForCall(0)
+			Expect(destroyRequest.SessionId).To(Equal(sessionId))
+		})
+		It("rejects an unsigned session id before the next service", func() {
+			err := s.service.Destroy(ctx, &DestroyRequest{SessionId: sessionId})
+			Expect(err).To(Equal(ErrInvalidToken))
+			Expect(s.fakeService.DestroyCallCount()).To(Equal(0))
+		})
+	})
+
+	Context("Extend()", func() {
+		It("passes the session id and the TTL of a valid token", func() {
+			err := s.service.Extend(ctx, &ExtendRequest{SessionId: s.ring.Sign(sessionId), TTL: 60})
+			Expect(err).To(BeNil())
+			_, extendRequest := s.fakeService.ExtendArgsForCall(0)
+			Expect(extendRequest).To(Equal(&ExtendRequest{SessionId: sessionId, TTL: 60}))
+		})
+		It("rejects a tampered token", func() {
+			err := s.service.Extend(ctx, &ExtendRequest{SessionId: s.ring.Sign(sessionId) + "x", TTL: 60})
+			Expect(err).To(Equal(ErrInvalidToken))
+			Expect(s.fakeService.ExtendCallCount()).To(Equal(0))
+		})
+	})
+
+	Context("Get()", func() {
+		It("returns the details under the token", func() {
+			t := s.ring.Sign(sessionId)
+			s.fakeService.GetReturns(&SessionDetails{SessionId: sessionId, Exists: true}, nil)
+			details, err := s.service.Get(ctx, &Session{SessionId: t})
+			Expect(err).To(BeNil())
+			Expect(details.SessionId).To(Equal(t))
+			_, getRequest := s.fakeService.GetArgsForCall(0)
+			Expect(getRequest.SessionId).To(Equal(sessionId))
+		})
+		It("rejects a forged token", func() {
+			_, err := s.service.Get(ctx, &Session{SessionId: sessionId + ".forged"})
+			Expect(err).To(Equal(ErrInvalidToken))
+			Expect(s.fakeService.GetCallCount()).To(Equal(0))
+		})
+	})
+
+	Context("GetData(), SetData() and PatchData()", func() {
+		It("reject an invalid token before the next service", func() {
+			_, err := s.service.GetData(ctx, &Session{SessionId: sessionId})
+			Expect(err).To(Equal(ErrInvalidToken))
+			Expect(s.service.SetData(ctx, &SessionDataRequest{SessionId: sessionId})).To(Equal(ErrInvalidToken))
+			Expect(s.service.PatchData(ctx, &SessionDataRequest{SessionId: sessionId})).To(Equal(ErrInvalidToken))
+			Expect(s.fakeService.GetDataCallCount()).To(Equal(0))
+			Expect(s.fakeService.SetDataCallCount()).To(Equal(0))
+			Expect(s.fakeService.PatchDataCallCount()).To(Equal(0))
+		})
+		It("pass the session id of a valid token", func() {
+			t := s.ring.Sign(sessionId)
+			data := map[string]interface{}{"role": "admin"}
+			s.fakeService.GetDataReturns(&SessionData{SessionId: sessionId, Data: data}, nil)
+			got, err := s.service.GetData(ctx, &Session{SessionId: t})
+			Expect(err).To(BeNil())
+			Expect(got).To(Equal(&SessionData{SessionId: t, Data: data}))
+			Expect(s.service.SetData(ctx, &SessionDataRequest{SessionId: t, Data: data})).To(Succeed())
+			_, setRequest := s.fakeService.SetDataArgsForCall(0)
+			Expect(setRequest).To(Equal(&SessionDataRequest{SessionId: sessionId, Data: data}))
+			Expect(s.service.PatchData(ctx, &SessionDataRequest{SessionId: t, Data: data})).To(Succeed())
+			_, patchRequest := s.fakeService.PatchDataArgsForCall(0)
+			Expect(patchRequest).To(Equal(&SessionDataRequest{SessionId: sessionId, Data: data}))
+		})
+	})
+
+	Context("Rotate()", func() {
+		It("returns the signed token of the new session id", func() {
+			s.fakeService.RotateReturns("5b6d0be2-d47b-4d0b-9e4c-6e0a5f1a0d11", nil)
+			t, err := s.service.Rotate(ctx, &RotateRequest{SessionId: s.ring.Sign(sessionId)})
+			Expect(err).To(BeNil())
+			Expect(t).To(Equal(s.ring.Sign("5b6d0be2-d47b-4d0b-9e4c-6e0a5f1a0d11")))
+			_, rotateRequest := s.fakeService.RotateArgsForCall(0)
+			Expect(rotateRequest.SessionId).To(Equal(sessionId))
+		})
+		It("rejects an unsigned session id before the next service", func() {
+			_, err := s.service.Rotate(ctx, &RotateRequest{SessionId: sessionId})
+			Expect(err).To(Equal(ErrInvalidToken))
+			Expect(s.fakeService.RotateCallCount()).To(Equal(0))
+		})
+	})
+
+	Context("CreateBatch()", func() {
+		It("returns the signed tokens of the created sessions", func() {
+			s.fakeService.CreateBatchReturns(&BatchResults{Results: []BatchResult{
+				{SessionId: sessionId},
+				{Err: ErrInvalidArgument},
+			}}, nil)
+			results, err := s.service.CreateBatch(ctx, &BatchCreateRequest{Sessions: []SessionRequest{{}, {TTL: -1}}})
+			Expect(err).To(BeNil())
+			Expect(results.Results).To(Equal([]BatchResult{
+				{SessionId: s.ring.Sign(sessionId)},
+				{Err: ErrInvalidArgument},
+			}))
+		})
+	})
+
+	Context("ExtendBatch() and DestroyBatch()", func() {
+		It("fail the invalid tokens and pass the session ids of the valid ones", func() {
+			t := s.ring.Sign(sessionId)
+			s.fakeService.ExtendBatchReturns(&BatchResults{Results: []BatchResult{{SessionId: sessionId, Err: ErrExtend}}}, nil)
+			s.fakeService.DestroyBatchReturns(&BatchResults{Results: []BatchResult{{SessionId: sessionId}}}, nil)
+
+			results, err := s.service.ExtendBatch(ctx, &BatchExtendRequest{Sessions: []ExtendRequest{{SessionId: sessionId}, {SessionId: t, TTL: 60}}})
+			Expect(err).To(BeNil())
+			Expect(results.Results).To(Equal([]BatchResult{
+				{SessionId: sessionId, Err: ErrInvalidToken},
+				{SessionId: t, Err: ErrExtend},
+			}))
+			_, extendRequest := s.fakeService.ExtendBatchArgsForCall(0)
+			Expect(extendRequest.Sessions).To(Equal([]ExtendRequest{{SessionId: sessionId, TTL: 60}}))
+
+			results, err = s.service.DestroyBatch(ctx, &BatchDestroyRequest{Sessions: []DestroyRequest{{SessionId: sessionId}, {SessionId: t}}})
+			Expect(err).To(BeNil())
+			Expect(results.Results).To(Equal([]BatchResult{
+				{SessionId: sessionId, Err: ErrInvalidToken},
+				{SessionId: t},
+			}))
+			_, destroyRequest := s.fakeService.DestroyBatchArgsForCall(0)
+			Expect(destroyRequest.Sessions).To(Equal([]DestroyRequest{{SessionId: sessionId}}))
+		})
+		It("do not call the next service without a valid token", func() {
+			results, err := s.service.DestroyBatch(ctx, &BatchDestroyRequest{Sessions: []DestroyRequest{{SessionId: sessionId}}})
+			Expect(err).To(BeNil())
+			Expect(results.Results[0].Err).To(Equal(ErrInvalidToken))
+			Expect(s.fakeService.DestroyBatchCallCount()).To(Equal(0))
+		})
+	})
+
+	Context("List(), ListSubject() and DestroySubject()", func() {
+		It("keep the cursor of the next page", func() {
+			s.fakeService.ListReturns(&Sessions{List: []string{sessionId}, NextCursor: "next"}, nil)
+			sessions, err := s.service.List(ctx, &ListRequest{Limit: 1})
+			Expect(err).To(BeNil())
+			Expect(sessions).To(Equal(&Sessions{List: []string{s.ring.Sign(sessionId)}, NextCursor: "next"}))
+		})
+		It("return signed tokens", func() {
+			s.fakeService.ListReturns(&Sessions{List: []string{sessionId}}, nil)
+			s.fakeService.ListSubjectReturns(&Sessions{List: []string{sessionId}}, nil)
+			s.fakeService.DestroySubjectReturns(&Sessions{List: []string{sessionId}}, nil)
+			expected := &Sessions{List: []string{s.ring.Sign(sessionId)}}
+
+			sessions, err := s.service.List(ctx, &ListRequest{})
+			Expect(err).To(BeNil())
+			Expect(sessions).To(Equal(expected))
+			sessions, err = s.service.ListSubject(ctx, &SubjectRequest{Subject: "alice"})
+			Expect(err).To(BeNil())
+			Expect(sessions).To(Equal(expected))
+			sessions, err = s.service.DestroySubject(ctx, &SubjectRequest{Subject: "alice"})
+			Expect(err).To(BeNil())
+			Expect(sessions).To(Equal(expected))
+		})
+	})
+})
@@ -0,0 +1,168 @@
+package session_management
+
+import (
+	"context"
+	"net/http"
+
+	httptransport "github.com/go-kit/kit/transport/http"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/hecomp/session-management/internal/models"
+)
+
+// TracerName is the name of the tracer of the spans started by the session management service
+const TracerName = "github.com/hecomp/session-management"
+
+// tracingService has the implementation of the tracing middleware methods. Every call runs
+// in a child span of the transport span, so the time spent in the service and the store
+// can be told apart from the time spent decoding the request.
+type tracingService struct {
+	tracer trace.Tracer
+	SessionMgmntService
+}
+
+// NewTracingService create a instance of tracing service
+func NewTracingService(tracer trace.Tracer, s SessionMgmntService) SessionMgmntService {
+	return &tracingService{tracer: tracer, SessionMgmntService: s}
+}
+
+// Create session is stored in-memory
+func (s *tracingService) Create(ctx context.Context, session *models.SessionRequest) (sessionId string, err error) {
+	ctx, span := s.tracer.Start(ctx, "SessionMgmntService.Create")
+	defer func() { endSpan(span, err) }()
+	return s.SessionMgmntService.Create(ctx, session)
+}
+
+// Destroy remove the session from its cache
+func (s *tracingService) Destroy(ctx context.Context, session *models.DestroyRequest) (err error) {
+	ctx, span := s.tracer.Start(ctx, "SessionMgmntService.Destroy")
+	defer func() { endSpan(span, err) }()
+	return s.SessionMgmntService.Destroy(ctx, session)
+}
+
+// Extend session id with the provided TTL
+func (s *tracingService) Extend(ctx context.Context, request *models.ExtendRequest) (err error) {
+	ctx, span := s.tracer.Start(ctx, "SessionMgmntService.Extend")
+	defer func() { endSpan(span, err) }()
+	return s.SessionMgmntService.Extend(ctx, request)
+}
+
+// Get validate the session and return its details
+func (s *tracingService) Get(ctx context.Context, session *models.Session) (details *models.SessionDetails, err error) {
+	ctx, span := s.tracer.Start(ctx, "SessionMgmntService.Get")
+	defer func() { endSpan(span, err) }()
+	return s.SessionMgmntService.Get(ctx, session)
+}
+
+// List return a page of the sessions
+func (s *tracingService) List(ctx context.Context, request *models.ListRequest) (sessions *models.Sessions, err error) {
+	ctx, span := s.tracer.Start(ctx, "SessionMgmntService.List")
+	defer func() { endSpan(span, err) }()
+	return s.SessionMgmntService.List(ctx, request)
+}
+
+// ListSubject return a list of the sessions of a subject
+func (s *tracingService) ListSubject(ctx context.Context, request *models.SubjectRequest) (sessions *models.Sessions, err error) {
+	ctx, span := s.tracer.Start(ctx, "SessionMgmntService.ListSubject")
+	defer func() { endSpan(span, err) }()
+	return s.SessionMgmntService.ListSubject(ctx, request)
+}
+
+// DestroySubject remove every session of a subject
+func (s *tracingService) DestroySubject(ctx context.Context, request *models.SubjectRequest) (sessions *models.Sessions, err error) {
+	ctx, span := s.tracer.Start(ctx, "SessionMgmntService.DestroySubject")
+	defer func() { endSpan(span, err) }()
+	return s.SessionMgmntService.DestroySubject(ctx, request)
+}
+
+// GetData return the data attached to the session
+func (s *tracingService) GetData(ctx context.Context, session *models.Session) (data *models.SessionData, err error) {
+	ctx, span := s.tracer.Start(ctx, "SessionMgmntService.GetData")
+	defer func() { endSpan(span, err) }()
+	return s.SessionMgmntService.GetData(ctx, session)
+}
+
+// SetData replace the data attached to the session
+func (s *tracingService) SetData(ctx context.Context, request *models.SessionDataRequest) (err error) {
+	ctx, span := s.tracer.Start(ctx, "SessionMgmntService.SetData")
+	defer func() { endSpan(span, err) }()
+	return s.SessionMgmntService.SetData(ctx, request)
+}
+
+// PatchData update individual keys of the data attached to the session
+func (s *tracingService) PatchData(ctx context.Context, request *models.SessionDataRequest) (err error) {
+	ctx, span := s.tracer.Start(ctx, "SessionMgmntService.PatchData")
+	defer func() { endSpan(span, err) }()
+	return s.SessionMgmntService.PatchData(ctx, request)
+}
+
+// Rotate replace the session id by a fresh one
+func (s *tracingService) Rotate(ctx context.Context, request *models.RotateRequest) (sessionId string, err error) {
+	ctx, span := s.tracer.Start(ctx, "SessionMgmntService.Rotate")
+	defer func() { endSpan(span, err) }()
+	return s.SessionMgmntService.Rotate(ctx, request)
+}
+
+// CreateBatch create the sessions of the batch
+func (s *tracingService) CreateBatch(ctx context.Context, request *models.BatchCreateRequest) (results *models.BatchResults, err error) {
+	ctx, span := s.tracer.Start(ctx, "SessionMgmntService.CreateBatch")
+	defer func() { endSpan(span, err) }()
+	return s.SessionMgmntService.CreateBatch(ctx, request)
+}
+
+// ExtendBatch extend the sessions of the batch
+func (s *tracingService) ExtendBatch(ctx context.Context, request *models.BatchExtendRequest) (results *models.BatchResults, err error) {
+	ctx, span := s.tracer.Start(ctx, "SessionMgmntService.ExtendBatch")
+	defer func() { endSpan(span, err) }()
+	return s.SessionMgmntService.ExtendBatch(ctx, request)
+}
+
+// DestroyBatch remove the sessions of the batch
+func (s *tracingService) DestroyBatch(ctx context.Context, request *models.BatchDestroyRequest) (results *models.BatchResults, err error) {
+	ctx, span := s.tracer.Start(ctx, "SessionMgmntService.DestroyBatch")
+	defer func() { endSpan(span, err) }()
+	return s.SessionMgmntService.DestroyBatch(ctx, request)
+}
+
+// endSpan records the error of the call, if any, and ends its span
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// TracingServerOptions returns the options of a go-kit HTTP server that run every request
+// of the route in a server span. The span continues the trace of the W3C traceparent
+// header of the request and ends once the response is written.
+func TracingServerOptions(tracer trace.Tracer, route string) []httptransport.ServerOption {
+	return []httptransport.ServerOption{
+		httptransport.ServerBefore(httpToContext(tracer, route)),
+		httptransport.ServerFinalizer(endHTTPSpan),
+	}
+}
+
+// httpToContext returns a RequestFunc starting the server span of the route as a child of
+// the span of the traceparent header
+func httpToContext(tracer trace.Tracer, route string) httptransport.RequestFunc {
+	return func(ctx context.Context, r *http.Request) context.Context {
+		ctx = propagation.TraceContext{}.Extract(ctx, propagation.HeaderCarrier(r.Header))
+		ctx, _ = tracer.Start(ctx, route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(semconv.HTTPServerAttributesFromHTTPRequest("", route, r)...))
+		return ctx
+	}
+}
+
+// endHTTPSpan is a ServerFinalizerFunc ending the server span with the status code of
+// the response
+func endHTTPSpan(ctx context.Context, code int, _ *http.Request) {
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(semconv.HTTPAttributesFromHTTPStatusCode(code)...)
+	span.SetStatus(semconv.SpanStatusFromHTTPStatusCode(code))
+	span.End()
+}
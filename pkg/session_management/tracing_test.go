@@ -0,0 +1,132 @@
+package session_management_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+
+	httptransport "github.com/go-kit/kit/transport/http"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	. "github.com/hecomp/session-management/internal/models"
+	"github.com/hecomp/session-management/pkg/repository"
+	. "github.com/hecomp/session-management/pkg/session_management"
+	"github.com/hecomp/session-management/pkg/session_management/session_managementfakes"
+)
+
+type TracingSuite struct {
+	recorder    *tracetest.SpanRecorder
+	tracer      trace.Tracer
+	service     SessionMgmntService
+	fakeService *session_managementfakes.FakeSessionMgmntService
+}
+
+var _ = Describe("Tracing", func() {
+
+	const traceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+	s := &TracingSuite{}
+
+	BeforeEach(func() {
+		s.recorder = tracetest.NewSpanRecorder()
+		s.tracer = sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(s.recorder)).Tracer(TracerName)
+		s.fakeService = new(session_managementfakes.FakeSessionMgmntService)
+		s.service = NewTracingService(s.tracer, s.fakeService)
+	})
+
+	Context("NewTracingService()", func() {
+		It("runs the call in a child span of the request and passes its context on", func() {
+			requestCtx, request := s.tracer.Start(ctx, "request")
+			s.fakeService.CreateReturns("90660b89-100e-4f8f-9801-2524df6fbe34", nil)
+			_, err := s.service.Create(requestCtx, &SessionRequest{})
+			Expect(err).To(BeNil())
+			request.End()
+
+			spans := s.recorder.Ended()
+			Expect(spans).To(HaveLen(2))
+			Expect(spans[0].Name()).To(Equal("SessionMgmntService.Create"))
+			Expect(spans[0].Parent().SpanID()).To(Equal(request.SpanContext().SpanID()))
+			Expect(spans[0].Status().Code).To(Equal(codes.Unset))
+
+			serviceCtx, _ := s.fakeService.CreateArgsForCall(0)
+			Expect(trace.SpanContextFromContext(serviceCtx)).To(Equal(spans[0].SpanContext()))
+		})
+		It("records the error of the call", func() {
+			s.fakeService.DestroyReturns(repository.ErrNotFound)
+			err := s.service.Destroy(ctx, &DestroyRequest{SessionId: "90660b89-100e-4f8f-9801-2524df6fbe34"})
+			Expect(err).To(Equal(repository.ErrNotFound))
+
+			spans := s.recorder.Ended()
+			Expect(spans).To(HaveLen(1))
+			Expect(spans[0].Name()).To(Equal("SessionMgmntService.Destroy"))
+			Expect(spans[0].Status().Code).To(Equal(codes.Error))
+			Expect(spans[0].Status().Description).To(Equal(repository.ErrNotFound.Error()))
+			Expect(spans[0].Events()).To(HaveLen(1))
+		})
+	})
+
+	Context("TracingServerOptions()", func() {
+		var endpointCtx context.Context
+
+		serve := func(code int, header http.Header) *httptest.ResponseRecorder {
+			server := httptransport.NewServer(
+				func(ctx context.Context, _ interface{}) (interface{}, error) {
+					endpointCtx = ctx
+					return nil, nil
+				},
+				httptransport.NopRequestDecoder,
+				func(_ context.Context, w http.ResponseWriter, _ interface{}) error {
+					w.WriteHeader(code)
+					return nil
+				},
+				TracingServerOptions(s.tracer, "/create")...)
+			req := httptest.NewRequest(http.MethodPost, "/create", nil)
+			for key, values := range header {
+				req.Header[key] = values
+			}
+			rec := httptest.NewRecorder()
+			server.ServeHTTP(rec, req)
+			return rec
+		}
+
+		It("continues the trace of the traceparent header", func() {
+			serve(http.StatusOK, http.Header{"Traceparent": {traceparent}})
+
+			spans := s.recorder.Ended()
+			Expect(spans).To(HaveLen(1))
+			span := spans[0]
+			Expect(span.Name()).To(Equal("/create"))
+			Expect(span.SpanKind()).To(Equal(trace.SpanKindServer))
+			Expect(span.SpanContext().TraceID().String()).To(Equal("4bf92f3577b34da6a3ce929d0e0e4736"))
+			Expect(span.Parent().SpanID().String()).To(Equal("00f067aa0ba902b7"))
+			Expect(span.Parent().IsRemote()).To(BeTrue())
+			Expect(span.Attributes()).To(ContainElement(attribute.Int("http.status_code", http.StatusOK)))
+			Expect(span.Attributes()).To(ContainElement(attribute.String("http.route", "/create")))
+			Expect(span.Status().Code).To(Equal(codes.Unset))
+
+			Expect(trace.SpanContextFromContext(endpointCtx)).To(Equal(span.SpanContext()))
+		})
+		It("starts a new trace without a traceparent header", func() {
+			serve(http.StatusOK, nil)
+
+			spans := s.recorder.Ended()
+			Expect(spans).To(HaveLen(1))
+			Expect(spans[0].Parent().IsValid()).To(BeFalse())
+			Expect(spans[0].SpanContext().TraceID().IsValid()).To(BeTrue())
+		})
+		It("marks the span of a server error as failed", func() {
+			serve(http.StatusInternalServerError, nil)
+
+			spans := s.recorder.Ended()
+			Expect(spans).To(HaveLen(1))
+			Expect(spans[0].Attributes()).To(ContainElement(attribute.Int("http.status_code", http.StatusInternalServerError)))
+			Expect(spans[0].Status().Code).To(Equal(codes.Error))
+		})
+	})
+})
@@ -5,16 +5,30 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	httptransport "github.com/go-kit/kit/transport/http"
+	"go.opentelemetry.io/otel/trace"
 
 	. "github.com/hecomp/session-management/internal/models"
+	"github.com/hecomp/session-management/pkg/auth"
 	. "github.com/hecomp/session-management/pkg/repository"
+	"github.com/hecomp/session-management/pkg/token"
 )
 
 const (
 	ContentType     = "Content-Type"
 	ApplicationJson = "application/json; charset=utf-8"
+	// JWKSPath serves the public keys verifying the session JWTs
+	JWKSPath = "/.well-known/jwks.json"
+	// RevocationsPath serves the destroyed sessions whose JWTs have not expired yet
+	RevocationsPath = "/revocations"
+	// LivenessPath serves the state of the components whose failure requires a restart
+	LivenessPath = "/healthz"
+	// ReadinessPath serves the state of the components the service needs to serve requests
+	ReadinessPath = "/readyz"
 )
 
 var (
@@ -26,34 +40,142 @@ var (
 	ErrUnknown = errors.New("unknown session")
 )
 
-// MakeHandler
-func MakeHandler(svc SessionMgmntService) http.Handler {
+// MakeHandler mounts the HTTP routes of the service, running every request in a span of the tracer.
+// When keys is not nil every route requires an API key of the key store granted its scope:
+// listing every session, the operations on all the sessions of a subject and the batch
+// operations require the admin scope.
+func MakeHandler(svc SessionMgmntService, tracer trace.Tracer, keys *auth.KeyStore) http.Handler {
 
 	mux := http.NewServeMux()
 
 	createHandler := httptransport.NewServer(
 		MakeCreateEndpoint(svc),
 		decodeHTTPCreateRequest,
-		encodeResponse)
+		encodeResponse,
+		serverOptions(tracer, "/create")...)
 	destroyHandler := httptransport.NewServer(
 		MakeDestroyEndpoint(svc),
 		decodeHTTPDestroyRequest,
-		encodeResponse)
+		encodeResponse,
+		serverOptions(tracer, "/destroy")...)
 	extendHandler := httptransport.NewServer(
 		MakeExtendEndpoint(svc),
 		decodeHTTPExtendRequest,
-		encodeResponse)
+		encodeResponse,
+		serverOptions(tracer, "/extend")...)
 	listHandler := httptransport.NewServer(
 		MakeListEndpoint(svc),
 		decodeHTTPListRequest,
-		encodeResponse)
+		encodeResponse,
+		serverOptions(tracer, "/list")...)
 
-	mux.Handle("/create", createHandler)
-	mux.Handle("/destroy", destroyHandler)
-	mux.Handle("/extend", extendHandler)
-	mux.Handle("/list", listHandler)
+	getHandler := httptransport.NewServer(
+		MakeGetEndpoint(svc),
+		decodeHTTPGetRequest,
+		encodeResponse,
+		serverOptions(tracer, "/sessions/{id}")...)
+	listSubjectHandler := httptransport.NewServer(
+		MakeListSubjectEndpoint(svc),
+		decodeHTTPSubjectRequest,
+		encodeResponse,
+		serverOptions(tracer, "/subject/list")...)
+	destroySubjectHandler := httptransport.NewServer(
+		MakeDestroySubjectEndpoint(svc),
+		decodeHTTPSubjectRequest,
+		encodeResponse,
+		serverOptions(tracer, "/subject/destroy")...)
+	getDataHandler := httptransport.NewServer(
+		MakeGetDataEndpoint(svc),
+		decodeHTTPGetDataRequest,
+		encodeResponse,
+		serverOptions(tracer, "/data/get")...)
+	setDataHandler := httptransport.NewServer(
+		MakeSetDataEndpoint(svc),
+		decodeHTTPSessionDataRequest,
+		encodeResponse,
+		serverOptions(tracer, "/data/set")...)
+	patchDataHandler := httptransport.NewServer(
+		MakePatchDataEndpoint(svc),
+		decodeHTTPSessionDataRequest,
+		encodeResponse,
+		serverOptions(tracer, "/data/patch")...)
+	rotateHandler := httptransport.NewServer(
+		MakeRotateEndpoint(svc),
+		decodeHTTPRotateRequest,
+		encodeResponse,
+		serverOptions(tracer, "/rotate")...)
+	createBatchHandler := httptransport.NewServer(
+		MakeCreateBatchEndpoint(svc),
+		decodeHTTPBatchCreateRequest,
+		encodeResponse,
+		serverOptions(tracer, "/batch/create")...)
+	extendBatchHandler := httptransport.NewServer(
+		MakeExtendBatchEndpoint(svc),
+		decodeHTTPBatchExtendRequest,
+		encodeResponse,
+		serverOptions(tracer, "/batch/extend")...)
+	destroyBatchHandler := httptransport.NewServer(
+		MakeDestroyBatchEndpoint(svc),
+		decodeHTTPBatchDestroyRequest,
+		encodeResponse,
+		serverOptions(tracer, "/batch/destroy")...)
 
-	http.Handle("/", accessControl(mux))
+	mux.Handle("/create", Authenticate(keys, auth.ScopeCreate, createHandler))
+	mux.Handle("/destroy", Authenticate(keys, auth.ScopeWrite, destroyHandler))
+	mux.Handle("/extend", Authenticate(keys, auth.ScopeWrite, extendHandler))
+	mux.Handle("/list", Authenticate(keys, auth.ScopeAdmin, listHandler))
+	mux.Handle("/sessions/", Authenticate(keys, auth.ScopeRead, getHandler))
+	mux.Handle("/subject/list", Authenticate(keys, auth.ScopeAdmin, listSubjectHandler))
+	mux.Handle("/subject/destroy", Authenticate(keys, auth.ScopeAdmin, destroySubjectHandler))
+	mux.Handle("/data/get", Authenticate(keys, auth.ScopeRead, getDataHandler))
+	mux.Handle("/data/set", Authenticate(keys, auth.ScopeWrite, setDataHandler))
+	mux.Handle("/data/patch", Authenticate(keys, auth.ScopeWrite, patchDataHandler))
+	mux.Handle("/rotate", Authenticate(keys, auth.ScopeWrite, rotateHandler))
+	mux.Handle("/batch/create", Authenticate(keys, auth.ScopeAdmin, createBatchHandler))
+	mux.Handle("/batch/extend", Authenticate(keys, auth.ScopeAdmin, extendBatchHandler))
+	mux.Handle("/batch/destroy", Authenticate(keys, auth.ScopeAdmin, destroyBatchHandler))
+
+	return mux
+}
+
+// MakeJWTHandler serves the JWKS of the issuer and the revocation list, which is all the
+// verifiers of the session JWTs need
+func MakeJWTHandler(issuer *token.JWTIssuer, revocations RevocationList) http.Handler {
+
+	mux := http.NewServeMux()
+
+	jwksHandler := httptransport.NewServer(
+		MakeJWKSEndpoint(issuer),
+		decodeHTTPEmptyRequest,
+		httptransport.EncodeJSONResponse)
+	revocationsHandler := httptransport.NewServer(
+		MakeRevocationsEndpoint(revocations),
+		decodeHTTPEmptyRequest,
+		httptransport.EncodeJSONResponse,
+		httptransport.ServerErrorEncoder(encodeError))
+
+	mux.Handle(JWKSPath, jwksHandler)
+	mux.Handle(RevocationsPath, revocationsHandler)
+
+	return mux
+}
+
+// MakeHealthHandler mounts the liveness and readiness probes of the service
+func MakeHealthHandler(health *Health) http.Handler {
+
+	mux := http.NewServeMux()
+
+	livenessHandler := httptransport.NewServer(
+		MakeLivenessEndpoint(health),
+		decodeHTTPEmptyRequest,
+		encodeHealthResponse)
+	readinessHandler := httptransport.NewServer(
+		MakeReadinessEndpoint(health),
+		decodeHTTPEmptyRequest,
+		encodeHealthResponse)
+
+	mux.Handle(LivenessPath, livenessHandler)
+	mux.Handle(ReadinessPath, readinessHandler)
 
 	return mux
 }
@@ -112,12 +234,188 @@ func decodeHTTPExtendRequest(_ context.Context, r *http.Request) (interface{}, e
 	}
 }
 
-// decodeHTTPListRequest is a transport/http.DecodeRequestFunc that decodes a
-// JSON-encoded signup request from the HTTP request body. Primarily useful in a
+// decodeHTTPRotateRequest is a transport/http.DecodeRequestFunc that decodes a
+// JSON-encoded rotate request from the HTTP request body. Primarily useful in a
+// server.
+func decodeHTTPRotateRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var rotateRequest RotateRequest
+
+	if r.Body == nil {
+		return nil, ErrBadRequest
+	}
+
+	err := json.NewDecoder(r.Body).Decode(&rotateRequest)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	} else {
+		return rotateRequest, nil
+	}
+}
+
+// decodeHTTPBatchCreateRequest is a transport/http.DecodeRequestFunc that decodes a
+// JSON-encoded batch create request from the HTTP request body. Primarily useful in a
 // server.
+func decodeHTTPBatchCreateRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var batchRequest BatchCreateRequest
+
+	if r.Body == nil {
+		return nil, ErrBadRequest
+	}
+
+	err := json.NewDecoder(r.Body).Decode(&batchRequest)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	} else {
+		return batchRequest, nil
+	}
+}
+
+// decodeHTTPBatchExtendRequest is a transport/http.DecodeRequestFunc that decodes a
+// JSON-encoded batch extend request from the HTTP request body. Primarily useful in a
+// server.
+func decodeHTTPBatchExtendRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var batchRequest BatchExtendRequest
+
+	if r.Body == nil {
+		return nil, ErrBadRequest
+	}
+
+	err := json.NewDecoder(r.Body).Decode(&batchRequest)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	} else {
+		return batchRequest, nil
+	}
+}
+
+// decodeHTTPBatchDestroyRequest is a transport/http.DecodeRequestFunc that decodes a
+// JSON-encoded batch destroy request from the HTTP request body. Primarily useful in a
+// server.
+func decodeHTTPBatchDestroyRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var batchRequest BatchDestroyRequest
+
+	if r.Body == nil {
+		return nil, ErrBadRequest
+	}
+
+	err := json.NewDecoder(r.Body).Decode(&batchRequest)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	} else {
+		return batchRequest, nil
+	}
+}
+
+// decodeHTTPListRequest is a transport/http.DecodeRequestFunc that decodes the
+// cursor, limit, subject, created_after, created_before, min_ttl, max_ttl, sort and order
+// parameters of the request query. The creation times are RFC 3339 timestamps and the TTL
+// bounds are in seconds. Primarily useful in a server.
 func decodeHTTPListRequest(_ context.Context, r *http.Request) (interface{}, error) {
-	var sessions Sessions
-	return sessions, nil
+	query := r.URL.Query()
+	listRequest := ListRequest{
+		Cursor:  query.Get("cursor"),
+		Subject: query.Get("subject"),
+		Sort:    query.Get("sort"),
+		Order:   query.Get("order"),
+	}
+
+	var err error
+	if v := query.Get("limit"); v != "" {
+		if listRequest.Limit, err = strconv.Atoi(v); err != nil {
+			return nil, ErrBadRequest
+		}
+	}
+	for name, t := range map[string]*time.Time{
+		"created_after":  &listRequest.CreatedAfter,
+		"created_before": &listRequest.CreatedBefore,
+	} {
+		if v := query.Get(name); v != "" {
+			if *t, err = time.Parse(time.RFC3339, v); err != nil {
+				return nil, ErrBadRequest
+			}
+		}
+	}
+	for name, ttl := range map[string]*int64{
+		"min_ttl": &listRequest.MinTTL,
+		"max_ttl": &listRequest.MaxTTL,
+	} {
+		if v := query.Get(name); v != "" {
+			if *ttl, err = strconv.ParseInt(v, 10, 64); err != nil {
+				return nil, ErrBadRequest
+			}
+		}
+	}
+	return listRequest, nil
+}
+
+// decodeHTTPEmptyRequest is a transport/http.DecodeRequestFunc for the requests
+// without parameters. Primarily useful in a server.
+func decodeHTTPEmptyRequest(_ context.Context, _ *http.Request) (interface{}, error) {
+	return nil, nil
+}
+
+// decodeHTTPGetRequest is a transport/http.DecodeRequestFunc that decodes the
+// session id from the /sessions/{id} request path. Primarily useful in a server.
+func decodeHTTPGetRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	sessionId := strings.TrimPrefix(r.URL.Path, "/sessions/")
+	if sessionId == "" || strings.Contains(sessionId, "/") {
+		return nil, ErrBadRouting
+	}
+	return Session{SessionId: sessionId}, nil
+}
+
+// decodeHTTPSubjectRequest is a transport/http.DecodeRequestFunc that decodes a
+// JSON-encoded subject request from the HTTP request body. Primarily useful in a
+// server.
+func decodeHTTPSubjectRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var subjectRequest SubjectRequest
+
+	if r.Body == nil {
+		return nil, ErrBadRequest
+	}
+
+	err := json.NewDecoder(r.Body).Decode(&subjectRequest)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	} else {
+		return subjectRequest, nil
+	}
+}
+
+// decodeHTTPGetDataRequest is a transport/http.DecodeRequestFunc that decodes a
+// JSON-encoded session request from the HTTP request body. Primarily useful in a
+// server.
+func decodeHTTPGetDataRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var session Session
+
+	if r.Body == nil {
+		return nil, ErrBadRequest
+	}
+
+	err := json.NewDecoder(r.Body).Decode(&session)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	} else {
+		return session, nil
+	}
+}
+
+// decodeHTTPSessionDataRequest is a transport/http.DecodeRequestFunc that decodes a
+// JSON-encoded session data request from the HTTP request body. Primarily useful in a
+// server.
+func decodeHTTPSessionDataRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var dataRequest SessionDataRequest
+
+	if r.Body == nil {
+		return nil, ErrBadRequest
+	}
+
+	err := json.NewDecoder(r.Body).Decode(&dataRequest)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	} else {
+		return dataRequest, nil
+	}
 }
 
 func encodeResponse(ctx context.Context, w http.ResponseWriter, response interface{}) error {
@@ -131,6 +429,18 @@ func encodeResponse(ctx context.Context, w http.ResponseWriter, response interfa
 	return json.NewEncoder(w).Encode(response)
 }
 
+// encodeHealthResponse writes the state of the service, with a 503 status code unless it is up
+func encodeHealthResponse(_ context.Context, w http.ResponseWriter, response interface{}) error {
+	status := response.(HealthStatus)
+	w.Header().Set(ContentType, ApplicationJson)
+	if status.Status == HealthUp {
+		w.WriteHeader(http.StatusOK)
+	} else {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	return json.NewEncoder(w).Encode(status)
+}
+
 type errorer interface {
 	error() error
 }
@@ -146,6 +456,28 @@ func encodeError(_ context.Context, err error, w http.ResponseWriter) {
 	case ErrInvalidArgument:
 		w.WriteHeader(http.StatusBadRequest)
 		statusCode = http.StatusBadRequest
+	case ErrSessionLimit:
+		w.WriteHeader(http.StatusConflict)
+		statusCode = http.StatusConflict
+	case ErrInvalidToken:
+		w.WriteHeader(http.StatusUnauthorized)
+		statusCode = http.StatusUnauthorized
+	case ErrUnauthenticated:
+		w.Header().Set("WWW-Authenticate", `Bearer realm="session-management"`)
+		w.WriteHeader(http.StatusUnauthorized)
+		statusCode = http.StatusUnauthorized
+	case ErrForbidden:
+		w.WriteHeader(http.StatusForbidden)
+		statusCode = http.StatusForbidden
+	case ErrBadRequest:
+		w.WriteHeader(http.StatusBadRequest)
+		statusCode = http.StatusBadRequest
+	case ErrBadRouting:
+		w.WriteHeader(http.StatusNotFound)
+		statusCode = http.StatusNotFound
+	case ErrMethodNotAllowed:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		statusCode = http.StatusMethodNotAllowed
 	default:
 		w.WriteHeader(http.StatusInternalServerError)
 		statusCode = http.StatusInternalServerError
@@ -156,11 +488,17 @@ func encodeError(_ context.Context, err error, w http.ResponseWriter) {
 	})
 }
 
+// serverOptions returns the options of the HTTP handlers, tracing the route and encoding
+// the errors decoding the request like the errors of the service
+func serverOptions(tracer trace.Tracer, route string) []httptransport.ServerOption {
+	return append(TracingServerOptions(tracer, route), httptransport.ServerErrorEncoder(encodeError))
+}
+
 func accessControl(h http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Origin, Content-Type")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Origin, Content-Type, Authorization, X-API-Key")
 
 		if r.Method == "OPTIONS" {
 			return
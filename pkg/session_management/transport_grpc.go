@@ -0,0 +1,420 @@
+package session_management
+
+import (
+	"context"
+	"strings"
+
+	grpctransport "github.com/go-kit/kit/transport/grpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	. "github.com/hecomp/session-management/internal/models"
+	"github.com/hecomp/session-management/pkg/auth"
+	"github.com/hecomp/session-management/pkg/pb"
+	. "github.com/hecomp/session-management/pkg/repository"
+)
+
+// grpcServer has the go-kit gRPC handlers of the session management endpoints.
+type grpcServer struct {
+	pb.UnimplementedSessionManagementServer
+	create  grpctransport.Handler
+	destroy grpctransport.Handler
+	extend  grpctransport.Handler
+	list    grpctransport.Handler
+	get     grpctransport.Handler
+
+	createBatch  grpctransport.Handler
+	extendBatch  grpctransport.Handler
+	destroyBatch grpctransport.Handler
+}
+
+// NewGRPCServer makes the set of endpoints available as a gRPC SessionManagementServer.
+func NewGRPCServer(svc SessionMgmntService) pb.SessionManagementServer {
+	return &grpcServer{
+		create: grpctransport.NewServer(
+			MakeCreateEndpoint(svc),
+			decodeGRPCCreateRequest,
+			encodeGRPCCreateResponse),
+		destroy: grpctransport.NewServer(
+			MakeDestroyEndpoint(svc),
+			decodeGRPCDestroyRequest,
+			encodeGRPCDestroyResponse),
+		extend: grpctransport.NewServer(
+			MakeExtendEndpoint(svc),
+			decodeGRPCExtendRequest,
+			encodeGRPCExtendResponse),
+		list: grpctransport.NewServer(
+			MakeListEndpoint(svc),
+			decodeGRPCListRequest,
+			encodeGRPCListResponse),
+		get: grpctransport.NewServer(
+			MakeGetEndpoint(svc),
+			decodeGRPCGetRequest,
+			encodeGRPCGetResponse),
+		createBatch: grpctransport.NewServer(
+			MakeCreateBatchEndpoint(svc),
+			decodeGRPCCreateBatchRequest,
+			encodeGRPCBatchResponse),
+		extendBatch: grpctransport.NewServer(
+			MakeExtendBatchEndpoint(svc),
+			decodeGRPCExtendBatchRequest,
+			encodeGRPCBatchResponse),
+		destroyBatch: grpctransport.NewServer(
+			MakeDestroyBatchEndpoint(svc),
+			decodeGRPCDestroyBatchRequest,
+			encodeGRPCBatchResponse),
+	}
+}
+
+// Create a session and return a unique session-id
+func (s *grpcServer) Create(ctx context.Context, req *pb.CreateRequest) (*pb.CreateReply, error) {
+	_, rep, err := s.create.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return rep.(*pb.CreateReply), nil
+}
+
+// Destroy remove the session from its cache
+func (s *grpcServer) Destroy(ctx context.Context, req *pb.DestroyRequest) (*pb.DestroyReply, error) {
+	_, rep, err := s.destroy.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return rep.(*pb.DestroyReply), nil
+}
+
+// Extend session id with the provided TTL
+func (s *grpcServer) Extend(ctx context.Context, req *pb.ExtendRequest) (*pb.ExtendReply, error) {
+	_, rep, err := s.extend.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return rep.(*pb.ExtendReply), nil
+}
+
+// List return a list of all the sessions currently tracked
+func (s *grpcServer) List(ctx context.Context, req *pb.ListRequest) (*pb.ListReply, error) {
+	_, rep, err := s.list.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return rep.(*pb.ListReply), nil
+}
+
+// Get validate the session and return its details
+func (s *grpcServer) Get(ctx context.Context, req *pb.GetRequest) (*pb.GetReply, error) {
+	_, rep, err := s.get.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return rep.(*pb.GetReply), nil
+}
+
+// CreateBatch create the sessions of the batch and return the session-id or the error of
+// each of them
+func (s *grpcServer) CreateBatch(ctx context.Context, req *pb.CreateBatchRequest) (*pb.BatchReply, error) {
+	_, rep, err := s.createBatch.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return rep.(*pb.BatchReply), nil
+}
+
+// ExtendBatch extend the sessions of the batch and return the error of each of them
+func (s *grpcServer) ExtendBatch(ctx context.Context, req *pb.ExtendBatchRequest) (*pb.BatchReply, error) {
+	_, rep, err := s.extendBatch.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return rep.(*pb.BatchReply), nil
+}
+
+// DestroyBatch remove the sessions of the batch and return the error of each of them
+func (s *grpcServer) DestroyBatch(ctx context.Context, req *pb.DestroyBatchRequest) (*pb.BatchReply, error) {
+	_, rep, err := s.destroyBatch.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return rep.(*pb.BatchReply), nil
+}
+
+// ClientCertificateInterceptor is a grpc.UnaryServerInterceptor attaching to the request
+// context the subject of the client certificate of the TLS connection, like
+// ClientCertificate does for the HTTP transport.
+func ClientCertificateInterceptor(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if p, ok := peer.FromContext(ctx); ok {
+		if info, ok := p.AuthInfo.(credentials.TLSInfo); ok && len(info.State.PeerCertificates) > 0 {
+			ctx = auth.NewClientContext(ctx, info.State.PeerCertificates[0].Subject.String())
+		}
+	}
+	return handler(ctx, req)
+}
+
+// grpcScopes are the scopes of the RPCs, matching those of the HTTP routes: listing
+// every session and the batch operations require the admin scope.
+var grpcScopes = map[string]auth.Scope{
+	"Create":       auth.ScopeCreate,
+	"Destroy":      auth.ScopeWrite,
+	"Extend":       auth.ScopeWrite,
+	"Get":          auth.ScopeRead,
+	"List":         auth.ScopeAdmin,
+	"CreateBatch":  auth.ScopeAdmin,
+	"ExtendBatch":  auth.ScopeAdmin,
+	"DestroyBatch": auth.ScopeAdmin,
+}
+
+// AuthenticateInterceptor returns a grpc.UnaryServerInterceptor letting through the calls
+// whose API key, or else whose client certificate subject, was granted the scope of the
+// RPC, like Authenticate does for the HTTP routes. The key is read from the x-api-key
+// metadata or else from a bearer token of the authorization metadata. An RPC without a
+// scope requires the admin scope. It must run after ClientCertificateInterceptor, and a
+// nil key store disables the authentication.
+func AuthenticateInterceptor(keys *auth.KeyStore) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if keys == nil {
+			return handler(ctx, req)
+		}
+		principal, found := keys.Authenticate(grpcAPIKey(ctx))
+		if subject, verified := auth.ClientFromContext(ctx); !found && verified {
+			principal, found = keys.AuthenticateSubject(subject)
+		}
+		if !found {
+			return nil, status.Error(codes.Unauthenticated, ErrUnauthenticated.Error())
+		}
+		scope, ok := grpcScopes[strings.TrimPrefix(info.FullMethod, "/"+pb.SessionManagement_ServiceDesc.ServiceName+"/")]
+		if !ok {
+			scope = auth.ScopeAdmin
+		}
+		if !principal.HasScope(scope) {
+			return nil, status.Error(codes.PermissionDenied, ErrForbidden.Error())
+		}
+		return handler(auth.NewContext(ctx, principal), req)
+	}
+}
+
+// grpcAPIKey returns the API key of the call, from the x-api-key metadata or else from a
+// bearer token
+func grpcAPIKey(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if keys := md.Get(APIKeyHeader); len(keys) > 0 && keys[0] != "" {
+		return keys[0]
+	}
+	for _, authorization := range md.Get("authorization") {
+		if strings.HasPrefix(authorization, "Bearer ") {
+			return strings.TrimPrefix(authorization, "Bearer ")
+		}
+	}
+	return ""
+}
+
+// decodeGRPCCreateRequest is a transport/grpc.DecodeRequestFunc that converts a
+// gRPC create request to a user-domain session request.
+func decodeGRPCCreateRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*pb.CreateRequest)
+	session := SessionRequest{
+		TTL:         req.Ttl,
+		IdleTimeout: req.IdleTimeout,
+		MaxLifetime: req.MaxLifetime,
+		Subject:     req.Subject,
+	}
+	if req.Data != nil {
+		session.Data = req.Data.AsMap()
+	}
+	return session, nil
+}
+
+// decodeGRPCDestroyRequest is a transport/grpc.DecodeRequestFunc that converts a
+// gRPC destroy request to a user-domain destroy request.
+func decodeGRPCDestroyRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*pb.DestroyRequest)
+	return DestroyRequest{SessionId: req.SessionId}, nil
+}
+
+// decodeGRPCExtendRequest is a transport/grpc.DecodeRequestFunc that converts a
+// gRPC extend request to a user-domain extend request.
+func decodeGRPCExtendRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*pb.ExtendRequest)
+	return ExtendRequest{TTL: req.Ttl, SessionId: req.SessionId}, nil
+}
+
+// decodeGRPCListRequest is a transport/grpc.DecodeRequestFunc that converts a
+// gRPC list request to a user-domain list request.
+func decodeGRPCListRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*pb.ListRequest)
+	listRequest := ListRequest{
+		Cursor:  req.Cursor,
+		Limit:   int(req.Limit),
+		Subject: req.Subject,
+		MinTTL:  req.MinTtl,
+		MaxTTL:  req.MaxTtl,
+		Sort:    req.Sort,
+		Order:   req.Order,
+	}
+	if req.CreatedAfter != nil {
+		listRequest.CreatedAfter = req.CreatedAfter.AsTime()
+	}
+	if req.CreatedBefore != nil {
+		listRequest.CreatedBefore = req.CreatedBefore.AsTime()
+	}
+	return listRequest, nil
+}
+
+// decodeGRPCGetRequest is a transport/grpc.DecodeRequestFunc that converts a
+// gRPC get request to a user-domain session.
+func decodeGRPCGetRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*pb.GetRequest)
+	return Session{SessionId: req.SessionId}, nil
+}
+
+// decodeGRPCCreateBatchRequest is a transport/grpc.DecodeRequestFunc that converts a
+// gRPC create batch request to a user-domain batch create request.
+func decodeGRPCCreateBatchRequest(ctx context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*pb.CreateBatchRequest)
+	batchRequest := BatchCreateRequest{Sessions: make([]SessionRequest, len(req.Sessions))}
+	for i, session := range req.Sessions {
+		decoded, err := decodeGRPCCreateRequest(ctx, session)
+		if err != nil {
+			return nil, err
+		}
+		batchRequest.Sessions[i] = decoded.(SessionRequest)
+	}
+	return batchRequest, nil
+}
+
+// decodeGRPCExtendBatchRequest is a transport/grpc.DecodeRequestFunc that converts a
+// gRPC extend batch request to a user-domain batch extend request.
+func decodeGRPCExtendBatchRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*pb.ExtendBatchRequest)
+	batchRequest := BatchExtendRequest{Sessions: make([]ExtendRequest, len(req.Sessions))}
+	for i, session := range req.Sessions {
+		batchRequest.Sessions[i] = ExtendRequest{TTL: session.Ttl, SessionId: session.SessionId}
+	}
+	return batchRequest, nil
+}
+
+// decodeGRPCDestroyBatchRequest is a transport/grpc.DecodeRequestFunc that converts a
+// gRPC destroy batch request to a user-domain batch destroy request.
+func decodeGRPCDestroyBatchRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*pb.DestroyBatchRequest)
+	batchRequest := BatchDestroyRequest{Sessions: make([]DestroyRequest, len(req.Sessions))}
+	for i, session := range req.Sessions {
+		batchRequest.Sessions[i] = DestroyRequest{SessionId: session.SessionId}
+	}
+	return batchRequest, nil
+}
+
+// encodeGRPCCreateResponse is a transport/grpc.EncodeResponseFunc that converts a
+// user-domain create response to a gRPC create reply.
+func encodeGRPCCreateResponse(_ context.Context, response interface{}) (interface{}, error) {
+	resp := response.(*SessionMgmntResponse)
+	if resp.Err != nil {
+		return nil, encodeGRPCError(resp.Err)
+	}
+	return &pb.CreateReply{SessionId: resp.Data.(*Session).SessionId}, nil
+}
+
+// encodeGRPCDestroyResponse is a transport/grpc.EncodeResponseFunc that converts a
+// user-domain destroy response to a gRPC destroy reply.
+func encodeGRPCDestroyResponse(_ context.Context, response interface{}) (interface{}, error) {
+	resp := response.(*SessionMgmntResponse)
+	if resp.Err != nil {
+		return nil, encodeGRPCError(resp.Err)
+	}
+	return &pb.DestroyReply{}, nil
+}
+
+// encodeGRPCExtendResponse is a transport/grpc.EncodeResponseFunc that converts a
+// user-domain extend response to a gRPC extend reply.
+func encodeGRPCExtendResponse(_ context.Context, response interface{}) (interface{}, error) {
+	resp := response.(*SessionMgmntResponse)
+	if resp.Err != nil {
+		return nil, encodeGRPCError(resp.Err)
+	}
+	return &pb.ExtendReply{}, nil
+}
+
+// encodeGRPCListResponse is a transport/grpc.EncodeResponseFunc that converts a
+// user-domain list response to a gRPC list reply.
+func encodeGRPCListResponse(_ context.Context, response interface{}) (interface{}, error) {
+	resp := response.(*SessionMgmntResponse)
+	if resp.Err != nil {
+		return nil, encodeGRPCError(resp.Err)
+	}
+	sessions := resp.Data.(*Sessions)
+	return &pb.ListReply{SessionIds: sessions.List, NextCursor: sessions.NextCursor}, nil
+}
+
+// encodeGRPCGetResponse is a transport/grpc.EncodeResponseFunc that converts a
+// user-domain session details response to a gRPC get reply.
+func encodeGRPCGetResponse(_ context.Context, response interface{}) (interface{}, error) {
+	resp := response.(*SessionMgmntResponse)
+	if resp.Err != nil {
+		return nil, encodeGRPCError(resp.Err)
+	}
+	details := resp.Data.(*SessionDetails)
+	rep := &pb.GetReply{
+		SessionId:   details.SessionId,
+		Exists:      details.Exists,
+		CreatedAt:   timestamppb.New(details.CreatedAt),
+		ExpiresAt:   timestamppb.New(details.ExpiresAt),
+		Ttl:         details.TTL,
+		Subject:     details.Subject,
+		LastAccess:  timestamppb.New(details.LastAccess),
+		IdleTimeout: details.IdleTimeout,
+	}
+	if details.MaxExpiresAt != nil {
+		rep.MaxExpiresAt = timestamppb.New(*details.MaxExpiresAt)
+	}
+	return rep, nil
+}
+
+// encodeGRPCBatchResponse is a transport/grpc.EncodeResponseFunc that converts a
+// user-domain batch response to a gRPC batch reply, with the status code of the error of
+// every session of the batch.
+func encodeGRPCBatchResponse(_ context.Context, response interface{}) (interface{}, error) {
+	resp := response.(*SessionMgmntResponse)
+	if resp.Err != nil {
+		return nil, encodeGRPCError(resp.Err)
+	}
+	results := resp.Data.(*BatchResults).Results
+	rep := &pb.BatchReply{Results: make([]*pb.BatchResult, len(results))}
+	for i, result := range results {
+		rep.Results[i] = &pb.BatchResult{SessionId: result.SessionId}
+		if result.Err != nil {
+			rep.Results[i].Code = int32(grpcCode(result.Err))
+			rep.Results[i].Error = result.Err.Error()
+		}
+	}
+	return rep, nil
+}
+
+// encodeGRPCError maps errors from business-logic to gRPC status errors
+func encodeGRPCError(err error) error {
+	return status.Error(grpcCode(err), err.Error())
+}
+
+// grpcCode returns the gRPC status code of an error from business-logic
+func grpcCode(err error) codes.Code {
+	var code codes.Code
+
+	switch err {
+	case ErrNotFound:
+		code = codes.NotFound
+	case ErrInvalidArgument, ErrEmpty:
+		code = codes.InvalidArgument
+	case ErrSessionLimit:
+		code = codes.ResourceExhausted
+	case ErrInvalidToken:
+		code = codes.Unauthenticated
+	default:
+		code = codes.Internal
+	}
+
+	return code
+}
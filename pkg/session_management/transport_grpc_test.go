@@ -0,0 +1,314 @@
+package session_management_test
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"net"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	. "github.com/hecomp/session-management/internal/models"
+	"github.com/hecomp/session-management/pkg/auth"
+	"github.com/hecomp/session-management/pkg/pb"
+	. "github.com/hecomp/session-management/pkg/repository"
+	. "github.com/hecomp/session-management/pkg/session_management"
+	"github.com/hecomp/session-management/pkg/session_management/session_managementfakes"
+)
+
+type GRPCSuite struct {
+	fakeService *session_managementfakes.FakeSessionMgmntService
+	server      *grpc.Server
+	conn        *grpc.ClientConn
+	client      pb.SessionManagementClient
+}
+
+var _ = Describe("gRPC Transport", func() {
+
+	s := &GRPCSuite{}
+
+	BeforeEach(func() {
+		listener := bufconn.Listen(1024 * 1024)
+		s.fakeService = new(session_managementfakes.FakeSessionMgmntService)
+		s.server = grpc.NewServer()
+		pb.RegisterSessionManagementServer(s.server, NewGRPCServer(s.fakeService))
+		go s.server.Serve(listener)
+
+		var err error
+		s.conn, err = grpc.Dial("bufnet",
+			grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+				return listener.Dial()
+			}),
+			grpc.WithInsecure())
+		Expect(err).To(BeNil())
+		s.client = pb.NewSessionManagementClient(s.conn)
+	})
+
+	AfterEach(func() {
+		s.conn.Close()
+		s.server.Stop()
+	})
+
+	Context("Create()", func() {
+		It("returns the new sessionId", func() {
+			uniqueUUID := "90660b89-100e-4f8f-9801-2524df6fbe34"
+			data, _ := structpb.NewStruct(map[string]interface{}{"user_id": "42"})
+			s.fakeService.CreateReturns(uniqueUUID, nil)
+			rep, err := s.client.Create(context.Background(), &pb.CreateRequest{Ttl: 50, Data: data})
+			Expect(err).To(BeNil())
+			Expect(rep.SessionId).To(Equal(uniqueUUID))
+
+			_, req := s.fakeService.CreateArgsForCall(0)
+			Expect(req).To(Equal(&SessionRequest{TTL: 50, Data: map[string]interface{}{"user_id": "42"}}))
+		})
+		It("maps a create error to an internal status", func() {
+			s.fakeService.CreateReturns("", errors.New("error create"))
+			_, err := s.client.Create(context.Background(), &pb.CreateRequest{})
+			Expect(status.Code(err)).To(Equal(codes.Internal))
+		})
+		It("maps ErrSessionLimit to a resource exhausted status", func() {
+			s.fakeService.CreateReturns("", ErrSessionLimit)
+			_, err := s.client.Create(context.Background(), &pb.CreateRequest{Subject: "user-42"})
+			Expect(status.Code(err)).To(Equal(codes.ResourceExhausted))
+		})
+		It("passes the deadline of the call to the service", func() {
+			deadline := time.Now().Add(time.Minute)
+			callCtx, cancel := context.WithDeadline(context.Background(), deadline)
+			defer cancel()
+			_, err := s.client.Create(callCtx, &pb.CreateRequest{})
+			Expect(err).To(BeNil())
+
+			serviceCtx, _ := s.fakeService.CreateArgsForCall(0)
+			serviceDeadline, ok := serviceCtx.Deadline()
+			Expect(ok).To(BeTrue())
+			Expect(serviceDeadline).To(BeTemporally("~", deadline, time.Second))
+		})
+	})
+
+	Context("Destroy()", func() {
+		It("maps ErrNotFound to a not found status", func() {
+			s.fakeService.DestroyReturns(ErrNotFound)
+			_, err := s.client.Destroy(context.Background(), &pb.DestroyRequest{SessionId: "90660b89-100e-4f8f-9801-2524df6fbe34"})
+			Expect(status.Code(err)).To(Equal(codes.NotFound))
+		})
+		It("maps ErrEmpty to an invalid argument status", func() {
+			s.fakeService.DestroyReturns(ErrEmpty)
+			_, err := s.client.Destroy(context.Background(), &pb.DestroyRequest{})
+			Expect(status.Code(err)).To(Equal(codes.InvalidArgument))
+		})
+		It("maps ErrInvalidToken to an unauthenticated status", func() {
+			s.fakeService.DestroyReturns(ErrInvalidToken)
+			_, err := s.client.Destroy(context.Background(), &pb.DestroyRequest{SessionId: "90660b89-100e-4f8f-9801-2524df6fbe34.forged"})
+			Expect(status.Code(err)).To(Equal(codes.Unauthenticated))
+		})
+	})
+
+	Context("Extend()", func() {
+		It("extends the session", func() {
+			s.fakeService.ExtendReturns(nil)
+			_, err := s.client.Extend(context.Background(), &pb.ExtendRequest{SessionId: "90660b89-100e-4f8f-9801-2524df6fbe34", Ttl: 100})
+			Expect(err).To(BeNil())
+			_, extendRequest := s.fakeService.ExtendArgsForCall(0)
+			Expect(extendRequest).To(Equal(&ExtendRequest{SessionId: "90660b89-100e-4f8f-9801-2524df6fbe34", TTL: 100}))
+		})
+	})
+
+	Context("List()", func() {
+		It("returns the sessionIds", func() {
+			sessions := &Sessions{List: []string{"90660b89-100e-4f8f-9801-2524df6fbe34"}}
+			s.fakeService.ListReturns(sessions, nil)
+			rep, err := s.client.List(context.Background(), &pb.ListRequest{})
+			Expect(err).To(BeNil())
+			Expect(rep.SessionIds).To(Equal(sessions.List))
+		})
+		It("passes the page, filters and ordering and returns the next cursor", func() {
+			createdAfter := time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+			s.fakeService.ListReturns(&Sessions{List: []string{"90660b89-100e-4f8f-9801-2524df6fbe34"}, NextCursor: "next"}, nil)
+			rep, err := s.client.List(context.Background(), &pb.ListRequest{
+				Cursor:       "cursor",
+				Limit:        10,
+				Subject:      "user-42",
+				CreatedAfter: timestamppb.New(createdAfter),
+				MinTtl:       60,
+				Sort:         "expires_at",
+				Order:        "desc",
+			})
+			Expect(err).To(BeNil())
+			Expect(rep.NextCursor).To(Equal("next"))
+			_, listRequest := s.fakeService.ListArgsForCall(0)
+			Expect(listRequest).To(Equal(&ListRequest{
+				Cursor:       "cursor",
+				Limit:        10,
+				Subject:      "user-42",
+				CreatedAfter: createdAfter,
+				MinTTL:       60,
+				Sort:         "expires_at",
+				Order:        "desc",
+			}))
+		})
+		It("maps an invalid argument", func() {
+			s.fakeService.ListReturns(nil, ErrInvalidArgument)
+			_, err := s.client.List(context.Background(), &pb.ListRequest{Limit: -1})
+			Expect(status.Code(err)).To(Equal(codes.InvalidArgument))
+		})
+	})
+
+	Context("Get()", func() {
+		It("returns the session details", func() {
+			uniqueUUID := "90660b89-100e-4f8f-9801-2524df6fbe34"
+			s.fakeService.GetReturns(&SessionDetails{SessionId: uniqueUUID, Exists: true, TTL: 30}, nil)
+			rep, err := s.client.Get(context.Background(), &pb.GetRequest{SessionId: uniqueUUID})
+			Expect(err).To(BeNil())
+			Expect(rep.SessionId).To(Equal(uniqueUUID))
+			Expect(rep.Exists).To(BeTrue())
+			Expect(rep.Ttl).To(Equal(int64(30)))
+		})
+		It("maps ErrInvalidArgument to an invalid argument status", func() {
+			s.fakeService.GetReturns(nil, ErrInvalidArgument)
+			_, err := s.client.Get(context.Background(), &pb.GetRequest{SessionId: "90660b89-100e-4f8f-9801-2524df6fbe34"})
+			Expect(status.Code(err)).To(Equal(codes.InvalidArgument))
+		})
+	})
+
+	Context("CreateBatch(), ExtendBatch() and DestroyBatch()", func() {
+		uniqueUUID := "90660b89-100e-4f8f-9801-2524df6fbe34"
+
+		It("return the status of every session of the batch", func() {
+			s.fakeService.CreateBatchReturns(&BatchResults{Results: []BatchResult{
+				{SessionId: uniqueUUID},
+				{Err: ErrSessionLimit},
+			}}, nil)
+			rep, err := s.client.CreateBatch(context.Background(), &pb.CreateBatchRequest{Sessions: []*pb.CreateRequest{
+				{Ttl: 60},
+				{Subject: "user-42"},
+			}})
+			Expect(err).To(BeNil())
+			Expect(rep.Results).To(HaveLen(2))
+			Expect(rep.Results[0].SessionId).To(Equal(uniqueUUID))
+			Expect(codes.Code(rep.Results[0].Code)).To(Equal(codes.OK))
+			Expect(codes.Code(rep.Results[1].Code)).To(Equal(codes.ResourceExhausted))
+			Expect(rep.Results[1].Error).To(Equal(ErrSessionLimit.Error()))
+			_, request := s.fakeService.CreateBatchArgsForCall(0)
+			Expect(request.Sessions).To(Equal([]SessionRequest{{TTL: 60}, {Subject: "user-42"}}))
+
+			s.fakeService.ExtendBatchReturns(&BatchResults{Results: []BatchResult{{SessionId: uniqueUUID, Err: ErrNotFound}}}, nil)
+			rep, err = s.client.ExtendBatch(context.Background(), &pb.ExtendBatchRequest{Sessions: []*pb.ExtendRequest{{SessionId: uniqueUUID, Ttl: 60}}})
+			Expect(err).To(BeNil())
+			Expect(codes.Code(rep.Results[0].Code)).To(Equal(codes.NotFound))
+			_, extendRequest := s.fakeService.ExtendBatchArgsForCall(0)
+			Expect(extendRequest.Sessions).To(Equal([]ExtendRequest{{SessionId: uniqueUUID, TTL: 60}}))
+
+			s.fakeService.DestroyBatchReturns(&BatchResults{Results: []BatchResult{{SessionId: uniqueUUID}}}, nil)
+			rep, err = s.client.DestroyBatch(context.Background(), &pb.DestroyBatchRequest{Sessions: []*pb.DestroyRequest{{SessionId: uniqueUUID}}})
+			Expect(err).To(BeNil())
+			Expect(codes.Code(rep.Results[0].Code)).To(Equal(codes.OK))
+		})
+		It("map an invalid batch to an invalid argument status", func() {
+			s.fakeService.DestroyBatchReturns(nil, ErrInvalidArgument)
+			_, err := s.client.DestroyBatch(context.Background(), &pb.DestroyBatchRequest{})
+			Expect(status.Code(err)).To(Equal(codes.InvalidArgument))
+		})
+	})
+
+	Context("AuthenticateInterceptor()", func() {
+		const writerKey = "writer-0123456789abcdef0123456789"
+		const adminKey = "admin-0123456789abcdef0123456789"
+
+		BeforeEach(func() {
+			keys, err := auth.NewKeyStore(
+				auth.APIKey{Name: "writer", Hash: auth.HashKey(writerKey), Scopes: []auth.Scope{auth.ScopeWrite}},
+				auth.APIKey{Name: "admin", Hash: auth.HashKey(adminKey), Scopes: []auth.Scope{auth.ScopeAdmin}},
+			)
+			Expect(err).To(BeNil())
+
+			s.conn.Close()
+			s.server.Stop()
+			listener := bufconn.Listen(1024 * 1024)
+			s.server = grpc.NewServer(grpc.ChainUnaryInterceptor(ClientCertificateInterceptor, AuthenticateInterceptor(keys)))
+			pb.RegisterSessionManagementServer(s.server, NewGRPCServer(s.fakeService))
+			go s.server.Serve(listener)
+			s.conn, err = grpc.Dial("bufnet",
+				grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+					return listener.Dial()
+				}),
+				grpc.WithInsecure())
+			Expect(err).To(BeNil())
+			s.client = pb.NewSessionManagementClient(s.conn)
+		})
+
+		withKey := func(key string) context.Context {
+			return metadata.AppendToOutgoingContext(context.Background(), "x-api-key", key)
+		}
+
+		It("rejects a call without a valid API key", func() {
+			_, err := s.client.List(context.Background(), &pb.ListRequest{})
+			Expect(status.Code(err)).To(Equal(codes.Unauthenticated))
+			_, err = s.client.Destroy(withKey("unknown-0123456789abcdef0123456789"), &pb.DestroyRequest{SessionId: "90660b89-100e-4f8f-9801-2524df6fbe34"})
+			Expect(status.Code(err)).To(Equal(codes.Unauthenticated))
+			Expect(s.fakeService.Invocations()).To(BeEmpty())
+		})
+		It("requires the scope of the RPC", func() {
+			s.fakeService.DestroyReturns(nil)
+			_, err := s.client.Destroy(withKey(writerKey), &pb.DestroyRequest{SessionId: "90660b89-100e-4f8f-9801-2524df6fbe34"})
+			Expect(err).To(BeNil())
+			_, err = s.client.List(withKey(writerKey), &pb.ListRequest{})
+			Expect(status.Code(err)).To(Equal(codes.PermissionDenied))
+			_, err = s.client.DestroyBatch(withKey(writerKey), &pb.DestroyBatchRequest{})
+			Expect(status.Code(err)).To(Equal(codes.PermissionDenied))
+			Expect(s.fakeService.ListCallCount()).To(BeZero())
+			Expect(s.fakeService.DestroyBatchCallCount()).To(BeZero())
+		})
+		It("accepts the API key as a bearer token", func() {
+			s.fakeService.ListReturns(&Sessions{}, nil)
+			ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+adminKey)
+			_, err := s.client.List(ctx, &pb.ListRequest{})
+			Expect(err).To(BeNil())
+		})
+		It("authenticates the client certificate subject", func() {
+			keys, err := auth.NewKeyStore(auth.APIKey{Name: "backoffice", Subject: "CN=backoffice", Scopes: []auth.Scope{auth.ScopeAdmin}})
+			Expect(err).To(BeNil())
+			ctx := auth.NewClientContext(context.Background(), "CN=backoffice")
+			principal, err := AuthenticateInterceptor(keys)(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/pb.SessionManagement/List"},
+				func(ctx context.Context, _ interface{}) (interface{}, error) {
+					principal, _ := auth.FromContext(ctx)
+					return principal.Name, nil
+				})
+			Expect(err).To(BeNil())
+			Expect(principal).To(Equal("backoffice"))
+		})
+	})
+
+	Context("ClientCertificateInterceptor()", func() {
+		handler := func(ctx context.Context, _ interface{}) (interface{}, error) {
+			subject, _ := auth.ClientFromContext(ctx)
+			return subject, nil
+		}
+
+		It("attaches the client certificate subject of the TLS connection", func() {
+			ctx := peer.NewContext(context.Background(), &peer.Peer{AuthInfo: credentials.TLSInfo{State: tls.ConnectionState{
+				PeerCertificates: []*x509.Certificate{{Subject: pkix.Name{CommonName: "backoffice"}}},
+			}}})
+			subject, err := ClientCertificateInterceptor(ctx, nil, &grpc.UnaryServerInfo{}, handler)
+			Expect(err).To(BeNil())
+			Expect(subject).To(Equal("CN=backoffice"))
+		})
+		It("attaches nothing without a client certificate", func() {
+			subject, err := ClientCertificateInterceptor(context.Background(), nil, &grpc.UnaryServerInfo{}, handler)
+			Expect(err).To(BeNil())
+			Expect(subject).To(Equal(""))
+		})
+	})
+})
@@ -0,0 +1,95 @@
+package session_management_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"go.opentelemetry.io/otel/trace"
+
+	. "github.com/hecomp/session-management/internal/models"
+	"github.com/hecomp/session-management/pkg/auth"
+	. "github.com/hecomp/session-management/pkg/session_management"
+	"github.com/hecomp/session-management/pkg/session_management/session_managementfakes"
+)
+
+var _ = Describe("HTTP Transport", func() {
+
+	var fakeService *session_managementfakes.FakeSessionMgmntService
+	var handler http.Handler
+
+	BeforeEach(func() {
+		fakeService = new(session_managementfakes.FakeSessionMgmntService)
+		handler = MakeHandler(fakeService, trace.NewNoopTracerProvider().Tracer(""), nil)
+	})
+
+	serve := func(method, path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+		return rec
+	}
+
+	Context("/list", func() {
+		It("lists a page of the sessions", func() {
+			fakeService.ListReturns(&Sessions{List: []string{}}, nil)
+			Expect(serve(http.MethodGet, "/list?limit=10").Code).To(Equal(http.StatusOK))
+			_, request := fakeService.ListArgsForCall(0)
+			Expect(request.Limit).To(Equal(10))
+		})
+		It("refuses malformed parameters with a 400", func() {
+			for _, query := range []string{"limit=ten", "created_after=yesterday", "min_ttl=1m"} {
+				rec := serve(http.MethodGet, "/list?"+query)
+				Expect(rec.Code).To(Equal(http.StatusBadRequest), query)
+				var body map[string]interface{}
+				Expect(json.NewDecoder(rec.Body).Decode(&body)).To(Succeed())
+				Expect(body).To(Equal(map[string]interface{}{"error": ErrBadRequest.Error(), "status_code": float64(http.StatusBadRequest)}))
+			}
+			Expect(fakeService.ListCallCount()).To(BeZero())
+		})
+	})
+
+	Context("with API keys", func() {
+		scopedKeys := map[auth.Scope]string{
+			auth.ScopeCreate: "creator-0123456789abcdef0123456789",
+			auth.ScopeRead:   "reader-0123456789abcdef0123456789",
+			auth.ScopeWrite:  "writer-0123456789abcdef0123456789",
+			auth.ScopeAdmin:  "admin-0123456789abcdef0123456789",
+		}
+
+		BeforeEach(func() {
+			var apiKeys []auth.APIKey
+			for scope, key := range scopedKeys {
+				apiKeys = append(apiKeys, auth.APIKey{Name: string(scope), Hash: auth.HashKey(key), Scopes: []auth.Scope{scope}})
+			}
+			keys, err := auth.NewKeyStore(apiKeys...)
+			Expect(err).To(BeNil())
+			handler = MakeHandler(fakeService, trace.NewNoopTracerProvider().Tracer(""), keys)
+		})
+
+		serveWithKey := func(path, key string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(`{"sessions": [{"session_id": "90660b89-100e-4f8f-9801-2524df6fbe34"}]}`))
+			req.Header.Set(APIKeyHeader, key)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			return rec
+		}
+
+		It("restricts the batch routes to the admin scope", func() {
+			fakeService.CreateBatchReturns(&BatchResults{}, nil)
+			fakeService.ExtendBatchReturns(&BatchResults{}, nil)
+			fakeService.DestroyBatchReturns(&BatchResults{}, nil)
+			for _, path := range []string{"/batch/create", "/batch/extend", "/batch/destroy"} {
+				for _, scope := range []auth.Scope{auth.ScopeCreate, auth.ScopeRead, auth.ScopeWrite} {
+					Expect(serveWithKey(path, scopedKeys[scope]).Code).To(Equal(http.StatusForbidden), path+" "+string(scope))
+				}
+				Expect(serveWithKey(path, scopedKeys[auth.ScopeAdmin]).Code).To(Equal(http.StatusOK), path)
+			}
+			Expect(fakeService.CreateBatchCallCount()).To(Equal(1))
+			Expect(fakeService.ExtendBatchCallCount()).To(Equal(1))
+			Expect(fakeService.DestroyBatchCallCount()).To(Equal(1))
+		})
+	})
+})
@@ -0,0 +1,364 @@
+package session_management
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	httptransport "github.com/go-kit/kit/transport/http"
+	"go.opentelemetry.io/otel/trace"
+
+	. "github.com/hecomp/session-management/internal/models"
+	"github.com/hecomp/session-management/pkg/auth"
+)
+
+const (
+	// V2SessionsPath is the collection of the sessions of the v2 API
+	V2SessionsPath = "/v2/sessions"
+	// V2SubjectsPath prefixes the sessions of a subject, /v2/subjects/{subject}/sessions
+	V2SubjectsPath = "/v2/subjects/"
+	// V2BatchPath is the batch operations on the sessions of the v2 API
+	V2BatchPath = "/v2/batch/sessions"
+)
+
+// ErrMethodNotAllowed is returned when a resource of the v2 API does not support the
+// method of the request
+var ErrMethodNotAllowed = errors.New("method not allowed")
+
+// methods routes the requests of a resource by their method. The other methods are
+// refused with a 405 whose Allow header lists the supported ones.
+type methods map[string]http.Handler
+
+func (m methods) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h, found := m[r.Method]
+	if !found {
+		allowed := make([]string, 0, len(m))
+		for method := range m {
+			allowed = append(allowed, method)
+		}
+		sort.Strings(allowed)
+		w.Header().Set("Allow", strings.Join(allowed, ", "))
+		encodeError(r.Context(), ErrMethodNotAllowed, w)
+		return
+	}
+	h.ServeHTTP(w, r)
+}
+
+// MakeV2Handler mounts the resource routes of the v2 API, running every request in a span
+// of the tracer:
+//
+//	POST   /v2/sessions                      create a session
+//	GET    /v2/sessions                      list a page of the sessions
+//	GET    /v2/sessions/{id}                 get a session
+//	PATCH  /v2/sessions/{id}                 extend a session
+//	DELETE /v2/sessions/{id}                 destroy a session
+//	POST   /v2/sessions/{id}/rotate          rotate a session id
+//	GET    /v2/sessions/{id}/data            get the data of a session
+//	PUT    /v2/sessions/{id}/data            set the data of a session
+//	PATCH  /v2/sessions/{id}/data            patch the data of a session
+//	GET    /v2/subjects/{subject}/sessions   list the sessions of a subject
+//	DELETE /v2/subjects/{subject}/sessions   destroy the sessions of a subject
+//	POST   /v2/batch/sessions                create a batch of sessions
+//	PATCH  /v2/batch/sessions                extend a batch of sessions
+//	DELETE /v2/batch/sessions                destroy a batch of sessions
+//
+// The routes require the scopes of their legacy counterparts when keys is not nil.
+func MakeV2Handler(svc SessionMgmntService, tracer trace.Tracer, keys *auth.KeyStore) http.Handler {
+
+	mux := http.NewServeMux()
+
+	createHandler := httptransport.NewServer(
+		MakeCreateEndpoint(svc),
+		decodeV2CreateRequest,
+		encodeV2CreateResponse,
+		serverOptions(tracer, "POST /v2/sessions")...)
+	listHandler := httptransport.NewServer(
+		MakeListEndpoint(svc),
+		decodeHTTPListRequest,
+		encodeResponse,
+		serverOptions(tracer, "GET /v2/sessions")...)
+	getHandler := httptransport.NewServer(
+		MakeGetEndpoint(svc),
+		decodeV2SessionRequest,
+		encodeResponse,
+		serverOptions(tracer, "GET /v2/sessions/{id}")...)
+	extendHandler := httptransport.NewServer(
+		MakeExtendEndpoint(svc),
+		decodeV2ExtendRequest,
+		encodeResponse,
+		serverOptions(tracer, "PATCH /v2/sessions/{id}")...)
+	destroyHandler := httptransport.NewServer(
+		MakeDestroyEndpoint(svc),
+		decodeV2DestroyRequest,
+		encodeResponse,
+		serverOptions(tracer, "DELETE /v2/sessions/{id}")...)
+	rotateHandler := httptransport.NewServer(
+		MakeRotateEndpoint(svc),
+		decodeV2RotateRequest,
+		encodeResponse,
+		serverOptions(tracer, "POST /v2/sessions/{id}/rotate")...)
+	getDataHandler := httptransport.NewServer(
+		MakeGetDataEndpoint(svc),
+		decodeV2SessionRequest,
+		encodeResponse,
+		serverOptions(tracer, "GET /v2/sessions/{id}/data")...)
+	setDataHandler := httptransport.NewServer(
+		MakeSetDataEndpoint(svc),
+		decodeV2SessionDataRequest,
+		encodeResponse,
+		serverOptions(tracer, "PUT /v2/sessions/{id}/data")...)
+	patchDataHandler := httptransport.NewServer(
+		MakePatchDataEndpoint(svc),
+		decodeV2SessionDataRequest,
+		encodeResponse,
+		serverOptions(tracer, "PATCH /v2/sessions/{id}/data")...)
+	listSubjectHandler := httptransport.NewServer(
+		MakeListSubjectEndpoint(svc),
+		decodeV2SubjectRequest,
+		encodeResponse,
+		serverOptions(tracer, "GET /v2/subjects/{subject}/sessions")...)
+	destroySubjectHandler := httptransport.NewServer(
+		MakeDestroySubjectEndpoint(svc),
+		decodeV2SubjectRequest,
+		encodeResponse,
+		serverOptions(tracer, "DELETE /v2/subjects/{subject}/sessions")...)
+	createBatchHandler := httptransport.NewServer(
+		MakeCreateBatchEndpoint(svc),
+		decodeV2BatchCreateRequest,
+		encodeResponse,
+		serverOptions(tracer, "POST /v2/batch/sessions")...)
+	extendBatchHandler := httptransport.NewServer(
+		MakeExtendBatchEndpoint(svc),
+		decodeV2BatchExtendRequest,
+		encodeResponse,
+		serverOptions(tracer, "PATCH /v2/batch/sessions")...)
+	destroyBatchHandler := httptransport.NewServer(
+		MakeDestroyBatchEndpoint(svc),
+		decodeV2BatchDestroyRequest,
+		encodeResponse,
+		serverOptions(tracer, "DELETE /v2/batch/sessions")...)
+
+	sessions := methods{
+		http.MethodPost: Authenticate(keys, auth.ScopeCreate, createHandler),
+		http.MethodGet:  Authenticate(keys, auth.ScopeAdmin, listHandler),
+	}
+	session := methods{
+		http.MethodGet:    Authenticate(keys, auth.ScopeRead, getHandler),
+		http.MethodPatch:  Authenticate(keys, auth.ScopeWrite, extendHandler),
+		http.MethodDelete: Authenticate(keys, auth.ScopeWrite, destroyHandler),
+	}
+	rotate := methods{
+		http.MethodPost: Authenticate(keys, auth.ScopeWrite, rotateHandler),
+	}
+	data := methods{
+		http.MethodGet:   Authenticate(keys, auth.ScopeRead, getDataHandler),
+		http.MethodPut:   Authenticate(keys, auth.ScopeWrite, setDataHandler),
+		http.MethodPatch: Authenticate(keys, auth.ScopeWrite, patchDataHandler),
+	}
+	subjectSessions := methods{
+		http.MethodGet:    Authenticate(keys, auth.ScopeAdmin, listSubjectHandler),
+		http.MethodDelete: Authenticate(keys, auth.ScopeAdmin, destroySubjectHandler),
+	}
+	batch := methods{
+		http.MethodPost:   Authenticate(keys, auth.ScopeAdmin, createBatchHandler),
+		http.MethodPatch:  Authenticate(keys, auth.ScopeAdmin, extendBatchHandler),
+		http.MethodDelete: Authenticate(keys, auth.ScopeAdmin, destroyBatchHandler),
+	}
+
+	mux.Handle(V2SessionsPath, sessions)
+	mux.Handle(V2SessionsPath+"/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		segments := pathSegments(r, V2SessionsPath+"/")
+		switch {
+		case len(segments) == 1:
+			session.ServeHTTP(w, r)
+		case len(segments) == 2 && segments[1] == "rotate":
+			rotate.ServeHTTP(w, r)
+		case len(segments) == 2 && segments[1] == "data":
+			data.ServeHTTP(w, r)
+		default:
+			encodeError(r.Context(), ErrBadRouting, w)
+		}
+	}))
+	mux.Handle(V2SubjectsPath, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		segments := pathSegments(r, V2SubjectsPath)
+		if len(segments) != 2 || segments[1] != "sessions" {
+			encodeError(r.Context(), ErrBadRouting, w)
+			return
+		}
+		subjectSessions.ServeHTTP(w, r)
+	}))
+	mux.Handle(V2BatchPath, batch)
+
+	return accessControl(mux)
+}
+
+// pathSegments returns the unescaped segments of the request path following the prefix,
+// or nil when one of them is empty. The segments are split on the escaped path, so a
+// session id or a subject may hold an escaped slash.
+func pathSegments(r *http.Request, prefix string) []string {
+	path := strings.TrimPrefix(r.URL.EscapedPath(), prefix)
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		unescaped, err := url.PathUnescape(segment)
+		if err != nil || unescaped == "" {
+			return nil
+		}
+		segments[i] = unescaped
+	}
+	return segments
+}
+
+// decodeV2CreateRequest is a transport/http.DecodeRequestFunc that decodes a
+// JSON-encoded session request from the HTTP request body. Primarily useful in a
+// server.
+func decodeV2CreateRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var session SessionRequest
+
+	if r.Body == nil {
+		return nil, ErrBadRequest
+	}
+	if err := json.NewDecoder(r.Body).Decode(&session); err != nil {
+		return nil, ErrBadRequest
+	}
+	return session, nil
+}
+
+// decodeV2SessionRequest is a transport/http.DecodeRequestFunc that decodes the
+// session id from the /v2/sessions/{id} request path. Primarily useful in a server.
+func decodeV2SessionRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	segments := pathSegments(r, V2SessionsPath+"/")
+	if len(segments) == 0 {
+		return nil, ErrBadRouting
+	}
+	return Session{SessionId: segments[0]}, nil
+}
+
+// decodeV2DestroyRequest is a transport/http.DecodeRequestFunc that decodes the
+// session id from the /v2/sessions/{id} request path. Primarily useful in a server.
+func decodeV2DestroyRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	session, err := decodeV2SessionRequest(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	return DestroyRequest{SessionId: session.(Session).SessionId}, nil
+}
+
+// decodeV2RotateRequest is a transport/http.DecodeRequestFunc that decodes the
+// session id from the /v2/sessions/{id}/rotate request path. Primarily useful in a server.
+func decodeV2RotateRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	session, err := decodeV2SessionRequest(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	return RotateRequest{SessionId: session.(Session).SessionId}, nil
+}
+
+// decodeV2ExtendRequest is a transport/http.DecodeRequestFunc that decodes the
+// session id from the /v2/sessions/{id} request path and the optional JSON-encoded
+// ttl from the request body. Primarily useful in a server.
+func decodeV2ExtendRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	session, err := decodeV2SessionRequest(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+
+	var extendRequest ExtendRequest
+	if r.Body != nil {
+		if err := json.NewDecoder(r.Body).Decode(&extendRequest); err != nil && err != io.EOF {
+			return nil, ErrBadRequest
+		}
+	}
+	extendRequest.SessionId = session.(Session).SessionId
+	return extendRequest, nil
+}
+
+// decodeV2SessionDataRequest is a transport/http.DecodeRequestFunc that decodes the
+// session id from the /v2/sessions/{id}/data request path and the JSON-encoded data
+// from the request body. Primarily useful in a server.
+func decodeV2SessionDataRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	session, err := decodeV2SessionRequest(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+
+	var dataRequest SessionDataRequest
+	if r.Body == nil {
+		return nil, ErrBadRequest
+	}
+	if err := json.NewDecoder(r.Body).Decode(&dataRequest); err != nil {
+		return nil, ErrBadRequest
+	}
+	dataRequest.SessionId = session.(Session).SessionId
+	return dataRequest, nil
+}
+
+// decodeV2SubjectRequest is a transport/http.DecodeRequestFunc that decodes the
+// subject from the /v2/subjects/{subject}/sessions request path. Primarily useful in a
+// server.
+func decodeV2SubjectRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	segments := pathSegments(r, V2SubjectsPath)
+	if len(segments) == 0 {
+		return nil, ErrBadRouting
+	}
+	return SubjectRequest{Subject: segments[0]}, nil
+}
+
+// decodeV2BatchCreateRequest is a transport/http.DecodeRequestFunc that decodes a
+// JSON-encoded batch create request from the HTTP request body. Primarily useful in a
+// server.
+func decodeV2BatchCreateRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var batchRequest BatchCreateRequest
+
+	if r.Body == nil {
+		return nil, ErrBadRequest
+	}
+	if err := json.NewDecoder(r.Body).Decode(&batchRequest); err != nil {
+		return nil, ErrBadRequest
+	}
+	return batchRequest, nil
+}
+
+// decodeV2BatchExtendRequest is a transport/http.DecodeRequestFunc that decodes a
+// JSON-encoded batch extend request from the HTTP request body. Primarily useful in a
+// server.
+func decodeV2BatchExtendRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var batchRequest BatchExtendRequest
+
+	if r.Body == nil {
+		return nil, ErrBadRequest
+	}
+	if err := json.NewDecoder(r.Body).Decode(&batchRequest); err != nil {
+		return nil, ErrBadRequest
+	}
+	return batchRequest, nil
+}
+
+// decodeV2BatchDestroyRequest is a transport/http.DecodeRequestFunc that decodes a
+// JSON-encoded batch destroy request from the HTTP request body. Primarily useful in a
+// server.
+func decodeV2BatchDestroyRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var batchRequest BatchDestroyRequest
+
+	if r.Body == nil {
+		return nil, ErrBadRequest
+	}
+	if err := json.NewDecoder(r.Body).Decode(&batchRequest); err != nil {
+		return nil, ErrBadRequest
+	}
+	return batchRequest, nil
+}
+
+// encodeV2CreateResponse writes the created session like encodeResponse, along with its
+// Location
+func encodeV2CreateResponse(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+	resp := response.(*SessionMgmntResponse)
+	if session, ok := resp.Data.(*Session); ok && resp.Err == nil {
+		w.Header().Set("Location", V2SessionsPath+"/"+url.PathEscape(session.SessionId))
+	}
+	return encodeResponse(ctx, w, response)
+}
@@ -0,0 +1,311 @@
+package session_management_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"go.opentelemetry.io/otel/trace"
+
+	. "github.com/hecomp/session-management/internal/models"
+	"github.com/hecomp/session-management/pkg/auth"
+	. "github.com/hecomp/session-management/pkg/repository"
+	. "github.com/hecomp/session-management/pkg/session_management"
+	"github.com/hecomp/session-management/pkg/session_management/session_managementfakes"
+)
+
+var _ = Describe("v2 HTTP Transport", func() {
+
+	const sessionId = "90660b89-100e-4f8f-9801-2524df6fbe34"
+
+	var fakeService *session_managementfakes.FakeSessionMgmntService
+	var handler http.Handler
+
+	BeforeEach(func() {
+		fakeService = new(session_managementfakes.FakeSessionMgmntService)
+		handler = MakeV2Handler(fakeService, trace.NewNoopTracerProvider().Tracer(""), nil)
+	})
+
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		var reader io.Reader
+		if body != "" {
+			reader = strings.NewReader(body)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(method, path, reader))
+		return rec
+	}
+
+	decode := func(rec *httptest.ResponseRecorder) map[string]interface{} {
+		var body map[string]interface{}
+		Expect(json.NewDecoder(rec.Body).Decode(&body)).To(Succeed())
+		return body
+	}
+
+	Context("/v2/sessions", func() {
+		It("creates a session on POST", func() {
+			fakeService.CreateReturns(sessionId, nil)
+			rec := serve(http.MethodPost, "/v2/sessions", `{"ttl": 60, "subject": "user-42"}`)
+			Expect(rec.Code).To(Equal(http.StatusCreated))
+			Expect(rec.Header().Get("Location")).To(Equal("/v2/sessions/" + sessionId))
+			_, request := fakeService.CreateArgsForCall(0)
+			Expect(request).To(Equal(&SessionRequest{TTL: 60, Subject: "user-42"}))
+		})
+		It("lists the sessions on GET", func() {
+			fakeService.ListReturns(&Sessions{List: []string{sessionId}}, nil)
+			rec := serve(http.MethodGet, "/v2/sessions", "")
+			Expect(rec.Code).To(Equal(http.StatusOK))
+			Expect(decode(rec)["data"]).To(Equal(map[string]interface{}{"list": []interface{}{sessionId}}))
+		})
+		It("lists an empty page on GET", func() {
+			fakeService.ListReturns(&Sessions{List: []string{}}, nil)
+			rec := serve(http.MethodGet, "/v2/sessions?subject=nobody", "")
+			Expect(rec.Code).To(Equal(http.StatusOK))
+			Expect(decode(rec)["data"]).To(Equal(map[string]interface{}{"list": []interface{}{}}))
+		})
+		It("pages, filters and orders the sessions by the query parameters", func() {
+			fakeService.ListReturns(&Sessions{List: []string{sessionId}, NextCursor: "next"}, nil)
+			rec := serve(http.MethodGet, "/v2/sessions?cursor=cursor&limit=10&subject=user-42"+
+				"&created_after=2021-03-01T00:00:00Z&created_before=2021-04-01T00:00:00Z"+
+				"&min_ttl=60&max_ttl=3600&sort=expires_at&order=desc", "")
+			Expect(rec.Code).To(Equal(http.StatusOK))
+			Expect(decode(rec)["data"]).To(Equal(map[string]interface{}{"list": []interface{}{sessionId}, "next_cursor": "next"}))
+			_, request := fakeService.ListArgsForCall(0)
+			Expect(request).To(Equal(&ListRequest{
+				Cursor:        "cursor",
+				Limit:         10,
+				Subject:       "user-42",
+				CreatedAfter:  time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC),
+				CreatedBefore: time.Date(2021, 4, 1, 0, 0, 0, 0, time.UTC),
+				MinTTL:        60,
+				MaxTTL:        3600,
+				Sort:          "expires_at",
+				Order:         "desc",
+			}))
+		})
+		It("refuses malformed query parameters with a 400", func() {
+			for _, query := range []string{"limit=ten", "created_after=yesterday", "min_ttl=1m"} {
+				rec := serve(http.MethodGet, "/v2/sessions?"+query, "")
+				Expect(rec.Code).To(Equal(http.StatusBadRequest))
+			}
+			Expect(fakeService.ListCallCount()).To(BeZero())
+		})
+		It("refuses invalid arguments with a 400", func() {
+			fakeService.ListReturns(nil, ErrInvalidArgument)
+			rec := serve(http.MethodGet, "/v2/sessions?sort=subject", "")
+			Expect(rec.Code).To(Equal(http.StatusBadRequest))
+		})
+		It("refuses a malformed body with a 400", func() {
+			rec := serve(http.MethodPost, "/v2/sessions", `{"ttl":`)
+			Expect(rec.Code).To(Equal(http.StatusBadRequest))
+			Expect(fakeService.CreateCallCount()).To(Equal(0))
+		})
+		It("refuses another method with a 405", func() {
+			rec := serve(http.MethodDelete, "/v2/sessions", "")
+			Expect(rec.Code).To(Equal(http.StatusMethodNotAllowed))
+			Expect(rec.Header().Get("Allow")).To(Equal("GET, POST"))
+			Expect(decode(rec)).To(Equal(map[string]interface{}{
+				"error":       ErrMethodNotAllowed.Error(),
+				"status_code": float64(http.StatusMethodNotAllowed),
+			}))
+		})
+	})
+
+	Context("/v2/sessions/{id}", func() {
+		It("gets the session on GET", func() {
+			fakeService.GetReturns(&SessionDetails{SessionId: sessionId, Exists: true}, nil)
+			rec := serve(http.MethodGet, "/v2/sessions/"+sessionId, "")
+			Expect(rec.Code).To(Equal(http.StatusOK))
+			_, session := fakeService.GetArgsForCall(0)
+			Expect(session.SessionId).To(Equal(sessionId))
+		})
+		It("extends the session on PATCH", func() {
+			rec := serve(http.MethodPatch, "/v2/sessions/"+sessionId, `{"ttl": 120}`)
+			Expect(rec.Code).To(Equal(http.StatusOK))
+			_, request := fakeService.ExtendArgsForCall(0)
+			Expect(request).To(Equal(&ExtendRequest{SessionId: sessionId, TTL: 120}))
+		})
+		It("extends the session by the default TTL on PATCH without a body", func() {
+			rec := serve(http.MethodPatch, "/v2/sessions/"+sessionId, "")
+			Expect(rec.Code).To(Equal(http.StatusOK))
+			_, request := fakeService.ExtendArgsForCall(0)
+			Expect(request).To(Equal(&ExtendRequest{SessionId: sessionId}))
+		})
+		It("destroys the session on DELETE", func() {
+			rec := serve(http.MethodDelete, "/v2/sessions/"+sessionId, "")
+			Expect(rec.Code).To(Equal(http.StatusOK))
+			_, request := fakeService.DestroyArgsForCall(0)
+			Expect(request.SessionId).To(Equal(sessionId))
+		})
+		It("maps the errors of the service", func() {
+			fakeService.DestroyReturns(ErrNotFound)
+			rec := serve(http.MethodDelete, "/v2/sessions/"+sessionId, "")
+			Expect(rec.Code).To(Equal(http.StatusNotFound))
+		})
+		It("refuses another method with a 405", func() {
+			rec := serve(http.MethodPost, "/v2/sessions/"+sessionId, "")
+			Expect(rec.Code).To(Equal(http.StatusMethodNotAllowed))
+			Expect(rec.Header().Get("Allow")).To(Equal("DELETE, GET, PATCH"))
+			Expect(fakeService.Invocations()).To(BeEmpty())
+		})
+		It("refuses an unknown sub-resource with a 404", func() {
+			rec := serve(http.MethodGet, "/v2/sessions/"+sessionId+"/unknown", "")
+			Expect(rec.Code).To(Equal(http.StatusNotFound))
+			rec = serve(http.MethodGet, "/v2/sessions/", "")
+			Expect(rec.Code).To(Equal(http.StatusNotFound))
+		})
+	})
+
+	Context("/v2/sessions/{id}/rotate", func() {
+		It("rotates the session on POST", func() {
+			fakeService.RotateReturns("fresh-id", nil)
+			rec := serve(http.MethodPost, "/v2/sessions/"+sessionId+"/rotate", "")
+			Expect(rec.Code).To(Equal(http.StatusOK))
+			_, request := fakeService.RotateArgsForCall(0)
+			Expect(request.SessionId).To(Equal(sessionId))
+		})
+		It("refuses another method with a 405", func() {
+			rec := serve(http.MethodGet, "/v2/sessions/"+sessionId+"/rotate", "")
+			Expect(rec.Code).To(Equal(http.StatusMethodNotAllowed))
+			Expect(rec.Header().Get("Allow")).To(Equal("POST"))
+		})
+	})
+
+	Context("/v2/sessions/{id}/data", func() {
+		It("gets the data on GET", func() {
+			fakeService.GetDataReturns(&SessionData{SessionId: sessionId, Data: map[string]interface{}{"user_id": "42"}}, nil)
+			rec := serve(http.MethodGet, "/v2/sessions/"+sessionId+"/data", "")
+			Expect(rec.Code).To(Equal(http.StatusOK))
+			_, session := fakeService.GetDataArgsForCall(0)
+			Expect(session.SessionId).To(Equal(sessionId))
+		})
+		It("sets the data on PUT", func() {
+			rec := serve(http.MethodPut, "/v2/sessions/"+sessionId+"/data", `{"data": {"user_id": "42"}}`)
+			Expect(rec.Code).To(Equal(http.StatusOK))
+			_, request := fakeService.SetDataArgsForCall(0)
+			Expect(request).To(Equal(&SessionDataRequest{SessionId: sessionId, Data: map[string]interface{}{"user_id": "42"}}))
+		})
+		It("patches the data on PATCH", func() {
+			rec := serve(http.MethodPatch, "/v2/sessions/"+sessionId+"/data", `{"data": {"theme": "dark"}}`)
+			Expect(rec.Code).To(Equal(http.StatusOK))
+			_, request := fakeService.PatchDataArgsForCall(0)
+			Expect(request).To(Equal(&SessionDataRequest{SessionId: sessionId, Data: map[string]interface{}{"theme": "dark"}}))
+		})
+		It("refuses another method with a 405", func() {
+			rec := serve(http.MethodPost, "/v2/sessions/"+sessionId+"/data", `{"data": {}}`)
+			Expect(rec.Code).To(Equal(http.StatusMethodNotAllowed))
+			Expect(rec.Header().Get("Allow")).To(Equal("GET, PATCH, PUT"))
+		})
+	})
+
+	Context("/v2/subjects/{subject}/sessions", func() {
+		It("lists the sessions of the subject on GET", func() {
+			fakeService.ListSubjectReturns(&Sessions{List: []string{sessionId}}, nil)
+			rec := serve(http.MethodGet, "/v2/subjects/user%2F42/sessions", "")
+			Expect(rec.Code).To(Equal(http.StatusOK))
+			_, request := fakeService.ListSubjectArgsForCall(0)
+			Expect(request.Subject).To(Equal("user/42"))
+		})
+		It("destroys the sessions of the subject on DELETE", func() {
+			fakeService.DestroySubjectReturns(&Sessions{List: []string{sessionId}}, nil)
+			rec := serve(http.MethodDelete, "/v2/subjects/user-42/sessions", "")
+			Expect(rec.Code).To(Equal(http.StatusOK))
+			_, request := fakeService.DestroySubjectArgsForCall(0)
+			Expect(request.Subject).To(Equal("user-42"))
+		})
+		It("refuses an unknown route with a 404", func() {
+			rec := serve(http.MethodGet, "/v2/subjects/user-42", "")
+			Expect(rec.Code).To(Equal(http.StatusNotFound))
+		})
+	})
+
+	Context("/v2/batch/sessions", func() {
+		It("creates the sessions on POST with the result of each of them", func() {
+			fakeService.CreateBatchReturns(&BatchResults{Results: []BatchResult{
+				{SessionId: sessionId},
+				{Err: ErrInvalidArgument},
+			}}, nil)
+			rec := serve(http.MethodPost, "/v2/batch/sessions", `{"sessions": [{"ttl": 60}, {"ttl": -1}]}`)
+			Expect(rec.Code).To(Equal(http.StatusOK))
+			Expect(decode(rec)["data"]).To(Equal(map[string]interface{}{"results": []interface{}{
+				map[string]interface{}{"session_id": sessionId, "status_code": float64(http.StatusCreated)},
+				map[string]interface{}{"error": ErrInvalidArgument.Error(), "status_code": float64(http.StatusBadRequest)},
+			}}))
+			_, request := fakeService.CreateBatchArgsForCall(0)
+			Expect(request).To(Equal(&BatchCreateRequest{Sessions: []SessionRequest{{TTL: 60}, {TTL: -1}}}))
+		})
+		It("extends the sessions on PATCH", func() {
+			fakeService.ExtendBatchReturns(&BatchResults{Results: []BatchResult{{SessionId: sessionId, Err: ErrNotFound}}}, nil)
+			rec := serve(http.MethodPatch, "/v2/batch/sessions", `{"sessions": [{"session_id": "`+sessionId+`", "ttl": 60}]}`)
+			Expect(rec.Code).To(Equal(http.StatusOK))
+			Expect(decode(rec)["data"]).To(Equal(map[string]interface{}{"results": []interface{}{
+				map[string]interface{}{"session_id": sessionId, "error": ErrNotFound.Error(), "status_code": float64(http.StatusNotFound)},
+			}}))
+			_, request := fakeService.ExtendBatchArgsForCall(0)
+			Expect(request).To(Equal(&BatchExtendRequest{Sessions: []ExtendRequest{{SessionId: sessionId, TTL: 60}}}))
+		})
+		It("destroys the sessions on DELETE", func() {
+			fakeService.DestroyBatchReturns(&BatchResults{Results: []BatchResult{{SessionId: sessionId}}}, nil)
+			rec := serve(http.MethodDelete, "/v2/batch/sessions", `{"sessions": [{"session_id": "`+sessionId+`"}]}`)
+			Expect(rec.Code).To(Equal(http.StatusOK))
+			_, request := fakeService.DestroyBatchArgsForCall(0)
+			Expect(request).To(Equal(&BatchDestroyRequest{Sessions: []DestroyRequest{{SessionId: sessionId}}}))
+		})
+		It("refuses an invalid batch with a 400", func() {
+			fakeService.DestroyBatchReturns(nil, ErrInvalidArgument)
+			Expect(serve(http.MethodDelete, "/v2/batch/sessions", `{"sessions": []}`).Code).To(Equal(http.StatusBadRequest))
+			Expect(serve(http.MethodDelete, "/v2/batch/sessions", `{"sessions": `).Code).To(Equal(http.StatusBadRequest))
+		})
+		It("refuses another method with a 405", func() {
+			rec := serve(http.MethodGet, "/v2/batch/sessions", "")
+			Expect(rec.Code).To(Equal(http.StatusMethodNotAllowed))
+			Expect(rec.Header().Get("Allow")).To(Equal("DELETE, PATCH, POST"))
+		})
+	})
+
+	Context("with API keys", func() {
+		const readerKey = "reader-0123456789abcdef0123456789"
+
+		BeforeEach(func() {
+			keys, err := auth.NewKeyStore(auth.APIKey{Name: "reader", Hash: auth.HashKey(readerKey), Scopes: []auth.Scope{auth.ScopeRead}})
+			Expect(err).To(BeNil())
+			handler = MakeV2Handler(fakeService, trace.NewNoopTracerProvider().Tracer(""), keys)
+		})
+
+		serveWithKey := func(method, path string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(method, path, nil)
+			req.Header.Set(APIKeyHeader, readerKey)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			return rec
+		}
+
+		It("restricts the batch routes to the admin scope", func() {
+			for _, scope := range []auth.Scope{auth.ScopeCreate, auth.ScopeRead, auth.ScopeWrite} {
+				key := string(scope) + "-0123456789abcdef0123456789"
+				keys, err := auth.NewKeyStore(auth.APIKey{Name: string(scope), Hash: auth.HashKey(key), Scopes: []auth.Scope{scope}})
+				Expect(err).To(BeNil())
+				handler = MakeV2Handler(fakeService, trace.NewNoopTracerProvider().Tracer(""), keys)
+				for _, method := range []string{http.MethodPost, http.MethodPatch, http.MethodDelete} {
+					req := httptest.NewRequest(method, "/v2/batch/sessions", strings.NewReader(`{"sessions": []}`))
+					req.Header.Set(APIKeyHeader, key)
+					rec := httptest.NewRecorder()
+					handler.ServeHTTP(rec, req)
+					Expect(rec.Code).To(Equal(http.StatusForbidden), method+" "+string(scope))
+				}
+			}
+			Expect(fakeService.Invocations()).To(BeEmpty())
+		})
+		It("requires the scope of the method", func() {
+			fakeService.GetReturns(&SessionDetails{SessionId: sessionId, Exists: true}, nil)
+			Expect(serveWithKey(http.MethodGet, "/v2/sessions/"+sessionId).Code).To(Equal(http.StatusOK))
+			Expect(serveWithKey(http.MethodDelete, "/v2/sessions/"+sessionId).Code).To(Equal(http.StatusForbidden))
+			Expect(serve(http.MethodGet, "/v2/sessions/"+sessionId, "").Code).To(Equal(http.StatusUnauthorized))
+		})
+	})
+})
@@ -0,0 +1,121 @@
+package sql_store
+
+import (
+	"database/sql"
+	"time"
+)
+
+// migration is a schema change applied once, in order of version
+type migration struct {
+	version    int
+	statements []string
+}
+
+// migrations are the schema changes of the sessions and revocations tables. New changes are appended
+// with the next version, applied migrations are never edited.
+var migrations = []migration{
+	{
+		version: 1,
+		statements: []string{
+			`CREATE TABLE sessions (
+				session_id     VARCHAR(64)  NOT NULL PRIMARY KEY,
+				subject        VARCHAR(255) NOT NULL DEFAULT '',
+				object         TEXT         NOT NULL,
+				expiration     BIGINT       NOT NULL,
+				last_access    BIGINT       NOT NULL,
+				idle_timeout   BIGINT       NOT NULL DEFAULT 0,
+				max_expiration BIGINT       NOT NULL DEFAULT 0
+			)`,
+			`CREATE INDEX sessions_expiration_idx ON sessions (expiration)`,
+		},
+	},
+	{
+		version: 2,
+		statements: []string{
+			`CREATE INDEX sessions_subject_idx ON sessions (subject)`,
+		},
+	},
+	{
+		version: 3,
+		statements: []string{
+			`CREATE TABLE revocations (
+				session_id     VARCHAR(64)  NOT NULL PRIMARY KEY,
+				subject        VARCHAR(255) NOT NULL DEFAULT '',
+				object         TEXT         NOT NULL,
+				expiration     BIGINT       NOT NULL,
+				last_access    BIGINT       NOT NULL,
+				idle_timeout   BIGINT       NOT NULL DEFAULT 0,
+				max_expiration BIGINT       NOT NULL DEFAULT 0
+			)`,
+			`CREATE INDEX revocations_expiration_idx ON revocations (expiration)`,
+		},
+	},
+	{
+		version: 4,
+		statements: []string{
+			`ALTER TABLE sessions ADD COLUMN created_at BIGINT NOT NULL DEFAULT 0`,
+			`CREATE INDEX sessions_created_at_idx ON sessions (created_at)`,
+			`ALTER TABLE revocations ADD COLUMN created_at BIGINT NOT NULL DEFAULT 0`,
+		},
+	},
+	{
+		version: 5,
+		statements: []string{
+			`ALTER TABLE sessions ADD COLUMN deadline BIGINT NOT NULL DEFAULT 0`,
+			`UPDATE sessions SET deadline = CASE
+				WHEN idle_timeout > 0 AND last_access + idle_timeout < expiration THEN last_access + idle_timeout
+				ELSE expiration END`,
+			`CREATE INDEX sessions_deadline_idx ON sessions (deadline)`,
+			`ALTER TABLE revocations ADD COLUMN deadline BIGINT NOT NULL DEFAULT 0`,
+			`UPDATE revocations SET deadline = expiration`,
+			`CREATE INDEX revocations_deadline_idx ON revocations (deadline)`,
+		},
+	},
+}
+
+// Migrate creates or upgrades the schema of the sessions table, recording the applied
+// versions in the schema_migrations table. Every migration runs in its own transaction.
+func Migrate(db *sql.DB, driverName string) error {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    INTEGER NOT NULL PRIMARY KEY,
+		applied_at BIGINT  NOT NULL
+	)`)
+	if err != nil {
+		return err
+	}
+
+	var current sql.NullInt64
+	if err := db.QueryRow(`SELECT MAX(version) FROM schema_migrations`).Scan(&current); err != nil {
+		return err
+	}
+
+	for _, m := range migrations {
+		if int64(m.version) <= current.Int64 {
+			continue
+		}
+		if err := apply(db, driverName, m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// apply runs the statements of the migration and records its version
+func apply(db *sql.DB, driverName string, m migration) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, statement := range m.statements {
+		if _, err := tx.Exec(statement); err != nil {
+			return err
+		}
+	}
+	query := rebind(driverName, `INSERT INTO schema_migrations (version, applied_at) VALUES (?, ?)`)
+	if _, err := tx.Exec(query, m.version, time.Now().UnixNano()); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,673 @@
+package sql_store
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/metrics/discard"
+
+	. "github.com/hecomp/session-management/internal/models"
+	"github.com/hecomp/session-management/pkg/in_memory"
+)
+
+// SweepBatch is the number of expired sessions deleted by each statement of the sweeper
+const SweepBatch = 500
+
+const (
+	// SessionsTable holds the sessions of the session store
+	SessionsTable = "sessions"
+	// RevocationsTable holds the revocation list of the destroyed sessions whose tokens have not expired
+	RevocationsTable = "revocations"
+)
+
+// maxBoundIds caps the number of session ids bound to a single statement
+const maxBoundIds = 500
+
+const selectColumns = `SELECT session_id, subject, object, expiration, last_access, idle_timeout, max_expiration, created_at FROM `
+
+// SQLStore represents a session store kept in a table of a relational database.
+// The deadline column holds the time each session expires at, the earlier of its expiration
+// and the end of its idle timeout. It is indexed, so the background sweeper deletes the
+// expired sessions in batches without scanning the table.
+type SQLStore struct {
+	logger       log.Logger
+	metrics      in_memory.Metrics
+	db           *sql.DB
+	driverName   string
+	table        string
+	stopCleanup  chan bool
+	stopOnce     sync.Once
+	probeCleanup chan struct{}
+}
+
+// NewSQLStore returns a new SQLStore instance over db, opened with the given driver name.
+// The schema is migrated before the store is returned, and expired sessions are deleted
+// every sessionInterval; a zero interval disables the sweeper.
+func NewSQLStore(db *sql.DB, driverName string, sessionInterval time.Duration, logger log.Logger) (in_memory.MemStore, error) {
+	return NewInstrumentedSQLStore(db, driverName, sessionInterval, in_memory.Metrics{}, logger)
+}
+
+// NewInstrumentedSQLStore returns a new SQLStore instance like NewSQLStore, reporting the
+// number of sessions and the expired-session sweeps to the given metrics.
+func NewInstrumentedSQLStore(db *sql.DB, driverName string, sessionInterval time.Duration, metrics in_memory.Metrics, logger log.Logger) (in_memory.MemStore, error) {
+	return newSQLStore(db, driverName, SessionsTable, sessionInterval, metrics, logger)
+}
+
+// NewRevocationSQLStore returns a new SQLStore instance like NewSQLStore, kept in the
+// revocations table so the revocation list does not mix with the sessions.
+func NewRevocationSQLStore(db *sql.DB, driverName string, sessionInterval time.Duration, logger log.Logger) (in_memory.MemStore, error) {
+	return newSQLStore(db, driverName, RevocationsTable, sessionInterval, in_memory.Metrics{}, logger)
+}
+
+func newSQLStore(db *sql.DB, driverName string, table string, sessionInterval time.Duration, metrics in_memory.Metrics, logger log.Logger) (in_memory.MemStore, error) {
+	if metrics.ActiveSessions == nil {
+		metrics.ActiveSessions = discard.NewGauge()
+	}
+	if metrics.ExpiredSessions == nil {
+		metrics.ExpiredSessions = discard.NewCounter()
+	}
+	if metrics.CleanupSweeps == nil {
+		metrics.CleanupSweeps = discard.NewCounter()
+	}
+
+	if err := Migrate(db, driverName); err != nil {
+		return nil, err
+	}
+
+	s := &SQLStore{
+		logger:     logger,
+		metrics:    metrics,
+		db:         db,
+		driverName: driverName,
+		table:      table,
+	}
+
+	if sessionInterval > 0 {
+		s.stopCleanup = make(chan bool)
+		s.probeCleanup = make(chan struct{})
+		go s.startSessionCleanup(sessionInterval)
+	}
+
+	return s, nil
+}
+
+// Find returns the data for a given session from the database. If the session id is not
+// found or is expired, the returned exists flag will be set to false.
+func (s *SQLStore) Find(ctx context.Context, sessionId string) ([]byte, bool, error) {
+	item, found, err := s.Lookup(ctx, sessionId)
+	if err != nil || !found {
+		return nil, false, err
+	}
+	return item.Oject, true, nil
+}
+
+// Lookup returns the item for a given session from the database, refreshing its last access
+// time and sliding its expiration when it has an idle timeout.
+func (s *SQLStore) Lookup(ctx context.Context, sessionId string) (Item, bool, error) {
+	s.logger.Log("method", "lookup", "sessionId", sessionId)
+	var item Item
+	var found bool
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		var err error
+		item, found, err = s.get(ctx, tx, sessionId)
+		if err != nil || !found {
+			return err
+		}
+
+		item.LastAccess = time.Now().UnixNano()
+		if item.IdleTimeout > 0 && item.LastAccess+item.IdleTimeout > item.Expiration {
+			item.Expiration = capExpiration(item, item.LastAccess+item.IdleTimeout)
+		}
+		_, err = tx.ExecContext(ctx, s.rebind(`UPDATE `+s.table+` SET last_access = ?, expiration = ?, deadline = ? WHERE session_id = ?`),
+			item.LastAccess, item.Expiration, item.Deadline(), sessionId)
+		return err
+	})
+	if err != nil || !found {
+		return Item{}, false, err
+	}
+	return item, true, nil
+}
+
+// Commit adds a session sessionId and data to the database with the given expiration time.
+// If the session sessionId already exists, then the data and expiration time are updated.
+func (s *SQLStore) Commit(ctx context.Context, sessionId string, b []byte, expiration time.Time) error {
+	return s.CommitWithSubject(ctx, sessionId, "", b, expiration)
+}
+
+// CommitWithSubject adds a session like Commit along with its subject.
+func (s *SQLStore) CommitWithSubject(ctx context.Context, sessionId string, subject string, b []byte, expiration time.Time) error {
+	return s.CommitItem(ctx, sessionId, Item{Oject: b, Expiration: expiration.UnixNano(), Subject: subject})
+}
+
+// CommitItem adds a session like CommitWithSubject, keeping the idle timeout, maximum
+// expiration and creation time of the item. The expiration is capped at the maximum
+// expiration.
+func (s *SQLStore) CommitItem(ctx context.Context, sessionId string, item Item) error {
+	s.logger.Log("method", "commit", "sessionId", sessionId)
+	item.Expiration = capExpiration(item, item.Expiration)
+	item.LastAccess = time.Now().UnixNano()
+	if item.CreatedAt == 0 {
+		item.CreatedAt = item.LastAccess
+	}
+
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, s.rebind(`DELETE FROM `+s.table+` WHERE session_id = ?`), sessionId); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(ctx, s.rebind(`INSERT INTO `+s.table+`
+			(session_id, subject, object, expiration, last_access, idle_timeout, max_expiration, created_at, deadline)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`),
+			sessionId, item.Subject, string(item.Oject), item.Expiration, item.LastAccess, item.IdleTimeout, item.MaxExpiration, item.CreatedAt, item.Deadline())
+		return err
+	})
+}
+
+// CommitBatch adds every session like CommitItem in a single transaction
+func (s *SQLStore) CommitBatch(ctx context.Context, items map[string]Item) error {
+	s.logger.Log("method", "commitBatch", "sessions", len(items))
+	now := time.Now().UnixNano()
+
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		del, err := tx.PrepareContext(ctx, s.rebind(`DELETE FROM `+s.table+` WHERE session_id = ?`))
+		if err != nil {
+			return err
+		}
+		defer del.Close()
+		insert, err := tx.PrepareContext(ctx, s.rebind(`INSERT INTO `+s.table+`
+			(session_id, subject, object, expiration, last_access, idle_timeout, max_expiration, created_at, deadline)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`))
+		if err != nil {
+			return err
+		}
+		defer insert.Close()
+
+		for sessionId, item := range items {
+			item.Expiration = capExpiration(item, item.Expiration)
+			item.LastAccess = now
+			if item.CreatedAt == 0 {
+				item.CreatedAt = now
+			}
+			if _, err := del.ExecContext(ctx, sessionId); err != nil {
+				return err
+			}
+			_, err := insert.ExecContext(ctx,
+				sessionId, item.Subject, string(item.Oject), item.Expiration, item.LastAccess, item.IdleTimeout, item.MaxExpiration, item.CreatedAt, item.Deadline())
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Delete removes a session sessionId and corresponding data from the database.
+func (s *SQLStore) Delete(ctx context.Context, sessionId string) error {
+	s.logger.Log("method", "delete", "sessionId", sessionId)
+	_, err := s.db.ExecContext(ctx, s.rebind(`DELETE FROM `+s.table+` WHERE session_id = ?`), sessionId)
+	return err
+}
+
+// DeleteBatch removes the sessions from the database in a single transaction and returns
+// the live sessions that were removed
+func (s *SQLStore) DeleteBatch(ctx context.Context, sessionIds []string) (map[string]Item, error) {
+	s.logger.Log("method", "deleteBatch", "sessions", len(sessionIds))
+	var deleted map[string]Item
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		var err error
+		deleted, err = s.getBatch(ctx, tx, sessionIds)
+		if err != nil {
+			return err
+		}
+		return inChunks(sessionIds, func(chunk []interface{}) error {
+			_, err := tx.ExecContext(ctx, s.rebind(`DELETE FROM `+s.table+` WHERE session_id IN (`+placeholders(len(chunk))+`)`), chunk...)
+			return err
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return deleted, nil
+}
+
+// DeleteBySubject removes every session of the subject from the database and returns the
+// ids of the live sessions that were removed.
+func (s *SQLStore) DeleteBySubject(ctx context.Context, subject string) ([]string, error) {
+	s.logger.Log("method", "deleteBySubject", "subject", subject)
+	var sessionIds []string
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		items, err := s.query(ctx, tx, ` WHERE subject = ? AND deadline >= ?`, subject, time.Now().UnixNano())
+		if err != nil {
+			return err
+		}
+		sessionIds = sessionIds[:0]
+		for sessionId := range items {
+			sessionIds = append(sessionIds, sessionId)
+		}
+
+		_, err = tx.ExecContext(ctx, s.rebind(`DELETE FROM `+s.table+` WHERE subject = ?`), subject)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sessionIds, nil
+}
+
+// ListBySubject returns the live sessions of the subject from the database, without
+// refreshing their last access time.
+func (s *SQLStore) ListBySubject(ctx context.Context, subject string) (map[string]Item, error) {
+	s.logger.Log("method", "listBySubject", "subject", subject)
+	return s.query(ctx, s.db, ` WHERE subject = ? AND deadline >= ?`, subject, time.Now().UnixNano())
+}
+
+// Reset extend a session ttl in the database. The new expiration is capped at the maximum
+// expiration of the session.
+func (s *SQLStore) Reset(ctx context.Context, sessionId string, expiration time.Time) ([]byte, bool, error) {
+	s.logger.Log("method", "reset")
+	var item Item
+	var found bool
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		var err error
+		item, found, err = s.get(ctx, tx, sessionId)
+		if err != nil || !found {
+			return err
+		}
+
+		item.Expiration = capExpiration(item, expiration.UnixNano())
+		item.LastAccess = time.Now().UnixNano()
+		_, err = tx.ExecContext(ctx, s.rebind(`UPDATE `+s.table+` SET expiration = ?, last_access = ?, deadline = ? WHERE session_id = ?`),
+			item.Expiration, item.LastAccess, item.Deadline(), sessionId)
+		return err
+	})
+	if err != nil || !found {
+		return nil, false, err
+	}
+	return item.Oject, true, nil
+}
+
+// ResetBatch extends the live sessions to their expiration like Reset in a single
+// transaction and returns the sessions that were extended
+func (s *SQLStore) ResetBatch(ctx context.Context, expirations map[string]time.Time) (map[string]Item, error) {
+	s.logger.Log("method", "resetBatch", "sessions", len(expirations))
+	sessionIds := make([]string, 0, len(expirations))
+	for sessionId := range expirations {
+		sessionIds = append(sessionIds, sessionId)
+	}
+
+	var extended map[string]Item
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		var err error
+		extended, err = s.getBatch(ctx, tx, sessionIds)
+		if err != nil || len(extended) == 0 {
+			return err
+		}
+
+		update, err := tx.PrepareContext(ctx, s.rebind(`UPDATE `+s.table+` SET expiration = ?, last_access = ?, deadline = ? WHERE session_id = ?`))
+		if err != nil {
+			return err
+		}
+		defer update.Close()
+
+		now := time.Now().UnixNano()
+		for sessionId, item := range extended {
+			item.Expiration = capExpiration(item, expirations[sessionId].UnixNano())
+			item.LastAccess = now
+			extended[sessionId] = item
+			if _, err := update.ExecContext(ctx, item.Expiration, item.LastAccess, item.Deadline(), sessionId); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return extended, nil
+}
+
+// Update replaces the data of a live session in the database, keeping its expiration time.
+// The access restarts the idle timeout of the session.
+func (s *SQLStore) Update(ctx context.Context, sessionId string, b []byte) (bool, error) {
+	s.logger.Log("method", "update", "sessionId", sessionId)
+	now := time.Now().UnixNano()
+	result, err := s.db.ExecContext(ctx, s.rebind(`UPDATE `+s.table+` SET object = ?, last_access = ?, deadline = CASE
+			WHEN idle_timeout > 0 AND ? + idle_timeout < expiration THEN ? + idle_timeout
+			ELSE expiration END
+		WHERE session_id = ? AND deadline >= ?`),
+		string(b), now, now, now, sessionId, now)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+// List return a list of all the live sessions from the database
+func (s *SQLStore) List(ctx context.Context) (map[string]Item, error) {
+	s.logger.Log("method", "list")
+	return s.query(ctx, s.db, ` WHERE deadline >= ?`, time.Now().UnixNano())
+}
+
+// Query returns the page of the live sessions selected by the query from the database. The
+// filters, the ordering and the limit are pushed down to the database, the cursor becoming
+// a keyset condition on the indexed sort column, so only the rows of the page are read.
+func (s *SQLStore) Query(ctx context.Context, query in_memory.Query) (in_memory.Page, error) {
+	s.logger.Log("method", "query", "subject", query.Subject, "limit", query.Limit)
+	after, hasCursor, err := query.After()
+	if err != nil {
+		return in_memory.Page{}, err
+	}
+
+	column := "created_at"
+	if query.SortBy == in_memory.SortByExpiration {
+		column = "expiration"
+	}
+	direction, compare := "ASC", ">"
+	if query.Descending {
+		direction, compare = "DESC", "<"
+	}
+
+	where := []string{"deadline >= ?"}
+	args := []interface{}{time.Now().UnixNano()}
+	condition := func(clause string, value interface{}) {
+		where = append(where, clause)
+		args = append(args, value)
+	}
+	if query.Subject != "" {
+		condition("subject = ?", query.Subject)
+	}
+	if query.CreatedAfter != 0 {
+		condition("created_at >= ?", query.CreatedAfter)
+	}
+	if query.CreatedBefore != 0 {
+		condition("created_at < ?", query.CreatedBefore)
+	}
+	if query.ExpiresAfter != 0 {
+		condition("expiration >= ?", query.ExpiresAfter)
+	}
+	if query.ExpiresBefore != 0 {
+		condition("expiration < ?", query.ExpiresBefore)
+	}
+	if hasCursor {
+		where = append(where, "("+column+" "+compare+" ? OR ("+column+" = ? AND session_id "+compare+" ?))")
+		args = append(args, after.Key, after.Key, after.SessionId)
+	}
+
+	statement := selectColumns + s.table + ` WHERE ` + strings.Join(where, " AND ") +
+		` ORDER BY ` + column + ` ` + direction + `, session_id ` + direction
+	if query.Limit > 0 {
+		// One more row than the limit tells whether there is a next page
+		statement += ` LIMIT ?`
+		args = append(args, query.Limit+1)
+	}
+
+	rows, err := s.db.QueryContext(ctx, s.rebind(statement), args...)
+	if err != nil {
+		return in_memory.Page{}, err
+	}
+	defer rows.Close()
+
+	var page in_memory.Page
+	for rows.Next() {
+		sessionId, item, err := scan(rows)
+		if err != nil {
+			return in_memory.Page{}, err
+		}
+		page.Items = append(page.Items, in_memory.QueryItem{SessionId: sessionId, Item: item})
+	}
+	if err := rows.Err(); err != nil {
+		return in_memory.Page{}, err
+	}
+
+	if query.Limit > 0 && len(page.Items) > query.Limit {
+		page.Items = page.Items[:query.Limit]
+		last := page.Items[len(page.Items)-1]
+		page.NextCursor = query.CursorAt(in_memory.Position{Key: query.Key(last.Item), SessionId: last.SessionId})
+	}
+	return page, nil
+}
+
+// Get returns every live session from the database
+func (s *SQLStore) Get(ctx context.Context) map[string]Item {
+	items, err := s.List(ctx)
+	if err != nil {
+		s.logger.Log("method", "get", "err", err)
+	}
+	return items
+}
+
+// StopSessionCleanup terminates the background sweeper goroutine for the SQLStore instance.
+func (s *SQLStore) StopSessionCleanup() {
+	s.logger.Log("stopCleanup")
+	if s.stopCleanup != nil {
+		s.stopOnce.Do(func() { s.stopCleanup <- true })
+	}
+}
+
+// Close stops the background sweeper goroutine of the SQLStore instance. Every write is
+// already committed to the database, which is left open as it may be shared with other
+// stores.
+func (s *SQLStore) Close(ctx context.Context) error {
+	s.StopSessionCleanup()
+	return nil
+}
+
+// Ping reports whether the database of the SQLStore instance is reachable
+func (s *SQLStore) Ping(ctx context.Context) error {
+	return s.db.PingContext(ctx)
+}
+
+// CheckCleanup reports whether the background sweeper goroutine of the SQLStore instance
+// answers before the context is done.
+func (s *SQLStore) CheckCleanup(ctx context.Context) error {
+	return in_memory.ProbeCleanup(ctx, s.probeCleanup)
+}
+
+// startSessionCleanup deletes the expired sessions on every tick
+func (s *SQLStore) startSessionCleanup(interval time.Duration) {
+	s.logger.Log("method", "startSessionCleanup")
+	ticker := time.NewTicker(interval)
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.deleteSessionExpired(); err != nil {
+				s.logger.Log("method", "deleteSessionExpired", "err", err)
+			}
+		case <-s.probeCleanup:
+		case <-s.stopCleanup:
+			ticker.Stop()
+			return
+		}
+	}
+}
+
+// deleteSessionExpired deletes the expired sessions SweepBatch at a time, so that no
+// statement holds locks on a large part of the table, and then reports the number of
+// sessions left.
+func (s *SQLStore) deleteSessionExpired() error {
+	s.logger.Log("deleteSessionExpired")
+	now := time.Now().UnixNano()
+	for {
+		rows, err := s.db.Query(s.rebind(`SELECT session_id FROM `+s.table+` WHERE deadline < ? ORDER BY deadline LIMIT ?`), now, SweepBatch)
+		if err != nil {
+			return err
+		}
+		var sessionIds []interface{}
+		for rows.Next() {
+			var sessionId string
+			if err := rows.Scan(&sessionId); err != nil {
+				rows.Close()
+				return err
+			}
+			sessionIds = append(sessionIds, sessionId)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		if len(sessionIds) > 0 {
+			placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(sessionIds)), ", ")
+			query := s.rebind(`DELETE FROM ` + s.table + ` WHERE session_id IN (` + placeholders + `) AND deadline < ?`)
+			if _, err := s.db.Exec(query, append(sessionIds, now)...); err != nil {
+				return err
+			}
+			s.metrics.ExpiredSessions.Add(float64(len(sessionIds)))
+		}
+		if len(sessionIds) < SweepBatch {
+			break
+		}
+	}
+	s.metrics.CleanupSweeps.Add(1)
+
+	var count int64
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM ` + s.table).Scan(&count); err != nil {
+		return err
+	}
+	s.metrics.ActiveSessions.Set(float64(count))
+	return nil
+}
+
+// inTx runs fn in a transaction, committed when fn succeeds
+func (s *SQLStore) inTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// querier is implemented by both *sql.DB and *sql.Tx
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// get returns the live item of the session
+func (s *SQLStore) get(ctx context.Context, q querier, sessionId string) (Item, bool, error) {
+	items, err := s.query(ctx, q, ` WHERE session_id = ? AND deadline >= ?`, sessionId, time.Now().UnixNano())
+	if err != nil {
+		return Item{}, false, err
+	}
+	item, found := items[sessionId]
+	return item, found, nil
+}
+
+// getBatch returns the live items of the sessions
+func (s *SQLStore) getBatch(ctx context.Context, q querier, sessionIds []string) (map[string]Item, error) {
+	items := make(map[string]Item)
+	now := time.Now().UnixNano()
+	err := inChunks(sessionIds, func(chunk []interface{}) error {
+		chunkItems, err := s.query(ctx, q, ` WHERE session_id IN (`+placeholders(len(chunk))+`) AND deadline >= ?`, append(chunk, now)...)
+		if err != nil {
+			return err
+		}
+		for sessionId, item := range chunkItems {
+			items[sessionId] = item
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// query returns the sessions selected by the where clause
+func (s *SQLStore) query(ctx context.Context, q querier, where string, args ...interface{}) (map[string]Item, error) {
+	rows, err := q.QueryContext(ctx, s.rebind(selectColumns+s.table+where), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make(map[string]Item)
+	for rows.Next() {
+		sessionId, item, err := scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		items[sessionId] = item
+	}
+	return items, rows.Err()
+}
+
+// scan reads the session of the current row of the selectColumns columns
+func scan(rows *sql.Rows) (string, Item, error) {
+	var sessionId, object string
+	var item Item
+	err := rows.Scan(&sessionId, &item.Subject, &object, &item.Expiration, &item.LastAccess, &item.IdleTimeout, &item.MaxExpiration, &item.CreatedAt)
+	if err != nil {
+		return "", Item{}, err
+	}
+	item.Oject = []byte(object)
+	return sessionId, item, nil
+}
+
+// inChunks calls fn with the session ids split in chunks of at most maxBoundIds, as the
+// number of values a statement binds is limited by some databases
+func inChunks(sessionIds []string, fn func(chunk []interface{}) error) error {
+	for start := 0; start < len(sessionIds); start += maxBoundIds {
+		end := start + maxBoundIds
+		if end > len(sessionIds) {
+			end = len(sessionIds)
+		}
+		chunk := make([]interface{}, 0, end-start+1)
+		for _, sessionId := range sessionIds[start:end] {
+			chunk = append(chunk, sessionId)
+		}
+		if err := fn(chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// placeholders returns the list of n ? placeholders of an IN clause
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+func (s *SQLStore) rebind(query string) string {
+	return rebind(s.driverName, query)
+}
+
+// rebind replaces the ? placeholders of the query by $1, $2, ... for the drivers of
+// databases that use numbered placeholders
+func rebind(driverName string, query string) string {
+	if driverName != "postgres" && driverName != "pgx" {
+		return query
+	}
+
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// capExpiration returns the expiration limited to the maximum expiration of the item
+func capExpiration(item Item, expiration int64) int64 {
+	if item.MaxExpiration > 0 && expiration > item.MaxExpiration {
+		return item.MaxExpiration
+	}
+	return expiration
+}
@@ -0,0 +1,510 @@
+package sql_store_test
+
+import (
+	"context"
+	"database/sql"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/metrics/generic"
+	_ "github.com/mattn/go-sqlite3"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/hecomp/session-management/internal/models"
+	. "github.com/hecomp/session-management/pkg/in_memory"
+	. "github.com/hecomp/session-management/pkg/sql_store"
+	"github.com/hecomp/session-management/pkg/test"
+)
+
+type SQLStoreSuite struct {
+	mem    MemStore
+	db     *sql.DB
+	dir    string
+	logger log.Logger
+}
+
+var _ = Describe("SQLStore", func() {
+
+	s := &SQLStoreSuite{}
+
+	BeforeEach(func() {
+		var err error
+		s.logger = test.GetLogger()
+		s.dir, err = ioutil.TempDir("", "sql_store")
+		Expect(err).To(BeNil())
+		s.db, err = sql.Open("sqlite3", filepath.Join(s.dir, "sessions.db"))
+		Expect(err).To(BeNil())
+		s.db.SetMaxOpenConns(1)
+		s.mem, err = NewSQLStore(s.db, "sqlite3", 0, s.logger)
+		Expect(err).To(BeNil())
+	})
+
+	AfterEach(func() {
+		s.db.Close()
+		os.RemoveAll(s.dir)
+	})
+
+	Describe("Migrate", func() {
+		It("applies every migration once", func() {
+			Expect(Migrate(s.db, "sqlite3")).To(Succeed())
+			var count int
+			Expect(s.db.QueryRow(`SELECT COUNT(*) FROM schema_migrations`).Scan(&count)).To(Succeed())
+			Expect(count).To(Equal(5))
+		})
+		It("indexes the expiration column", func() {
+			var name string
+			err := s.db.QueryRow(`SELECT name FROM sqlite_master WHERE type = 'index' AND tbl_name = 'sessions' AND sql LIKE '%expiration%'`).Scan(&name)
+			Expect(err).To(BeNil())
+			Expect(name).To(Equal("sessions_expiration_idx"))
+		})
+		It("indexes the deadline column", func() {
+			var name string
+			err := s.db.QueryRow(`SELECT name FROM sqlite_master WHERE type = 'index' AND tbl_name = 'sessions' AND sql LIKE '%deadline%'`).Scan(&name)
+			Expect(err).To(BeNil())
+			Expect(name).To(Equal("sessions_deadline_idx"))
+		})
+	})
+
+	Describe("Revocation store", func() {
+		It("keeps its entries apart from the sessions", func() {
+			revocations, err := NewRevocationSQLStore(s.db, "sqlite3", 0, s.logger)
+			Expect(err).To(BeNil())
+			uniqueUUID := "90660b89-100e-4f8f-9801-2524df6fbe34"
+			Expect(revocations.Commit(ctx, uniqueUUID, []byte(uniqueUUID), time.Now().Add(time.Minute))).To(Succeed())
+
+			_, found, err := revocations.Find(ctx, uniqueUUID)
+			Expect(err).To(BeNil())
+			Expect(found).To(BeTrue())
+			sessionMap, err := s.mem.List(ctx)
+			Expect(err).To(BeNil())
+			Expect(sessionMap).To(BeEmpty())
+		})
+	})
+
+	Describe("Create session", func() {
+		Context("Commit()", func() {
+			It("stores an unique sessionId in the database", func() {
+				uniqueUUID := "90660b89-100e-4f8f-9801-2524df6fbe34"
+				err := s.mem.Commit(ctx, uniqueUUID, []byte(uniqueUUID), time.Now().Add(time.Minute))
+				Expect(err).To(BeNil())
+				sessionMap, err := s.mem.List(ctx)
+				Expect(err).To(BeNil())
+				Expect(string(sessionMap[uniqueUUID].Oject)).To(Equal(uniqueUUID))
+			})
+			It("replaces an existing sessionId", func() {
+				uniqueUUID := "90660b89-100e-4f8f-9801-2524df6fbe34"
+				Expect(s.mem.Commit(ctx, uniqueUUID, []byte(uniqueUUID), time.Now().Add(time.Minute))).To(Succeed())
+				Expect(s.mem.Commit(ctx, uniqueUUID, []byte("data"), time.Now().Add(time.Minute))).To(Succeed())
+				sessionMap, err := s.mem.List(ctx)
+				Expect(err).To(BeNil())
+				Expect(sessionMap).To(HaveLen(1))
+				Expect(string(sessionMap[uniqueUUID].Oject)).To(Equal("data"))
+			})
+			It("fails when the context is canceled", func() {
+				uniqueUUID := "90660b89-100e-4f8f-9801-2524df6fbe34"
+				canceled, cancel := context.WithCancel(ctx)
+				cancel()
+				err := s.mem.Commit(canceled, uniqueUUID, []byte(uniqueUUID), time.Now().Add(time.Minute))
+				Expect(err).To(Equal(context.Canceled))
+				sessionMap, err := s.mem.List(ctx)
+				Expect(err).To(BeNil())
+				Expect(sessionMap).To(BeEmpty())
+			})
+		})
+	})
+
+	Describe("Find session", func() {
+		uniqueUUID := "90660b89-100e-4f8f-9801-2524df6fbe34"
+		BeforeEach(func() {
+			err := s.mem.Commit(ctx, uniqueUUID, []byte(uniqueUUID), time.Now().Add(time.Minute))
+			Expect(err).To(BeNil())
+		})
+
+		Context("Find()", func() {
+			It("finds sessionId in the database", func() {
+				obj, found, err := s.mem.Find(ctx, uniqueUUID)
+				Expect(err).To(BeNil())
+				Expect(found).To(BeTrue())
+				Expect(string(obj)).To(Equal(uniqueUUID))
+			})
+			It("does not matches sessionId in the database", func() {
+				_, found, err := s.mem.Find(ctx, "90660b89-100e-4f8f-9801-2524df6fbe99")
+				Expect(err).To(BeNil())
+				Expect(found).ToNot(BeTrue())
+			})
+			It("expired sessionId in the database", func() {
+				err := s.mem.Commit(ctx, uniqueUUID, []byte(uniqueUUID), time.Now().Add(100*time.Millisecond))
+				Expect(err).To(BeNil())
+				time.Sleep(101 * time.Millisecond)
+				_, found, err := s.mem.Find(ctx, uniqueUUID)
+				Expect(err).To(BeNil())
+				Expect(found).ToNot(BeTrue())
+			})
+			It("slides the expiration of a session with an idle timeout", func() {
+				err := s.mem.CommitItem(ctx, uniqueUUID, models.Item{
+					Oject:         []byte(uniqueUUID),
+					Expiration:    time.Now().Add(time.Second).UnixNano(),
+					IdleTimeout:   int64(time.Minute),
+					MaxExpiration: time.Now().Add(time.Hour).UnixNano(),
+				})
+				Expect(err).To(BeNil())
+				item, found, err := s.mem.Lookup(ctx, uniqueUUID)
+				Expect(err).To(BeNil())
+				Expect(found).To(BeTrue())
+				Expect(item.Expiration).To(BeNumerically("~", time.Now().Add(time.Minute).UnixNano(), int64(time.Second)))
+			})
+			It("expires a session idle for its idle timeout before its expiration", func() {
+				err := s.mem.CommitItem(ctx, uniqueUUID, models.Item{
+					Oject:       []byte(uniqueUUID),
+					Expiration:  time.Now().Add(5 * time.Second).UnixNano(),
+					IdleTimeout: int64(100 * time.Millisecond),
+				})
+				Expect(err).To(BeNil())
+				time.Sleep(60 * time.Millisecond)
+				_, found, err := s.mem.Find(ctx, uniqueUUID)
+				Expect(err).To(BeNil())
+				Expect(found).To(BeTrue())
+
+				time.Sleep(150 * time.Millisecond)
+				_, found, err = s.mem.Find(ctx, uniqueUUID)
+				Expect(err).To(BeNil())
+				Expect(found).ToNot(BeTrue())
+				sessionMap, err := s.mem.List(ctx)
+				Expect(err).To(BeNil())
+				Expect(sessionMap).To(BeEmpty())
+			})
+		})
+	})
+
+	Describe("Destroy session", func() {
+		uniqueUUID := "90660b89-100e-4f8f-9801-2524df6fbe34"
+		BeforeEach(func() {
+			err := s.mem.Commit(ctx, uniqueUUID, []byte(uniqueUUID), time.Now().Add(time.Minute))
+			Expect(err).To(BeNil())
+		})
+
+		Context("Delete()", func() {
+			It("deletes sessionId in the database", func() {
+				Expect(s.mem.Delete(ctx, uniqueUUID)).To(Succeed())
+				_, found, err := s.mem.Find(ctx, uniqueUUID)
+				Expect(err).To(BeNil())
+				Expect(found).ToNot(BeTrue())
+			})
+		})
+	})
+
+	Describe("Extend session", func() {
+		uniqueUUID := "90660b89-100e-4f8f-9801-2524df6fbe34"
+		BeforeEach(func() {
+			err := s.mem.Commit(ctx, uniqueUUID, []byte(uniqueUUID), time.Now().Add(time.Minute))
+			Expect(err).To(BeNil())
+		})
+
+		Context("Reset()", func() {
+			It("extend sessionId in the database", func() {
+				expiration := time.Now().Add(5 * time.Minute)
+				obj, found, err := s.mem.Reset(ctx, uniqueUUID, expiration)
+				Expect(err).To(BeNil())
+				Expect(found).To(BeTrue())
+				Expect(string(obj)).To(Equal(uniqueUUID))
+				Expect(s.mem.Get(ctx)[uniqueUUID].Expiration).To(Equal(expiration.UnixNano()))
+			})
+			It("extend sessionId in the database not found", func() {
+				_, found, err := s.mem.Reset(ctx, "90660b89-100e-4f8f-9801-2524df6fbe99", time.Now().Add(time.Minute))
+				Expect(err).To(BeNil())
+				Expect(found).ToNot(BeTrue())
+			})
+		})
+	})
+
+	Describe("Update session", func() {
+		uniqueUUID := "90660b89-100e-4f8f-9801-2524df6fbe34"
+		BeforeEach(func() {
+			err := s.mem.Commit(ctx, uniqueUUID, []byte(uniqueUUID), time.Now().Add(time.Minute))
+			Expect(err).To(BeNil())
+		})
+
+		Context("Update()", func() {
+			It("replaces the data of the session", func() {
+				found, err := s.mem.Update(ctx, uniqueUUID, []byte("data"))
+				Expect(err).To(BeNil())
+				Expect(found).To(BeTrue())
+				obj, _, err := s.mem.Find(ctx, uniqueUUID)
+				Expect(err).To(BeNil())
+				Expect(string(obj)).To(Equal("data"))
+			})
+			It("update sessionId in the database not found", func() {
+				found, err := s.mem.Update(ctx, "90660b89-100e-4f8f-9801-2524df6fbe99", []byte("data"))
+				Expect(err).To(BeNil())
+				Expect(found).ToNot(BeTrue())
+			})
+		})
+	})
+
+	Describe("Subject sessions", func() {
+		uniqueUUID1 := "90660b89-100e-4f8f-9801-2524df6fbe34"
+		uniqueUUID2 := "90660b89-100e-4f8f-9801-2524df6fbe99"
+		uniqueUUID3 := "90660b89-100e-4f8f-9801-2524df6fbe88"
+		BeforeEach(func() {
+			err := s.mem.CommitWithSubject(ctx, uniqueUUID1, "user-42", []byte(uniqueUUID1), time.Now().Add(time.Minute))
+			Expect(err).To(BeNil())
+			err = s.mem.CommitWithSubject(ctx, uniqueUUID2, "user-42", []byte(uniqueUUID2), time.Now().Add(time.Minute))
+			Expect(err).To(BeNil())
+			err = s.mem.CommitWithSubject(ctx, uniqueUUID3, "user-7", []byte(uniqueUUID3), time.Now().Add(time.Minute))
+			Expect(err).To(BeNil())
+		})
+
+		Context("ListBySubject()", func() {
+			It("lists the sessions of the subject", func() {
+				sessionMap, err := s.mem.ListBySubject(ctx, "user-42")
+				Expect(err).To(BeNil())
+				Expect(sessionMap).To(HaveLen(2))
+				Expect(sessionMap).To(HaveKey(uniqueUUID1))
+				Expect(sessionMap).To(HaveKey(uniqueUUID2))
+			})
+		})
+
+		Context("DeleteBySubject()", func() {
+			It("deletes every session of the subject", func() {
+				sessionIds, err := s.mem.DeleteBySubject(ctx, "user-42")
+				Expect(err).To(BeNil())
+				Expect(sessionIds).To(ConsistOf(uniqueUUID1, uniqueUUID2))
+				sessionMap, err := s.mem.List(ctx)
+				Expect(err).To(BeNil())
+				Expect(sessionMap).To(HaveLen(1))
+				Expect(sessionMap).To(HaveKey(uniqueUUID3))
+			})
+		})
+	})
+
+	Describe("Query sessions", func() {
+		base := time.Now().Add(-time.Hour).UnixNano()
+		sessionIds := []string{
+			"90660b89-100e-4f8f-9801-2524df6fbe00",
+			"90660b89-100e-4f8f-9801-2524df6fbe01",
+			"90660b89-100e-4f8f-9801-2524df6fbe02",
+			"90660b89-100e-4f8f-9801-2524df6fbe03",
+			"90660b89-100e-4f8f-9801-2524df6fbe04",
+		}
+		BeforeEach(func() {
+			for i, sessionId := range sessionIds {
+				subject := "user-42"
+				if i%2 == 1 {
+					subject = "user-7"
+				}
+				err := s.mem.CommitItem(ctx, sessionId, models.Item{
+					Oject:      []byte(sessionId),
+					Subject:    subject,
+					CreatedAt:  base + int64(i)*int64(time.Second),
+					Expiration: time.Now().Add(time.Duration(10-i) * time.Minute).UnixNano(),
+				})
+				Expect(err).To(BeNil())
+			}
+		})
+
+		ids := func(page Page) []string {
+			var list []string
+			for _, item := range page.Items {
+				list = append(list, item.SessionId)
+			}
+			return list
+		}
+
+		Context("Query()", func() {
+			It("walks the pages in a stable order", func() {
+				query := Query{Limit: 2}
+				var list []string
+				for {
+					page, err := s.mem.Query(ctx, query)
+					Expect(err).To(BeNil())
+					Expect(len(page.Items)).To(BeNumerically("<=", 2))
+					list = append(list, ids(page)...)
+					if page.NextCursor == "" {
+						break
+					}
+					query.Cursor = page.NextCursor
+				}
+				Expect(list).To(Equal(sessionIds))
+			})
+			It("orders the sessions by expiration", func() {
+				page, err := s.mem.Query(ctx, Query{SortBy: SortByExpiration})
+				Expect(err).To(BeNil())
+				Expect(ids(page)).To(Equal([]string{sessionIds[4], sessionIds[3], sessionIds[2], sessionIds[1], sessionIds[0]}))
+				page, err = s.mem.Query(ctx, Query{Limit: 2, SortBy: SortByExpiration, Descending: true})
+				Expect(err).To(BeNil())
+				page, err = s.mem.Query(ctx, Query{Limit: 2, SortBy: SortByExpiration, Descending: true, Cursor: page.NextCursor})
+				Expect(err).To(BeNil())
+				Expect(ids(page)).To(Equal(sessionIds[2:4]))
+			})
+			It("filters the sessions", func() {
+				page, err := s.mem.Query(ctx, Query{Subject: "user-42", CreatedAfter: base + int64(time.Second)})
+				Expect(err).To(BeNil())
+				Expect(ids(page)).To(Equal([]string{sessionIds[2], sessionIds[4]}))
+				page, err = s.mem.Query(ctx, Query{
+					CreatedBefore: base + 3*int64(time.Second),
+					ExpiresBefore: time.Now().Add(9*time.Minute + 30*time.Second).UnixNano(),
+				})
+				Expect(err).To(BeNil())
+				Expect(ids(page)).To(Equal(sessionIds[1:3]))
+			})
+			It("refuses an invalid cursor", func() {
+				_, err := s.mem.Query(ctx, Query{Cursor: "not a cursor"})
+				Expect(err).To(Equal(ErrInvalidCursor))
+			})
+			It("skips the expired sessions", func() {
+				err := s.mem.CommitItem(ctx, "expired", models.Item{Oject: []byte("expired"), Expiration: time.Now().Add(-time.Minute).UnixNano()})
+				Expect(err).To(BeNil())
+				page, err := s.mem.Query(ctx, Query{})
+				Expect(err).To(BeNil())
+				Expect(ids(page)).To(Equal(sessionIds))
+			})
+		})
+	})
+
+	Describe("Batch sessions", func() {
+		// sessionIds outnumber the session ids bound to a single statement
+		sessionIds := make([]string, 600)
+		for i := range sessionIds {
+			sessionIds[i] = "batch-" + strconv.Itoa(i)
+		}
+		BeforeEach(func() {
+			items := make(map[string]models.Item, len(sessionIds))
+			for _, sessionId := range sessionIds {
+				items[sessionId] = models.Item{Oject: []byte(sessionId), Subject: "user-42", Expiration: time.Now().Add(time.Minute).UnixNano()}
+			}
+			Expect(s.mem.CommitBatch(ctx, items)).To(Succeed())
+			Expect(s.mem.Commit(ctx, "expired", []byte("expired"), time.Now().Add(-time.Second))).To(Succeed())
+		})
+
+		Context("CommitBatch()", func() {
+			It("stores every session of the batch", func() {
+				sessionMap, err := s.mem.ListBySubject(ctx, "user-42")
+				Expect(err).To(BeNil())
+				Expect(sessionMap).To(HaveLen(len(sessionIds)))
+				Expect(sessionMap[sessionIds[0]].CreatedAt).ToNot(BeZero())
+			})
+		})
+
+		Context("ResetBatch()", func() {
+			It("extends the live sessions of the batch", func() {
+				expiration := time.Now().Add(time.Hour)
+				expirations := map[string]time.Time{"expired": expiration, "unknown": expiration}
+				for _, sessionId := range sessionIds {
+					expirations[sessionId] = expiration
+				}
+				extended, err := s.mem.ResetBatch(ctx, expirations)
+				Expect(err).To(BeNil())
+				Expect(extended).To(HaveLen(len(sessionIds)))
+
+				item, found, err := s.mem.Lookup(ctx, sessionIds[len(sessionIds)-1])
+				Expect(err).To(BeNil())
+				Expect(found).To(BeTrue())
+				Expect(item.Expiration).To(Equal(expiration.UnixNano()))
+			})
+		})
+
+		Context("DeleteBatch()", func() {
+			It("deletes the sessions of the batch and returns the live ones", func() {
+				deleted, err := s.mem.DeleteBatch(ctx, append([]string{"expired", "unknown"}, sessionIds[1:]...))
+				Expect(err).To(BeNil())
+				Expect(deleted).To(HaveLen(len(sessionIds) - 1))
+				Expect(string(deleted[sessionIds[1]].Oject)).To(Equal(sessionIds[1]))
+
+				var count int
+				Expect(s.db.QueryRow(`SELECT COUNT(*) FROM sessions`).Scan(&count)).To(Succeed())
+				Expect(count).To(Equal(1))
+			})
+		})
+	})
+
+	Describe("Delete Session Expired", func() {
+		var metrics Metrics
+		BeforeEach(func() {
+			metrics = Metrics{
+				ActiveSessions:  generic.NewGauge("active_sessions"),
+				ExpiredSessions: generic.NewCounter("expired_sessions_total"),
+				CleanupSweeps:   generic.NewCounter("cleanup_sweeps_total"),
+			}
+			var err error
+			s.mem, err = NewInstrumentedSQLStore(s.db, "sqlite3", 50*time.Millisecond, metrics, s.logger)
+			Expect(err).To(BeNil())
+
+			live := time.Now().Add(time.Minute)
+			expired := time.Now().Add(-time.Second)
+			for i := 0; i < SweepBatch+10; i++ {
+				sessionId := "expired-" + strconv.Itoa(i)
+				Expect(s.mem.Commit(ctx, sessionId, []byte(sessionId), expired)).To(Succeed())
+			}
+			Expect(s.mem.Commit(ctx, "90660b89-100e-4f8f-9801-2524df6fbe34", nil, live)).To(Succeed())
+			Expect(s.mem.CommitItem(ctx, "idle", models.Item{Expiration: live.UnixNano(), IdleTimeout: int64(time.Millisecond)})).To(Succeed())
+		})
+
+		AfterEach(func() {
+			s.mem.(*SQLStore).StopSessionCleanup()
+		})
+
+		It("deletes the expired sessions in batches", func() {
+			Eventually(metrics.ExpiredSessions.(*generic.Counter).Value).Should(Equal(float64(SweepBatch + 11)))
+			Eventually(metrics.ActiveSessions.(*generic.Gauge).Value).Should(Equal(float64(1)))
+			var count int
+			Expect(s.db.QueryRow(`SELECT COUNT(*) FROM sessions`).Scan(&count)).To(Succeed())
+			Expect(count).To(Equal(1))
+		})
+	})
+	Describe("Health checks", func() {
+		var checkCtx context.Context
+		var cancel context.CancelFunc
+		BeforeEach(func() {
+			var err error
+			s.mem, err = NewSQLStore(s.db, "sqlite3", 10*time.Millisecond, s.logger)
+			Expect(err).To(BeNil())
+			checkCtx, cancel = context.WithTimeout(ctx, 100*time.Millisecond)
+		})
+
+		AfterEach(func() {
+			cancel()
+		})
+
+		Context("Ping()", func() {
+			It("succeeds while the database is reachable", func() {
+				Expect(s.mem.(HealthChecker).Ping(checkCtx)).To(Succeed())
+				s.mem.(*SQLStore).StopSessionCleanup()
+			})
+			It("fails once the database is closed", func() {
+				s.mem.(*SQLStore).StopSessionCleanup()
+				Expect(s.db.Close()).To(Succeed())
+				Expect(s.mem.(HealthChecker).Ping(checkCtx)).ToNot(Succeed())
+			})
+		})
+		Context("CheckCleanup()", func() {
+			It("succeeds while the sweeper goroutine runs", func() {
+				Expect(s.mem.(HealthChecker).CheckCleanup(checkCtx)).To(Succeed())
+				s.mem.(*SQLStore).StopSessionCleanup()
+			})
+			It("fails once the sweeper goroutine stopped", func() {
+				s.mem.(*SQLStore).StopSessionCleanup()
+				Expect(s.mem.(HealthChecker).CheckCleanup(checkCtx)).To(Equal(ErrCleanupStalled))
+			})
+		})
+	})
+	Describe("Close store", func() {
+		Context("Close()", func() {
+			It("stops the sweeper goroutine and leaves the database open", func() {
+				var err error
+				s.mem, err = NewSQLStore(s.db, "sqlite3", 10*time.Millisecond, s.logger)
+				Expect(err).To(BeNil())
+				Expect(s.mem.Close(ctx)).To(Succeed())
+
+				checkCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+				defer cancel()
+				Expect(s.mem.(HealthChecker).CheckCleanup(checkCtx)).To(Equal(ErrCleanupStalled))
+				Expect(s.db.PingContext(ctx)).To(Succeed())
+			})
+		})
+	})
+})
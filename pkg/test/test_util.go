@@ -1,6 +1,7 @@
 package test
 
 import (
+	"encoding/json"
 	"os"
 
 	"github.com/go-kit/kit/log"
@@ -24,8 +25,14 @@ func GetLogger() log.Logger {
 func ConvertMapToList(sessionMap map[string]Item) *Sessions {
 	session := &Sessions{}
 
-	for _, value := range sessionMap {
-		session.List = append(session.List, string(value.Oject))
+	for sessionId := range sessionMap {
+		session.List = append(session.List, sessionId)
 	}
 	return session
 }
+
+// MarshalRecord encodes the record stored for a session with the given data
+func MarshalRecord(sessionId string, data map[string]interface{}) []byte {
+	b, _ := json.Marshal(&Record{SessionId: sessionId, Data: data})
+	return b
+}
@@ -0,0 +1,202 @@
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/log"
+)
+
+var (
+	// ErrNoClientCertificate is returned when a client of a mutual-TLS listener sends no certificate
+	ErrNoClientCertificate = errors.New("client certificate required")
+	// ErrNoCertificates is returned when the CA bundle holds no PEM certificate
+	ErrNoCertificates = errors.New("no certificate found in the CA bundle")
+)
+
+// Reloader holds the certificate of the TLS listeners and, for mutual TLS, the CA bundle
+// verifying the client certificates. The files are polled and reloaded when they change
+// on disk, so a renewed certificate is served without a restart; a file that fails to
+// load is logged and the previous certificates are kept.
+type Reloader struct {
+	logger     log.Logger
+	certFile   string
+	keyFile    string
+	caFile     string
+	mu         sync.RWMutex
+	cert       *tls.Certificate
+	clientCAs  *x509.CertPool
+	modTimes   map[string]time.Time
+	stopReload chan bool
+	stopOnce   sync.Once
+}
+
+// NewReloader returns a Reloader serving the certificate of certFile and keyFile, and
+// requiring client certificates signed by the CA bundle of caFile unless it is empty. The
+// files are checked for changes every reloadInterval; a zero interval disables the reload.
+func NewReloader(certFile, keyFile, caFile string, reloadInterval time.Duration, logger log.Logger) (*Reloader, error) {
+	r := &Reloader{
+		logger:   logger,
+		certFile: certFile,
+		keyFile:  keyFile,
+		caFile:   caFile,
+	}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+
+	if reloadInterval > 0 {
+		r.stopReload = make(chan bool)
+		go r.startReload(reloadInterval)
+	}
+
+	return r, nil
+}
+
+// ServerConfig returns the configuration of a TLS listener serving the current certificate.
+// When a CA bundle is set, the clients must present a certificate it verifies.
+func (r *Reloader) ServerConfig() *tls.Config {
+	config := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: r.getCertificate,
+	}
+	if r.caFile != "" {
+		// The verification is done by verifyClientCertificate rather than by crypto/tls,
+		// whose ClientCAs pool can not be swapped once the listener is started.
+		config.ClientAuth = tls.RequireAnyClientCert
+		config.VerifyPeerCertificate = r.verifyClientCertificate
+	}
+	return config
+}
+
+// StopReload terminates the background reload goroutine for the Reloader instance.
+func (r *Reloader) StopReload() {
+	r.logger.Log("stopReload")
+	if r.stopReload != nil {
+		r.stopOnce.Do(func() { r.stopReload <- true })
+	}
+}
+
+// getCertificate is the tls.Config GetCertificate callback returning the current certificate
+func (r *Reloader) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, nil
+}
+
+// verifyClientCertificate is the tls.Config VerifyPeerCertificate callback verifying the
+// client certificate against the current CA bundle
+func (r *Reloader) verifyClientCertificate(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+	if len(rawCerts) == 0 {
+		return ErrNoClientCertificate
+	}
+	certs := make([]*x509.Certificate, len(rawCerts))
+	for i, raw := range rawCerts {
+		cert, err := x509.ParseCertificate(raw)
+		if err != nil {
+			return err
+		}
+		certs[i] = cert
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+	r.mu.RLock()
+	roots := r.clientCAs
+	r.mu.RUnlock()
+
+	_, err := certs[0].Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+	return err
+}
+
+// startReload reloads the files on every tick when one of them changed
+func (r *Reloader) startReload(interval time.Duration) {
+	r.logger.Log("method", "startReload")
+	ticker := time.NewTicker(interval)
+	for {
+		select {
+		case <-ticker.C:
+			if !r.changed() {
+				continue
+			}
+			if err := r.load(); err != nil {
+				r.logger.Log("method", "reload", "err", err)
+				continue
+			}
+			r.logger.Log("method", "reload", "cert", r.certFile)
+		case <-r.stopReload:
+			ticker.Stop()
+			return
+		}
+	}
+}
+
+// changed reports whether the modification time of one of the files changed since they
+// were loaded
+func (r *Reloader) changed() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for path, modTime := range r.modTimes {
+		info, err := os.Stat(path)
+		if err != nil {
+			r.logger.Log("method", "changed", "err", err)
+			continue
+		}
+		if !info.ModTime().Equal(modTime) {
+			return true
+		}
+	}
+	return false
+}
+
+// load reads the certificate and the CA bundle, replacing the current ones when both
+// load
+func (r *Reloader) load() error {
+	modTimes := make(map[string]time.Time)
+	for _, path := range []string{r.certFile, r.keyFile, r.caFile} {
+		if path == "" {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		modTimes[path] = info.ModTime()
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return err
+	}
+
+	var clientCAs *x509.CertPool
+	if r.caFile != "" {
+		b, err := ioutil.ReadFile(r.caFile)
+		if err != nil {
+			return err
+		}
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(b) {
+			return fmt.Errorf("%s: %w", r.caFile, ErrNoCertificates)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cert = &cert
+	r.clientCAs = clientCAs
+	r.modTimes = modTimes
+	return nil
+}
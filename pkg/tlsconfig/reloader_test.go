@@ -0,0 +1,238 @@
+package tlsconfig_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/hecomp/session-management/pkg/tlsconfig"
+)
+
+// authority is a test certificate authority
+type authority struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+// newAuthority returns a self-signed certificate authority
+func newAuthority(name string) authority {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).To(BeNil())
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	Expect(err).To(BeNil())
+	cert, err := x509.ParseCertificate(der)
+	Expect(err).To(BeNil())
+	return authority{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue returns the PEM certificate and key of a leaf certificate signed by the authority
+func (a authority) issue(subject pkix.Name, usage x509.ExtKeyUsage) ([]byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).To(BeNil())
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      subject,
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, a.cert, &key.PublicKey, a.key)
+	Expect(err).To(BeNil())
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	Expect(err).To(BeNil())
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+var _ = Describe("Reloader", func() {
+
+	var dir, certFile, keyFile, caFile string
+	var serverCA, clientCA authority
+
+	writeFile := func(path string, b []byte) {
+		Expect(ioutil.WriteFile(path, b, 0600)).To(Succeed())
+	}
+
+	writeServerCertificate := func(name string) {
+		cert, key := serverCA.issue(pkix.Name{CommonName: name}, x509.ExtKeyUsageServerAuth)
+		writeFile(certFile, cert)
+		writeFile(keyFile, key)
+	}
+
+	servedName := func(r *Reloader) string {
+		cert, err := r.ServerConfig().GetCertificate(&tls.ClientHelloInfo{})
+		Expect(err).To(BeNil())
+		leaf, err := x509.ParseCertificate(cert.Certificate[0])
+		Expect(err).To(BeNil())
+		return leaf.Subject.CommonName
+	}
+
+	// handshake runs a TLS handshake between a listener of the reloader and a client
+	// presenting the certificate, if any, and returns the error seen by the listener
+	handshake := func(r *Reloader, clientCert []tls.Certificate) error {
+		listener, err := tls.Listen("tcp", "127.0.0.1:0", r.ServerConfig())
+		Expect(err).To(BeNil())
+		defer listener.Close()
+
+		serverErr := make(chan error, 1)
+		go func() {
+			conn, err := listener.Accept()
+			if err != nil {
+				serverErr <- err
+				return
+			}
+			defer conn.Close()
+			serverErr <- conn.(*tls.Conn).Handshake()
+		}()
+
+		roots := x509.NewCertPool()
+		roots.AddCert(serverCA.cert)
+		conn, err := tls.Dial("tcp", listener.Addr().String(), &tls.Config{
+			RootCAs:      roots,
+			ServerName:   "localhost",
+			Certificates: clientCert,
+		})
+		if err == nil {
+			defer conn.Close()
+		}
+		return <-serverErr
+	}
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "tlsconfig")
+		Expect(err).To(BeNil())
+		certFile = filepath.Join(dir, "server.pem")
+		keyFile = filepath.Join(dir, "server.key")
+		caFile = filepath.Join(dir, "clients.pem")
+
+		serverCA = newAuthority("server CA")
+		clientCA = newAuthority("client CA")
+		writeServerCertificate("first")
+		writeFile(caFile, clientCA.pem)
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	Context("NewReloader()", func() {
+		It("serves the certificate", func() {
+			r, err := NewReloader(certFile, keyFile, "", 0, log.NewNopLogger())
+			Expect(err).To(BeNil())
+			Expect(servedName(r)).To(Equal("first"))
+			Expect(handshake(r, nil)).To(Succeed())
+		})
+		It("refuses a missing or invalid file", func() {
+			_, err := NewReloader(filepath.Join(dir, "missing.pem"), keyFile, "", 0, log.NewNopLogger())
+			Expect(err).ToNot(BeNil())
+			_, err = NewReloader(certFile, certFile, "", 0, log.NewNopLogger())
+			Expect(err).ToNot(BeNil())
+			writeFile(caFile, []byte("not a certificate"))
+			_, err = NewReloader(certFile, keyFile, caFile, 0, log.NewNopLogger())
+			Expect(err).To(MatchError(ContainSubstring(ErrNoCertificates.Error())))
+		})
+	})
+
+	Context("ServerConfig()", func() {
+		var r *Reloader
+
+		BeforeEach(func() {
+			var err error
+			r, err = NewReloader(certFile, keyFile, caFile, 0, log.NewNopLogger())
+			Expect(err).To(BeNil())
+		})
+
+		clientCertificate := func(ca authority, usage x509.ExtKeyUsage) []tls.Certificate {
+			certPEM, keyPEM := ca.issue(pkix.Name{CommonName: "backoffice"}, usage)
+			cert, err := tls.X509KeyPair(certPEM, keyPEM)
+			Expect(err).To(BeNil())
+			return []tls.Certificate{cert}
+		}
+
+		It("accepts a client certificate signed by the client CA bundle", func() {
+			Expect(handshake(r, clientCertificate(clientCA, x509.ExtKeyUsageClientAuth))).To(Succeed())
+		})
+		It("refuses a client without a certificate", func() {
+			Expect(handshake(r, nil)).ToNot(Succeed())
+		})
+		It("refuses a client certificate signed by another CA", func() {
+			Expect(handshake(r, clientCertificate(serverCA, x509.ExtKeyUsageClientAuth))).ToNot(Succeed())
+		})
+		It("refuses a certificate not meant for client authentication", func() {
+			Expect(handshake(r, clientCertificate(clientCA, x509.ExtKeyUsageServerAuth))).ToNot(Succeed())
+		})
+	})
+
+	Context("reload", func() {
+		var r *Reloader
+
+		BeforeEach(func() {
+			var err error
+			r, err = NewReloader(certFile, keyFile, caFile, 10*time.Millisecond, log.NewNopLogger())
+			Expect(err).To(BeNil())
+		})
+
+		AfterEach(func() {
+			r.StopReload()
+		})
+
+		// touch moves the modification time of the files forward, the rewrites of a test
+		// may fall within the resolution of the file system clock
+		touch := func(paths ...string) {
+			later := time.Now().Add(time.Minute)
+			for _, path := range paths {
+				Expect(os.Chtimes(path, later, later)).To(Succeed())
+			}
+		}
+
+		It("serves the new certificate once the files change", func() {
+			writeServerCertificate("second")
+			touch(certFile, keyFile)
+			Eventually(func() string { return servedName(r) }).Should(Equal("second"))
+		})
+		It("verifies the client certificates against the new CA bundle", func() {
+			newClientCA := newAuthority("new client CA")
+			writeFile(caFile, newClientCA.pem)
+			touch(caFile)
+			certPEM, keyPEM := newClientCA.issue(pkix.Name{CommonName: "backoffice"}, x509.ExtKeyUsageClientAuth)
+			cert, err := tls.X509KeyPair(certPEM, keyPEM)
+			Expect(err).To(BeNil())
+			Eventually(func() error { return handshake(r, []tls.Certificate{cert}) }).Should(Succeed())
+		})
+		It("keeps the certificate when the new files do not load", func() {
+			writeFile(certFile, []byte("not a certificate"))
+			touch(certFile)
+			Consistently(func() string { return servedName(r) }, 100*time.Millisecond).Should(Equal("first"))
+		})
+		It("stops more than once", func() {
+			r.StopReload()
+			r.StopReload()
+		})
+	})
+})
@@ -0,0 +1,290 @@
+package token
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// algorithm is the only JWS algorithm issued and accepted, ECDSA P-256 with SHA-256
+const algorithm = "ES256"
+
+// coordinateSize is the number of bytes of a P-256 coordinate and of each half of a signature
+const coordinateSize = 32
+
+var (
+	// ErrInvalidJWT is returned when a JWT is malformed, not signed by a known key or issued by someone else
+	ErrInvalidJWT = errors.New("invalid jwt")
+	// ErrExpiredJWT is returned when a JWT is past its exp claim
+	ErrExpiredJWT = errors.New("expired jwt")
+)
+
+// JWTKey is a named ECDSA P-256 key. The primary key of a JWTIssuer needs its private key,
+// the keys that are only accepted may carry the public key alone.
+type JWTKey struct {
+	Id         string
+	PrivateKey *ecdsa.PrivateKey
+	PublicKey  *ecdsa.PublicKey
+}
+
+// Claims are the claims of a session JWT
+type Claims struct {
+	Issuer    string `json:"iss,omitempty"`
+	Subject   string `json:"sub,omitempty"`
+	SessionId string `json:"jti"`
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// JWK is the public key of a JWTKey in the JSON Web Key format
+type JWK struct {
+	Kty string `json:"kty"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+}
+
+// JWKS is the JSON Web Key Set served to the verifiers of the JWTs
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// jwtHeader is the JOSE header of a session JWT
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Typ string `json:"typ"`
+	Kid string `json:"kid"`
+}
+
+// JWTIssuer issues session JWTs signed with ES256 by its primary key and parses the JWTs
+// signed by any of its keys. Like the KeyRing, a key is rotated by making a new key
+// primary while the old one stays accepted until the JWTs it signed expired.
+type JWTIssuer struct {
+	issuer  string
+	primary JWTKey
+	keys    map[string]*ecdsa.PublicKey
+}
+
+// NewJWTIssuer returns a JWT issuer setting the iss claim to issuer, signing with the
+// primary key and accepting the signatures of the primary and the other keys.
+func NewJWTIssuer(issuer string, primary JWTKey, accepted ...JWTKey) (*JWTIssuer, error) {
+	if primary.PrivateKey == nil {
+		return nil, fmt.Errorf("primary key %q has no private key", primary.Id)
+	}
+
+	j := &JWTIssuer{issuer: issuer, primary: primary, keys: make(map[string]*ecdsa.PublicKey)}
+	for _, key := range append([]JWTKey{primary}, accepted...) {
+		public := key.PublicKey
+		if key.PrivateKey != nil {
+			public = &key.PrivateKey.PublicKey
+		}
+		if public == nil || public.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("key %q is not a P-256 key", key.Id)
+		}
+		j.keys[key.Id] = public
+	}
+	return j, nil
+}
+
+// LoadJWTIssuer reads the keys of a JWT issuer from a JSON file of the form
+//
+//	{"primary": "2021-07", "keys": {"2021-07": "2021-07.pem", "2021-01": "2021-01.pub.pem"}}
+//
+// naming PEM files relative to the directory of the JSON file. The primary key must be a
+// private key, the other keys may be public keys.
+func LoadJWTIssuer(issuer string, path string) (*JWTIssuer, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file keyRingFile
+	if err := json.Unmarshal(b, &file); err != nil {
+		return nil, err
+	}
+
+	var primary JWTKey
+	var accepted []JWTKey
+	for id, name := range file.Keys {
+		if !filepath.IsAbs(name) {
+			name = filepath.Join(filepath.Dir(path), name)
+		}
+		key, err := loadJWTKey(id, name)
+		if err != nil {
+			return nil, err
+		}
+		if id == file.Primary {
+			primary = key
+			continue
+		}
+		accepted = append(accepted, key)
+	}
+	if primary.Id == "" {
+		return nil, ErrNoPrimaryKey
+	}
+	return NewJWTIssuer(issuer, primary, accepted...)
+}
+
+// Issue returns the JWT of the claims signed with the primary key. The iss claim is set to
+// the issuer of the JWTIssuer and iat defaults to now.
+func (j *JWTIssuer) Issue(claims Claims) (string, error) {
+	claims.Issuer = j.issuer
+	if claims.IssuedAt == 0 {
+		claims.IssuedAt = time.Now().Unix()
+	}
+
+	header, err := json.Marshal(jwtHeader{Alg: algorithm, Typ: "JWT", Kid: j.primary.Id})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := encode(header) + "." + encode(payload)
+	digest := sha256.Sum256([]byte(signingInput))
+	r, s, err := ecdsa.Sign(rand.Reader, j.primary.PrivateKey, digest[:])
+	if err != nil {
+		return "", err
+	}
+
+	sig := make([]byte, 2*coordinateSize)
+	r.FillBytes(sig[:coordinateSize])
+	s.FillBytes(sig[coordinateSize:])
+	return signingInput + "." + encode(sig), nil
+}
+
+// Parse returns the claims of a JWT signed by one of the keys of the issuer. It returns
+// ErrExpiredJWT when the JWT is valid but past its exp claim at now.
+func (j *JWTIssuer) Parse(token string, now time.Time) (Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return Claims{}, ErrInvalidJWT
+	}
+
+	var header jwtHeader
+	if err := decodeJSON(parts[0], &header); err != nil || header.Alg != algorithm {
+		return Claims{}, ErrInvalidJWT
+	}
+	public, found := j.keys[header.Kid]
+	if !found {
+		return Claims{}, ErrInvalidJWT
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || len(sig) != 2*coordinateSize {
+		return Claims{}, ErrInvalidJWT
+	}
+	r := new(big.Int).SetBytes(sig[:coordinateSize])
+	s := new(big.Int).SetBytes(sig[coordinateSize:])
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if !ecdsa.Verify(public, digest[:], r, s) {
+		return Claims{}, ErrInvalidJWT
+	}
+
+	var claims Claims
+	if err := decodeJSON(parts[1], &claims); err != nil {
+		return Claims{}, ErrInvalidJWT
+	}
+	if claims.Issuer != j.issuer || claims.SessionId == "" {
+		return Claims{}, ErrInvalidJWT
+	}
+	if now.Unix() >= claims.ExpiresAt {
+		return claims, ErrExpiredJWT
+	}
+	return claims, nil
+}
+
+// JWKS returns the public keys of the issuer, sorted by key id
+func (j *JWTIssuer) JWKS() JWKS {
+	ids := make([]string, 0, len(j.keys))
+	for id := range j.keys {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	jwks := JWKS{Keys: make([]JWK, 0, len(ids))}
+	for _, id := range ids {
+		public := j.keys[id]
+		x := make([]byte, coordinateSize)
+		y := make([]byte, coordinateSize)
+		public.X.FillBytes(x)
+		public.Y.FillBytes(y)
+		jwks.Keys = append(jwks.Keys, JWK{
+			Kty: "EC",
+			Crv: "P-256",
+			X:   encode(x),
+			Y:   encode(y),
+			Kid: id,
+			Use: "sig",
+			Alg: algorithm,
+		})
+	}
+	return jwks
+}
+
+// loadJWTKey reads an EC private key, a PKCS #8 private key or a PKIX public key from a PEM file
+func loadJWTKey(id string, path string) (JWTKey, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return JWTKey{}, err
+	}
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return JWTKey{}, fmt.Errorf("key %q: no PEM block in %s", id, path)
+	}
+
+	var parsed interface{}
+	switch block.Type {
+	case "EC PRIVATE KEY":
+		parsed, err = x509.ParseECPrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		err = fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return JWTKey{}, fmt.Errorf("key %q: %v", id, err)
+	}
+
+	switch key := parsed.(type) {
+	case *ecdsa.PrivateKey:
+		return JWTKey{Id: id, PrivateKey: key}, nil
+	case *ecdsa.PublicKey:
+		return JWTKey{Id: id, PublicKey: key}, nil
+	}
+	return JWTKey{}, fmt.Errorf("key %q is not an ECDSA key", id)
+}
+
+// encode returns the unpadded base64url encoding used by JWTs
+func encode(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// decodeJSON decodes a base64url encoded JWT segment into v
+func decodeJSON(segment string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
@@ -0,0 +1,201 @@
+package token_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/hecomp/session-management/pkg/token"
+)
+
+type JWTIssuerSuite struct {
+	oldKey JWTKey
+	newKey JWTKey
+	issuer *JWTIssuer
+	claims Claims
+	dir    string
+}
+
+var _ = Describe("JWTIssuer", func() {
+
+	s := &JWTIssuerSuite{}
+
+	generate := func(id string) JWTKey {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).To(BeNil())
+		return JWTKey{Id: id, PrivateKey: key}
+	}
+
+	segment := func(v interface{}) string {
+		b, err := json.Marshal(v)
+		Expect(err).To(BeNil())
+		return base64.RawURLEncoding.EncodeToString(b)
+	}
+
+	BeforeEach(func() {
+		var err error
+		s.oldKey = generate("2021-01")
+		s.newKey = generate("2021-07")
+		s.issuer, err = NewJWTIssuer("session-management", s.newKey)
+		Expect(err).To(BeNil())
+		s.claims = Claims{SessionId: sessionId, Subject: "user-42", ExpiresAt: time.Now().Add(time.Minute).Unix()}
+		s.dir, err = ioutil.TempDir("", "jwt")
+		Expect(err).To(BeNil())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(s.dir)
+	})
+
+	Context("Issue() and Parse()", func() {
+		It("round trips the claims", func() {
+			jwt, err := s.issuer.Issue(s.claims)
+			Expect(err).To(BeNil())
+			claims, err := s.issuer.Parse(jwt, time.Now())
+			Expect(err).To(BeNil())
+			Expect(claims.SessionId).To(Equal(sessionId))
+			Expect(claims.Subject).To(Equal("user-42"))
+			Expect(claims.Issuer).To(Equal("session-management"))
+			Expect(claims.ExpiresAt).To(Equal(s.claims.ExpiresAt))
+			Expect(claims.IssuedAt).ToNot(BeZero())
+		})
+		It("rejects expired JWTs", func() {
+			jwt, err := s.issuer.Issue(s.claims)
+			Expect(err).To(BeNil())
+			_, err = s.issuer.Parse(jwt, time.Unix(s.claims.ExpiresAt, 0))
+			Expect(err).To(Equal(ErrExpiredJWT))
+		})
+		It("rejects tampered claims", func() {
+			jwt, err := s.issuer.Issue(s.claims)
+			Expect(err).To(BeNil())
+			parts := strings.Split(jwt, ".")
+			forged := s.claims
+			forged.Issuer = "session-management"
+			forged.Subject = "admin"
+			_, err = s.issuer.Parse(parts[0]+"."+segment(forged)+"."+parts[2], time.Now())
+			Expect(err).To(Equal(ErrInvalidJWT))
+		})
+		It("rejects unsigned JWTs", func() {
+			header := segment(map[string]string{"alg": "none", "typ": "JWT", "kid": "2021-07"})
+			_, err := s.issuer.Parse(header+"."+segment(s.claims)+".", time.Now())
+			Expect(err).To(Equal(ErrInvalidJWT))
+		})
+		It("rejects malformed JWTs", func() {
+			for _, jwt := range []string{"", sessionId, "a.b", "a.b.c.d", "!.!.!"} {
+				_, err := s.issuer.Parse(jwt, time.Now())
+				Expect(err).To(Equal(ErrInvalidJWT), jwt)
+			}
+		})
+		It("rejects JWTs of another issuer", func() {
+			other, err := NewJWTIssuer("someone-else", s.newKey)
+			Expect(err).To(BeNil())
+			jwt, err := other.Issue(s.claims)
+			Expect(err).To(BeNil())
+			_, err = s.issuer.Parse(jwt, time.Now())
+			Expect(err).To(Equal(ErrInvalidJWT))
+		})
+		It("accepts the JWTs of old keys after a rotation and rejects unknown keys", func() {
+			old, err := NewJWTIssuer("session-management", s.oldKey)
+			Expect(err).To(BeNil())
+			jwt, err := old.Issue(s.claims)
+			Expect(err).To(BeNil())
+
+			_, err = s.issuer.Parse(jwt, time.Now())
+			Expect(err).To(Equal(ErrInvalidJWT))
+
+			rotated, err := NewJWTIssuer("session-management", s.newKey, JWTKey{Id: s.oldKey.Id, PublicKey: &s.oldKey.PrivateKey.PublicKey})
+			Expect(err).To(BeNil())
+			claims, err := rotated.Parse(jwt, time.Now())
+			Expect(err).To(BeNil())
+			Expect(claims.SessionId).To(Equal(sessionId))
+		})
+	})
+
+	Context("NewJWTIssuer()", func() {
+		It("needs the private key of the primary key", func() {
+			_, err := NewJWTIssuer("session-management", JWTKey{Id: "2021-07", PublicKey: &s.newKey.PrivateKey.PublicKey})
+			Expect(err).ToNot(BeNil())
+		})
+		It("rejects keys of other curves", func() {
+			key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+			Expect(err).To(BeNil())
+			_, err = NewJWTIssuer("session-management", JWTKey{Id: "p384", PrivateKey: key})
+			Expect(err).ToNot(BeNil())
+		})
+	})
+
+	Context("JWKS()", func() {
+		It("publishes the public keys verifying the JWTs", func() {
+			issuer, err := NewJWTIssuer("session-management", s.newKey, s.oldKey)
+			Expect(err).To(BeNil())
+			jwks := issuer.JWKS()
+			Expect(jwks.Keys).To(HaveLen(2))
+			Expect(jwks.Keys[0].Kid).To(Equal("2021-01"))
+			Expect(jwks.Keys[1].Kid).To(Equal("2021-07"))
+
+			jwk := jwks.Keys[1]
+			Expect(jwk.Kty).To(Equal("EC"))
+			Expect(jwk.Crv).To(Equal("P-256"))
+			Expect(jwk.Alg).To(Equal("ES256"))
+			x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+			Expect(err).To(BeNil())
+			y, err := base64.RawURLEncoding.DecodeString(jwk.Y)
+			Expect(err).To(BeNil())
+			Expect(new(big.Int).SetBytes(x)).To(Equal(s.newKey.PrivateKey.X))
+			Expect(new(big.Int).SetBytes(y)).To(Equal(s.newKey.PrivateKey.Y))
+		})
+	})
+
+	Context("LoadJWTIssuer()", func() {
+		writePEM := func(name, blockType string, der []byte) {
+			b := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+			Expect(ioutil.WriteFile(filepath.Join(s.dir, name), b, 0600)).To(Succeed())
+		}
+
+		It("loads a private primary key and a public accepted key", func() {
+			der, err := x509.MarshalECPrivateKey(s.newKey.PrivateKey)
+			Expect(err).To(BeNil())
+			writePEM("2021-07.pem", "EC PRIVATE KEY", der)
+			der, err = x509.MarshalPKIXPublicKey(&s.oldKey.PrivateKey.PublicKey)
+			Expect(err).To(BeNil())
+			writePEM("2021-01.pub.pem", "PUBLIC KEY", der)
+			path := filepath.Join(s.dir, "jwt-keys.json")
+			Expect(ioutil.WriteFile(path, []byte(`{"primary": "2021-07", "keys": {"2021-07": "2021-07.pem", "2021-01": "2021-01.pub.pem"}}`), 0600)).To(Succeed())
+
+			issuer, err := LoadJWTIssuer("session-management", path)
+			Expect(err).To(BeNil())
+			Expect(issuer.JWKS().Keys).To(HaveLen(2))
+
+			jwt, err := issuer.Issue(s.claims)
+			Expect(err).To(BeNil())
+			_, err = s.issuer.Parse(jwt, time.Now())
+			Expect(err).To(BeNil())
+		})
+		It("fails without the primary key", func() {
+			path := filepath.Join(s.dir, "jwt-keys.json")
+			Expect(ioutil.WriteFile(path, []byte(`{"primary": "2021-07", "keys": {}}`), 0600)).To(Succeed())
+			_, err := LoadJWTIssuer("session-management", path)
+			Expect(err).To(Equal(ErrNoPrimaryKey))
+		})
+		It("fails on files that are not PEM", func() {
+			Expect(ioutil.WriteFile(filepath.Join(s.dir, "2021-07.pem"), []byte("not pem"), 0600)).To(Succeed())
+			path := filepath.Join(s.dir, "jwt-keys.json")
+			Expect(ioutil.WriteFile(path, []byte(`{"primary": "2021-07", "keys": {"2021-07": "2021-07.pem"}}`), 0600)).To(Succeed())
+			_, err := LoadJWTIssuer("session-management", path)
+			Expect(err).ToNot(BeNil())
+		})
+	})
+})
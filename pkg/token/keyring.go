@@ -0,0 +1,116 @@
+package token
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+)
+
+// MinKeySize is the minimum number of bytes of a signing key
+const MinKeySize = 32
+
+// separator splits the session id from its signature in a token
+const separator = "."
+
+var (
+	// ErrNoPrimaryKey is returned when the primary key of a key ring is not one of its keys
+	ErrNoPrimaryKey = errors.New("primary key not found in the key ring")
+)
+
+// Key is a named HMAC-SHA256 signing key
+type Key struct {
+	Id     string
+	Secret []byte
+}
+
+// KeyRing signs session ids with its primary key and accepts the signatures of any of its
+// keys, so a key can be rotated by making a new key primary while keeping the old one
+// until the tokens it signed expired.
+type KeyRing struct {
+	primary Key
+	keys    []Key
+}
+
+// keyRingFile is the JSON layout of the key files, naming the primary key and every key
+type keyRingFile struct {
+	Primary string            `json:"primary"`
+	Keys    map[string]string `json:"keys"`
+}
+
+// NewKeyRing returns a key ring signing with the primary key and accepting the signatures
+// of the primary and the other keys.
+func NewKeyRing(primary Key, accepted ...Key) (*KeyRing, error) {
+	for _, key := range append([]Key{primary}, accepted...) {
+		if len(key.Secret) < MinKeySize {
+			return nil, fmt.Errorf("key %q is shorter than %d bytes", key.Id, MinKeySize)
+		}
+	}
+	return &KeyRing{primary: primary, keys: append([]Key{primary}, accepted...)}, nil
+}
+
+// LoadKeyRing reads a key ring from a JSON file of the form
+//
+//	{"primary": "2021-07", "keys": {"2021-07": "<base64>", "2021-01": "<base64>"}}
+func LoadKeyRing(path string) (*KeyRing, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file keyRingFile
+	if err := json.Unmarshal(b, &file); err != nil {
+		return nil, err
+	}
+
+	var primary Key
+	var accepted []Key
+	for id, encoded := range file.Keys {
+		secret, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %v", id, err)
+		}
+		if id == file.Primary {
+			primary = Key{Id: id, Secret: secret}
+			continue
+		}
+		accepted = append(accepted, Key{Id: id, Secret: secret})
+	}
+	if primary.Id == "" {
+		return nil, ErrNoPrimaryKey
+	}
+	return NewKeyRing(primary, accepted...)
+}
+
+// Sign returns the token id.signature of the session id, signed with the primary key
+func (k *KeyRing) Sign(sessionId string) string {
+	return sessionId + separator + signature(k.primary, sessionId)
+}
+
+// Verify returns the session id of the token when it is signed by one of the keys of
+// the key ring. Signatures are compared in constant time.
+func (k *KeyRing) Verify(token string) (string, bool) {
+	i := strings.LastIndex(token, separator)
+	if i <= 0 {
+		return "", false
+	}
+	sessionId, sig := token[:i], token[i+len(separator):]
+
+	for _, key := range k.keys {
+		if hmac.Equal([]byte(sig), []byte(signature(key, sessionId))) {
+			return sessionId, true
+		}
+	}
+	return "", false
+}
+
+// signature returns the base64url encoded HMAC-SHA256 of the session id
+func signature(key Key, sessionId string) string {
+	mac := hmac.New(sha256.New, key.Secret)
+	mac.Write([]byte(sessionId))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
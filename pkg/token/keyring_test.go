@@ -0,0 +1,129 @@
+package token_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/hecomp/session-management/pkg/token"
+)
+
+const sessionId = "90660b89-100e-4f8f-9801-2524df6fbe34"
+
+type KeyRingSuite struct {
+	oldKey Key
+	newKey Key
+	dir    string
+}
+
+var _ = Describe("KeyRing", func() {
+
+	s := &KeyRingSuite{}
+
+	BeforeEach(func() {
+		var err error
+		s.oldKey = Key{Id: "2021-01", Secret: bytes.Repeat([]byte{1}, MinKeySize)}
+		s.newKey = Key{Id: "2021-07", Secret: bytes.Repeat([]byte{2}, MinKeySize)}
+		s.dir, err = ioutil.TempDir("", "token")
+		Expect(err).To(BeNil())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(s.dir)
+	})
+
+	Context("Sign() and Verify()", func() {
+		It("round trips a session id", func() {
+			ring, err := NewKeyRing(s.newKey)
+			Expect(err).To(BeNil())
+			t := ring.Sign(sessionId)
+			Expect(t).To(HavePrefix(sessionId + "."))
+			id, ok := ring.Verify(t)
+			Expect(ok).To(BeTrue())
+			Expect(id).To(Equal(sessionId))
+		})
+		It("rejects tampered tokens", func() {
+			ring, err := NewKeyRing(s.newKey)
+			Expect(err).To(BeNil())
+			t := ring.Sign(sessionId)
+			for _, tampered := range []string{
+				"",
+				sessionId,
+				sessionId + ".",
+				"." + strings.SplitN(t, ".", 2)[1],
+				strings.Replace(t, "9066", "9067", 1),
+				t[:len(t)-1],
+			} {
+				_, ok := ring.Verify(tampered)
+				Expect(ok).To(BeFalse(), tampered)
+			}
+		})
+		It("accepts the tokens of old keys after a rotation", func() {
+			old, err := NewKeyRing(s.oldKey)
+			Expect(err).To(BeNil())
+			rotated, err := NewKeyRing(s.newKey, s.oldKey)
+			Expect(err).To(BeNil())
+			id, ok := rotated.Verify(old.Sign(sessionId))
+			Expect(ok).To(BeTrue())
+			Expect(id).To(Equal(sessionId))
+			Expect(rotated.Sign(sessionId)).ToNot(Equal(old.Sign(sessionId)))
+		})
+		It("rejects the tokens of retired keys", func() {
+			old, err := NewKeyRing(s.oldKey)
+			Expect(err).To(BeNil())
+			retired, err := NewKeyRing(s.newKey)
+			Expect(err).To(BeNil())
+			_, ok := retired.Verify(old.Sign(sessionId))
+			Expect(ok).To(BeFalse())
+		})
+	})
+
+	Context("NewKeyRing()", func() {
+		It("rejects short keys", func() {
+			_, err := NewKeyRing(Key{Id: "short", Secret: []byte("secret")})
+			Expect(err).ToNot(BeNil())
+		})
+	})
+
+	Context("LoadKeyRing()", func() {
+		write := func(content string) string {
+			path := filepath.Join(s.dir, "keys.json")
+			Expect(ioutil.WriteFile(path, []byte(content), 0600)).To(Succeed())
+			return path
+		}
+		encode := func(key Key) string {
+			return base64.StdEncoding.EncodeToString(key.Secret)
+		}
+
+		It("loads the primary and the accepted keys", func() {
+			path := write(`{"primary": "2021-07", "keys": {"2021-07": "` + encode(s.newKey) + `", "2021-01": "` + encode(s.oldKey) + `"}}`)
+			ring, err := LoadKeyRing(path)
+			Expect(err).To(BeNil())
+			expected, _ := NewKeyRing(s.newKey)
+			Expect(ring.Sign(sessionId)).To(Equal(expected.Sign(sessionId)))
+			old, _ := NewKeyRing(s.oldKey)
+			_, ok := ring.Verify(old.Sign(sessionId))
+			Expect(ok).To(BeTrue())
+		})
+		It("fails without the primary key", func() {
+			path := write(`{"primary": "2021-12", "keys": {"2021-07": "` + encode(s.newKey) + `"}}`)
+			_, err := LoadKeyRing(path)
+			Expect(err).To(Equal(ErrNoPrimaryKey))
+		})
+		It("fails on keys that are not base64", func() {
+			path := write(`{"primary": "2021-07", "keys": {"2021-07": "not base64!"}}`)
+			_, err := LoadKeyRing(path)
+			Expect(err).ToNot(BeNil())
+		})
+		It("fails on a missing file", func() {
+			_, err := LoadKeyRing(filepath.Join(s.dir, "missing.json"))
+			Expect(err).ToNot(BeNil())
+		})
+	})
+})